@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"log"
+	"os"
 
 	"inbox451/internal/config"
+	"inbox451/internal/core"
+	"inbox451/internal/models"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -42,6 +48,82 @@ func install(db *sqlx.DB, config *config.Config, prompt, idempotent bool) {
 			log.Fatalf("Error recording migration version %s: %v", m.version, err)
 		}
 	}
+
+	if err := installAdmin(db, config); err != nil {
+		logger.Fatalf("Error creating admin user: %v", err)
+	}
+}
+
+// installAdmin creates the first admin user and an API token for it, without
+// them nobody could authenticate against the API. The user can be set with
+// INBOX451_ADMIN_USERNAME, INBOX451_ADMIN_EMAIL and INBOX451_ADMIN_PASSWORD,
+// a random password is generated when none is given.
+func installAdmin(db *sqlx.DB, config *config.Config) error {
+	c, err := core.NewCore(config, db, version, commit, date)
+	if err != nil {
+		return err
+	}
+
+	password := os.Getenv("INBOX451_ADMIN_PASSWORD")
+	if password == "" {
+		if password, err = randomPassword(); err != nil {
+			return err
+		}
+	}
+
+	user := &models.User{
+		Name:          "Administrator",
+		Username:      envOrDefault("INBOX451_ADMIN_USERNAME", "admin"),
+		Password:      password,
+		Email:         envOrDefault("INBOX451_ADMIN_EMAIL", "admin@localhost"),
+		Status:        "active",
+		Role:          "admin",
+		PasswordLogin: true,
+	}
+
+	ctx := core.WithSystemContext(context.Background())
+	if err := c.UserService.Create(ctx, user); err != nil {
+		return err
+	}
+
+	token, err := c.TokenService.CreateForUser(ctx, user.ID, &models.Token{Name: "Install token"})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created admin user %q with password: %s\n", user.Username, password)
+	fmt.Printf("API token (send as \"Authorization: Bearer <token>\"): %s\n", token.Token)
+	return nil
+}
+
+// ensureAdmin creates an admin user like --install when the database has
+// none
+func ensureAdmin(db *sqlx.DB, config *config.Config) error {
+	var exists bool
+	if err := db.Get(&exists, `SELECT EXISTS (SELECT 1 FROM users WHERE role = 'admin')`); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	log.Printf("no admin user found, creating one")
+	return installAdmin(db, config)
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
 func checkSchema(db *sqlx.DB) (bool, error) {
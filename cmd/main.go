@@ -71,19 +71,37 @@ type ServerInstance struct {
 }
 
 func startServers(core *core.Core) error {
-	// Create error channel for servers
-	errChan := make(chan serverError, 3)
-
 	// Create a channel to listen for interrupt signals
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	smtpServer, err := smtp.NewServer(core)
+	if err != nil {
+		return err
+	}
+	imapServer, err := imap.NewServer(core)
+	if err != nil {
+		return err
+	}
+
 	// Initialize all servers
 	servers := []ServerInstance{
 		{server: api.NewServer(core), name: "HTTP"},
-		{server: smtp.NewServer(core), name: "SMTP"},
-		{server: imap.NewServer(core), name: "IMAP"},
+		{server: smtpServer, name: "SMTP"},
+		{server: imapServer, name: "IMAP"},
+		{server: core.SessionReaper, name: "Session reaper"},
+		{server: core.WebhookDispatcher, name: "Webhooks"},
+		{server: core.RetentionWorker, name: "Retention"},
+	}
+	if core.EventBridge != nil {
+		servers = append(servers, ServerInstance{server: core.EventBridge, name: "Events"})
 	}
+	if core.Relay != nil {
+		servers = append(servers, ServerInstance{server: core.OutboundDispatcher, name: "Outbound"})
+	}
+
+	// Create error channel for servers
+	errChan := make(chan serverError, len(servers))
 
 	// Start all servers
 	for _, s := range servers {
@@ -162,6 +180,7 @@ func initFlags() *koanf.Koanf {
 	f.Bool("install", false, "setup database (first time)")
 	f.Bool("upgrade", false, "upgrade database to the current version")
 	f.Bool("yes", false, "assume 'yes' to prompts during --install/upgrade")
+	f.Bool("create-admin", false, "create an admin user and an API token for it")
 
 	if err := f.Parse(os.Args[1:]); err != nil {
 		logger.Fatalf("error loading flags: %v", err)
@@ -217,6 +236,13 @@ func main() {
 	// Check DB migrations and up-to-date
 	checkUpgrade(db)
 
+	if ko.Bool("create-admin") {
+		if err := installAdmin(db, cfg); err != nil {
+			logger.Fatalf("Error creating admin user: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	// Create core
 	core, err := core.NewCore(cfg, db, version, commit, date)
 	if err != nil {
@@ -20,6 +20,8 @@ type migFunc struct {
 
 var migList = []migFunc{
 	{"v0.1.0", migrations.V0_1_0},
+	{"v0.2.0", migrations.V0_2_0},
+	{"v0.3.0", migrations.V0_3_0},
 }
 
 func upgrade(db *sqlx.DB, config *config.Config, prompt bool) {
@@ -43,7 +45,6 @@ func upgrade(db *sqlx.DB, config *config.Config, prompt bool) {
 
 	if len(toRun) == 0 {
 		logger.Printf("no upgrades to run. Database is up to date.")
-		return
 	}
 
 	for _, m := range toRun {
@@ -57,7 +58,15 @@ func upgrade(db *sqlx.DB, config *config.Config, prompt bool) {
 		}
 	}
 
-	log.Printf("upgrade complete")
+	// Databases installed before API authentication have no admin to log
+	// in with, this also covers those already upgraded
+	if err := ensureAdmin(db, config); err != nil {
+		logger.Fatalf("error creating admin user: %v", err)
+	}
+
+	if len(toRun) > 0 {
+		log.Printf("upgrade complete")
+	}
 }
 
 func checkUpgrade(db *sqlx.DB) {
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) getAttachments(c echo.Context) error {
+	messageID, _ := strconv.Atoi(c.Param("messageId"))
+
+	attachments, err := s.core.AttachmentService.ListByMessage(c.Request().Context(), messageID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, attachments)
+}
+
+func (s *Server) downloadAttachment(c echo.Context) error {
+	messageID, _ := strconv.Atoi(c.Param("messageId"))
+	attachmentID, _ := strconv.Atoi(c.Param("attachmentId"))
+
+	attachment, err := s.core.AttachmentService.Get(c.Request().Context(), messageID, attachmentID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})
+	c.Response().Header().Set(echo.HeaderContentDisposition, disposition)
+	return c.Blob(http.StatusOK, attachment.ContentType, attachment.Content)
+}
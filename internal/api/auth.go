@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"inbox451/internal/core"
+	"inbox451/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// POST /auth/login
+//
+// login starts a session for the web UI. The session ID is only handed out in
+// an HttpOnly cookie, so scripts running in the browser never see it.
+func (s *Server) login(c echo.Context) error {
+	type LoginInput struct {
+		Username string `json:"username" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	input := new(LoginInput)
+	if err := c.Bind(input); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := c.Validate(input); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	session, user, err := s.core.SessionService.Login(c.Request().Context(),
+		input.Username, input.Password, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	c.SetCookie(s.sessionCookie(session.SessionID, session.ExpiresAt))
+	return c.JSON(http.StatusOK, user)
+}
+
+// POST /auth/logout
+func (s *Server) logout(c echo.Context) error {
+	if cookie, err := c.Cookie(middleware.SessionCookieName); err == nil {
+		if err := s.core.SessionService.Logout(c.Request().Context(), cookie.Value); err != nil {
+			return s.core.HandleError(err, http.StatusInternalServerError)
+		}
+	}
+
+	c.SetCookie(s.sessionCookie("", time.Unix(0, 0)))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GET /auth/me
+func (s *Server) me(c echo.Context) error {
+	user, ok := core.UserFromContext(c.Request().Context())
+	if !ok {
+		return s.core.HandleError(core.ErrUnauthorized, http.StatusUnauthorized)
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+func (s *Server) sessionCookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   !s.core.Config.Server.HTTP.InsecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) createDomain(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+	var domain models.Domain
+	if err := c.Bind(&domain); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	domain.ProjectID = projectID
+
+	if err := c.Validate(&domain); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := s.core.DomainService.Create(c.Request().Context(), &domain); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, domain)
+}
+
+func (s *Server) getDomains(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+
+	var query models.PaginationQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.DomainService.ListByProject(c.Request().Context(), projectID, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getDomain(c echo.Context) error {
+	domainID, _ := strconv.Atoi(c.Param("domainId"))
+	domain, err := s.core.DomainService.Get(c.Request().Context(), domainID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, domain)
+}
+
+// verifyDomain checks the TXT record of a domain and returns the domain,
+// verified when the record carries its token
+func (s *Server) verifyDomain(c echo.Context) error {
+	domainID, _ := strconv.Atoi(c.Param("domainId"))
+	domain, err := s.core.DomainService.Verify(c.Request().Context(), domainID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, domain)
+}
+
+func (s *Server) deleteDomain(c echo.Context) error {
+	domainID, _ := strconv.Atoi(c.Param("domainId"))
+	if err := s.core.DomainService.Delete(c.Request().Context(), domainID); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+	null "github.com/volatiletech/null/v9"
+	"golang.org/x/net/websocket"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment so
+// proxies do not close the connection
+const eventKeepAlive = 15 * time.Second
+
+// messageEvent is the WebSocket frame announcing a new message, over SSE the
+// ID and type travel in the id and event fields
+type messageEvent struct {
+	ID      int             `json:"id"`
+	Type    events.Type     `json:"type"`
+	Message *models.Message `json:"message"`
+}
+
+// streamInboxEvents streams the messages stored in an inbox, over a
+// WebSocket when the request asks for an upgrade and as Server-Sent Events
+// otherwise. The Last-Event-ID header, or the last_event_id query parameter,
+// resumes the stream after the given message.
+func (s *Server) streamInboxEvents(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+
+	afterID, err := lastEventID(c)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	messages, err := s.core.MessageService.Watch(ctx, models.MessageFilter{InboxID: inboxID}, afterID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	if strings.EqualFold(c.Request().Header.Get(echo.HeaderUpgrade), "websocket") {
+		s.streamWebSocket(c, messages, cancel)
+		return nil
+	}
+	return s.streamSSE(c, messages)
+}
+
+func (s *Server) streamSSE(c echo.Context, messages <-chan *models.Message) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(message)
+			if err != nil {
+				s.core.Logger.Error("Failed to encode message %d: %v", message.ID, err)
+				return nil
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, events.MessageCreated, data); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// streamWebSocket sends every message as a JSON messageEvent. Anything the
+// client sends is discarded, the stream ends when the client goes away.
+func (s *Server) streamWebSocket(c echo.Context, messages <-chan *models.Message, cancel context.CancelFunc) {
+	server := websocket.Server{
+		Handshake: checkWebSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			go func() {
+				defer cancel()
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+			}()
+
+			for message := range messages {
+				event := messageEvent{ID: message.ID, Type: events.MessageCreated, Message: message}
+				if err := websocket.JSON.Send(ws, event); err != nil {
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+}
+
+// checkWebSocketOrigin accepts clients without an Origin header, such as
+// test suites, and browsers on the same host as the API
+func checkWebSocketOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || !strings.EqualFold(u.Host, req.Host) {
+		return fmt.Errorf("origin %q not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+// lastEventID returns the message a stream resumes after, null when the
+// client starts a new stream
+func lastEventID(c echo.Context) (null.Int, error) {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("last_event_id")
+	}
+	if value == "" {
+		return null.Int{}, nil
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return null.Int{}, fmt.Errorf("invalid last event ID %q", value)
+	}
+	return null.IntFrom(id), nil
+}
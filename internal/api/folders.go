@@ -0,0 +1,189 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/labstack/echo/v4"
+	null "github.com/volatiletech/null/v9"
+)
+
+func (s *Server) createFolder(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	var folder models.Folder
+	if err := c.Bind(&folder); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	folder.InboxID = inboxID
+
+	if err := c.Validate(&folder); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := s.core.FolderService.Create(c.Request().Context(), &folder); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, folder)
+}
+
+func (s *Server) getFolders(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+
+	var query models.PaginationQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.FolderService.ListByInbox(c.Request().Context(), inboxID, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getFolder(c echo.Context) error {
+	folder, err := s.folderFromPath(c)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, folder)
+}
+
+// updateFolder renames a folder, the folders below it are renamed along
+// with it
+func (s *Server) updateFolder(c echo.Context) error {
+	folder, err := s.folderFromPath(c)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	var input models.Folder
+	if err := c.Bind(&input); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	input.InboxID = folder.InboxID
+
+	if err := c.Validate(&input); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := s.core.FolderService.Rename(c.Request().Context(), folder, input.Name); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) deleteFolder(c echo.Context) error {
+	folder, err := s.folderFromPath(c)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	if err := s.core.FolderService.Delete(c.Request().Context(), folder.ID); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) getFolderMessages(c echo.Context) error {
+	folder, err := s.folderFromPath(c)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	var query models.PaginationQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.MessageService.ListByFolder(c.Request().Context(),
+		folder.InboxID, null.IntFrom(folder.ID), query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// moveMessage files a message into a folder of its inbox, a null folder_id
+// moves it back to the top level. The moved message gets a new ID and is
+// returned.
+func (s *Server) moveMessage(c echo.Context) error {
+	ctx := c.Request().Context()
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	messageID, _ := strconv.Atoi(c.Param("messageId"))
+
+	type MoveInput struct {
+		FolderID null.Int `json:"folder_id"`
+	}
+
+	input := new(MoveInput)
+	if err := c.Bind(input); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	message, err := s.core.MessageService.Get(ctx, messageID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	if message.InboxID != inboxID {
+		return s.core.HandleError(storage.ErrNotFound, http.StatusNotFound)
+	}
+
+	if input.FolderID.Valid {
+		folder, err := s.core.FolderService.Get(ctx, input.FolderID.Int)
+		if err != nil {
+			return s.core.HandleError(err, http.StatusInternalServerError)
+		}
+		if folder.InboxID != inboxID {
+			return s.core.HandleError(storage.ErrNotFound, http.StatusNotFound)
+		}
+	}
+
+	moved, err := s.core.MessageService.Move(ctx, []*models.Message{message}, inboxID, input.FolderID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	message, err = s.core.MessageService.Get(ctx, moved[0])
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, message)
+}
+
+// folderFromPath loads the folder of the request path and makes sure it
+// belongs to the inbox of the path
+func (s *Server) folderFromPath(c echo.Context) (*models.Folder, error) {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	folderID, _ := strconv.Atoi(c.Param("folderId"))
+
+	folder, err := s.core.FolderService.Get(c.Request().Context(), folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder.InboxID != inboxID {
+		return nil, storage.ErrNotFound
+	}
+	return folder, nil
+}
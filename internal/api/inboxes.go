@@ -63,6 +63,15 @@ func (s *Server) getInbox(c echo.Context) error {
 	return c.JSON(http.StatusOK, inbox)
 }
 
+func (s *Server) getInboxUsage(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	usage, err := s.core.InboxService.Usage(c.Request().Context(), inboxID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, usage)
+}
+
 func (s *Server) updateInbox(c echo.Context) error {
 	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
 	projectID, _ := strconv.Atoi(c.Param("projectId"))
@@ -26,13 +26,73 @@ func (s *Server) getMessages(c echo.Context) error {
 		return s.core.HandleError(err, http.StatusBadRequest)
 	}
 
-	response, err := s.core.MessageService.ListByInbox(c.Request().Context(), inboxID, query.Limit, query.Offset, query.IsRead)
+	var response *models.PaginatedResponse
+	var err error
+	if query.IsSearch() {
+		filter := query.Filter()
+		filter.InboxID = inboxID
+		response, err = s.core.MessageService.ListByFilter(c.Request().Context(), filter, query.Limit, query.Offset)
+	} else {
+		response, err = s.core.MessageService.ListByInbox(c.Request().Context(), inboxID, query.Limit, query.Offset, query.IsRead, query.To)
+	}
 	if err != nil {
 		return s.core.HandleError(err, http.StatusInternalServerError)
 	}
 	return c.JSON(http.StatusOK, response)
 }
 
+// searchProjectMessages searches the messages of every inbox of a project,
+// it accepts the same filters as getMessages
+func (s *Server) searchProjectMessages(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+
+	var query models.MessageQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	filter := query.Filter()
+	filter.ProjectID = projectID
+
+	response, err := s.core.MessageService.ListByFilter(c.Request().Context(), filter, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// waitForMessage blocks until a message of the inbox matches the filters of
+// getMessages, returning a matching message already stored right away
+func (s *Server) waitForMessage(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+
+	var query models.MessageWaitQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	filter := query.Filter()
+	filter.InboxID = inboxID
+
+	message, err := s.core.MessageService.Wait(c.Request().Context(), *filter, query.Timeout.Duration)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, message)
+}
+
 func (s *Server) getMessage(c echo.Context) error {
 	messageID, _ := strconv.Atoi(c.Param("messageId"))
 
@@ -49,6 +109,16 @@ func (s *Server) getMessage(c echo.Context) error {
 	return c.JSON(http.StatusOK, message)
 }
 
+func (s *Server) getMessageRaw(c echo.Context) error {
+	messageID, _ := strconv.Atoi(c.Param("messageId"))
+
+	raw, err := s.core.MessageService.GetRaw(c.Request().Context(), messageID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.Blob(http.StatusOK, "message/rfc822", raw)
+}
+
 func (s *Server) markMessageRead(c echo.Context) error {
 	messageID, _ := strconv.Atoi(c.Param("messageId"))
 
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sendMessage queues a message composed from an inbox for the relay, its
+// delivery status is tracked by the returned outbound message
+func (s *Server) sendMessage(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+
+	var msg models.OutgoingMessage
+	if err := c.Bind(&msg); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := c.Validate(&msg); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	outbound, err := s.core.OutboundService.Send(c.Request().Context(), inboxID, &msg)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusAccepted, outbound)
+}
+
+// getOutboundMessages lists the messages sent from an inbox, most recent
+// first, optionally only those with the given status
+func (s *Server) getOutboundMessages(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+
+	var query models.OutboundMessageQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.OutboundService.ListByInbox(c.Request().Context(), inboxID, query.Status, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getOutboundMessage(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	outboundID, _ := strconv.Atoi(c.Param("outboundId"))
+
+	outbound, err := s.core.OutboundService.Get(c.Request().Context(), inboxID, outboundID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, outbound)
+}
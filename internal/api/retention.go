@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+	null "github.com/volatiletech/null/v9"
+)
+
+func (s *Server) getProjectRetention(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+	policy, err := s.core.RetentionService.GetForProject(c.Request().Context(), projectID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) getInboxRetention(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	policy, err := s.core.RetentionService.GetForInbox(c.Request().Context(), inboxID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (s *Server) updateProjectRetention(c echo.Context) error {
+	return s.updateRetention(c, null.Int{})
+}
+
+func (s *Server) updateInboxRetention(c echo.Context) error {
+	inboxID, _ := strconv.Atoi(c.Param("inboxId"))
+	return s.updateRetention(c, null.IntFrom(inboxID))
+}
+
+// updateRetention replaces the retention policy of a project, or of one of
+// its inboxes
+func (s *Server) updateRetention(c echo.Context, inboxID null.Int) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+
+	var policy models.RetentionPolicy
+	if err := c.Bind(&policy); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	policy.ProjectID = projectID
+	policy.InboxID = inboxID
+
+	if err := s.core.RetentionService.Set(c.Request().Context(), &policy); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
@@ -1,11 +1,25 @@
 package api
 
-import "github.com/labstack/echo/v4"
+import (
+	"inbox451/internal/middleware"
+
+	"github.com/labstack/echo/v4"
+)
 
 func (s *Server) routes(api *echo.Group) {
 	// Health check endpoint
 	api.GET("/health", s.healthCheck)
 
+	// Session routes of the web UI
+	api.POST("/auth/login", s.login)
+	api.POST("/auth/logout", s.logout)
+
+	// Every other route requires an API token or a session, nested resources
+	// must belong to their parents
+	api = api.Group("", middleware.AuthMiddleware(s.core), middleware.ScopeMiddleware(s.core))
+
+	api.GET("/auth/me", s.me)
+
 	// User routes
 	api.GET("/users", s.getUsers)
 	api.GET("/users/:userId", s.getUser)
@@ -31,13 +45,41 @@ func (s *Server) routes(api *echo.Group) {
 	api.POST("/users/:userId/tokens", s.CreateTokenForUser)
 	api.DELETE("/users/:userId/tokens/:tokenId", s.DeleteTokenByUser)
 
+	// Session routes
+	api.GET("/users/:userId/sessions", s.getSessions)
+	api.DELETE("/users/:userId/sessions", s.revokeSessions)
+	api.DELETE("/users/:userId/sessions/:sessionId", s.revokeSession)
+
 	// Inbox routes
 	api.GET("/projects/:projectId/inboxes", s.getInboxes)
 	api.GET("/projects/:projectId/inboxes/:inboxId", s.getInbox)
+	api.GET("/projects/:projectId/inboxes/:inboxId/usage", s.getInboxUsage)
 	api.POST("/projects/:projectId/inboxes", s.createInbox)
 	api.PUT("/projects/:projectId/inboxes/:inboxId", s.updateInbox)
 	api.DELETE("/projects/:projectId/inboxes/:inboxId", s.deleteInbox)
 
+	// Domain routes
+	api.GET("/projects/:projectId/domains", s.getDomains)
+	api.GET("/projects/:projectId/domains/:domainId", s.getDomain)
+	api.POST("/projects/:projectId/domains", s.createDomain)
+	api.POST("/projects/:projectId/domains/:domainId/verify", s.verifyDomain)
+	api.DELETE("/projects/:projectId/domains/:domainId", s.deleteDomain)
+
+	// Webhook routes
+	api.GET("/projects/:projectId/webhooks", s.getWebhooks)
+	api.GET("/projects/:projectId/webhooks/:webhookId", s.getWebhook)
+	api.POST("/projects/:projectId/webhooks", s.createWebhook)
+	api.PUT("/projects/:projectId/webhooks/:webhookId", s.updateWebhook)
+	api.DELETE("/projects/:projectId/webhooks/:webhookId", s.deleteWebhook)
+	api.GET("/projects/:projectId/webhooks/:webhookId/deliveries", s.getWebhookDeliveries)
+	api.POST("/projects/:projectId/webhooks/:webhookId/deliveries/:deliveryId/replay", s.replayWebhookDelivery)
+
+	// Retention routes
+	api.GET("/projects/:projectId/retention", s.getProjectRetention)
+	api.PUT("/projects/:projectId/retention", s.updateProjectRetention)
+	api.GET("/projects/:projectId/inboxes/:inboxId/retention", s.getInboxRetention)
+	api.PUT("/projects/:projectId/inboxes/:inboxId/retention", s.updateInboxRetention)
+
 	// Rule routes
 	api.GET("/projects/:projectId/inboxes/:inboxId/rules", s.getRules)
 	api.GET("/projects/:projectId/inboxes/:inboxId/rules/:ruleId", s.getRule)
@@ -45,10 +87,32 @@ func (s *Server) routes(api *echo.Group) {
 	api.PUT("/projects/:projectId/inboxes/:inboxId/rules/:ruleId", s.updateRule)
 	api.DELETE("/projects/:projectId/inboxes/:inboxId/rules/:ruleId", s.deleteRule)
 
+	// Folder routes
+	api.GET("/projects/:projectId/inboxes/:inboxId/folders", s.getFolders)
+	api.GET("/projects/:projectId/inboxes/:inboxId/folders/:folderId", s.getFolder)
+	api.GET("/projects/:projectId/inboxes/:inboxId/folders/:folderId/messages", s.getFolderMessages)
+	api.POST("/projects/:projectId/inboxes/:inboxId/folders", s.createFolder)
+	api.PUT("/projects/:projectId/inboxes/:inboxId/folders/:folderId", s.updateFolder)
+	api.DELETE("/projects/:projectId/inboxes/:inboxId/folders/:folderId", s.deleteFolder)
+
 	// Message routes
+	api.GET("/projects/:projectId/messages/search", s.searchProjectMessages)
 	api.GET("/projects/:projectId/inboxes/:inboxId/messages", s.getMessages)
+	api.GET("/projects/:projectId/inboxes/:inboxId/messages/wait", s.waitForMessage)
+	api.GET("/projects/:projectId/inboxes/:inboxId/events", s.streamInboxEvents)
+	api.POST("/projects/:projectId/inboxes/:inboxId/messages/send", s.sendMessage)
 	api.GET("/projects/:projectId/inboxes/:inboxId/messages/:messageId", s.getMessage)
+	api.GET("/projects/:projectId/inboxes/:inboxId/messages/:messageId/raw", s.getMessageRaw)
 	api.PUT("/projects/:projectId/inboxes/:inboxId/messages/:messageId/read", s.markMessageRead)
 	api.PUT("/projects/:projectId/inboxes/:inboxId/messages/:messageId/unread", s.markMessageUnread)
+	api.PUT("/projects/:projectId/inboxes/:inboxId/messages/:messageId/folder", s.moveMessage)
 	api.DELETE("/projects/:projectId/inboxes/:inboxId/messages/:messageId", s.deleteMessage)
+
+	// Attachment routes
+	api.GET("/projects/:projectId/inboxes/:inboxId/messages/:messageId/attachments", s.getAttachments)
+	api.GET("/projects/:projectId/inboxes/:inboxId/messages/:messageId/attachments/:attachmentId", s.downloadAttachment)
+
+	// Outbound message routes
+	api.GET("/projects/:projectId/inboxes/:inboxId/outbound", s.getOutboundMessages)
+	api.GET("/projects/:projectId/inboxes/:inboxId/outbound/:outboundId", s.getOutboundMessage)
 }
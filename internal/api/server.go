@@ -38,8 +38,9 @@ func NewServer(core *core.Core) *Server {
 		echo: e,
 	}
 
-	// Add timeout middleware with a 30-second timeout
-	e.Use(middleware.TimeoutMiddleware(30 * time.Second))
+	// Add timeout middleware with a 30-second timeout, event streams stay
+	// open until the client goes away and waits bound themselves
+	e.Use(middleware.TimeoutMiddleware(30*time.Second, isLongLived))
 
 	// Set custom validator
 	e.Validator = &CustomValidator{validator: validator.New()}
@@ -98,6 +99,13 @@ func NewServer(core *core.Core) *Server {
 	return s
 }
 
+// isLongLived reports whether a request is routed to an endpoint that may
+// hold the connection open longer than the request timeout
+func isLongLived(c echo.Context) bool {
+	path := c.Path()
+	return strings.HasSuffix(path, "/events") || strings.HasSuffix(path, "/messages/wait")
+}
+
 // Add the error handler method
 func (s *Server) errorHandler(err error, c echo.Context) {
 	if he, ok := err.(*echo.HTTPError); ok {
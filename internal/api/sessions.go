@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GET /users/:userId/sessions
+func (s *Server) getSessions(c echo.Context) error {
+	userID, _ := strconv.Atoi(c.Param("userId"))
+
+	var query models.PaginationQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.SessionService.ListByUser(c.Request().Context(), userID, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// DELETE /users/:userId/sessions/:sessionId
+func (s *Server) revokeSession(c echo.Context) error {
+	userID, _ := strconv.Atoi(c.Param("userId"))
+	sessionID, _ := strconv.Atoi(c.Param("sessionId"))
+
+	if err := s.core.SessionService.Revoke(c.Request().Context(), userID, sessionID); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DELETE /users/:userId/sessions
+func (s *Server) revokeSessions(c echo.Context) error {
+	userID, _ := strconv.Atoi(c.Param("userId"))
+
+	if _, err := s.core.SessionService.RevokeAll(c.Request().Context(), userID); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) createWebhook(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+	webhook := models.Webhook{IsActive: true}
+	if err := c.Bind(&webhook); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	webhook.ProjectID = projectID
+
+	if err := c.Validate(&webhook); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := s.core.WebhookService.Create(c.Request().Context(), &webhook); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (s *Server) getWebhooks(c echo.Context) error {
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+
+	var query models.PaginationQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.WebhookService.ListByProject(c.Request().Context(), projectID, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (s *Server) getWebhook(c echo.Context) error {
+	webhookID, _ := strconv.Atoi(c.Param("webhookId"))
+	webhook, err := s.core.WebhookService.Get(c.Request().Context(), webhookID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (s *Server) updateWebhook(c echo.Context) error {
+	webhookID, _ := strconv.Atoi(c.Param("webhookId"))
+	projectID, _ := strconv.Atoi(c.Param("projectId"))
+
+	webhook := models.Webhook{IsActive: true}
+	if err := c.Bind(&webhook); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+	webhook.ID = webhookID
+	webhook.ProjectID = projectID
+
+	if err := c.Validate(&webhook); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if err := s.core.WebhookService.Update(c.Request().Context(), &webhook); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) deleteWebhook(c echo.Context) error {
+	webhookID, _ := strconv.Atoi(c.Param("webhookId"))
+	if err := s.core.WebhookService.Delete(c.Request().Context(), webhookID); err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getWebhookDeliveries lists the delivery log of a webhook, most recent
+// first
+func (s *Server) getWebhookDeliveries(c echo.Context) error {
+	webhookID, _ := strconv.Atoi(c.Param("webhookId"))
+
+	var query models.WebhookDeliveryQuery
+	if err := c.Bind(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	if err := c.Validate(&query); err != nil {
+		return s.core.HandleError(err, http.StatusBadRequest)
+	}
+
+	response, err := s.core.WebhookService.ListDeliveries(c.Request().Context(), webhookID, query.Status, query.Limit, query.Offset)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// replayWebhookDelivery queues a finished delivery again and returns it
+func (s *Server) replayWebhookDelivery(c echo.Context) error {
+	webhookID, _ := strconv.Atoi(c.Param("webhookId"))
+	deliveryID, _ := strconv.Atoi(c.Param("deliveryId"))
+
+	delivery, err := s.core.WebhookService.ReplayDelivery(c.Request().Context(), webhookID, deliveryID)
+	if err != nil {
+		return s.core.HandleError(err, http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
@@ -19,24 +19,115 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration `koanf:"conn_max_lifetime"`
 }
 
+// RelayConfig describes the upstream SMTP relay used for outbound mail.
+type RelayConfig struct {
+	Addr     string `koanf:"addr"`
+	Username string `koanf:"username"`
+	Password string `koanf:"password"`
+	From     string `koanf:"from"`
+	TLS      bool   `koanf:"tls"`
+	StartTLS bool   `koanf:"starttls"`
+}
+
+// TLSConfig describes TLS for a mail listener. When enabled STARTTLS is
+// offered on the plain port, and ImplicitPort optionally serves TLS from the
+// first byte (465 for SMTP, 993 for IMAP).
+type TLSConfig struct {
+	Enabled  bool   `koanf:"enabled"`
+	CertFile string `koanf:"cert_file"`
+	KeyFile  string `koanf:"key_file"`
+	// SelfSigned generates a certificate at startup when no files are
+	// given, for development only
+	SelfSigned   bool   `koanf:"self_signed"`
+	ImplicitPort string `koanf:"implicit_port"`
+	// AuthRequiresTLS refuses authentication over unencrypted connections
+	AuthRequiresTLS bool `koanf:"auth_requires_tls"`
+}
+
 type Config struct {
 	Server struct {
 		HTTP struct {
 			Port string `koanf:"port"`
+			// SessionTTL is the lifetime of the sessions of the web UI
+			SessionTTL time.Duration `koanf:"session_ttl"`
+			// SessionReapInterval is how often expired sessions are
+			// deleted
+			SessionReapInterval time.Duration `koanf:"session_reap_interval"`
+			// InsecureCookies drops the Secure attribute of the session
+			// cookie, for development over plain HTTP
+			InsecureCookies bool `koanf:"insecure_cookies"`
 		} `koanf:"http"`
 		SMTP struct {
 			Port     string `koanf:"port"`
 			Hostname string `koanf:"hostname"`
 			Username string `koanf:"username"`
 			Password string `koanf:"password"`
+			// RequireAuth rejects MAIL FROM until the client has
+			// authenticated, whatever the settings of the projects
+			RequireAuth bool `koanf:"require_auth"`
+			// MaxMessageBytes bounds the size of a message, it is
+			// announced with the SIZE extension
+			MaxMessageBytes int64 `koanf:"max_message_bytes"`
+			// MaxRecipients bounds the recipients of a message
+			MaxRecipients int `koanf:"max_recipients"`
+			// ReadTimeout and WriteTimeout bound how long a client may
+			// stay silent, or take to accept a reply
+			ReadTimeout  time.Duration `koanf:"read_timeout"`
+			WriteTimeout time.Duration `koanf:"write_timeout"`
+			// MaxConnectionsPerIP bounds the simultaneous connections of
+			// a client address, 0 for no limit
+			MaxConnectionsPerIP int `koanf:"max_connections_per_ip"`
+			// MaxLargeMessages bounds the messages above 1 MiB that are
+			// parsed and stored at once
+			MaxLargeMessages int       `koanf:"max_large_messages"`
+			TLS              TLSConfig `koanf:"tls"`
 		} `koanf:"smtp"`
 		IMAP struct {
-			Port     string `koanf:"port"`
-			Hostname string `koanf:"hostname"`
+			Port     string    `koanf:"port"`
+			Hostname string    `koanf:"hostname"`
+			TLS      TLSConfig `koanf:"tls"`
 		}
 	} `koanf:"server"`
 	Database DatabaseConfig `koanf:"database"`
-	Logging  struct {
+	Relay    RelayConfig    `koanf:"relay"`
+	Events   struct {
+		// PostgresNotify shares message events between instances using
+		// the database LISTEN/NOTIFY mechanism
+		PostgresNotify bool `koanf:"postgres_notify"`
+	} `koanf:"events"`
+	Webhooks struct {
+		// Timeout bounds each delivery request
+		Timeout time.Duration `koanf:"timeout"`
+		// MaxAttempts is the number of attempts before a delivery is
+		// marked as failed
+		MaxAttempts int `koanf:"max_attempts"`
+		// RetryBackoff is the delay before the first retry, it doubles
+		// with every further attempt
+		RetryBackoff time.Duration `koanf:"retry_backoff"`
+		// PollInterval is how often deliveries due for a retry are
+		// looked for
+		PollInterval time.Duration `koanf:"poll_interval"`
+	} `koanf:"webhooks"`
+	Retention struct {
+		// Interval is how often messages outside the retention policies
+		// are purged
+		Interval time.Duration `koanf:"interval"`
+		// BatchSize is the number of messages deleted at once
+		BatchSize int `koanf:"batch_size"`
+	} `koanf:"retention"`
+	Outbound struct {
+		// Timeout bounds each attempt at handing a message to the relay
+		Timeout time.Duration `koanf:"timeout"`
+		// MaxAttempts is the number of attempts before a message is
+		// marked as failed
+		MaxAttempts int `koanf:"max_attempts"`
+		// RetryBackoff is the delay before the first retry, it doubles
+		// with every further attempt
+		RetryBackoff time.Duration `koanf:"retry_backoff"`
+		// PollInterval is how often queued messages are looked for
+		PollInterval time.Duration `koanf:"poll_interval"`
+	} `koanf:"outbound"`
+	Logging struct {
 		Level  logger.Level `koanf:"level"`
 		Format string       `koanf:"format"`
 	} `koanf:"logging"`
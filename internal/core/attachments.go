@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+
+	"inbox451/internal/models"
+)
+
+type AttachmentService struct {
+	core *Core
+}
+
+func NewAttachmentService(core *Core) AttachmentService {
+	return AttachmentService{core: core}
+}
+
+func (s *AttachmentService) ListByMessage(ctx context.Context, messageID int) ([]*models.Attachment, error) {
+	s.core.Logger.Debug("Listing attachments for message %d", messageID)
+
+	if err := s.core.authorizeMessage(ctx, messageID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.core.Repository.ListAttachmentsByMessage(ctx, messageID)
+	if err != nil {
+		s.core.Logger.Error("Failed to list attachments: %v", err)
+		return nil, err
+	}
+
+	s.core.Logger.Debug("Successfully retrieved %d attachments for message %d", len(attachments), messageID)
+	return attachments, nil
+}
+
+// Get returns an attachment including its content. The attachment must belong
+// to the given message.
+func (s *AttachmentService) Get(ctx context.Context, messageID, attachmentID int) (*models.Attachment, error) {
+	s.core.Logger.Debug("Fetching attachment %d of message %d", attachmentID, messageID)
+
+	if err := s.core.authorizeMessage(ctx, messageID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.core.Repository.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch attachment: %v", err)
+		return nil, err
+	}
+
+	if attachment == nil || attachment.MessageID != messageID {
+		s.core.Logger.Info("Attachment %d not found for message %d", attachmentID, messageID)
+		return nil, ErrNotFound
+	}
+
+	return attachment, nil
+}
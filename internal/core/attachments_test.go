@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAttachmentTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	core := &Core{
+		Logger:     logger,
+		Repository: mockRepo,
+	}
+	core.AttachmentService = NewAttachmentService(core)
+
+	return core, mockRepo
+}
+
+func TestAttachmentService_ListByMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		messageID int
+		mockFn    func(*mocks.Repository)
+		want      []*models.Attachment
+		wantErr   bool
+	}{
+		{
+			name:      "attachments found",
+			messageID: 1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAttachmentsByMessage", mock.Anything, 1).Return([]*models.Attachment{
+					{Base: models.Base{ID: 1}, MessageID: 1, Filename: "report.pdf"},
+				}, nil)
+			},
+			want: []*models.Attachment{
+				{Base: models.Base{ID: 1}, MessageID: 1, Filename: "report.pdf"},
+			},
+		},
+		{
+			name:      "repository error",
+			messageID: 1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAttachmentsByMessage", mock.Anything, 1).Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupAttachmentTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.AttachmentService.ListByMessage(WithSystemContext(context.Background()), tt.messageID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAttachmentService_Get(t *testing.T) {
+	tests := []struct {
+		name         string
+		messageID    int
+		attachmentID int
+		mockFn       func(*mocks.Repository)
+		wantErr      bool
+		errType      error
+	}{
+		{
+			name:         "attachment of message",
+			messageID:    1,
+			attachmentID: 5,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetAttachment", mock.Anything, 5).
+					Return(&models.Attachment{Base: models.Base{ID: 5}, MessageID: 1, Content: []byte("PDF")}, nil)
+			},
+		},
+		{
+			name:         "attachment of another message",
+			messageID:    2,
+			attachmentID: 5,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetAttachment", mock.Anything, 5).
+					Return(&models.Attachment{Base: models.Base{ID: 5}, MessageID: 1}, nil)
+			},
+			wantErr: true,
+			errType: ErrNotFound,
+		},
+		{
+			name:         "non-existent attachment",
+			messageID:    1,
+			attachmentID: 999,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetAttachment", mock.Anything, 999).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: true,
+			errType: storage.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupAttachmentTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.AttachmentService.Get(WithSystemContext(context.Background()), tt.messageID, tt.attachmentID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.errType)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.attachmentID, got.ID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+)
+
+// Roles, both the global user_role of a user and the project_role of a
+// project membership
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	systemContextKey
+)
+
+// WithUser returns a copy of ctx carrying the authenticated user
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// WithSystemContext returns a copy of ctx for internal work done on behalf of
+// no user, such as SMTP delivery and the background workers. The services do
+// not restrict it, unless a user is attached to it as well.
+func WithSystemContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemContextKey, true)
+}
+
+// UserFromContext returns the authenticated user carried by ctx, if any
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*models.User)
+	return user, ok && user != nil
+}
+
+// The authorize helpers below are called by the services before touching the
+// repository. A context created with WithSystemContext is an internal call
+// and is not restricted, a context carrying neither a user nor the system
+// marker is refused. Users with the global admin role may do anything. Other users need a membership in the project owning
+// the resource: reading and working with mail requires any role, managing the
+// project, its members, inboxes and rules requires the project admin role.
+// Resources of projects the user is not a member of are reported as not
+// found rather than forbidden, so their existence is not revealed.
+
+func isAdmin(user *models.User) bool {
+	return user.Role == RoleAdmin
+}
+
+// caller returns the user ctx acts for, nil for a system context
+func caller(ctx context.Context) (*models.User, error) {
+	if user, ok := UserFromContext(ctx); ok {
+		return user, nil
+	}
+	if system, _ := ctx.Value(systemContextKey).(bool); system {
+		return nil, nil
+	}
+	return nil, ErrUnauthorized
+}
+
+// unrestricted reports whether ctx may do anything, which is the case of
+// system contexts and global admins. Otherwise user is the caller.
+func unrestricted(ctx context.Context) (user *models.User, ok bool, err error) {
+	user, err = caller(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, user == nil || isAdmin(user), nil
+}
+
+// authorizeAdmin requires the global admin role
+func (c *Core) authorizeAdmin(ctx context.Context) error {
+	_, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+	return ErrForbidden
+}
+
+// authorizeUser requires the caller to be the given user or a global admin
+func (c *Core) authorizeUser(ctx context.Context, userID int) error {
+	user, ok, err := unrestricted(ctx)
+	if err != nil || ok || user.ID == userID {
+		return err
+	}
+	return ErrForbidden
+}
+
+// authorizeProject requires a membership of the project with at least the
+// given role
+func (c *Core) authorizeProject(ctx context.Context, projectID int, role string) error {
+	user, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+
+	member, err := c.Repository.GetProjectUser(ctx, projectID, user.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Logger.Info("User %d is not a member of project %d", user.ID, projectID)
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if role == RoleAdmin && member.Role != RoleAdmin {
+		c.Logger.Info("User %d is not an admin of project %d", user.ID, projectID)
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizeInbox requires a membership of the project owning the inbox
+func (c *Core) authorizeInbox(ctx context.Context, inboxID int, role string) error {
+	_, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+
+	inbox, err := c.Repository.GetInbox(ctx, inboxID)
+	if err != nil {
+		return err
+	}
+	return c.authorizeProject(ctx, inbox.ProjectID, role)
+}
+
+// authorizeMessage requires a membership of the project owning the message
+func (c *Core) authorizeMessage(ctx context.Context, messageID int, role string) error {
+	_, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+
+	message, err := c.Repository.GetMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	return c.authorizeInbox(ctx, message.InboxID, role)
+}
+
+// authorizeRule requires a membership of the project owning the rule
+func (c *Core) authorizeRule(ctx context.Context, ruleID int, role string) error {
+	_, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+
+	rule, err := c.Repository.GetRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	return c.authorizeInbox(ctx, rule.InboxID, role)
+}
+
+// authorizeFolder requires a membership of the project owning the folder
+func (c *Core) authorizeFolder(ctx context.Context, folderID int, role string) error {
+	_, ok, err := unrestricted(ctx)
+	if err != nil || ok {
+		return err
+	}
+
+	folder, err := c.Repository.GetFolder(ctx, folderID)
+	if err != nil {
+		return err
+	}
+	return c.authorizeInbox(ctx, folder.InboxID, role)
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupAuthTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+
+	core := &Core{
+		Logger:     logger.New(io.Discard, logger.DEBUG),
+		Repository: mockRepo,
+		Events:     events.NewBus(),
+	}
+	core.UserService = NewUserService(core)
+	core.ProjectService = NewProjectService(core)
+	core.InboxService = NewInboxService(core)
+	core.MessageService = NewMessageService(core)
+
+	return core, mockRepo
+}
+
+var (
+	adminUser  = &models.User{Base: models.Base{ID: 1}, Role: RoleAdmin, Status: "active"}
+	memberUser = &models.User{Base: models.Base{ID: 2}, Role: RoleUser, Status: "active"}
+)
+
+func TestUserFromContext(t *testing.T) {
+	_, ok := UserFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = UserFromContext(WithUser(context.Background(), nil))
+	assert.False(t, ok)
+
+	user, ok := UserFromContext(WithUser(context.Background(), memberUser))
+	assert.True(t, ok)
+	assert.Equal(t, memberUser, user)
+}
+
+func TestCore_authorizeProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		role    string
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name:   "internal call",
+			ctx:    WithSystemContext(context.Background()),
+			role:   RoleAdmin,
+			mockFn: func(m *mocks.Repository) {},
+		},
+		{
+			name:    "no user",
+			ctx:     context.Background(),
+			role:    RoleUser,
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "user in an internal call",
+			ctx:  WithUser(WithSystemContext(context.Background()), memberUser),
+			role: RoleUser,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 10, 2).
+					Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:   "global admin",
+			ctx:    WithUser(context.Background(), adminUser),
+			role:   RoleAdmin,
+			mockFn: func(m *mocks.Repository) {},
+		},
+		{
+			name: "member",
+			ctx:  WithUser(context.Background(), memberUser),
+			role: RoleUser,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 10, 2).
+					Return(&models.ProjectUser{ProjectID: 10, UserID: 2, Role: RoleUser}, nil)
+			},
+		},
+		{
+			name: "member without the admin role",
+			ctx:  WithUser(context.Background(), memberUser),
+			role: RoleAdmin,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 10, 2).
+					Return(&models.ProjectUser{ProjectID: 10, UserID: 2, Role: RoleUser}, nil)
+			},
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "project admin",
+			ctx:  WithUser(context.Background(), memberUser),
+			role: RoleAdmin,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 10, 2).
+					Return(&models.ProjectUser{ProjectID: 10, UserID: 2, Role: RoleAdmin}, nil)
+			},
+		},
+		{
+			name: "not a member",
+			ctx:  WithUser(context.Background(), memberUser),
+			role: RoleUser,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 10, 2).
+					Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupAuthTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.authorizeProject(tt.ctx, 10, tt.role)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCore_authorizeUser(t *testing.T) {
+	core, _ := setupAuthTestCore(t)
+
+	assert.NoError(t, core.authorizeUser(WithSystemContext(context.Background()), 5))
+	assert.ErrorIs(t, core.authorizeUser(context.Background(), 5), ErrUnauthorized)
+	assert.NoError(t, core.authorizeUser(WithUser(context.Background(), adminUser), 5))
+	assert.NoError(t, core.authorizeUser(WithUser(context.Background(), memberUser), memberUser.ID))
+	assert.ErrorIs(t, core.authorizeUser(WithUser(context.Background(), memberUser), 5), ErrForbidden)
+}
+
+func TestAuthorization_Services(t *testing.T) {
+	member := WithUser(context.Background(), memberUser)
+
+	t.Run("users are listed by admins only", func(t *testing.T) {
+		core, _ := setupAuthTestCore(t)
+
+		_, err := core.UserService.List(member, 10, 0)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("users cannot change their own role", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("GetUser", mock.Anything, memberUser.ID).Return(memberUser, nil)
+		mockRepo.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+			return u.Role == RoleUser && u.Status == "active"
+		})).Return(nil)
+
+		user := &models.User{Base: models.Base{ID: memberUser.ID}, Name: "Me", Role: RoleAdmin, Status: "active"}
+		assert.NoError(t, core.UserService.Update(member, user))
+		assert.Equal(t, RoleUser, user.Role)
+	})
+
+	t.Run("projects are listed by membership", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("ListProjectsByUser", mock.Anything, memberUser.ID, 10, 0).
+			Return([]*models.Project{{Base: models.Base{ID: 10}}}, 1, nil)
+
+		response, err := core.ProjectService.List(member, 10, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, response.Pagination.Total)
+	})
+
+	t.Run("project creator becomes its admin", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("CreateProject", mock.Anything, mock.AnythingOfType("*models.Project")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Project).ID = 10 }).
+			Return(nil)
+		mockRepo.On("ProjectAddUser", mock.Anything, &models.ProjectUser{ProjectID: 10, UserID: memberUser.ID, Role: RoleAdmin}).
+			Return(nil)
+
+		assert.NoError(t, core.ProjectService.Create(member, &models.Project{Name: "Team"}))
+	})
+
+	t.Run("project members cannot delete the project", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("GetProjectUser", mock.Anything, 10, memberUser.ID).
+			Return(&models.ProjectUser{ProjectID: 10, UserID: memberUser.ID, Role: RoleUser}, nil)
+
+		assert.ErrorIs(t, core.ProjectService.Delete(member, 10), ErrForbidden)
+	})
+
+	t.Run("messages of other projects are not found", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("GetMessage", mock.Anything, 7).
+			Return(&models.Message{Base: models.Base{ID: 7}, InboxID: 3}, nil)
+		mockRepo.On("GetInbox", mock.Anything, 3).
+			Return(&models.Inbox{Base: models.Base{ID: 3}, ProjectID: 20}, nil)
+		mockRepo.On("GetProjectUser", mock.Anything, 20, memberUser.ID).
+			Return(nil, storage.ErrNotFound)
+
+		_, err := core.MessageService.Get(member, 7)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("members can list the messages of their inboxes", func(t *testing.T) {
+		core, mockRepo := setupAuthTestCore(t)
+		mockRepo.On("GetInbox", mock.Anything, 3).
+			Return(&models.Inbox{Base: models.Base{ID: 3}, ProjectID: 10}, nil)
+		mockRepo.On("GetProjectUser", mock.Anything, 10, memberUser.ID).
+			Return(&models.ProjectUser{ProjectID: 10, UserID: memberUser.ID, Role: RoleUser}, nil)
+		mockRepo.On("ListMessagesByInboxWithFilter", mock.Anything, 3, (*bool)(nil), "", 10, 0).
+			Return([]*models.Message{}, 0, nil)
+
+		_, err := core.MessageService.ListByInbox(member, 3, 10, 0, nil, "")
+		assert.NoError(t, err)
+	})
+}
@@ -3,11 +3,14 @@ package core
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 
 	"inbox451/internal/config"
+	"inbox451/internal/events"
 	"inbox451/internal/logger"
 	"inbox451/internal/models"
+	"inbox451/internal/relay"
 	"inbox451/internal/storage"
 
 	"github.com/jmoiron/sqlx"
@@ -18,16 +21,41 @@ type Core struct {
 	Config     *config.Config
 	Logger     *logger.Logger
 	Repository storage.Repository
-	Version    string
-	Commit     string
-	BuildDate  string
-
-	UserService    UserService
-	TokenService   TokenService
-	ProjectService ProjectService
-	InboxService   InboxService
-	RuleService    RuleService
-	MessageService MessageService
+	Relay      relay.Sender
+	// Resolver looks up the TXT records verifying the domains of projects
+	Resolver Resolver
+	// Events carries message events to in-process subscribers such as the
+	// IMAP IDLE notifications. EventBridge is only set when events are
+	// shared between instances.
+	Events      *events.Bus
+	EventBridge *events.PostgresBridge
+	// SessionReaper deletes expired sessions in the background
+	SessionReaper *SessionReaper
+	// WebhookDispatcher sends the webhook deliveries in the background
+	WebhookDispatcher *WebhookDispatcher
+	// RetentionWorker purges the messages outside the retention policies
+	// in the background
+	RetentionWorker *RetentionWorker
+	// OutboundDispatcher hands the messages sent from inboxes to the relay
+	// in the background
+	OutboundDispatcher *OutboundDispatcher
+	Version            string
+	Commit             string
+	BuildDate          string
+
+	UserService       UserService
+	TokenService      TokenService
+	SessionService    SessionService
+	ProjectService    ProjectService
+	InboxService      InboxService
+	DomainService     DomainService
+	WebhookService    WebhookService
+	RetentionService  RetentionService
+	OutboundService   OutboundService
+	RuleService       RuleService
+	MessageService    MessageService
+	FolderService     FolderService
+	AttachmentService AttachmentService
 }
 
 func NewCore(cfg *config.Config, db *sqlx.DB, version, commit, date string) (*Core, error) {
@@ -42,22 +70,43 @@ func NewCore(cfg *config.Config, db *sqlx.DB, version, commit, date string) (*Co
 		Config:     cfg,
 		Logger:     baseLogger,
 		Repository: repo,
+		Resolver:   net.DefaultResolver,
 		Version:    version,
 		Commit:     commit,
 		BuildDate:  date,
 	}
 
+	if cfg.Relay.Addr != "" {
+		core.Relay = relay.New(cfg.Relay)
+	}
+
+	core.Events = events.NewBus()
+	if cfg.Events.PostgresNotify {
+		core.EventBridge = events.NewPostgresBridge(db, cfg.Database.URL, core.Events, baseLogger)
+	}
+
 	core.UserService = NewUserService(core)
 	core.ProjectService = NewProjectService(core)
 	core.InboxService = NewInboxService(core)
+	core.DomainService = NewDomainService(core)
 	core.RuleService = NewRuleService(core)
 	core.MessageService = NewMessageService(core)
+	core.FolderService = NewFolderService(core)
+	core.AttachmentService = NewAttachmentService(core)
 	core.TokenService = NewTokensService(core)
+	core.SessionService = NewSessionService(core)
+	core.SessionReaper = NewSessionReaper(core)
+	core.WebhookService = NewWebhookService(core)
+	core.WebhookDispatcher = NewWebhookDispatcher(core)
+	core.RetentionService = NewRetentionService(core)
+	core.RetentionWorker = NewRetentionWorker(core)
+	core.OutboundService = NewOutboundService(core)
+	core.OutboundDispatcher = NewOutboundDispatcher(core)
 
 	return core, nil
 }
 
 func (c *Core) StoreMessage(message *models.Message) error {
-	ctx := context.Background()
+	ctx := WithSystemContext(context.Background())
 	return c.MessageService.Store(ctx, message)
 }
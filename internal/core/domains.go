@@ -0,0 +1,247 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"inbox451/internal/models"
+)
+
+const (
+	// DomainVerificationPrefix is prepended to a domain to name the TXT
+	// record carrying its verification token
+	DomainVerificationPrefix = "_inbox451."
+	// DomainVerificationValue prefixes the token in the TXT record
+	DomainVerificationValue = "inbox451-verification="
+)
+
+// Resolver looks up the DNS TXT records used to verify domains, it is
+// satisfied by *net.Resolver
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type DomainService struct {
+	core *Core
+}
+
+func NewDomainService(core *Core) DomainService {
+	return DomainService{core: core}
+}
+
+// Create claims a domain for a project. The domain starts out unverified,
+// the returned domain describes the TXT record proving ownership.
+func (s *DomainService) Create(ctx context.Context, domain *models.Domain) error {
+	domain.Name = normalizeDomain(domain.Name)
+	s.core.Logger.Info("Creating domain %s for project %d", domain.Name, domain.ProjectID)
+
+	if err := s.core.authorizeProject(ctx, domain.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+
+	token, err := generateVerificationToken()
+	if err != nil {
+		s.core.Logger.Error("Failed to generate verification token: %v", err)
+		return err
+	}
+	domain.VerificationToken = token
+	domain.VerifiedAt.Valid = false
+
+	if err := s.core.Repository.CreateDomain(ctx, domain); err != nil {
+		s.core.Logger.Error("Failed to create domain: %v", err)
+		return err
+	}
+
+	describeVerification(domain)
+	s.core.Logger.Info("Successfully created domain with ID: %d", domain.ID)
+	return nil
+}
+
+func (s *DomainService) Get(ctx context.Context, id int) (*models.Domain, error) {
+	s.core.Logger.Debug("Fetching domain with ID: %d", id)
+
+	domain, err := s.core.Repository.GetDomain(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch domain: %v", err)
+		return nil, err
+	}
+
+	if err := s.core.authorizeProject(ctx, domain.ProjectID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	describeVerification(domain)
+	return domain, nil
+}
+
+func (s *DomainService) ListByProject(ctx context.Context, projectID, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing domains for project %d with limit: %d and offset: %d", projectID, limit, offset)
+
+	if err := s.core.authorizeProject(ctx, projectID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	domains, total, err := s.core.Repository.ListDomainsByProject(ctx, projectID, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list domains: %v", err)
+		return nil, err
+	}
+
+	for _, domain := range domains {
+		describeVerification(domain)
+	}
+
+	response := &models.PaginatedResponse{
+		Data: domains,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully retrieved %d domains (total: %d)", len(domains), total)
+	return response, nil
+}
+
+// Verify looks up the TXT record of a domain and marks the domain as
+// verified when it carries the verification token. Verifying a domain that
+// another project has already verified fails with a conflict.
+func (s *DomainService) Verify(ctx context.Context, id int) (*models.Domain, error) {
+	s.core.Logger.Info("Verifying domain with ID: %d", id)
+
+	domain, err := s.core.Repository.GetDomain(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch domain: %v", err)
+		return nil, err
+	}
+
+	if err := s.core.authorizeProject(ctx, domain.ProjectID, RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	describeVerification(domain)
+	if domain.VerifiedAt.Valid {
+		return domain, nil
+	}
+
+	records, err := s.core.Resolver.LookupTXT(ctx, domain.VerificationRecord)
+	if err != nil {
+		s.core.Logger.Info("Failed to look up TXT record %s: %v", domain.VerificationRecord, err)
+		return nil, &APIError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("domain verification failed: no TXT record found at %s", domain.VerificationRecord),
+		}
+	}
+
+	if !hasVerificationRecord(records, domain.VerificationValue) {
+		s.core.Logger.Info("TXT record %s does not carry the verification token of domain %d", domain.VerificationRecord, id)
+		return nil, &APIError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("domain verification failed: %s does not contain %q", domain.VerificationRecord, domain.VerificationValue),
+		}
+	}
+
+	if err := s.core.Repository.VerifyDomain(ctx, domain); err != nil {
+		s.core.Logger.Error("Failed to verify domain: %v", err)
+		return nil, err
+	}
+
+	s.core.Logger.Info("Successfully verified domain %s of project %d", domain.Name, domain.ProjectID)
+	return domain, nil
+}
+
+func (s *DomainService) Delete(ctx context.Context, id int) error {
+	s.core.Logger.Info("Deleting domain with ID: %d", id)
+
+	domain, err := s.core.Repository.GetDomain(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch domain: %v", err)
+		return err
+	}
+
+	if err := s.core.authorizeProject(ctx, domain.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.DeleteDomain(ctx, id); err != nil {
+		s.core.Logger.Error("Failed to delete domain: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully deleted domain with ID: %d", id)
+	return nil
+}
+
+// AcceptsAddress reports whether the domain of an address, or one of its
+// parent domains, is a verified domain of the project. The most specific
+// verified domain decides: a subdomain verified by another project is not
+// covered by the parent domain. Only accepted addresses may be used for
+// inboxes and receive mail.
+func (s *DomainService) AcceptsAddress(ctx context.Context, projectID int, address string) (bool, error) {
+	return s.core.acceptsAddress(ctx, projectID, address)
+}
+
+func (c *Core) acceptsAddress(ctx context.Context, projectID int, address string) (bool, error) {
+	_, host, found := strings.Cut(address, "@")
+	if !found || host == "" {
+		return false, nil
+	}
+
+	// A subdomain verified by another project takes precedence over the
+	// parent domain verified by this one
+	domain, err := c.Repository.GetVerifiedDomainForHost(ctx, normalizeDomain(host))
+	if err != nil {
+		c.Logger.Error("Failed to look up verified domain for %s: %v", host, err)
+		return false, err
+	}
+	return domain != nil && domain.ProjectID == projectID, nil
+}
+
+// requireVerifiedDomain fails unless the address of an inbox is on a
+// verified domain of its project
+func (c *Core) requireVerifiedDomain(ctx context.Context, projectID int, address string) error {
+	ok, err := c.acceptsAddress(ctx, projectID, address)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.Logger.Info("Rejecting address %s outside the verified domains of project %d", address, projectID)
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("%s is not on a verified domain of the project", address),
+		}
+	}
+	return nil
+}
+
+// describeVerification fills in the TXT record proving ownership of a domain
+func describeVerification(domain *models.Domain) {
+	domain.VerificationRecord = DomainVerificationPrefix + domain.Name
+	domain.VerificationValue = DomainVerificationValue + domain.VerificationToken
+}
+
+func hasVerificationRecord(records []string, value string) bool {
+	for _, record := range records {
+		if strings.TrimSpace(record) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomain(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+func generateVerificationToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
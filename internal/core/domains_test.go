@@ -0,0 +1,212 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+// fakeResolver serves TXT records from a map, names without records fail
+// like an NXDOMAIN answer
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+func setupDomainTestCore(t *testing.T, resolver Resolver) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	core := &Core{
+		Logger:     logger,
+		Repository: mockRepo,
+		Resolver:   resolver,
+	}
+	core.DomainService = NewDomainService(core)
+
+	return core, mockRepo
+}
+
+func TestDomainService_Create(t *testing.T) {
+	core, mockRepo := setupDomainTestCore(t, fakeResolver{})
+
+	mockRepo.On("CreateDomain", mock.Anything, mock.MatchedBy(func(d *models.Domain) bool {
+		return d.ProjectID == 1 && d.Name == "qa.example.com" && len(d.VerificationToken) == 32
+	})).Return(nil)
+
+	domain := &models.Domain{ProjectID: 1, Name: "QA.Example.com."}
+	require.NoError(t, core.DomainService.Create(WithSystemContext(context.Background()), domain))
+
+	assert.Equal(t, "qa.example.com", domain.Name)
+	assert.Equal(t, "_inbox451.qa.example.com", domain.VerificationRecord)
+	assert.Equal(t, "inbox451-verification="+domain.VerificationToken, domain.VerificationValue)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDomainService_Verify(t *testing.T) {
+	now := time.Now()
+	newDomain := func() *models.Domain {
+		return &models.Domain{
+			Base:              models.Base{ID: 1},
+			ProjectID:         1,
+			Name:              "example.com",
+			VerificationToken: "abc123",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		resolver fakeResolver
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name: "token published",
+			resolver: fakeResolver{
+				"_inbox451.example.com": {"v=spf1 -all", "inbox451-verification=abc123"},
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 1).Return(newDomain(), nil)
+				m.On("VerifyDomain", mock.Anything, mock.AnythingOfType("*models.Domain")).
+					Run(func(args mock.Arguments) {
+						args.Get(1).(*models.Domain).VerifiedAt = null.TimeFrom(now)
+					}).Return(nil)
+			},
+		},
+		{
+			name: "wrong token",
+			resolver: fakeResolver{
+				"_inbox451.example.com": {"inbox451-verification=other"},
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 1).Return(newDomain(), nil)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "no record",
+			resolver: fakeResolver{},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 1).Return(newDomain(), nil)
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "verified by another project",
+			resolver: fakeResolver{
+				"_inbox451.example.com": {"inbox451-verification=abc123"},
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 1).Return(newDomain(), nil)
+				m.On("VerifyDomain", mock.Anything, mock.AnythingOfType("*models.Domain")).
+					Return(storage.ErrConflict)
+			},
+			wantErr: storage.ErrConflict,
+		},
+		{
+			name:     "already verified",
+			resolver: fakeResolver{},
+			mockFn: func(m *mocks.Repository) {
+				domain := newDomain()
+				domain.VerifiedAt = null.TimeFrom(now)
+				m.On("GetDomain", mock.Anything, 1).Return(domain, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupDomainTestCore(t, tt.resolver)
+			tt.mockFn(mockRepo)
+
+			domain, err := core.DomainService.Verify(WithSystemContext(context.Background()), 1)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantCode != 0:
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.wantCode, apiErr.Code)
+			default:
+				require.NoError(t, err)
+				assert.True(t, domain.VerifiedAt.Valid)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDomainService_AcceptsAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		mockFn  func(*mocks.Repository)
+		want    bool
+	}{
+		{
+			name:    "verified domain",
+			address: "qa@Example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+					Return(&models.Domain{ProjectID: 1, Name: "example.com"}, nil)
+			},
+			want: true,
+		},
+		{
+			name:    "unverified domain",
+			address: "qa@other.example",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "other.example").Return(nil, nil)
+			},
+			want: false,
+		},
+		{
+			name:    "subdomain verified by another project",
+			address: "qa@sub.example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "sub.example.com").
+					Return(&models.Domain{ProjectID: 2, Name: "sub.example.com"}, nil)
+			},
+			want: false,
+		},
+		{
+			name:    "no domain",
+			address: "qa",
+			mockFn:  func(m *mocks.Repository) {},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupDomainTestCore(t, fakeResolver{})
+			tt.mockFn(mockRepo)
+
+			got, err := core.DomainService.AcceptsAddress(WithSystemContext(context.Background()), 1, tt.address)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -38,6 +38,44 @@ var (
 		Code:    http.StatusBadRequest,
 		Message: "bad request",
 	}
+
+	ErrConflict = &APIError{
+		Code:    http.StatusConflict,
+		Message: "resource already exists",
+	}
+
+	ErrForbidden = &APIError{
+		Code:    http.StatusForbidden,
+		Message: "permission denied",
+	}
+
+	ErrUnauthorized = &APIError{
+		Code:    http.StatusUnauthorized,
+		Message: "invalid credentials",
+	}
+
+	// ErrInboxRateLimited, ErrInboxFull and ErrMessageTooLarge reject a
+	// message exceeding a quota of its inbox
+	ErrInboxRateLimited = &APIError{
+		Code:    http.StatusTooManyRequests,
+		Message: "inbox received too many messages in the last hour",
+	}
+
+	ErrInboxFull = &APIError{
+		Code:    http.StatusInsufficientStorage,
+		Message: "inbox storage quota exceeded",
+	}
+
+	ErrMessageTooLarge = &APIError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "message exceeds the size limit of the inbox",
+	}
+
+	// ErrRelayDisabled rejects outgoing mail while no relay is configured
+	ErrRelayDisabled = &APIError{
+		Code:    http.StatusServiceUnavailable,
+		Message: "sending mail requires a relay, relay.addr is not configured",
+	}
 )
 
 func (c *Core) HandleError(err error, code int) error {
@@ -56,6 +94,8 @@ func (c *Core) HandleError(err error, code int) error {
 		return echo.NewHTTPError(http.StatusNotFound, ErrNotFound)
 	case errors.Is(err, storage.ErrNoRowsAffected):
 		return echo.NewHTTPError(http.StatusNotFound, ErrNotFound)
+	case errors.Is(err, storage.ErrConflict):
+		return echo.NewHTTPError(http.StatusConflict, ErrConflict)
 	}
 
 	if code >= 500 {
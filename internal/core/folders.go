@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"inbox451/internal/models"
+)
+
+type FolderService struct {
+	core *Core
+}
+
+func NewFolderService(core *Core) FolderService {
+	return FolderService{core: core}
+}
+
+func (s *FolderService) Create(ctx context.Context, folder *models.Folder) error {
+	s.core.Logger.Info("Creating folder %q in inbox %d", folder.Name, folder.InboxID)
+
+	if err := s.core.authorizeInbox(ctx, folder.InboxID, RoleUser); err != nil {
+		return err
+	}
+
+	if err := validateFolderName(folder.Name); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.CreateFolder(ctx, folder); err != nil {
+		s.core.Logger.Error("Failed to create folder: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully created folder with ID: %d", folder.ID)
+	return nil
+}
+
+func (s *FolderService) Get(ctx context.Context, id int) (*models.Folder, error) {
+	s.core.Logger.Debug("Fetching folder with ID: %d", id)
+
+	folder, err := s.core.Repository.GetFolder(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch folder: %v", err)
+		return nil, err
+	}
+
+	if folder == nil {
+		s.core.Logger.Info("Folder not found with ID: %d", id)
+		return nil, ErrNotFound
+	}
+
+	if err := s.core.authorizeInbox(ctx, folder.InboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (s *FolderService) GetByName(ctx context.Context, inboxID int, name string) (*models.Folder, error) {
+	s.core.Logger.Debug("Fetching folder %q of inbox %d", name, inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	folder, err := s.core.Repository.GetFolderByName(ctx, inboxID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// Rename renames a folder, the folders below it in the hierarchy are renamed
+// along with it
+func (s *FolderService) Rename(ctx context.Context, folder *models.Folder, name string) error {
+	s.core.Logger.Info("Renaming folder %d from %q to %q", folder.ID, folder.Name, name)
+
+	if err := s.core.authorizeInbox(ctx, folder.InboxID, RoleUser); err != nil {
+		return err
+	}
+
+	if err := validateFolderName(name); err != nil {
+		return err
+	}
+	if strings.HasPrefix(name, folder.Name+"/") {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "a folder cannot be moved below itself",
+		}
+	}
+
+	if err := s.core.Repository.RenameFolder(ctx, folder.InboxID, folder.Name, name); err != nil {
+		s.core.Logger.Error("Failed to rename folder: %v", err)
+		return err
+	}
+
+	folder.Name = name
+	s.core.Logger.Info("Successfully renamed folder with ID: %d", folder.ID)
+	return nil
+}
+
+// Delete removes a folder together with the messages filed in it
+func (s *FolderService) Delete(ctx context.Context, id int) error {
+	s.core.Logger.Info("Deleting folder with ID: %d", id)
+
+	if err := s.core.authorizeFolder(ctx, id, RoleUser); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.DeleteFolder(ctx, id); err != nil {
+		s.core.Logger.Error("Failed to delete folder: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully deleted folder with ID: %d", id)
+	return nil
+}
+
+func (s *FolderService) ListByInbox(ctx context.Context, inboxID, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing folders for inbox %d with limit: %d and offset: %d", inboxID, limit, offset)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	folders, total, err := s.core.Repository.ListFoldersByInbox(ctx, inboxID, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list folders: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: folders,
+	}
+	response.Pagination.Total = total
+	response.Pagination.Limit = limit
+	response.Pagination.Offset = offset
+
+	s.core.Logger.Info("Successfully retrieved %d folders (total: %d)", len(folders), total)
+	return response, nil
+}
+
+// ListAllByInbox returns every folder of an inbox ordered by name
+func (s *FolderService) ListAllByInbox(ctx context.Context, inboxID int) ([]*models.Folder, error) {
+	s.core.Logger.Debug("Listing all folders for inbox %d", inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	folders, err := s.core.Repository.ListAllFoldersByInbox(ctx, inboxID)
+	if err != nil {
+		s.core.Logger.Error("Failed to list folders: %v", err)
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// validateFolderName checks a folder name is usable as an IMAP mailbox name:
+// "/" separates hierarchy levels, so no level may be empty, and the IMAP
+// LIST wildcards are not allowed
+func validateFolderName(name string) error {
+	if name == "" {
+		return &APIError{Code: http.StatusBadRequest, Message: "folder name is required"}
+	}
+
+	for _, level := range strings.Split(name, "/") {
+		if strings.TrimSpace(level) == "" {
+			return &APIError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid folder name %q: empty hierarchy level", name),
+			}
+		}
+	}
+
+	if strings.ContainsAny(name, "*%") {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid folder name %q: * and %% are not allowed", name),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupFolderTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	core := &Core{
+		Logger:     logger,
+		Repository: mockRepo,
+	}
+	core.FolderService = NewFolderService(core)
+
+	return core, mockRepo
+}
+
+func TestFolderService_Create(t *testing.T) {
+	tests := []struct {
+		name    string
+		folder  *models.Folder
+		mockFn  func(*mocks.Repository)
+		wantErr bool
+	}{
+		{
+			name:   "successful creation",
+			folder: &models.Folder{InboxID: 1, Name: "Archive/2024"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateFolder", mock.Anything, mock.AnythingOfType("*models.Folder")).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty hierarchy level",
+			folder:  &models.Folder{InboxID: 1, Name: "Archive//2024"},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
+		{
+			name:    "trailing delimiter",
+			folder:  &models.Folder{InboxID: 1, Name: "Archive/"},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
+		{
+			name:    "wildcard",
+			folder:  &models.Folder{InboxID: 1, Name: "Arch*"},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
+		{
+			name:   "duplicate name",
+			folder: &models.Folder{InboxID: 1, Name: "Archive"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateFolder", mock.Anything, mock.AnythingOfType("*models.Folder")).Return(storage.ErrConflict)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupFolderTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.FolderService.Create(WithSystemContext(context.Background()), tt.folder)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFolderService_Get(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		wantErr bool
+	}{
+		{
+			name: "existing folder",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolder", mock.Anything, 3).Return(&models.Folder{Base: models.Base{ID: 3}, InboxID: 1, Name: "Archive"}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolder", mock.Anything, 3).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupFolderTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.FolderService.Get(WithSystemContext(context.Background()), 3)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, got.ID)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFolderService_Rename(t *testing.T) {
+	tests := []struct {
+		name     string
+		newName  string
+		mockFn   func(*mocks.Repository)
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:    "successful rename",
+			newName: "Old",
+			mockFn: func(m *mocks.Repository) {
+				m.On("RenameFolder", mock.Anything, 1, "Archive", "Old").Return(nil)
+			},
+			wantName: "Old",
+			wantErr:  false,
+		},
+		{
+			name:     "below itself",
+			newName:  "Archive/Old",
+			mockFn:   func(m *mocks.Repository) {},
+			wantName: "Archive",
+			wantErr:  true,
+		},
+		{
+			name:    "repository error",
+			newName: "Old",
+			mockFn: func(m *mocks.Repository) {
+				m.On("RenameFolder", mock.Anything, 1, "Archive", "Old").Return(errors.New("database error"))
+			},
+			wantName: "Archive",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupFolderTestCore(t)
+			tt.mockFn(mockRepo)
+
+			folder := &models.Folder{Base: models.Base{ID: 3}, InboxID: 1, Name: "Archive"}
+			err := core.FolderService.Rename(WithSystemContext(context.Background()), folder, tt.newName)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantName, folder.Name)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFolderService_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		wantErr bool
+	}{
+		{
+			name: "successful deletion",
+			mockFn: func(m *mocks.Repository) {
+				m.On("DeleteFolder", mock.Anything, 3).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(m *mocks.Repository) {
+				m.On("DeleteFolder", mock.Anything, 3).Return(storage.ErrNoRowsAffected)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupFolderTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.FolderService.Delete(WithSystemContext(context.Background()), 3)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFolderService_ListByInbox(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "folders found",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListFoldersByInbox", mock.Anything, 1, 10, 0).Return([]*models.Folder{
+					{Base: models.Base{ID: 3}, InboxID: 1, Name: "Archive"},
+				}, 1, nil)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListFoldersByInbox", mock.Anything, 1, 10, 0).Return(nil, 0, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupFolderTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.FolderService.ListByInbox(WithSystemContext(context.Background()), 1, 10, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.Pagination.Total)
+				assert.Len(t, got.Data, tt.want)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
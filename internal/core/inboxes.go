@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"inbox451/internal/models"
 )
@@ -17,6 +19,18 @@ func NewInboxService(core *Core) InboxService {
 func (s *InboxService) Create(ctx context.Context, inbox *models.Inbox) error {
 	s.core.Logger.Info("Creating new inbox for project %d: %s", inbox.ProjectID, inbox.Email)
 
+	if err := s.core.authorizeProject(ctx, inbox.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := validateQuotas(inbox); err != nil {
+		return err
+	}
+
+	if err := s.core.requireVerifiedDomain(ctx, inbox.ProjectID, inbox.Email); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.CreateInbox(ctx, inbox); err != nil {
 		s.core.Logger.Error("Failed to create inbox: %v", err)
 		return err
@@ -40,12 +54,60 @@ func (s *InboxService) Get(ctx context.Context, id int) (*models.Inbox, error) {
 		return nil, ErrNotFound
 	}
 
+	if err := s.core.authorizeProject(ctx, inbox.ProjectID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	return inbox, nil
+}
+
+// GetByAddress resolves the recipient address of an incoming message to an
+// inbox. Besides inboxes with exactly that email, plus-addresses such as
+// inbox+anything@example.com reach the inbox of their base address, and
+// inboxes whose email contains * wildcards, such as *@qa.example.com, catch
+// every matching address. Returns nil when no inbox accepts the address.
+func (s *InboxService) GetByAddress(ctx context.Context, address string) (*models.Inbox, error) {
+	inbox, err := s.core.Repository.GetInboxByAddress(ctx, address, BaseAddress(address))
+	if err != nil {
+		s.core.Logger.Error("Failed to find inbox for address %s: %v", address, err)
+		return nil, err
+	}
 	return inbox, nil
 }
 
+// BaseAddress strips the +tag from the local part of a plus-address, other
+// addresses are returned unchanged
+func BaseAddress(address string) string {
+	local, domain, found := strings.Cut(address, "@")
+	if !found {
+		return address
+	}
+	if base, _, tagged := strings.Cut(local, "+"); tagged && base != "" {
+		return base + "@" + domain
+	}
+	return address
+}
+
 func (s *InboxService) Update(ctx context.Context, inbox *models.Inbox) error {
 	s.core.Logger.Info("Updating inbox with ID: %d", inbox.ID)
 
+	if err := s.core.authorizeInbox(ctx, inbox.ID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := validateQuotas(inbox); err != nil {
+		return err
+	}
+
+	existing, err := s.core.Repository.GetInbox(ctx, inbox.ID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch inbox: %v", err)
+		return err
+	}
+	if err := s.core.requireVerifiedDomain(ctx, existing.ProjectID, inbox.Email); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.UpdateInbox(ctx, inbox); err != nil {
 		s.core.Logger.Error("Failed to update inbox: %v", err)
 		return err
@@ -58,6 +120,10 @@ func (s *InboxService) Update(ctx context.Context, inbox *models.Inbox) error {
 func (s *InboxService) Delete(ctx context.Context, id int) error {
 	s.core.Logger.Info("Deleting inbox with ID: %d", id)
 
+	if err := s.core.authorizeInbox(ctx, id, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.DeleteInbox(ctx, id); err != nil {
 		s.core.Logger.Error("Failed to delete inbox: %v", err)
 		return err
@@ -70,6 +136,10 @@ func (s *InboxService) Delete(ctx context.Context, id int) error {
 func (s *InboxService) ListByProject(ctx context.Context, projectID, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Info("Listing inboxes for project %d with limit: %d and offset: %d", projectID, limit, offset)
 
+	if err := s.core.authorizeProject(ctx, projectID, RoleUser); err != nil {
+		return nil, err
+	}
+
 	inboxes, total, err := s.core.Repository.ListInboxesByProject(ctx, projectID, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list inboxes: %v", err)
@@ -86,3 +156,81 @@ func (s *InboxService) ListByProject(ctx context.Context, projectID, limit, offs
 	s.core.Logger.Info("Successfully retrieved %d inboxes (total: %d)", len(inboxes), total)
 	return response, nil
 }
+
+// ListByUser returns every inbox reachable by the user through the projects
+// they belong to
+func (s *InboxService) ListByUser(ctx context.Context, userID int) ([]*models.Inbox, error) {
+	s.core.Logger.Debug("Listing inboxes for user %d", userID)
+
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	inboxes, err := s.core.Repository.ListInboxesByUser(ctx, userID)
+	if err != nil {
+		s.core.Logger.Error("Failed to list inboxes for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	return inboxes, nil
+}
+
+// Usage returns the messages an inbox holds and received during the last
+// hour, together with its quotas
+func (s *InboxService) Usage(ctx context.Context, id int) (*models.InboxUsage, error) {
+	inbox, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.core.Repository.GetInboxUsage(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch usage of inbox %d: %v", id, err)
+		return nil, err
+	}
+
+	usage.MaxMessagesPerHour = inbox.MaxMessagesPerHour
+	usage.MaxTotalBytes = inbox.MaxTotalBytes
+	usage.MaxMessageBytes = inbox.MaxMessageBytes
+	return usage, nil
+}
+
+// CheckQuota reports whether an inbox accepts another message of size bytes.
+// A size of 0 stands for a message of unknown size, only checking whether
+// the inbox is already at its limits. Concurrent deliveries are not
+// serialized, so an inbox may end up slightly over its quotas.
+func (s *InboxService) CheckQuota(ctx context.Context, inbox *models.Inbox, size int) error {
+	if inbox.MaxMessageBytes.Valid && size > inbox.MaxMessageBytes.Int {
+		return ErrMessageTooLarge
+	}
+	if !inbox.MaxMessagesPerHour.Valid && !inbox.MaxTotalBytes.Valid {
+		return nil
+	}
+
+	usage, err := s.core.Repository.GetInboxUsage(ctx, inbox.ID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch usage of inbox %d: %v", inbox.ID, err)
+		return err
+	}
+
+	if inbox.MaxMessagesPerHour.Valid && usage.MessagesLastHour >= inbox.MaxMessagesPerHour.Int {
+		return ErrInboxRateLimited
+	}
+	if inbox.MaxTotalBytes.Valid &&
+		(usage.TotalBytes >= inbox.MaxTotalBytes.Int64 || usage.TotalBytes+int64(size) > inbox.MaxTotalBytes.Int64) {
+		return ErrInboxFull
+	}
+	return nil
+}
+
+func validateQuotas(inbox *models.Inbox) error {
+	if (inbox.MaxMessagesPerHour.Valid && inbox.MaxMessagesPerHour.Int <= 0) ||
+		(inbox.MaxTotalBytes.Valid && inbox.MaxTotalBytes.Int64 <= 0) ||
+		(inbox.MaxMessageBytes.Valid && inbox.MaxMessageBytes.Int <= 0) {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "inbox quotas must be positive",
+		}
+	}
+	return nil
+}
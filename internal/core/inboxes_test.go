@@ -44,6 +44,8 @@ func TestInboxService_Create(t *testing.T) {
 				Email:     "test@example.com",
 			},
 			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+					Return(&models.Domain{ProjectID: 1, Name: "example.com"}, nil)
 				m.On("CreateInbox", mock.Anything, mock.AnythingOfType("*models.Inbox")).
 					Return(nil)
 			},
@@ -56,11 +58,35 @@ func TestInboxService_Create(t *testing.T) {
 				Email:     "test@example.com",
 			},
 			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+					Return(&models.Domain{ProjectID: 1, Name: "example.com"}, nil)
 				m.On("CreateInbox", mock.Anything, mock.AnythingOfType("*models.Inbox")).
 					Return(errors.New("database error"))
 			},
 			wantErr: true,
 		},
+		{
+			name: "non-positive quota",
+			inbox: &models.Inbox{
+				ProjectID:     1,
+				Email:         "test@example.com",
+				MaxTotalBytes: null.Int64From(0),
+			},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
+		{
+			name: "domain not verified",
+			inbox: &models.Inbox{
+				ProjectID: 1,
+				Email:     "test@Unverified.example",
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetVerifiedDomainForHost", mock.Anything, "unverified.example").
+					Return(nil, nil)
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -68,7 +94,7 @@ func TestInboxService_Create(t *testing.T) {
 			core, mockRepo := setupInboxTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.InboxService.Create(context.Background(), tt.inbox)
+			err := core.InboxService.Create(WithSystemContext(context.Background()), tt.inbox)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -132,7 +158,7 @@ func TestInboxService_Get(t *testing.T) {
 			core, mockRepo := setupInboxTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.InboxService.Get(context.Background(), tt.id)
+			got, err := core.InboxService.Get(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -148,6 +174,83 @@ func TestInboxService_Get(t *testing.T) {
 	}
 }
 
+func TestInboxService_GetByAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		mockFn  func(*mocks.Repository)
+		want    *models.Inbox
+		wantErr bool
+	}{
+		{
+			name:    "plus-address resolves with its base address",
+			address: "qa+run42@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "qa+run42@example.com", "qa@example.com").
+					Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 1, Email: "qa@example.com"}, nil)
+			},
+			want:    &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 1, Email: "qa@example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "no matching inbox",
+			address: "nobody@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "nobody@example.com", "nobody@example.com").
+					Return(nil, nil)
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "repository error",
+			address: "qa@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "qa@example.com", "qa@example.com").
+					Return(nil, errors.New("database error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupInboxTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.InboxService.GetByAddress(WithSystemContext(context.Background()), tt.address)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBaseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"qa+run42@example.com", "qa@example.com"},
+		{"qa+a+b@example.com", "qa@example.com"},
+		{"qa@example.com", "qa@example.com"},
+		{"+tag@example.com", "+tag@example.com"},
+		{"no-domain+tag", "no-domain+tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.want, BaseAddress(tt.address))
+		})
+	}
+}
+
 func TestInboxService_Update(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -163,6 +266,10 @@ func TestInboxService_Update(t *testing.T) {
 				Email:     "updated@example.com",
 			},
 			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 1).
+					Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 1, Email: "test@example.com"}, nil)
+				m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+					Return(&models.Domain{ProjectID: 1, Name: "example.com"}, nil)
 				m.On("UpdateInbox", mock.Anything, mock.AnythingOfType("*models.Inbox")).
 					Return(nil)
 			},
@@ -176,8 +283,23 @@ func TestInboxService_Update(t *testing.T) {
 				Email:     "updated@example.com",
 			},
 			mockFn: func(m *mocks.Repository) {
-				m.On("UpdateInbox", mock.Anything, mock.AnythingOfType("*models.Inbox")).
-					Return(storage.ErrNotFound)
+				m.On("GetInbox", mock.Anything, 999).
+					Return(nil, storage.ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "move to an unverified domain",
+			inbox: &models.Inbox{
+				Base:      models.Base{ID: 1},
+				ProjectID: 1,
+				Email:     "updated@other.example",
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 1).
+					Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 1, Email: "test@example.com"}, nil)
+				m.On("GetVerifiedDomainForHost", mock.Anything, "other.example").
+					Return(nil, nil)
 			},
 			wantErr: true,
 		},
@@ -188,7 +310,7 @@ func TestInboxService_Update(t *testing.T) {
 			core, mockRepo := setupInboxTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.InboxService.Update(context.Background(), tt.inbox)
+			err := core.InboxService.Update(WithSystemContext(context.Background()), tt.inbox)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -230,7 +352,7 @@ func TestInboxService_Delete(t *testing.T) {
 			core, mockRepo := setupInboxTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.InboxService.Delete(context.Background(), tt.id)
+			err := core.InboxService.Delete(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -331,7 +453,7 @@ func TestInboxService_ListByProject(t *testing.T) {
 			core, mockRepo := setupInboxTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.InboxService.ListByProject(context.Background(), tt.projectID, tt.limit, tt.offset)
+			got, err := core.InboxService.ListByProject(WithSystemContext(context.Background()), tt.projectID, tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -343,3 +465,145 @@ func TestInboxService_ListByProject(t *testing.T) {
 		})
 	}
 }
+
+func TestInboxService_ListByUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  int
+		mockFn  func(*mocks.Repository)
+		want    []*models.Inbox
+		wantErr bool
+	}{
+		{
+			name:   "successful list",
+			userID: 1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListInboxesByUser", mock.Anything, 1).
+					Return([]*models.Inbox{{Base: models.Base{ID: 1}, ProjectID: 1, Email: "test@example.com"}}, nil)
+			},
+			want:    []*models.Inbox{{Base: models.Base{ID: 1}, ProjectID: 1, Email: "test@example.com"}},
+			wantErr: false,
+		},
+		{
+			name:   "repository error",
+			userID: 1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListInboxesByUser", mock.Anything, 1).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupInboxTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.InboxService.ListByUser(WithSystemContext(context.Background()), tt.userID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInboxService_Usage(t *testing.T) {
+	core, mockRepo := setupInboxTestCore(t)
+
+	mockRepo.On("GetInbox", mock.Anything, 1).Return(&models.Inbox{
+		Base:          models.Base{ID: 1},
+		ProjectID:     1,
+		MaxTotalBytes: null.Int64From(1 << 20),
+	}, nil)
+	mockRepo.On("GetInboxUsage", mock.Anything, 1).
+		Return(&models.InboxUsage{InboxID: 1, Messages: 4, TotalBytes: 2048, MessagesLastHour: 2}, nil)
+
+	usage, err := core.InboxService.Usage(WithSystemContext(context.Background()), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, usage.Messages)
+	assert.Equal(t, null.Int64From(1<<20), usage.MaxTotalBytes)
+	assert.False(t, usage.MaxMessagesPerHour.Valid)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestInboxService_CheckQuota(t *testing.T) {
+	usage := &models.InboxUsage{InboxID: 1, Messages: 10, TotalBytes: 900, MessagesLastHour: 5}
+
+	tests := []struct {
+		name    string
+		inbox   *models.Inbox
+		size    int
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name:   "no quotas",
+			inbox:  &models.Inbox{Base: models.Base{ID: 1}},
+			size:   4096,
+			mockFn: func(m *mocks.Repository) {},
+		},
+		{
+			name:    "message too large",
+			inbox:   &models.Inbox{Base: models.Base{ID: 1}, MaxMessageBytes: null.IntFrom(1024)},
+			size:    4096,
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: ErrMessageTooLarge,
+		},
+		{
+			name:  "within quotas",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxMessagesPerHour: null.IntFrom(6), MaxTotalBytes: null.Int64From(1000)},
+			size:  100,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(usage, nil)
+			},
+		},
+		{
+			name:  "hourly limit reached",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxMessagesPerHour: null.IntFrom(5)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(usage, nil)
+			},
+			wantErr: ErrInboxRateLimited,
+		},
+		{
+			name:  "message would exceed storage",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxTotalBytes: null.Int64From(1000)},
+			size:  101,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(usage, nil)
+			},
+			wantErr: ErrInboxFull,
+		},
+		{
+			name:  "storage full before the size is known",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxTotalBytes: null.Int64From(900)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(usage, nil)
+			},
+			wantErr: ErrInboxFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupInboxTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.InboxService.CheckQuota(WithSystemContext(context.Background()), tt.inbox, tt.size)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
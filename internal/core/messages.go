@@ -1,9 +1,15 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"time"
 
+	"inbox451/internal/events"
 	"inbox451/internal/models"
+
+	"github.com/emersion/go-message/mail"
+	null "github.com/volatiletech/null/v9"
 )
 
 type MessageService struct {
@@ -17,12 +23,63 @@ func NewMessageService(core *Core) MessageService {
 func (s *MessageService) Store(ctx context.Context, message *models.Message) error {
 	s.core.Logger.Info("Storing new message for inbox %d from %s", message.InboxID, message.Sender)
 
+	if err := s.core.authorizeInbox(ctx, message.InboxID, RoleUser); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.CreateMessage(ctx, message); err != nil {
 		s.core.Logger.Error("Failed to store message: %v", err)
 		return err
 	}
 
 	s.core.Logger.Info("Successfully stored message with ID: %d", message.ID)
+
+	s.core.Events.Publish(events.Event{
+		Type:       events.MessageCreated,
+		InboxID:    message.InboxID,
+		FolderID:   message.FolderID,
+		MessageIDs: []int{message.ID},
+	})
+
+	// Rule failures must never cause an accepted message to be rejected
+	if err := s.core.RuleService.Apply(ctx, message); err != nil {
+		s.core.Logger.Error("Failed to apply rules to message %d: %v", message.ID, err)
+	}
+
+	return nil
+}
+
+// StoreAll stores one copy of a message per recipient inbox atomically, so a
+// failure leaves none of the inboxes with a partial delivery.
+func (s *MessageService) StoreAll(ctx context.Context, messages []*models.Message) error {
+	s.core.Logger.Info("Storing %d message copies", len(messages))
+
+	for _, message := range messages {
+		if err := s.core.authorizeInbox(ctx, message.InboxID, RoleUser); err != nil {
+			return err
+		}
+	}
+
+	if err := s.core.Repository.CreateMessages(ctx, messages); err != nil {
+		s.core.Logger.Error("Failed to store messages: %v", err)
+		return err
+	}
+
+	for _, message := range messages {
+		s.core.Logger.Info("Successfully stored message with ID: %d in inbox %d", message.ID, message.InboxID)
+
+		s.core.Events.Publish(events.Event{
+			Type:       events.MessageCreated,
+			InboxID:    message.InboxID,
+			FolderID:   message.FolderID,
+			MessageIDs: []int{message.ID},
+		})
+
+		if err := s.core.RuleService.Apply(ctx, message); err != nil {
+			s.core.Logger.Error("Failed to apply rules to message %d: %v", message.ID, err)
+		}
+	}
+
 	return nil
 }
 
@@ -40,14 +97,197 @@ func (s *MessageService) Get(ctx context.Context, id int) (*models.Message, erro
 		return nil, ErrNotFound
 	}
 
+	if err := s.core.authorizeInbox(ctx, message.InboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
 	return message, nil
 }
 
-func (s *MessageService) ListByInbox(ctx context.Context, inboxID int, limit, offset int, isRead *bool) (*models.PaginatedResponse, error) {
-	s.core.Logger.Info("Listing messages for inbox %d with limit: %d, offset: %d, isRead: %v",
-		inboxID, limit, offset, isRead)
+// GetRaw returns the RFC 5322 source of a message. Messages stored before the
+// raw source was persisted are rendered from their stored fields instead.
+func (s *MessageService) GetRaw(ctx context.Context, id int) ([]byte, error) {
+	s.core.Logger.Debug("Fetching raw source of message with ID: %d", id)
+
+	message, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.core.Repository.GetMessageRaw(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch raw message: %v", err)
+		return nil, err
+	}
+
+	if raw == nil {
+		s.core.Logger.Debug("Message %d has no stored source, rendering it", id)
+		return renderMessage(message)
+	}
+
+	return raw, nil
+}
+
+// ListFlagsByFolder returns every message of a folder ordered by ID, with
+// only the ID, flags, size and date loaded. A null folderID lists the top
+// level of the inbox.
+func (s *MessageService) ListFlagsByFolder(ctx context.Context, inboxID int, folderID null.Int) ([]*models.Message, error) {
+	s.core.Logger.Debug("Listing message flags for inbox %d", inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.core.Repository.ListMessageFlagsByFolder(ctx, inboxID, folderID)
+	if err != nil {
+		s.core.Logger.Error("Failed to list messages: %v", err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// StatusByFolder returns the message counters of a folder, a null folderID
+// selects the top level of the inbox
+func (s *MessageService) StatusByFolder(ctx context.Context, inboxID int, folderID null.Int) (*models.FolderStatus, error) {
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	status, err := s.core.Repository.GetFolderStatus(ctx, inboxID, folderID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch folder status: %v", err)
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// CountByFolder returns the number of messages of a folder, a null folderID
+// counts the top level of the inbox
+func (s *MessageService) CountByFolder(ctx context.Context, inboxID int, folderID null.Int) (int, error) {
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return 0, err
+	}
+
+	total, err := s.core.Repository.CountMessagesByFolder(ctx, inboxID, folderID)
+	if err != nil {
+		s.core.Logger.Error("Failed to count messages: %v", err)
+		return 0, err
+	}
+	return total, nil
+}
+
+// CountBelow returns for each of ids the number of messages of a folder with
+// a lower ID
+func (s *MessageService) CountBelow(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.core.Repository.CountMessagesBelow(ctx, inboxID, folderID, ids)
+	if err != nil {
+		s.core.Logger.Error("Failed to count messages: %v", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+// Search returns the IDs of the messages of a folder matching the search, in
+// ascending order
+func (s *MessageService) Search(ctx context.Context, inboxID int, folderID null.Int, search *models.MessageSearch) ([]int, error) {
+	s.core.Logger.Debug("Searching messages of inbox %d", inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.core.Repository.SearchMessages(ctx, inboxID, folderID, search)
+	if err != nil {
+		s.core.Logger.Error("Failed to search messages: %v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// ListByInbox returns a page of the messages of an inbox. isRead optionally
+// keeps only the read or unread messages, a non-empty receiver only those
+// sent to a matching original recipient, * matching any characters.
+func (s *MessageService) ListByInbox(ctx context.Context, inboxID int, limit, offset int, isRead *bool, receiver string) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing messages for inbox %d with limit: %d, offset: %d, isRead: %v, receiver: %q",
+		inboxID, limit, offset, isRead, receiver)
 
-	messages, total, err := s.core.Repository.ListMessagesByInboxWithFilter(ctx, inboxID, isRead, limit, offset)
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.core.Repository.ListMessagesByInboxWithFilter(ctx, inboxID, isRead, receiver, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list messages: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: messages,
+	}
+	response.Pagination.Total = total
+	response.Pagination.Limit = limit
+	response.Pagination.Offset = offset
+
+	s.core.Logger.Info("Successfully retrieved %d messages (total: %d)", len(messages), total)
+	return response, nil
+}
+
+// ListByFilter returns a page of the messages matching a filter, either
+// within an inbox or across every inbox of a project
+func (s *MessageService) ListByFilter(ctx context.Context, filter *models.MessageFilter, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Searching messages of project %d inbox %d with limit: %d, offset: %d, query: %q",
+		filter.ProjectID, filter.InboxID, limit, offset, filter.Query)
+
+	switch {
+	case filter.InboxID > 0:
+		if err := s.core.authorizeInbox(ctx, filter.InboxID, RoleUser); err != nil {
+			return nil, err
+		}
+	case filter.ProjectID > 0:
+		if err := s.core.authorizeProject(ctx, filter.ProjectID, RoleUser); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrBadRequest
+	}
+
+	messages, total, err := s.core.Repository.ListMessagesByFilter(ctx, filter, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to search messages: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: messages,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully found %d messages (total: %d)", len(messages), total)
+	return response, nil
+}
+
+// ListByFolder returns a page of the messages of a folder, a null folderID
+// lists the top level of the inbox
+func (s *MessageService) ListByFolder(ctx context.Context, inboxID int, folderID null.Int, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing messages for inbox %d folder %d with limit: %d, offset: %d",
+		inboxID, folderID.Int, limit, offset)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.core.Repository.ListMessagesByFolder(ctx, inboxID, folderID, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list messages: %v", err)
 		return nil, err
@@ -67,6 +307,10 @@ func (s *MessageService) ListByInbox(ctx context.Context, inboxID int, limit, of
 func (s *MessageService) MarkAsRead(ctx context.Context, messageID int) error {
 	s.core.Logger.Debug("Marking message %d as read", messageID)
 
+	if err := s.core.authorizeMessage(ctx, messageID, RoleUser); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.UpdateMessageReadStatus(ctx, messageID, true); err != nil {
 		s.core.Logger.Error("Failed to mark message as read: %v", err)
 		return err
@@ -79,6 +323,10 @@ func (s *MessageService) MarkAsRead(ctx context.Context, messageID int) error {
 func (s *MessageService) MarkAsUnread(ctx context.Context, messageID int) error {
 	s.core.Logger.Debug("Marking message %d as unread", messageID)
 
+	if err := s.core.authorizeMessage(ctx, messageID, RoleUser); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.UpdateMessageReadStatus(ctx, messageID, false); err != nil {
 		s.core.Logger.Error("Failed to mark message as unread: %v", err)
 		return err
@@ -88,14 +336,209 @@ func (s *MessageService) MarkAsUnread(ctx context.Context, messageID int) error
 	return nil
 }
 
+// UpdateFlags persists the flag fields (IsRead, IsFlagged, IsAnswered,
+// IsDeleted and Keywords) of a message
+func (s *MessageService) UpdateFlags(ctx context.Context, message *models.Message) error {
+	s.core.Logger.Debug("Updating flags of message %d", message.ID)
+
+	if err := s.core.authorizeMessage(ctx, message.ID, RoleUser); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.UpdateMessageFlags(ctx, message); err != nil {
+		s.core.Logger.Error("Failed to update message flags: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Expunge permanently removes the messages of a folder flagged as deleted,
+// restricted to ids when it is not nil, and returns the removed IDs
+func (s *MessageService) Expunge(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	s.core.Logger.Debug("Expunging deleted messages of inbox %d", inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	expunged, err := s.core.Repository.ExpungeMessages(ctx, inboxID, folderID, ids)
+	if err != nil {
+		s.core.Logger.Error("Failed to expunge messages: %v", err)
+		return nil, err
+	}
+
+	s.core.Logger.Info("Expunged %d messages from inbox %d", len(expunged), inboxID)
+
+	if len(expunged) > 0 {
+		s.core.Events.Publish(events.Event{
+			Type:       events.MessagesDeleted,
+			InboxID:    inboxID,
+			FolderID:   folderID,
+			MessageIDs: expunged,
+		})
+	}
+	return expunged, nil
+}
+
+// Copy copies messages into a folder of an inbox, a null folderID copies to
+// the top level. The IDs of the copies are returned in the order of messages.
+func (s *MessageService) Copy(ctx context.Context, messages []*models.Message, inboxID int, folderID null.Int) ([]int, error) {
+	s.core.Logger.Debug("Copying %d messages to inbox %d", len(messages), inboxID)
+
+	if err := s.authorizeTransfer(ctx, messages, inboxID); err != nil {
+		return nil, err
+	}
+
+	copies, err := s.core.Repository.CopyMessages(ctx, messageIDs(messages), inboxID, folderID)
+	if err != nil {
+		s.core.Logger.Error("Failed to copy messages: %v", err)
+		return nil, err
+	}
+
+	s.core.Events.Publish(events.Event{
+		Type:       events.MessageCreated,
+		InboxID:    inboxID,
+		FolderID:   folderID,
+		MessageIDs: copies,
+	})
+	return copies, nil
+}
+
+// Move moves messages into a folder of an inbox, a null folderID moves them
+// to the top level. Moved messages get new IDs, which are returned in the
+// order of messages.
+func (s *MessageService) Move(ctx context.Context, messages []*models.Message, inboxID int, folderID null.Int) ([]int, error) {
+	s.core.Logger.Debug("Moving %d messages to inbox %d", len(messages), inboxID)
+
+	if err := s.authorizeTransfer(ctx, messages, inboxID); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.core.Repository.MoveMessages(ctx, messageIDs(messages), inboxID, folderID)
+	if err != nil {
+		s.core.Logger.Error("Failed to move messages: %v", err)
+		return nil, err
+	}
+
+	s.core.Logger.Info("Moved %d messages to inbox %d", len(moved), inboxID)
+
+	// Sessions watching the destination see the new messages before the
+	// originals disappear from the source
+	s.core.Events.Publish(events.Event{
+		Type:       events.MessageCreated,
+		InboxID:    inboxID,
+		FolderID:   folderID,
+		MessageIDs: moved,
+	})
+	for _, source := range groupByFolder(messages) {
+		s.core.Events.Publish(events.Event{
+			Type:       events.MessagesDeleted,
+			InboxID:    source[0].InboxID,
+			FolderID:   source[0].FolderID,
+			MessageIDs: messageIDs(source),
+		})
+	}
+	return moved, nil
+}
+
 func (s *MessageService) Delete(ctx context.Context, messageID int) error {
 	s.core.Logger.Debug("Deleting message with ID: %d", messageID)
 
+	// The inbox is needed to notify subscribers once the message is gone
+	message, err := s.Get(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.DeleteMessage(ctx, messageID); err != nil {
 		s.core.Logger.Error("Failed to delete message: %v", err)
 		return err
 	}
 
 	s.core.Logger.Info("Successfully deleted message with ID: %d", messageID)
+
+	s.core.Events.Publish(events.Event{
+		Type:       events.MessagesDeleted,
+		InboxID:    message.InboxID,
+		FolderID:   message.FolderID,
+		MessageIDs: []int{messageID},
+	})
 	return nil
 }
+
+// authorizeTransfer requires access to the inboxes holding the messages and
+// to the destination inbox
+func (s *MessageService) authorizeTransfer(ctx context.Context, messages []*models.Message, inboxID int) error {
+	inboxes := map[int]bool{inboxID: true}
+	for _, message := range messages {
+		inboxes[message.InboxID] = true
+	}
+	for id := range inboxes {
+		if err := s.core.authorizeInbox(ctx, id, RoleUser); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func messageIDs(messages []*models.Message) []int {
+	ids := make([]int, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+// groupByFolder splits messages by the inbox folder they are filed in,
+// keeping the order of first appearance
+func groupByFolder(messages []*models.Message) [][]*models.Message {
+	type key struct {
+		inboxID  int
+		folderID null.Int
+	}
+
+	var groups [][]*models.Message
+	index := make(map[key]int)
+	for _, message := range messages {
+		k := key{message.InboxID, message.FolderID}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], message)
+	}
+	return groups
+}
+
+// renderMessage builds a plain text RFC 5322 message from the stored fields
+// of a message.
+func renderMessage(message *models.Message) ([]byte, error) {
+	date := time.Now()
+	if message.CreatedAt.Valid {
+		date = message.CreatedAt.Time
+	}
+
+	var h mail.Header
+	h.SetDate(date)
+	h.SetSubject(message.Subject)
+	h.SetAddressList("From", []*mail.Address{{Address: message.Sender}})
+	h.SetAddressList("To", []*mail.Address{{Address: message.Receiver}})
+	h.SetContentType("text/plain", map[string]string{"charset": "utf-8"})
+
+	var buf bytes.Buffer
+	w, err := mail.CreateSingleInlineWriter(&buf, h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte(message.Body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"inbox451/internal/events"
 	"inbox451/internal/logger"
 	"inbox451/internal/mocks"
 	"inbox451/internal/models"
@@ -24,8 +25,10 @@ func setupMessageTestCore(t *testing.T) (*Core, *mocks.Repository) {
 	core := &Core{
 		Logger:     logger,
 		Repository: mockRepo,
+		Events:     events.NewBus(),
 	}
 	core.MessageService = NewMessageService(core)
+	core.RuleService = NewRuleService(core)
 
 	return core, mockRepo
 }
@@ -49,6 +52,8 @@ func TestMessageService_Store(t *testing.T) {
 			mockFn: func(m *mocks.Repository) {
 				m.On("CreateMessage", mock.Anything, mock.AnythingOfType("*models.Message")).
 					Return(nil)
+				m.On("ListAllRulesByInbox", mock.Anything, 1).
+					Return([]*models.ForwardRule{}, nil)
 			},
 			wantErr: false,
 		},
@@ -74,7 +79,7 @@ func TestMessageService_Store(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.MessageService.Store(context.Background(), tt.message)
+			err := core.MessageService.Store(WithSystemContext(context.Background()), tt.message)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -86,6 +91,126 @@ func TestMessageService_Store(t *testing.T) {
 	}
 }
 
+func TestMessageService_StoreAll(t *testing.T) {
+	newMessages := func() []*models.Message {
+		return []*models.Message{
+			{InboxID: 1, Sender: "sender@example.com", Receiver: "one@example.com", Subject: "Test Subject"},
+			{InboxID: 2, Sender: "sender@example.com", Receiver: "two@example.com", Subject: "Test Subject"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		wantErr bool
+	}{
+		{
+			name: "stores every copy and applies rules per inbox",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateMessages", mock.Anything, mock.AnythingOfType("[]*models.Message")).
+					Return(nil)
+				m.On("ListAllRulesByInbox", mock.Anything, 1).
+					Return([]*models.ForwardRule{}, nil)
+				m.On("ListAllRulesByInbox", mock.Anything, 2).
+					Return([]*models.ForwardRule{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "repository error skips rules",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateMessages", mock.Anything, mock.AnythingOfType("[]*models.Message")).
+					Return(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.MessageService.StoreAll(WithSystemContext(context.Background()), newMessages())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_GetRaw(t *testing.T) {
+	now := time.Now()
+	stored := &models.Message{
+		Base:     models.Base{ID: 1, CreatedAt: null.TimeFrom(now)},
+		InboxID:  1,
+		Sender:   "sender@example.com",
+		Receiver: "inbox@example.com",
+		Subject:  "Test Subject",
+		Body:     "Test Body",
+	}
+
+	tests := []struct {
+		name     string
+		id       int
+		mockFn   func(*mocks.Repository)
+		contains []string
+		wantErr  bool
+	}{
+		{
+			name: "stored source is returned untouched",
+			id:   1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 1).Return(stored, nil)
+				m.On("GetMessageRaw", mock.Anything, 1).
+					Return([]byte("Message-ID: <abc@example.com>\r\nSubject: Test Subject\r\n\r\nTest Body"), nil)
+			},
+			contains: []string{"Message-ID: <abc@example.com>", "Test Body"},
+		},
+		{
+			name: "source is rendered for legacy messages",
+			id:   1,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 1).Return(stored, nil)
+				m.On("GetMessageRaw", mock.Anything, 1).Return(nil, nil)
+			},
+			contains: []string{"Subject: Test Subject", "From: <sender@example.com>", "Test Body"},
+		},
+		{
+			name: "non-existent message",
+			id:   999,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 999).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.MessageService.GetRaw(WithSystemContext(context.Background()), tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, string(got), want)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestMessageService_Get(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -144,7 +269,7 @@ func TestMessageService_Get(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.MessageService.Get(context.Background(), tt.id)
+			got, err := core.MessageService.Get(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -190,7 +315,7 @@ func TestMessageService_ListByInbox(t *testing.T) {
 						IsRead:   true,
 					},
 				}
-				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, &isRead, 10, 0).
+				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, &isRead, "", 10, 0).
 					Return(messages, 1, nil)
 			},
 			want: &models.PaginatedResponse{
@@ -240,7 +365,7 @@ func TestMessageService_ListByInbox(t *testing.T) {
 						IsRead:   false,
 					},
 				}
-				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, (*bool)(nil), 10, 0).
+				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, (*bool)(nil), "", 10, 0).
 					Return(messages, 2, nil)
 			},
 			want: &models.PaginatedResponse{
@@ -279,7 +404,122 @@ func TestMessageService_ListByInbox(t *testing.T) {
 			offset:  0,
 			isRead:  nil,
 			mockFn: func(m *mocks.Repository) {
-				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, (*bool)(nil), 10, 0).
+				m.On("ListMessagesByInboxWithFilter", mock.Anything, 1, (*bool)(nil), "", 10, 0).
+					Return([]*models.Message(nil), 0, errors.New("database error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.MessageService.ListByInbox(WithSystemContext(context.Background()), tt.inboxID, tt.limit, tt.offset, tt.isRead, "")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_ListByFilter(t *testing.T) {
+	member := &models.User{Base: models.Base{ID: 2}, Role: RoleUser}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		filter  *models.MessageFilter
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name:   "project-wide search",
+			ctx:    WithSystemContext(context.Background()),
+			filter: &models.MessageFilter{ProjectID: 1, Query: "invoice"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListMessagesByFilter", mock.Anything, &models.MessageFilter{ProjectID: 1, Query: "invoice"}, 10, 0).
+					Return([]*models.Message{{Base: models.Base{ID: 5}, InboxID: 3}}, 1, nil)
+			},
+		},
+		{
+			name:   "project of another user",
+			ctx:    WithUser(context.Background(), member),
+			filter: &models.MessageFilter{ProjectID: 1, Query: "invoice"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 1, 2).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "neither inbox nor project",
+			ctx:     WithSystemContext(context.Background()),
+			filter:  &models.MessageFilter{Query: "invoice"},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: ErrBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.MessageService.ListByFilter(tt.ctx, tt.filter, 10, 0)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, got.Pagination.Total)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_ListByFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		folderID null.Int
+		mockFn   func(*mocks.Repository)
+		want     *models.PaginatedResponse
+		wantErr  bool
+	}{
+		{
+			name:     "successful list",
+			folderID: null.IntFrom(3),
+			mockFn: func(m *mocks.Repository) {
+				messages := []*models.Message{
+					{Base: models.Base{ID: 4}, InboxID: 1, FolderID: null.IntFrom(3), Subject: "Filed"},
+				}
+				m.On("ListMessagesByFolder", mock.Anything, 1, null.IntFrom(3), 10, 0).
+					Return(messages, 1, nil)
+			},
+			want: &models.PaginatedResponse{
+				Data: []*models.Message{
+					{Base: models.Base{ID: 4}, InboxID: 1, FolderID: null.IntFrom(3), Subject: "Filed"},
+				},
+				Pagination: models.Pagination{
+					Total:  1,
+					Limit:  10,
+					Offset: 0,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "repository error",
+			folderID: null.Int{},
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListMessagesByFolder", mock.Anything, 1, null.Int{}, 10, 0).
 					Return([]*models.Message(nil), 0, errors.New("database error"))
 			},
 			want:    nil,
@@ -292,7 +532,53 @@ func TestMessageService_ListByInbox(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.MessageService.ListByInbox(context.Background(), tt.inboxID, tt.limit, tt.offset, tt.isRead)
+			got, err := core.MessageService.ListByFolder(WithSystemContext(context.Background()), 1, tt.folderID, 10, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_StatusByFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		folderID null.Int
+		mockFn   func(*mocks.Repository)
+		want     *models.FolderStatus
+		wantErr  bool
+	}{
+		{
+			name:     "successful status",
+			folderID: null.IntFrom(3),
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderStatus", mock.Anything, 1, null.IntFrom(3)).
+					Return(&models.FolderStatus{Messages: 4, Unseen: 1, FirstUnseen: 2, UIDNext: 31}, nil)
+			},
+			want: &models.FolderStatus{Messages: 4, Unseen: 1, FirstUnseen: 2, UIDNext: 31},
+		},
+		{
+			name:     "repository error",
+			folderID: null.Int{},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderStatus", mock.Anything, 1, null.Int{}).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.MessageService.StatusByFolder(WithSystemContext(context.Background()), 1, tt.folderID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -335,7 +621,7 @@ func TestMessageService_MarkAsRead(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.MessageService.MarkAsRead(context.Background(), tt.messageID)
+			err := core.MessageService.MarkAsRead(WithSystemContext(context.Background()), tt.messageID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -377,7 +663,7 @@ func TestMessageService_MarkAsUnread(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.MessageService.MarkAsUnread(context.Background(), tt.messageID)
+			err := core.MessageService.MarkAsUnread(WithSystemContext(context.Background()), tt.messageID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -400,6 +686,8 @@ func TestMessageService_Delete(t *testing.T) {
 			name:      "successful deletion",
 			messageID: 1,
 			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 1).
+					Return(&models.Message{Base: models.Base{ID: 1}, InboxID: 1}, nil)
 				m.On("DeleteMessage", mock.Anything, 1).Return(nil)
 			},
 			wantErr: false,
@@ -408,7 +696,170 @@ func TestMessageService_Delete(t *testing.T) {
 			name:      "non-existent message",
 			messageID: 999,
 			mockFn: func(m *mocks.Repository) {
-				m.On("DeleteMessage", mock.Anything, 999).Return(storage.ErrNotFound)
+				m.On("GetMessage", mock.Anything, 999).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name:      "delete error",
+			messageID: 2,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 2).
+					Return(&models.Message{Base: models.Base{ID: 2}, InboxID: 1}, nil)
+				m.On("DeleteMessage", mock.Anything, 2).Return(errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.MessageService.Delete(WithSystemContext(context.Background()), tt.messageID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_UpdateFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *models.Message
+		mockFn  func(*mocks.Repository)
+		wantErr bool
+	}{
+		{
+			name:    "successful update",
+			message: &models.Message{Base: models.Base{ID: 1}, IsRead: true, IsFlagged: true},
+			mockFn: func(m *mocks.Repository) {
+				m.On("UpdateMessageFlags", mock.Anything, mock.AnythingOfType("*models.Message")).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "non-existent message",
+			message: &models.Message{Base: models.Base{ID: 999}},
+			mockFn: func(m *mocks.Repository) {
+				m.On("UpdateMessageFlags", mock.Anything, mock.AnythingOfType("*models.Message")).
+					Return(storage.ErrNoRowsAffected)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.MessageService.UpdateFlags(WithSystemContext(context.Background()), tt.message)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_Expunge(t *testing.T) {
+	tests := []struct {
+		name       string
+		ids        []int
+		mockFn     func(*mocks.Repository)
+		want       []int
+		wantEvents []events.Event
+		wantErr    bool
+	}{
+		{
+			name: "expunge all deleted",
+			ids:  nil,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ExpungeMessages", mock.Anything, 1, null.Int{}, []int(nil)).
+					Return([]int{2, 3}, nil)
+			},
+			want: []int{2, 3},
+			wantEvents: []events.Event{
+				{Type: events.MessagesDeleted, InboxID: 1, MessageIDs: []int{2, 3}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "nothing to expunge",
+			ids:  nil,
+			mockFn: func(m *mocks.Repository) {
+				m.On("ExpungeMessages", mock.Anything, 1, null.Int{}, []int(nil)).
+					Return([]int{}, nil)
+			},
+			want:    []int{},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			ids:  []int{2},
+			mockFn: func(m *mocks.Repository) {
+				m.On("ExpungeMessages", mock.Anything, 1, null.Int{}, []int{2}).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			var published []events.Event
+			core.Events.Subscribe(func(ev events.Event) { published = append(published, ev) })
+
+			got, err := core.MessageService.Expunge(WithSystemContext(context.Background()), 1, null.Int{}, tt.ids)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantEvents, published)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_Search(t *testing.T) {
+	unread := false
+	search := &models.MessageSearch{From: []string{"alice"}, IsRead: &unread}
+
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "matching messages",
+			mockFn: func(m *mocks.Repository) {
+				m.On("SearchMessages", mock.Anything, 1, null.IntFrom(2), search).Return([]int{3, 8}, nil)
+			},
+			want:    []int{3, 8},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("SearchMessages", mock.Anything, 1, null.IntFrom(2), search).Return(nil, errors.New("database error"))
 			},
 			wantErr: true,
 		},
@@ -419,12 +870,130 @@ func TestMessageService_Delete(t *testing.T) {
 			core, mockRepo := setupMessageTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.MessageService.Delete(context.Background(), tt.messageID)
+			got, err := core.MessageService.Search(WithSystemContext(context.Background()), 1, null.IntFrom(2), search)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_Copy(t *testing.T) {
+	messages := []*models.Message{
+		{Base: models.Base{ID: 2}, InboxID: 1},
+		{Base: models.Base{ID: 5}, InboxID: 1},
+	}
+
+	tests := []struct {
+		name       string
+		mockFn     func(*mocks.Repository)
+		want       []int
+		wantEvents []events.Event
+		wantErr    bool
+	}{
+		{
+			name: "copies into the folder",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CopyMessages", mock.Anything, []int{2, 5}, 1, null.IntFrom(3)).
+					Return([]int{10, 11}, nil)
+			},
+			want: []int{10, 11},
+			wantEvents: []events.Event{
+				{Type: events.MessageCreated, InboxID: 1, FolderID: null.IntFrom(3), MessageIDs: []int{10, 11}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CopyMessages", mock.Anything, []int{2, 5}, 1, null.IntFrom(3)).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			var published []events.Event
+			core.Events.Subscribe(func(ev events.Event) { published = append(published, ev) })
+
+			got, err := core.MessageService.Copy(WithSystemContext(context.Background()), messages, 1, null.IntFrom(3))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.Equal(t, tt.wantEvents, published)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessageService_Move(t *testing.T) {
+	messages := []*models.Message{
+		{Base: models.Base{ID: 2}, InboxID: 1},
+		{Base: models.Base{ID: 4}, InboxID: 1, FolderID: null.IntFrom(7)},
+		{Base: models.Base{ID: 5}, InboxID: 1},
+	}
+
+	tests := []struct {
+		name       string
+		mockFn     func(*mocks.Repository)
+		want       []int
+		wantEvents []events.Event
+		wantErr    bool
+	}{
+		{
+			name: "moves and notifies every source folder",
+			mockFn: func(m *mocks.Repository) {
+				m.On("MoveMessages", mock.Anything, []int{2, 4, 5}, 1, null.IntFrom(3)).
+					Return([]int{10, 11, 12}, nil)
+			},
+			want: []int{10, 11, 12},
+			wantEvents: []events.Event{
+				{Type: events.MessageCreated, InboxID: 1, FolderID: null.IntFrom(3), MessageIDs: []int{10, 11, 12}},
+				{Type: events.MessagesDeleted, InboxID: 1, MessageIDs: []int{2, 5}},
+				{Type: events.MessagesDeleted, InboxID: 1, FolderID: null.IntFrom(7), MessageIDs: []int{4}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("MoveMessages", mock.Anything, []int{2, 4, 5}, 1, null.IntFrom(3)).
+					Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupMessageTestCore(t)
+			tt.mockFn(mockRepo)
+
+			var published []events.Event
+			core.Events.Subscribe(func(ev events.Event) { published = append(published, ev) })
+
+			got, err := core.MessageService.Move(WithSystemContext(context.Background()), messages, 1, null.IntFrom(3))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
 			}
+			assert.Equal(t, tt.wantEvents, published)
 
 			mockRepo.AssertExpectations(t)
 		})
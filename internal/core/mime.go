@@ -0,0 +1,215 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-message"
+	_ "github.com/emersion/go-message/charset" // decode non UTF-8 charsets
+	"github.com/emersion/go-message/mail"
+)
+
+// maxSubjectLength mirrors the size of the messages.subject column
+const maxSubjectLength = 200
+
+// ParseMessage parses a raw RFC 5322 message into a models.Message. The first
+// text/plain part becomes Body, the first text/html part becomes HTMLBody and
+// every other leaf part (including inline images) is returned as an
+// attachment. Envelope fields (sender, receiver, inbox) are left to the
+// caller.
+func ParseMessage(raw []byte) (*models.Message, error) {
+	msg, err := ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	msg.Raw = raw
+	msg.Size = len(raw)
+	return msg, nil
+}
+
+// ReadMessage parses a message from r like ParseMessage, without holding its
+// raw source. Raw and Size are left to the caller as well.
+func ReadMessage(r io.Reader) (*models.Message, error) {
+	mr, err := mail.CreateReader(r)
+	if err != nil && !isRecoverableParseError(err) {
+		return nil, err
+	}
+	defer mr.Close()
+
+	subject, err := mr.Header.Subject()
+	if err != nil {
+		subject = mr.Header.Get("Subject")
+	}
+
+	msg := &models.Message{
+		Subject: truncateRunes(subject, maxSubjectLength),
+	}
+
+	var text, html bool
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !isRecoverableParseError(err) {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(part.Body)
+		if err != nil && !isRecoverableParseError(err) {
+			return nil, err
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+
+		if _, inline := part.Header.(*mail.InlineHeader); inline {
+			switch {
+			case contentType == "text/plain" && !text:
+				msg.Body = string(content)
+				text = true
+				continue
+			case contentType == "text/html" && !html:
+				msg.HTMLBody = string(content)
+				html = true
+				continue
+			}
+		}
+
+		msg.Attachments = append(msg.Attachments, newAttachment(part.Header, contentType, content))
+	}
+
+	return msg, nil
+}
+
+func newAttachment(h mail.PartHeader, contentType string, content []byte) *models.Attachment {
+	attachment := &models.Attachment{
+		ContentType: contentType,
+		ContentID:   strings.Trim(h.Get("Content-Id"), "<>"),
+		Size:        len(content),
+		Content:     content,
+	}
+
+	switch h := h.(type) {
+	case *mail.AttachmentHeader:
+		attachment.Filename, _ = h.Filename()
+	case *mail.InlineHeader:
+		attachment.Filename, _ = (&mail.AttachmentHeader{Header: h.Header}).Filename()
+	}
+
+	if attachment.Filename == "" {
+		attachment.Filename = "attachment"
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			attachment.Filename += exts[0]
+		}
+	}
+
+	return attachment
+}
+
+// composeMessage renders a message composed through the API as a
+// multipart/mixed message. Its text and HTML bodies are alternatives of each
+// other, followed by the attachments.
+func composeMessage(from string, msg *models.OutgoingMessage, date time.Time) ([]byte, error) {
+	var h mail.Header
+	h.SetDate(date)
+	h.SetSubject(msg.Subject)
+	h.SetAddressList("From", []*mail.Address{{Address: from}})
+	h.SetAddressList("To", addressList(msg.To))
+	if len(msg.Cc) > 0 {
+		h.SetAddressList("Cc", addressList(msg.Cc))
+	}
+	_, domain, _ := strings.Cut(from, "@")
+	if err := h.GenerateMessageIDWithHostname(domain); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := mail.CreateWriter(&buf, h)
+	if err != nil {
+		return nil, err
+	}
+
+	iw, err := w.CreateInline()
+	if err != nil {
+		return nil, err
+	}
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if body.content == "" {
+			continue
+		}
+		var ph mail.InlineHeader
+		ph.SetContentType(body.contentType, map[string]string{"charset": "utf-8"})
+		pw, err := iw.CreatePart(ph)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAndClose(pw, []byte(body.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := iw.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		var ah mail.AttachmentHeader
+		ah.SetContentType(contentType, nil)
+		ah.SetFilename(attachment.Filename)
+		pw, err := w.CreateAttachment(ah)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeAndClose(pw, attachment.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeAndClose(w io.WriteCloser, content []byte) error {
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func addressList(addresses []string) []*mail.Address {
+	list := make([]*mail.Address, len(addresses))
+	for i, address := range addresses {
+		list[i] = &mail.Address{Address: address}
+	}
+	return list
+}
+
+// isRecoverableParseError reports whether a parse error still leaves a usable
+// part behind (unknown charsets or transfer encodings).
+func isRecoverableParseError(err error) bool {
+	return message.IsUnknownCharset(err) || message.IsUnknownEncoding(err)
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
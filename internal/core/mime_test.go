@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crlf(s string) []byte {
+	return []byte(strings.ReplaceAll(s, "\n", "\r\n"))
+}
+
+func TestParseMessage(t *testing.T) {
+	t.Run("plain text message", func(t *testing.T) {
+		raw := crlf(`From: sender@example.com
+To: inbox@example.com
+Subject: =?utf-8?q?Caf=C3=A9?=
+
+Hello there
+`)
+		msg, err := ParseMessage(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Café", msg.Subject)
+		assert.Equal(t, "Hello there\r\n", msg.Body)
+		assert.Empty(t, msg.HTMLBody)
+		assert.Empty(t, msg.Attachments)
+		assert.Equal(t, raw, msg.Raw)
+		assert.Equal(t, len(raw), msg.Size)
+	})
+
+	t.Run("multipart message with alternatives and attachment", func(t *testing.T) {
+		raw := crlf(`From: sender@example.com
+To: inbox@example.com
+Subject: Report
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=utf-8
+
+Plain body
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>HTML body</p>
+--inner--
+--outer
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="report.pdf"
+Content-Transfer-Encoding: base64
+
+UERG
+--outer
+Content-Type: image/png
+Content-Disposition: inline
+Content-Id: <logo@example.com>
+Content-Transfer-Encoding: base64
+
+UE5H
+--outer--
+`)
+		msg, err := ParseMessage(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Report", msg.Subject)
+		assert.Equal(t, "Plain body", msg.Body)
+		assert.Equal(t, "<p>HTML body</p>", msg.HTMLBody)
+		require.Len(t, msg.Attachments, 2)
+
+		assert.Equal(t, "report.pdf", msg.Attachments[0].Filename)
+		assert.Equal(t, "application/pdf", msg.Attachments[0].ContentType)
+		assert.Equal(t, []byte("PDF"), msg.Attachments[0].Content)
+		assert.Equal(t, 3, msg.Attachments[0].Size)
+
+		assert.Equal(t, "image/png", msg.Attachments[1].ContentType)
+		assert.Equal(t, "logo@example.com", msg.Attachments[1].ContentID)
+		assert.Equal(t, []byte("PNG"), msg.Attachments[1].Content)
+		assert.True(t, strings.HasPrefix(msg.Attachments[1].Filename, "attachment"))
+	})
+
+	t.Run("long subject is truncated", func(t *testing.T) {
+		raw := crlf("Subject: " + strings.Repeat("a", 250) + "\n\nbody\n")
+		msg, err := ParseMessage(raw)
+		require.NoError(t, err)
+		assert.Len(t, msg.Subject, maxSubjectLength)
+	})
+}
+
+func TestComposeMessage(t *testing.T) {
+	date := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	msg := &models.OutgoingMessage{
+		To:      []string{"dev@example.com"},
+		Cc:      []string{"ops@example.com"},
+		Subject: "Café order",
+		Text:    "Hello there",
+		HTML:    "<p>Hello there</p>",
+		Attachments: []*models.OutgoingAttachment{
+			{Filename: "report.csv", ContentType: "text/csv", Content: []byte("a,b\n1,2\n")},
+			{Filename: "blob.bin", Content: []byte{0, 1, 2}},
+		},
+	}
+
+	raw, err := composeMessage("qa@example.com", msg, date)
+	require.NoError(t, err)
+
+	header := string(raw[:bytes.Index(raw, []byte("\r\n\r\n"))])
+	assert.Contains(t, header, "From: <qa@example.com>")
+	assert.Contains(t, header, "To: <dev@example.com>")
+	assert.Contains(t, header, "Cc: <ops@example.com>")
+	assert.Contains(t, header, "Date: Fri, 10 May 2024 12:00:00 +0000")
+	assert.Regexp(t, `Message-Id: <[^>]+@example\.com>`, header)
+
+	parsed, err := ParseMessage(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "Café order", parsed.Subject)
+	assert.Equal(t, "Hello there", parsed.Body)
+	assert.Equal(t, "<p>Hello there</p>", parsed.HTMLBody)
+	require.Len(t, parsed.Attachments, 2)
+	assert.Equal(t, "report.csv", parsed.Attachments[0].Filename)
+	assert.Equal(t, "text/csv", parsed.Attachments[0].ContentType)
+	assert.Equal(t, []byte("a,b\n1,2\n"), parsed.Attachments[0].Content)
+	assert.Equal(t, "application/octet-stream", parsed.Attachments[1].ContentType)
+	assert.Equal(t, []byte{0, 1, 2}, parsed.Attachments[1].Content)
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+// SentFolder is the folder of an inbox holding the copies of the messages
+// sent from it, it is created with the first message sent
+const SentFolder = "Sent"
+
+type OutboundService struct {
+	core *Core
+}
+
+func NewOutboundService(core *Core) OutboundService {
+	return OutboundService{core: core}
+}
+
+// Send composes a message from an inbox and queues it for the relay. A copy
+// is filed in the Sent folder of the inbox right away, it counts against the
+// quotas of the inbox like received mail. The returned outbound message
+// tracks the delivery to the relay.
+func (s *OutboundService) Send(ctx context.Context, inboxID int, msg *models.OutgoingMessage) (*models.OutboundMessage, error) {
+	s.core.Logger.Info("Sending message from inbox %d to %d recipients", inboxID, len(msg.To)+len(msg.Cc))
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	inbox, err := s.core.Repository.GetInbox(ctx, inboxID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch inbox: %v", err)
+		return nil, err
+	}
+	if inbox == nil {
+		return nil, ErrNotFound
+	}
+
+	if s.core.Relay == nil {
+		return nil, ErrRelayDisabled
+	}
+	if strings.Contains(inbox.Email, "*") {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "messages cannot be sent from a wildcard inbox",
+		}
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "message needs a text or an HTML body",
+		}
+	}
+
+	raw, err := composeMessage(inbox.Email, msg, time.Now())
+	if err != nil {
+		s.core.Logger.Error("Failed to compose message: %v", err)
+		return nil, err
+	}
+	// Sent messages are held to the size accepted over SMTP
+	if limit := s.core.Config.Server.SMTP.MaxMessageBytes; limit > 0 && int64(len(raw)) > limit {
+		return nil, &APIError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("message exceeds the size limit of %d bytes", limit),
+		}
+	}
+
+	if err := s.core.InboxService.CheckQuota(ctx, inbox, len(raw)); err != nil {
+		s.core.Logger.Info("Refusing message from inbox %d over its quota: %v", inboxID, err)
+		return nil, err
+	}
+
+	sent, err := ParseMessage(raw)
+	if err != nil {
+		s.core.Logger.Error("Failed to parse composed message: %v", err)
+		return nil, err
+	}
+
+	folder, err := s.sentFolder(ctx, inboxID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch Sent folder of inbox %d: %v", inboxID, err)
+		return nil, err
+	}
+
+	sent.InboxID = inboxID
+	sent.FolderID = null.IntFrom(folder.ID)
+	sent.Sender = inbox.Email
+	sent.Receiver = msg.To[0]
+
+	outbound := &models.OutboundMessage{
+		InboxID:    inboxID,
+		Sender:     inbox.Email,
+		Recipients: recipients(msg),
+		Raw:        raw,
+	}
+	if err := s.core.Repository.QueueOutboundMessage(ctx, outbound, sent); err != nil {
+		s.core.Logger.Error("Failed to queue outbound message: %v", err)
+		return nil, err
+	}
+
+	s.core.Logger.Info("Successfully queued outbound message with ID: %d", outbound.ID)
+
+	s.core.Events.Publish(events.Event{
+		Type:       events.MessageCreated,
+		InboxID:    sent.InboxID,
+		FolderID:   sent.FolderID,
+		MessageIDs: []int{sent.ID},
+		Sent:       true,
+	})
+	// The dispatcher runs whenever a relay is configured, which was checked
+	// above
+	s.core.OutboundDispatcher.Wake()
+
+	return outbound, nil
+}
+
+// Get returns a message sent from an inbox with its delivery status
+func (s *OutboundService) Get(ctx context.Context, inboxID, id int) (*models.OutboundMessage, error) {
+	s.core.Logger.Debug("Fetching outbound message %d of inbox %d", id, inboxID)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	outbound, err := s.core.Repository.GetOutboundMessage(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch outbound message: %v", err)
+		return nil, err
+	}
+	if outbound.InboxID != inboxID {
+		return nil, ErrNotFound
+	}
+
+	return outbound, nil
+}
+
+// ListByInbox returns a page of the messages sent from an inbox, most recent
+// first, optionally only those with the given status
+func (s *OutboundService) ListByInbox(ctx context.Context, inboxID int, status string, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing outbound messages of inbox %d with status %q, limit: %d and offset: %d", inboxID, status, limit, offset)
+
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.core.Repository.ListOutboundMessagesByInbox(ctx, inboxID, status, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list outbound messages: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: messages,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully retrieved %d outbound messages (total: %d)", len(messages), total)
+	return response, nil
+}
+
+// sentFolder returns the Sent folder of an inbox, creating it when needed
+func (s *OutboundService) sentFolder(ctx context.Context, inboxID int) (*models.Folder, error) {
+	folder, err := s.core.Repository.GetFolderByName(ctx, inboxID, SentFolder)
+	if !errors.Is(err, storage.ErrNotFound) {
+		return folder, err
+	}
+
+	folder = &models.Folder{InboxID: inboxID, Name: SentFolder}
+	err = s.core.Repository.CreateFolder(ctx, folder)
+	if errors.Is(err, storage.ErrConflict) {
+		// Created by a message sent at the same time
+		return s.core.Repository.GetFolderByName(ctx, inboxID, SentFolder)
+	}
+	return folder, err
+}
+
+// recipients is the envelope of a message, every To and Cc address once
+func recipients(msg *models.OutgoingMessage) []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, address := range append(append([]string{}, msg.To...), msg.Cc...) {
+		key := strings.ToLower(address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		list = append(list, address)
+	}
+	return list
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"inbox451/internal/models"
+	"inbox451/internal/relay"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+const (
+	// DefaultOutboundTimeout bounds an attempt at handing a message to the
+	// relay when outbound.timeout is not configured
+	DefaultOutboundTimeout = 30 * time.Second
+	// DefaultOutboundMaxAttempts is the number of attempts of a message when
+	// outbound.max_attempts is not configured
+	DefaultOutboundMaxAttempts = 6
+	// DefaultOutboundRetryBackoff is the delay before the first retry when
+	// outbound.retry_backoff is not configured
+	DefaultOutboundRetryBackoff = time.Minute
+	// DefaultOutboundPollInterval is how often queued messages are looked
+	// for when outbound.poll_interval is not configured
+	DefaultOutboundPollInterval = 10 * time.Second
+
+	// maxOutboundBackoff caps the delay between two attempts
+	maxOutboundBackoff = 6 * time.Hour
+	// outboundBatchSize is the number of messages claimed and sent at once
+	outboundBatchSize = 10
+)
+
+// OutboundDispatcher hands the messages sent from inboxes to the relay. The
+// messages are queued in the database, so failed attempts are retried with
+// an exponential backoff and survive restarts. Messages the relay rejects
+// permanently are not retried. It is run alongside the servers and stopped
+// with Shutdown.
+type OutboundDispatcher struct {
+	core   *Core
+	worker *queueWorker[*models.OutboundMessage]
+}
+
+func NewOutboundDispatcher(core *Core) *OutboundDispatcher {
+	cfg := core.Config.Outbound
+	settings := queueSettings{
+		timeout:     cfg.Timeout,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.RetryBackoff,
+		interval:    cfg.PollInterval,
+	}.withDefaults(queueSettings{
+		timeout:     DefaultOutboundTimeout,
+		maxAttempts: DefaultOutboundMaxAttempts,
+		backoff:     DefaultOutboundRetryBackoff,
+		interval:    DefaultOutboundPollInterval,
+	})
+
+	d := &OutboundDispatcher{core: core}
+	d.worker = newQueueWorker(core.Logger, "outbound messages", settings, outboundBatchSize, maxOutboundBackoff,
+		core.Repository.ClaimOutboundMessages, d.send)
+	return d
+}
+
+// Wake makes the dispatcher look for queued messages right away. It only
+// acts while the dispatcher runs, which is when a relay is configured.
+func (d *OutboundDispatcher) Wake() {
+	if d == nil {
+		return
+	}
+	d.worker.Wake()
+}
+
+// ListenAndServe sends the queued messages that are due until Shutdown is
+// called. It is only run when a relay is configured.
+func (d *OutboundDispatcher) ListenAndServe() error {
+	return d.worker.ListenAndServe()
+}
+
+// Shutdown stops the dispatcher. Messages being sent are abandoned and
+// attempted again once their lease expires.
+func (d *OutboundDispatcher) Shutdown(ctx context.Context) error {
+	return d.worker.Shutdown(ctx)
+}
+
+// send makes one attempt at handing a message to the relay and records its
+// outcome
+func (d *OutboundDispatcher) send(ctx context.Context, outbound *models.OutboundMessage) {
+	sendCtx, cancel := context.WithTimeout(ctx, d.worker.timeout)
+	err := d.core.Relay.Send(sendCtx, outbound.Sender, outbound.Recipients, outbound.Raw)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+
+	outbound.Attempts++
+	switch {
+	case err == nil:
+		d.core.Logger.Info("Sent outbound message %d to %d recipients", outbound.ID, len(outbound.Recipients))
+		outbound.Status = models.OutboundSent
+		outbound.NextAttemptAt = null.Time{}
+		outbound.LastError = null.String{}
+		outbound.SentAt = null.TimeFrom(time.Now())
+	case relay.IsPermanent(err) || outbound.Attempts >= d.worker.maxAttempts:
+		d.core.Logger.Info("Outbound message %d failed after %d attempts: %v", outbound.ID, outbound.Attempts, err)
+		outbound.Status = models.OutboundFailed
+		outbound.NextAttemptAt = null.Time{}
+		outbound.LastError = null.StringFrom(err.Error())
+	default:
+		outbound.Status = models.OutboundQueued
+		outbound.NextAttemptAt = null.TimeFrom(time.Now().Add(d.worker.retryDelay(outbound.Attempts)))
+		outbound.LastError = null.StringFrom(err.Error())
+	}
+
+	if err := d.core.Repository.UpdateOutboundMessage(ctx, outbound); err != nil {
+		d.core.Logger.Error("Failed to record outbound message %d: %v", outbound.ID, err)
+	}
+}
@@ -0,0 +1,405 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"inbox451/internal/config"
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/relay"
+	"inbox451/internal/storage"
+
+	"github.com/emersion/go-smtp"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+type outboundRelay struct {
+	err  error
+	from string
+	to   []string
+}
+
+func (r *outboundRelay) Send(_ context.Context, from string, to []string, _ []byte) error {
+	r.from = from
+	r.to = to
+	return r.err
+}
+
+func setupOutboundTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	cfg := &config.Config{}
+	cfg.Outbound.MaxAttempts = 3
+	cfg.Outbound.RetryBackoff = time.Minute
+
+	core := &Core{
+		Config:     cfg,
+		Logger:     logger,
+		Repository: mockRepo,
+		Relay:      &outboundRelay{},
+		Events:     events.NewBus(),
+	}
+	core.InboxService = NewInboxService(core)
+	core.OutboundService = NewOutboundService(core)
+	core.OutboundDispatcher = NewOutboundDispatcher(core)
+
+	return core, mockRepo
+}
+
+func TestOutboundService_Send(t *testing.T) {
+	inbox := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}
+	message := func() *models.OutgoingMessage {
+		return &models.OutgoingMessage{
+			To:      []string{"dev@example.com", "ops@example.com"},
+			Cc:      []string{"OPS@example.com", "lead@example.com"},
+			Subject: "Reply",
+			Text:    "Thanks",
+			Attachments: []*models.OutgoingAttachment{
+				{Filename: "log.txt", ContentType: "text/plain", Content: []byte("ok")},
+			},
+		}
+	}
+
+	t.Run("queues the message and files a copy", func(t *testing.T) {
+		core, mockRepo := setupOutboundTestCore(t)
+		mockRepo.On("GetInbox", mock.Anything, 1).Return(inbox, nil)
+		mockRepo.On("GetFolderByName", mock.Anything, 1, SentFolder).
+			Return(&models.Folder{Base: models.Base{ID: 5}, InboxID: 1, Name: SentFolder}, nil)
+
+		var sent *models.Message
+		mockRepo.On("QueueOutboundMessage", mock.Anything, mock.AnythingOfType("*models.OutboundMessage"), mock.AnythingOfType("*models.Message")).
+			Run(func(args mock.Arguments) {
+				args.Get(1).(*models.OutboundMessage).ID = 3
+				sent = args.Get(2).(*models.Message)
+				sent.ID = 7
+			}).
+			Return(nil)
+
+		var created []events.Event
+		core.Events.Subscribe(func(ev events.Event) { created = append(created, ev) })
+
+		outbound, err := core.OutboundService.Send(WithSystemContext(context.Background()), 1, message())
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, outbound.ID)
+		assert.Equal(t, "qa@example.com", outbound.Sender)
+		assert.Equal(t, pq.StringArray{"dev@example.com", "ops@example.com", "lead@example.com"}, outbound.Recipients)
+		assert.NotEmpty(t, outbound.Raw)
+
+		require.NotNil(t, sent)
+		assert.Equal(t, null.IntFrom(5), sent.FolderID)
+		assert.Equal(t, "qa@example.com", sent.Sender)
+		assert.Equal(t, "dev@example.com", sent.Receiver)
+		assert.Equal(t, "Reply", sent.Subject)
+		assert.Equal(t, "Thanks", sent.Body)
+		require.Len(t, sent.Attachments, 1)
+		assert.Equal(t, "log.txt", sent.Attachments[0].Filename)
+
+		require.Len(t, created, 1)
+		assert.Equal(t, events.MessageCreated, created[0].Type)
+		assert.Equal(t, null.IntFrom(5), created[0].FolderID)
+		assert.Equal(t, []int{7}, created[0].MessageIDs)
+		assert.True(t, created[0].Sent)
+	})
+
+	t.Run("creates the Sent folder", func(t *testing.T) {
+		core, mockRepo := setupOutboundTestCore(t)
+		mockRepo.On("GetInbox", mock.Anything, 1).Return(inbox, nil)
+		mockRepo.On("GetFolderByName", mock.Anything, 1, SentFolder).Return(nil, storage.ErrNotFound)
+		mockRepo.On("CreateFolder", mock.Anything, mock.AnythingOfType("*models.Folder")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Folder).ID = 6 }).
+			Return(nil)
+		mockRepo.On("QueueOutboundMessage", mock.Anything, mock.Anything, mock.MatchedBy(func(m *models.Message) bool {
+			return m.FolderID == null.IntFrom(6)
+		})).Return(nil)
+
+		_, err := core.OutboundService.Send(WithSystemContext(context.Background()), 1, message())
+		require.NoError(t, err)
+	})
+
+	tests := []struct {
+		name     string
+		inbox    *models.Inbox
+		mutate   func(*Core, *models.OutgoingMessage)
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name:    "no relay",
+			inbox:   inbox,
+			mutate:  func(c *Core, _ *models.OutgoingMessage) { c.Relay = nil },
+			wantErr: ErrRelayDisabled,
+		},
+		{
+			name:     "wildcard inbox",
+			inbox:    &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "*@qa.example.com"},
+			mutate:   func(*Core, *models.OutgoingMessage) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "no body",
+			inbox:    inbox,
+			mutate:   func(_ *Core, msg *models.OutgoingMessage) { msg.Text = "" },
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:  "too large",
+			inbox: inbox,
+			mutate: func(c *Core, _ *models.OutgoingMessage) {
+				c.Config.Server.SMTP.MaxMessageBytes = 100
+			},
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupOutboundTestCore(t)
+			mockRepo.On("GetInbox", mock.Anything, 1).Return(tt.inbox, nil)
+
+			msg := message()
+			tt.mutate(core, msg)
+
+			_, err := core.OutboundService.Send(WithSystemContext(context.Background()), 1, msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+		})
+	}
+}
+
+func TestOutboundService_SendQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		inbox    *models.Inbox
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name: "inbox full",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com",
+				MaxTotalBytes: null.Int64From(1000)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(&models.InboxUsage{TotalBytes: 900}, nil)
+			},
+			wantErr:  ErrInboxFull,
+			wantCode: http.StatusInsufficientStorage,
+		},
+		{
+			name: "message over the inbox limit",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com",
+				MaxMessageBytes: null.IntFrom(100)},
+			mockFn:   func(*mocks.Repository) {},
+			wantErr:  ErrMessageTooLarge,
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupOutboundTestCore(t)
+			mockRepo.On("GetInbox", mock.Anything, 1).Return(tt.inbox, nil)
+			tt.mockFn(mockRepo)
+
+			_, err := core.OutboundService.Send(WithSystemContext(context.Background()), 1, &models.OutgoingMessage{
+				To:      []string{"dev@example.com"},
+				Subject: "Reply",
+				Text:    "Thanks",
+			})
+			assert.ErrorIs(t, err, tt.wantErr)
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.wantCode, apiErr.Code)
+			mockRepo.AssertNotCalled(t, "QueueOutboundMessage", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOutboundService_SendAuthorization(t *testing.T) {
+	core, mockRepo := setupOutboundTestCore(t)
+	mockRepo.On("GetInbox", mock.Anything, 1).
+		Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}, nil)
+	mockRepo.On("GetProjectUser", mock.Anything, 2, 7).Return(nil, storage.ErrNotFound)
+
+	ctx := WithUser(context.Background(), &models.User{Base: models.Base{ID: 7}, Role: RoleUser})
+	_, err := core.OutboundService.Send(ctx, 1, &models.OutgoingMessage{
+		To:   []string{"dev@example.com"},
+		Text: "Thanks",
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+	mockRepo.AssertNotCalled(t, "QueueOutboundMessage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutboundService_Get(t *testing.T) {
+	core, mockRepo := setupOutboundTestCore(t)
+	mockRepo.On("GetOutboundMessage", mock.Anything, 3).
+		Return(&models.OutboundMessage{Base: models.Base{ID: 3}, InboxID: 1}, nil)
+
+	outbound, err := core.OutboundService.Get(WithSystemContext(context.Background()), 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, outbound.ID)
+
+	_, err = core.OutboundService.Get(WithSystemContext(context.Background()), 2, 3)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOutboundDispatcher_Send(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		attempts     int
+		wantStatus   string
+		wantAttempts int
+		wantRetry    bool
+	}{
+		{
+			name:         "accepted",
+			wantStatus:   models.OutboundSent,
+			wantAttempts: 1,
+		},
+		{
+			name:         "relay unreachable",
+			err:          errors.New("failed to connect to relay"),
+			attempts:     1,
+			wantStatus:   models.OutboundQueued,
+			wantAttempts: 2,
+			wantRetry:    true,
+		},
+		{
+			name:         "out of attempts",
+			err:          errors.New("failed to connect to relay"),
+			attempts:     2,
+			wantStatus:   models.OutboundFailed,
+			wantAttempts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupOutboundTestCore(t)
+			sender := &outboundRelay{err: tt.err}
+			core.Relay = sender
+
+			var recorded *models.OutboundMessage
+			mockRepo.On("UpdateOutboundMessage", mock.Anything, mock.AnythingOfType("*models.OutboundMessage")).
+				Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.OutboundMessage) }).
+				Return(nil)
+
+			outbound := &models.OutboundMessage{
+				Base:       models.Base{ID: 3},
+				InboxID:    1,
+				Sender:     "qa@example.com",
+				Recipients: pq.StringArray{"dev@example.com"},
+				Raw:        []byte("Subject: Hi\r\n\r\nHello\r\n"),
+				Status:     models.OutboundQueued,
+				Attempts:   tt.attempts,
+			}
+			before := time.Now()
+			core.OutboundDispatcher.send(WithSystemContext(context.Background()), outbound)
+
+			assert.Equal(t, "qa@example.com", sender.from)
+			assert.Equal(t, []string{"dev@example.com"}, sender.to)
+
+			require.NotNil(t, recorded)
+			assert.Equal(t, tt.wantStatus, recorded.Status)
+			assert.Equal(t, tt.wantAttempts, recorded.Attempts)
+			assert.Equal(t, tt.wantRetry, recorded.NextAttemptAt.Valid)
+			assert.Equal(t, tt.err == nil, recorded.SentAt.Valid)
+			assert.Equal(t, tt.err != nil, recorded.LastError.Valid)
+			if tt.wantRetry {
+				assert.WithinDuration(t, before.Add(2*time.Minute), recorded.NextAttemptAt.Time, 5*time.Second)
+			}
+		})
+	}
+}
+
+// rejectingBackend is an SMTP server refusing every recipient
+type rejectingBackend struct {
+	code int
+}
+
+func (b *rejectingBackend) NewSession(*smtp.Conn) (smtp.Session, error) {
+	return &rejectingSession{code: b.code}, nil
+}
+
+type rejectingSession struct {
+	code int
+}
+
+func (s *rejectingSession) Reset()                               {}
+func (s *rejectingSession) Logout() error                        { return nil }
+func (s *rejectingSession) Mail(string, *smtp.MailOptions) error { return nil }
+func (s *rejectingSession) Data(io.Reader) error                 { return nil }
+func (s *rejectingSession) Rcpt(string, *smtp.RcptOptions) error {
+	return &smtp.SMTPError{Code: s.code, Message: "mailbox unavailable"}
+}
+
+func TestOutboundDispatcher_RelayRejection(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		wantStatus string
+	}{
+		{name: "permanent", code: 550, wantStatus: models.OutboundFailed},
+		{name: "temporary", code: 450, wantStatus: models.OutboundQueued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			server := smtp.NewServer(&rejectingBackend{code: tt.code})
+			server.Domain = "localhost"
+			go func() { _ = server.Serve(listener) }()
+			defer server.Close()
+
+			core, mockRepo := setupOutboundTestCore(t)
+			core.Relay = relay.New(config.RelayConfig{Addr: listener.Addr().String()})
+
+			var recorded *models.OutboundMessage
+			mockRepo.On("UpdateOutboundMessage", mock.Anything, mock.AnythingOfType("*models.OutboundMessage")).
+				Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.OutboundMessage) }).
+				Return(nil)
+
+			core.OutboundDispatcher.send(WithSystemContext(context.Background()), &models.OutboundMessage{
+				Base:       models.Base{ID: 3},
+				Sender:     "qa@example.com",
+				Recipients: pq.StringArray{"dev@example.com"},
+				Raw:        []byte("Subject: Hi\r\n\r\nHello\r\n"),
+				Status:     models.OutboundQueued,
+			})
+
+			require.NotNil(t, recorded)
+			assert.Equal(t, tt.wantStatus, recorded.Status)
+			assert.Equal(t, 1, recorded.Attempts)
+			assert.Contains(t, recorded.LastError.String, "mailbox unavailable")
+		})
+	}
+}
+
+func TestOutboundDispatcher_RetryDelay(t *testing.T) {
+	core, _ := setupOutboundTestCore(t)
+	d := core.OutboundDispatcher
+
+	assert.Equal(t, time.Minute, d.worker.retryDelay(1))
+	assert.Equal(t, 4*time.Minute, d.worker.retryDelay(3))
+	assert.Equal(t, maxOutboundBackoff, d.worker.retryDelay(100))
+}
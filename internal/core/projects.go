@@ -14,9 +14,19 @@ func NewProjectService(core *Core) ProjectService {
 	return ProjectService{core: core}
 }
 
+// List returns every project to admins and the projects the caller is a
+// member of to other users
 func (s *ProjectService) List(ctx context.Context, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Debug("Listing projects with limit: %d and offset: %d", limit, offset)
 
+	user, ok, err := unrestricted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s.ListByUser(ctx, user.ID, limit, offset)
+	}
+
 	projects, total, err := s.core.Repository.ListProjects(ctx, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list projects: %v", err)
@@ -39,6 +49,10 @@ func (s *ProjectService) List(ctx context.Context, limit, offset int) (*models.P
 func (s *ProjectService) ListByUser(ctx context.Context, userID int, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Debug("Listing projects with limit: %d and offset: %d for user %d", limit, offset, userID)
 
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	projects, total, err := s.core.Repository.ListProjectsByUser(ctx, userID, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list projects: %v", err)
@@ -61,6 +75,10 @@ func (s *ProjectService) ListByUser(ctx context.Context, userID int, limit, offs
 func (s *ProjectService) Get(ctx context.Context, projectId int) (*models.Project, error) {
 	s.core.Logger.Debug("Fetching project with ID: %d", projectId)
 
+	if err := s.core.authorizeProject(ctx, projectId, RoleUser); err != nil {
+		return nil, err
+	}
+
 	project, err := s.core.Repository.GetProject(ctx, projectId)
 	if err != nil {
 		s.core.Logger.Error("Failed to fetch project: %v", err)
@@ -75,14 +93,28 @@ func (s *ProjectService) Get(ctx context.Context, projectId int) (*models.Projec
 	return project, nil
 }
 
+// Create creates a project, the caller becomes its admin
 func (s *ProjectService) Create(ctx context.Context, project *models.Project) error {
 	s.core.Logger.Info("Creating new project: %s", project.Name)
 
+	user, err := caller(ctx)
+	if err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.CreateProject(ctx, project); err != nil {
 		s.core.Logger.Error("Failed to create project: %v", err)
 		return err
 	}
 
+	if user != nil {
+		member := &models.ProjectUser{ProjectID: project.ID, UserID: user.ID, Role: RoleAdmin}
+		if err := s.core.Repository.ProjectAddUser(ctx, member); err != nil {
+			s.core.Logger.Error("Failed to add creator to project: %v", err)
+			return err
+		}
+	}
+
 	s.core.Logger.Info("Successfully created project with ID: %d", project.ID)
 	return nil
 }
@@ -90,6 +122,10 @@ func (s *ProjectService) Create(ctx context.Context, project *models.Project) er
 func (s *ProjectService) Update(ctx context.Context, project *models.Project) error {
 	s.core.Logger.Info("Updating project with ID: %d", project.ID)
 
+	if err := s.core.authorizeProject(ctx, project.ID, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.UpdateProject(ctx, project); err != nil {
 		s.core.Logger.Error("Failed to update project: %v", err)
 		return err
@@ -102,6 +138,10 @@ func (s *ProjectService) Update(ctx context.Context, project *models.Project) er
 func (s *ProjectService) AddUser(ctx context.Context, projectUser *models.ProjectUser) error {
 	s.core.Logger.Debug("Adding user %d to project %d with role=%s", projectUser.UserID, projectUser.ProjectID, projectUser.Role)
 
+	if err := s.core.authorizeProject(ctx, projectUser.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.ProjectAddUser(ctx, projectUser); err != nil {
 		s.core.Logger.Error("Failed to add user to project: %v", err)
 		return err
@@ -114,6 +154,10 @@ func (s *ProjectService) AddUser(ctx context.Context, projectUser *models.Projec
 func (s *ProjectService) RemoveUser(ctx context.Context, projectID int, userID int) error {
 	s.core.Logger.Debug("Remove user %d to project %d", userID, projectID)
 
+	if err := s.core.authorizeProject(ctx, projectID, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.ProjectRemoveUser(ctx, projectID, userID); err != nil {
 		s.core.Logger.Error("Failed to add user to project: %v", err)
 		return err
@@ -126,6 +170,10 @@ func (s *ProjectService) RemoveUser(ctx context.Context, projectID int, userID i
 func (s *ProjectService) Delete(ctx context.Context, id int) error {
 	s.core.Logger.Info("Deleting project with ID: %d", id)
 
+	if err := s.core.authorizeProject(ctx, id, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.DeleteProject(ctx, id); err != nil {
 		s.core.Logger.Error("Failed to delete project: %v", err)
 		return err
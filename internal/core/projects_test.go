@@ -66,7 +66,7 @@ func TestProjectService_Create(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.ProjectService.Create(context.Background(), tt.project)
+			err := core.ProjectService.Create(WithSystemContext(context.Background()), tt.project)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -128,7 +128,7 @@ func TestProjectService_Get(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.ProjectService.Get(context.Background(), tt.id)
+			got, err := core.ProjectService.Get(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -194,7 +194,7 @@ func TestProjectService_List(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.ProjectService.List(context.Background(), tt.limit, tt.offset)
+			got, err := core.ProjectService.List(WithSystemContext(context.Background()), tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -245,7 +245,7 @@ func TestProjectService_Update(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.ProjectService.Update(context.Background(), tt.project)
+			err := core.ProjectService.Update(WithSystemContext(context.Background()), tt.project)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -287,7 +287,7 @@ func TestProjectService_Delete(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.ProjectService.Delete(context.Background(), tt.id)
+			err := core.ProjectService.Delete(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -340,7 +340,7 @@ func TestProjectService_AddUser(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.ProjectService.AddUser(context.Background(), tt.projectUser)
+			err := core.ProjectService.AddUser(WithSystemContext(context.Background()), tt.projectUser)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -386,7 +386,7 @@ func TestProjectService_RemoveUser(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.ProjectService.RemoveUser(context.Background(), tt.projectID, tt.userID)
+			err := core.ProjectService.RemoveUser(WithSystemContext(context.Background()), tt.projectID, tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -481,7 +481,7 @@ func TestProjectService_ListByUser(t *testing.T) {
 			core, mockRepo := setupProjectTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.ProjectService.ListByUser(context.Background(), tt.userID, tt.limit, tt.offset)
+			got, err := core.ProjectService.ListByUser(WithSystemContext(context.Background()), tt.userID, tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"inbox451/internal/logger"
+)
+
+// queueSettings configure a queueWorker, they mirror the timeout,
+// max_attempts, retry_backoff and poll_interval options of the webhooks and
+// outbound sections
+type queueSettings struct {
+	// timeout bounds the processing of a job
+	timeout time.Duration
+	// maxAttempts is the number of attempts before a job is marked as
+	// failed
+	maxAttempts int
+	// backoff is the delay before the first retry, it doubles with every
+	// further attempt
+	backoff time.Duration
+	// interval is how often due jobs are looked for
+	interval time.Duration
+}
+
+// withDefaults returns the settings with the unset ones taken from defaults
+func (s queueSettings) withDefaults(defaults queueSettings) queueSettings {
+	if s.timeout <= 0 {
+		s.timeout = defaults.timeout
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = defaults.maxAttempts
+	}
+	if s.backoff <= 0 {
+		s.backoff = defaults.backoff
+	}
+	if s.interval <= 0 {
+		s.interval = defaults.interval
+	}
+	return s
+}
+
+// queueWorker runs the jobs queued in the database by the dispatchers, such
+// as webhook deliveries and outbound messages. Due jobs are claimed a batch
+// at a time and processed concurrently. Claiming hides them from the workers
+// of other instances for the lease, so a job abandoned by a stopped instance
+// is picked up again once its lease expires. Processing a job records its
+// outcome, retries are scheduled with retryDelay.
+type queueWorker[T any] struct {
+	queueSettings
+	name   string
+	logger *logger.Logger
+	// lease is how long a claimed job is hidden from other workers while it
+	// is being processed
+	lease      time.Duration
+	batchSize  int
+	maxBackoff time.Duration
+	claim      func(ctx context.Context, limit int, lease time.Duration) ([]T, error)
+	process    func(ctx context.Context, job T)
+	wake       chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// newQueueWorker returns a worker claiming up to batchSize jobs at once with
+// claim and handing each of them to process. The name of the jobs is used in
+// log messages.
+func newQueueWorker[T any](
+	logger *logger.Logger,
+	name string,
+	settings queueSettings,
+	batchSize int,
+	maxBackoff time.Duration,
+	claim func(ctx context.Context, limit int, lease time.Duration) ([]T, error),
+	process func(ctx context.Context, job T),
+) *queueWorker[T] {
+	ctx, cancel := context.WithCancel(WithSystemContext(context.Background()))
+	return &queueWorker[T]{
+		queueSettings: settings,
+		name:          name,
+		logger:        logger,
+		lease:         settings.timeout + time.Minute,
+		batchSize:     batchSize,
+		maxBackoff:    maxBackoff,
+		claim:         claim,
+		process:       process,
+		wake:          make(chan struct{}, 1),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Wake makes the worker look for due jobs right away. Only a running worker
+// acts on it, a single wake-up is kept until ListenAndServe starts.
+func (w *queueWorker[T]) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ListenAndServe processes the due jobs until Shutdown is called
+func (w *queueWorker[T]) ListenAndServe() error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.processDue(w.ctx)
+
+		select {
+		case <-w.ctx.Done():
+			return nil
+		case <-w.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the worker. Jobs being processed are abandoned and claimed
+// again once their lease expires.
+func (w *queueWorker[T]) Shutdown(ctx context.Context) error {
+	w.cancel()
+	return nil
+}
+
+// processDue processes the due jobs, a batch at a time
+func (w *queueWorker[T]) processDue(ctx context.Context) {
+	for {
+		jobs, err := w.claim(ctx, w.batchSize, w.lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error("Failed to claim %s: %v", w.name, err)
+			}
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, job := range jobs {
+			wg.Add(1)
+			go func(job T) {
+				defer wg.Done()
+				w.process(ctx, job)
+			}(job)
+		}
+		wg.Wait()
+
+		if len(jobs) < w.batchSize {
+			return
+		}
+	}
+}
+
+// retryDelay is the delay after the given number of failed attempts, it
+// doubles with every attempt up to maxBackoff
+func (w *queueWorker[T]) retryDelay(attempts int) time.Duration {
+	delay := w.backoff
+	for i := 1; i < attempts && delay < w.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.maxBackoff {
+		delay = w.maxBackoff
+	}
+	return delay
+}
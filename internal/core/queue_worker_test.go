@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"inbox451/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueSettings_WithDefaults(t *testing.T) {
+	defaults := queueSettings{timeout: time.Second, maxAttempts: 3, backoff: time.Minute, interval: 5 * time.Second}
+
+	assert.Equal(t, defaults, queueSettings{}.withDefaults(defaults))
+
+	configured := queueSettings{timeout: 2 * time.Second, maxAttempts: 1, backoff: time.Hour, interval: time.Minute}
+	assert.Equal(t, configured, configured.withDefaults(defaults))
+}
+
+func TestQueueWorker_ProcessDue(t *testing.T) {
+	errClaim := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		batches    [][]int
+		claimErr   error
+		wantClaims int
+		want       []int
+	}{
+		{
+			name:       "nothing due",
+			batches:    [][]int{{}},
+			wantClaims: 1,
+		},
+		{
+			name:       "partial batch",
+			batches:    [][]int{{1, 2}},
+			wantClaims: 1,
+			want:       []int{1, 2},
+		},
+		{
+			name:       "full batches are followed by another claim",
+			batches:    [][]int{{1, 2, 3}, {4, 5, 6}, {7}},
+			wantClaims: 3,
+			want:       []int{1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:       "claim failure",
+			claimErr:   errClaim,
+			wantClaims: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var processed []int
+			claims := 0
+
+			w := newQueueWorker(logger.New(io.Discard, logger.DEBUG), "jobs", queueSettings{timeout: time.Second}, 3, time.Hour,
+				func(ctx context.Context, limit int, lease time.Duration) ([]int, error) {
+					assert.Equal(t, 3, limit)
+					assert.Equal(t, time.Minute+time.Second, lease)
+					claims++
+					if tt.claimErr != nil {
+						return nil, tt.claimErr
+					}
+					return tt.batches[claims-1], nil
+				},
+				func(ctx context.Context, job int) {
+					mu.Lock()
+					defer mu.Unlock()
+					processed = append(processed, job)
+				})
+
+			w.processDue(context.Background())
+
+			assert.Equal(t, tt.wantClaims, claims)
+			assert.ElementsMatch(t, tt.want, processed)
+		})
+	}
+}
+
+func TestQueueWorker_ListenAndServe(t *testing.T) {
+	claimed := make(chan struct{}, 10)
+	w := newQueueWorker(logger.New(io.Discard, logger.DEBUG), "jobs", queueSettings{interval: time.Hour}, 10, time.Hour,
+		func(ctx context.Context, limit int, lease time.Duration) ([]int, error) {
+			claimed <- struct{}{}
+			return nil, nil
+		},
+		func(ctx context.Context, job int) {})
+
+	// Wake-ups before the worker runs are coalesced
+	w.Wake()
+	w.Wake()
+
+	done := make(chan error)
+	go func() { done <- w.ListenAndServe() }()
+
+	// Due jobs are looked for on start, and once more for the pending
+	// wake-up
+	for i := 0; i < 2; i++ {
+		select {
+		case <-claimed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("worker did not look for due jobs")
+		}
+	}
+
+	w.Wake()
+	select {
+	case <-claimed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not wake up")
+	}
+
+	require.NoError(t, w.Shutdown(context.Background()))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop")
+	}
+	assert.Empty(t, claimed)
+}
+
+func TestQueueWorker_RetryDelay(t *testing.T) {
+	w := newQueueWorker(logger.New(io.Discard, logger.DEBUG), "jobs", queueSettings{backoff: time.Minute}, 10, time.Hour,
+		func(context.Context, int, time.Duration) ([]int, error) { return nil, nil },
+		func(context.Context, int) {})
+
+	assert.Equal(t, time.Minute, w.retryDelay(0))
+	assert.Equal(t, time.Minute, w.retryDelay(1))
+	assert.Equal(t, 2*time.Minute, w.retryDelay(2))
+	assert.Equal(t, 32*time.Minute, w.retryDelay(6))
+	assert.Equal(t, time.Hour, w.retryDelay(7))
+	assert.Equal(t, time.Hour, w.retryDelay(100))
+}
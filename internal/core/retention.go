@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+const (
+	// DefaultRetentionInterval is how often messages are purged when
+	// retention.interval is not configured
+	DefaultRetentionInterval = time.Hour
+	// DefaultRetentionBatchSize is the number of messages deleted at once
+	// when retention.batch_size is not configured
+	DefaultRetentionBatchSize = 1000
+)
+
+// PurgeReport sums up the messages deleted by a purge
+type PurgeReport struct {
+	Inboxes  int
+	Messages int
+	Bytes    int64
+}
+
+type RetentionService struct {
+	core *Core
+}
+
+func NewRetentionService(core *Core) RetentionService {
+	return RetentionService{core: core}
+}
+
+// GetForProject returns the retention policy of a project. A project without
+// a policy gets an empty one, keeping its messages forever.
+func (s *RetentionService) GetForProject(ctx context.Context, projectID int) (*models.RetentionPolicy, error) {
+	s.core.Logger.Debug("Fetching retention policy of project %d", projectID)
+
+	if err := s.core.authorizeProject(ctx, projectID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.core.Repository.GetProjectRetentionPolicy(ctx, projectID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &models.RetentionPolicy{ProjectID: projectID}, nil
+	}
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch retention policy: %v", err)
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetForInbox returns the retention policy set on an inbox itself. Limits it
+// leaves unset fall back to the policy of the project.
+func (s *RetentionService) GetForInbox(ctx context.Context, inboxID int) (*models.RetentionPolicy, error) {
+	s.core.Logger.Debug("Fetching retention policy of inbox %d", inboxID)
+
+	inbox, err := s.core.Repository.GetInbox(ctx, inboxID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch inbox: %v", err)
+		return nil, err
+	}
+
+	if err := s.core.authorizeProject(ctx, inbox.ProjectID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.core.Repository.GetInboxRetentionPolicy(ctx, inboxID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return &models.RetentionPolicy{ProjectID: inbox.ProjectID, InboxID: null.IntFrom(inboxID)}, nil
+	}
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch retention policy: %v", err)
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Set replaces the retention policy of a project, or of an inbox when the
+// policy has one. Limits left unset are removed.
+func (s *RetentionService) Set(ctx context.Context, policy *models.RetentionPolicy) error {
+	s.core.Logger.Info("Setting retention policy of project %d", policy.ProjectID)
+
+	if policy.InboxID.Valid {
+		inbox, err := s.core.Repository.GetInbox(ctx, policy.InboxID.Int)
+		if err != nil {
+			s.core.Logger.Error("Failed to fetch inbox: %v", err)
+			return err
+		}
+		if inbox.ProjectID != policy.ProjectID {
+			return ErrNotFound
+		}
+	}
+
+	if err := s.core.authorizeProject(ctx, policy.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if (policy.MaxAgeHours.Valid && policy.MaxAgeHours.Int <= 0) ||
+		(policy.MaxMessages.Valid && policy.MaxMessages.Int <= 0) ||
+		(policy.MaxSizeBytes.Valid && policy.MaxSizeBytes.Int64 <= 0) {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "retention limits must be positive",
+		}
+	}
+
+	if err := s.core.Repository.UpsertRetentionPolicy(ctx, policy); err != nil {
+		s.core.Logger.Error("Failed to set retention policy: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully set retention policy with ID: %d", policy.ID)
+	return nil
+}
+
+// Purge deletes the messages falling outside the retention policies, oldest
+// first and batchSize messages at a time. Subscribers are notified of every
+// batch. A failing inbox is logged and skipped, the report covers what was
+// deleted until ctx was cancelled.
+func (s *RetentionService) Purge(ctx context.Context, batchSize int) (*PurgeReport, error) {
+	policies, err := s.core.Repository.ListEffectiveRetentionPolicies(ctx)
+	if err != nil {
+		s.core.Logger.Error("Failed to list retention policies: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &PurgeReport{}
+	for _, policy := range policies {
+		deleted, size, err := s.purgeInbox(ctx, policy, now, batchSize)
+		if deleted > 0 {
+			s.core.Logger.Info("Purged %d messages (%d bytes) from inbox %d", deleted, size, policy.InboxID.Int)
+			report.Inboxes++
+			report.Messages += deleted
+			report.Bytes += size
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			s.core.Logger.Error("Failed to purge messages of inbox %d: %v", policy.InboxID.Int, err)
+		}
+	}
+
+	if report.Messages > 0 {
+		s.core.Logger.Info("Purged %d messages (%d bytes) from %d inboxes", report.Messages, report.Bytes, report.Inboxes)
+	}
+	return report, nil
+}
+
+func (s *RetentionService) purgeInbox(ctx context.Context, policy *models.RetentionPolicy, now time.Time, batchSize int) (int, int64, error) {
+	var deleted int
+	var size int64
+	for {
+		messages, err := s.core.Repository.PurgeMessages(ctx, policy, now, batchSize)
+		if err != nil {
+			return deleted, size, err
+		}
+
+		deleted += len(messages)
+		for _, message := range messages {
+			size += int64(message.Size)
+		}
+		for _, group := range groupByFolder(messages) {
+			s.core.Events.Publish(events.Event{
+				Type:       events.MessagesDeleted,
+				InboxID:    group[0].InboxID,
+				FolderID:   group[0].FolderID,
+				MessageIDs: messageIDs(group),
+			})
+		}
+
+		if len(messages) < batchSize {
+			return deleted, size, nil
+		}
+	}
+}
+
+// RetentionWorker periodically purges the messages falling outside the
+// retention policies. It is run alongside the servers and stopped with
+// Shutdown, which interrupts a purge in progress.
+type RetentionWorker struct {
+	core      *Core
+	interval  time.Duration
+	batchSize int
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewRetentionWorker(core *Core) *RetentionWorker {
+	interval := core.Config.Retention.Interval
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	batchSize := core.Config.Retention.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultRetentionBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(WithSystemContext(context.Background()))
+	return &RetentionWorker{
+		core:      core,
+		interval:  interval,
+		batchSize: batchSize,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// ListenAndServe purges messages right away and then once per interval
+// until Shutdown is called
+func (w *RetentionWorker) ListenAndServe() error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// Failures are logged by Purge and retried on the next tick
+		_, _ = w.core.RetentionService.Purge(w.ctx, w.batchSize)
+
+		select {
+		case <-w.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *RetentionWorker) Shutdown(ctx context.Context) error {
+	w.cancel()
+	return nil
+}
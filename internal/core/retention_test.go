@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"inbox451/internal/config"
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupRetentionTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	core := &Core{
+		Config:     &config.Config{},
+		Logger:     logger,
+		Repository: mockRepo,
+		Events:     events.NewBus(),
+	}
+	core.RetentionService = NewRetentionService(core)
+
+	return core, mockRepo
+}
+
+func TestRetentionService_GetForProject(t *testing.T) {
+	t.Run("existing policy", func(t *testing.T) {
+		core, mockRepo := setupRetentionTestCore(t)
+		mockRepo.On("GetProjectRetentionPolicy", mock.Anything, 1).
+			Return(&models.RetentionPolicy{ProjectID: 1, MaxAgeHours: null.IntFrom(24)}, nil)
+
+		policy, err := core.RetentionService.GetForProject(WithSystemContext(context.Background()), 1)
+		require.NoError(t, err)
+		assert.Equal(t, null.IntFrom(24), policy.MaxAgeHours)
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		core, mockRepo := setupRetentionTestCore(t)
+		mockRepo.On("GetProjectRetentionPolicy", mock.Anything, 1).Return(nil, storage.ErrNotFound)
+
+		policy, err := core.RetentionService.GetForProject(WithSystemContext(context.Background()), 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, policy.ProjectID)
+		assert.True(t, policy.IsEmpty())
+	})
+}
+
+func TestRetentionService_Set(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *models.RetentionPolicy
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name:   "project policy",
+			policy: &models.RetentionPolicy{ProjectID: 1, MaxMessages: null.IntFrom(100)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("UpsertRetentionPolicy", mock.Anything, mock.AnythingOfType("*models.RetentionPolicy")).Return(nil)
+			},
+		},
+		{
+			name:   "inbox policy",
+			policy: &models.RetentionPolicy{ProjectID: 1, InboxID: null.IntFrom(2), MaxAgeHours: null.IntFrom(1)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 2).Return(&models.Inbox{Base: models.Base{ID: 2}, ProjectID: 1}, nil)
+				m.On("UpsertRetentionPolicy", mock.Anything, mock.AnythingOfType("*models.RetentionPolicy")).Return(nil)
+			},
+		},
+		{
+			name:   "inbox of another project",
+			policy: &models.RetentionPolicy{ProjectID: 1, InboxID: null.IntFrom(2)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 2).Return(&models.Inbox{Base: models.Base{ID: 2}, ProjectID: 3}, nil)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:     "non-positive limit",
+			policy:   &models.RetentionPolicy{ProjectID: 1, MaxSizeBytes: null.Int64From(0)},
+			mockFn:   func(m *mocks.Repository) {},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupRetentionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.RetentionService.Set(WithSystemContext(context.Background()), tt.policy)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantCode != 0:
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.wantCode, apiErr.Code)
+			default:
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRetentionService_Purge(t *testing.T) {
+	core, mockRepo := setupRetentionTestCore(t)
+
+	first := &models.RetentionPolicy{ProjectID: 1, InboxID: null.IntFrom(2), MaxMessages: null.IntFrom(10)}
+	failing := &models.RetentionPolicy{ProjectID: 1, InboxID: null.IntFrom(3), MaxAgeHours: null.IntFrom(1)}
+	last := &models.RetentionPolicy{ProjectID: 4, InboxID: null.IntFrom(5), MaxSizeBytes: null.Int64From(1024)}
+
+	mockRepo.On("ListEffectiveRetentionPolicies", mock.Anything).
+		Return([]*models.RetentionPolicy{first, failing, last}, nil)
+	mockRepo.On("PurgeMessages", mock.Anything, first, mock.Anything, 2).Return([]*models.Message{
+		{Base: models.Base{ID: 1}, InboxID: 2, Size: 100},
+		{Base: models.Base{ID: 2}, InboxID: 2, FolderID: null.IntFrom(7), Size: 200},
+	}, nil).Once()
+	mockRepo.On("PurgeMessages", mock.Anything, first, mock.Anything, 2).Return([]*models.Message{
+		{Base: models.Base{ID: 3}, InboxID: 2, Size: 300},
+	}, nil).Once()
+	mockRepo.On("PurgeMessages", mock.Anything, failing, mock.Anything, 2).Return(nil, errors.New("db down"))
+	mockRepo.On("PurgeMessages", mock.Anything, last, mock.Anything, 2).Return([]*models.Message{}, nil)
+
+	var deleted []events.Event
+	core.Events.Subscribe(func(ev events.Event) {
+		deleted = append(deleted, ev)
+	})
+
+	report, err := core.RetentionService.Purge(WithSystemContext(context.Background()), 2)
+	require.NoError(t, err)
+	assert.Equal(t, &PurgeReport{Inboxes: 1, Messages: 3, Bytes: 600}, report)
+
+	require.Len(t, deleted, 3)
+	assert.Equal(t, []int{1}, deleted[0].MessageIDs)
+	assert.Equal(t, null.IntFrom(7), deleted[1].FolderID)
+	assert.Equal(t, []int{2}, deleted[1].MessageIDs)
+	assert.Equal(t, []int{3}, deleted[2].MessageIDs)
+	for _, ev := range deleted {
+		assert.Equal(t, events.MessagesDeleted, ev.Type)
+		assert.Equal(t, 2, ev.InboxID)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
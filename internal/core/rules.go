@@ -1,7 +1,12 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
 
 	"inbox451/internal/models"
 )
@@ -17,6 +22,14 @@ func NewRuleService(core *Core) RuleService {
 func (s *RuleService) Create(ctx context.Context, rule *models.ForwardRule) error {
 	s.core.Logger.Info("Creating new rule for inbox %d", rule.InboxID)
 
+	if err := s.core.authorizeInbox(ctx, rule.InboxID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.CreateRule(ctx, rule); err != nil {
 		s.core.Logger.Error("Failed to create rule: %v", err)
 		return err
@@ -40,12 +53,24 @@ func (s *RuleService) Get(ctx context.Context, id int) (*models.ForwardRule, err
 		return nil, ErrNotFound
 	}
 
+	if err := s.core.authorizeInbox(ctx, rule.InboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
 	return rule, nil
 }
 
 func (s *RuleService) Update(ctx context.Context, rule *models.ForwardRule) error {
 	s.core.Logger.Info("Updating rule with ID: %d", rule.ID)
 
+	if err := s.core.authorizeRule(ctx, rule.ID, RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.UpdateRule(ctx, rule); err != nil {
 		s.core.Logger.Error("Failed to update rule: %v", err)
 		return err
@@ -58,6 +83,10 @@ func (s *RuleService) Update(ctx context.Context, rule *models.ForwardRule) erro
 func (s *RuleService) Delete(ctx context.Context, id int) error {
 	s.core.Logger.Info("Deleting rule with ID: %d", id)
 
+	if err := s.core.authorizeRule(ctx, id, RoleAdmin); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.DeleteRule(ctx, id); err != nil {
 		s.core.Logger.Error("Failed to delete rule: %v", err)
 		return err
@@ -70,6 +99,10 @@ func (s *RuleService) Delete(ctx context.Context, id int) error {
 func (s *RuleService) ListByInbox(ctx context.Context, inboxID, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Info("Listing rules for inbox %d with limit: %d and offset: %d", inboxID, limit, offset)
 
+	if err := s.core.authorizeInbox(ctx, inboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
 	rules, total, err := s.core.Repository.ListRulesByInbox(ctx, inboxID, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list rules: %v", err)
@@ -86,3 +119,171 @@ func (s *RuleService) ListByInbox(ctx context.Context, inboxID, limit, offset in
 	s.core.Logger.Info("Successfully retrieved %d rules (total: %d)", len(rules), total)
 	return response, nil
 }
+
+// Apply evaluates every rule of the message's inbox against the message,
+// records a hit on each matching rule and relays the message to the rule's
+// forward_to address when an outbound relay is configured. Relay delivery
+// happens in the background so a slow relay never delays ingestion.
+func (s *RuleService) Apply(ctx context.Context, message *models.Message) error {
+	rules, err := s.core.Repository.ListAllRulesByInbox(ctx, message.InboxID)
+	if err != nil {
+		s.core.Logger.Error("Failed to load rules for inbox %d: %v", message.InboxID, err)
+		return err
+	}
+
+	for _, rule := range rules {
+		matched, err := matchRule(rule, message)
+		if err != nil {
+			s.core.Logger.Warn("Skipping invalid rule %d: %v", rule.ID, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		s.core.Logger.Info("Message %d matched rule %d", message.ID, rule.ID)
+
+		if err := s.core.Repository.IncrementRuleHitCount(ctx, rule.ID); err != nil {
+			s.core.Logger.Error("Failed to record hit for rule %d: %v", rule.ID, err)
+		}
+
+		if rule.ForwardTo == "" {
+			continue
+		}
+		if s.core.Relay == nil {
+			s.core.Logger.Warn("Rule %d matched but no outbound relay is configured", rule.ID)
+			continue
+		}
+
+		if err := s.forward(ctx, rule, message); err != nil {
+			s.core.Logger.Error("Failed to queue forward of message %d to %s: %v", message.ID, rule.ForwardTo, err)
+		}
+	}
+
+	return nil
+}
+
+// forward queues the message for the relay like the messages sent from the
+// inbox, so failed deliveries are retried by the OutboundDispatcher. No copy
+// is filed in the Sent folder.
+func (s *RuleService) forward(ctx context.Context, rule *models.ForwardRule, message *models.Message) error {
+	raw := message.Raw
+	if raw == nil {
+		var err error
+		if raw, err = renderMessage(message); err != nil {
+			return fmt.Errorf("rendering message: %w", err)
+		}
+	}
+
+	// Trace headers are prepended so the original source is relayed untouched
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "X-Inbox451-Rule: %d\r\n", rule.ID)
+	fmt.Fprintf(&buf, "X-Inbox451-Original-Recipient: %s\r\n", message.Receiver)
+	buf.Write(raw)
+
+	outbound := &models.OutboundMessage{
+		InboxID:    message.InboxID,
+		Sender:     message.Sender,
+		Recipients: []string{rule.ForwardTo},
+		Raw:        buf.Bytes(),
+	}
+	if err := s.core.Repository.QueueOutboundMessage(ctx, outbound, nil); err != nil {
+		return err
+	}
+
+	s.core.Logger.Info("Queued forward of message %d to %s (rule %d) as outbound message %d",
+		message.ID, rule.ForwardTo, rule.ID, outbound.ID)
+	s.core.OutboundDispatcher.Wake()
+	return nil
+}
+
+// validateRule makes sure a rule has at least one condition and that its
+// patterns can be compiled for the selected match type.
+func validateRule(rule *models.ForwardRule) error {
+	if rule.MatchType == "" {
+		rule.MatchType = models.RuleMatchExact
+	}
+
+	if rule.Sender == "" && rule.Receiver == "" && rule.Subject == "" {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "rule must match on at least one of sender, receiver or subject",
+		}
+	}
+
+	for _, pattern := range []string{rule.Sender, rule.Receiver, rule.Subject} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compilePattern(rule.MatchType, pattern); err != nil {
+			return &APIError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("invalid %s pattern %q: %v", rule.MatchType, pattern, err),
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchRule reports whether every non-empty field of the rule matches the
+// corresponding field of the message.
+func matchRule(rule *models.ForwardRule, message *models.Message) (bool, error) {
+	if rule.Sender == "" && rule.Receiver == "" && rule.Subject == "" {
+		return false, nil
+	}
+
+	fields := []struct {
+		pattern string
+		value   string
+	}{
+		{rule.Sender, message.Sender},
+		{rule.Receiver, message.Receiver},
+		{rule.Subject, message.Subject},
+	}
+
+	for _, f := range fields {
+		if f.pattern == "" {
+			continue
+		}
+		ok, err := matchField(rule.MatchType, f.pattern, f.value)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// matchField matches a single value against a pattern. Exact and wildcard
+// patterns are case-insensitive; regular expressions are used verbatim.
+func matchField(matchType, pattern, value string) (bool, error) {
+	if matchType == "" || matchType == models.RuleMatchExact {
+		return strings.EqualFold(pattern, value), nil
+	}
+
+	re, err := compilePattern(matchType, pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+// compilePattern turns a wildcard or regex pattern into a regular expression.
+// In wildcard patterns "*" matches any run of characters and "?" matches a
+// single character.
+func compilePattern(matchType, pattern string) (*regexp.Regexp, error) {
+	switch matchType {
+	case models.RuleMatchExact:
+		return regexp.Compile("(?i)^" + regexp.QuoteMeta(pattern) + "$")
+	case models.RuleMatchWildcard:
+		quoted := regexp.QuoteMeta(pattern)
+		quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+		quoted = strings.ReplaceAll(quoted, `\?`, ".")
+		return regexp.Compile("(?i)^" + quoted + "$")
+	case models.RuleMatchRegex:
+		return regexp.Compile(pattern)
+	default:
+		return nil, fmt.Errorf("unknown match type %q", matchType)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	null "github.com/volatiletech/null/v9"
 )
 
@@ -65,6 +66,25 @@ func TestRuleService_Create(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "rule without conditions",
+			rule: &models.ForwardRule{
+				InboxID:   1,
+				ForwardTo: "qa@example.com",
+			},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
+		{
+			name: "invalid regex",
+			rule: &models.ForwardRule{
+				InboxID:   1,
+				Subject:   "([a-z",
+				MatchType: models.RuleMatchRegex,
+			},
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,7 +92,7 @@ func TestRuleService_Create(t *testing.T) {
 			core, mockRepo := setupRuleTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.RuleService.Create(context.Background(), tt.rule)
+			err := core.RuleService.Create(WithSystemContext(context.Background()), tt.rule)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -140,7 +160,7 @@ func TestRuleService_Get(t *testing.T) {
 			core, mockRepo := setupRuleTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.RuleService.Get(context.Background(), tt.id)
+			got, err := core.RuleService.Get(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -200,7 +220,7 @@ func TestRuleService_Update(t *testing.T) {
 			core, mockRepo := setupRuleTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.RuleService.Update(context.Background(), tt.rule)
+			err := core.RuleService.Update(WithSystemContext(context.Background()), tt.rule)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -242,7 +262,7 @@ func TestRuleService_Delete(t *testing.T) {
 			core, mockRepo := setupRuleTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.RuleService.Delete(context.Background(), tt.id)
+			err := core.RuleService.Delete(WithSystemContext(context.Background()), tt.id)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -351,7 +371,7 @@ func TestRuleService_ListByInbox(t *testing.T) {
 			core, mockRepo := setupRuleTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.RuleService.ListByInbox(context.Background(), tt.inboxID, tt.limit, tt.offset)
+			got, err := core.RuleService.ListByInbox(WithSystemContext(context.Background()), tt.inboxID, tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -363,3 +383,155 @@ func TestRuleService_ListByInbox(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleService_Apply(t *testing.T) {
+	message := &models.Message{
+		Base:     models.Base{ID: 10},
+		InboxID:  1,
+		Sender:   "alerts@ci.example.com",
+		Receiver: "qa@example.com",
+		Subject:  "Build #42 failed",
+		Body:     "Test Body",
+	}
+
+	tests := []struct {
+		name        string
+		mockFn      func(*mocks.Repository)
+		wantForward []string
+		wantErr     bool
+	}{
+		{
+			name: "matching rule is counted and forwarded",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAllRulesByInbox", mock.Anything, 1).Return([]*models.ForwardRule{
+					{Base: models.Base{ID: 1}, InboxID: 1, Sender: "*@ci.example.com", MatchType: models.RuleMatchWildcard, ForwardTo: "team@example.com"},
+					{Base: models.Base{ID: 2}, InboxID: 1, Subject: "Build passed", MatchType: models.RuleMatchExact, ForwardTo: "other@example.com"},
+				}, nil)
+				m.On("IncrementRuleHitCount", mock.Anything, 1).Return(nil)
+			},
+			wantForward: []string{"team@example.com"},
+		},
+		{
+			name: "failure to queue a forward is not an error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAllRulesByInbox", mock.Anything, 1).Return([]*models.ForwardRule{
+					{Base: models.Base{ID: 1}, InboxID: 1, Sender: "*@ci.example.com", MatchType: models.RuleMatchWildcard, ForwardTo: "team@example.com"},
+				}, nil)
+				m.On("IncrementRuleHitCount", mock.Anything, 1).Return(nil)
+				m.On("QueueOutboundMessage", mock.Anything, mock.Anything, (*models.Message)(nil)).
+					Return(errors.New("database error"))
+			},
+		},
+		{
+			name: "matching rule without destination is only counted",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAllRulesByInbox", mock.Anything, 1).Return([]*models.ForwardRule{
+					{Base: models.Base{ID: 3}, InboxID: 1, Subject: `^Build #\d+ failed$`, MatchType: models.RuleMatchRegex},
+				}, nil)
+				m.On("IncrementRuleHitCount", mock.Anything, 3).Return(nil)
+			},
+		},
+		{
+			name: "repository error",
+			mockFn: func(m *mocks.Repository) {
+				m.On("ListAllRulesByInbox", mock.Anything, 1).Return(nil, errors.New("database error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupRuleTestCore(t)
+			core.Relay = &outboundRelay{}
+			tt.mockFn(mockRepo)
+
+			// Forwards are queued for the outbound dispatcher, without a
+			// copy in the Sent folder
+			var queued []*models.OutboundMessage
+			if tt.wantForward != nil {
+				mockRepo.On("QueueOutboundMessage", mock.Anything, mock.AnythingOfType("*models.OutboundMessage"), (*models.Message)(nil)).
+					Run(func(args mock.Arguments) {
+						queued = append(queued, args.Get(1).(*models.OutboundMessage))
+					}).
+					Return(nil)
+			}
+
+			err := core.RuleService.Apply(WithSystemContext(context.Background()), message)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			if tt.wantForward != nil {
+				require.Len(t, queued, 1)
+				assert.Equal(t, 1, queued[0].InboxID)
+				assert.Equal(t, "alerts@ci.example.com", queued[0].Sender)
+				assert.Equal(t, tt.wantForward, []string(queued[0].Recipients))
+				assert.Contains(t, string(queued[0].Raw), "X-Inbox451-Rule: 1\r\n")
+				assert.Contains(t, string(queued[0].Raw), "Subject: Build #42 failed")
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	message := &models.Message{
+		Sender:   "Alerts@CI.example.com",
+		Receiver: "qa+run-17@example.com",
+		Subject:  "Build #42 failed",
+	}
+
+	tests := []struct {
+		name string
+		rule *models.ForwardRule
+		want bool
+	}{
+		{
+			name: "exact match is case-insensitive",
+			rule: &models.ForwardRule{Sender: "alerts@ci.example.com", MatchType: models.RuleMatchExact},
+			want: true,
+		},
+		{
+			name: "empty match type defaults to exact",
+			rule: &models.ForwardRule{Subject: "Build #42"},
+			want: false,
+		},
+		{
+			name: "wildcard match",
+			rule: &models.ForwardRule{Receiver: "qa+*@example.com", MatchType: models.RuleMatchWildcard},
+			want: true,
+		},
+		{
+			name: "wildcard single character",
+			rule: &models.ForwardRule{Subject: "Build #4? failed", MatchType: models.RuleMatchWildcard},
+			want: true,
+		},
+		{
+			name: "regex match",
+			rule: &models.ForwardRule{Subject: `#\d+ failed$`, MatchType: models.RuleMatchRegex},
+			want: true,
+		},
+		{
+			name: "all fields must match",
+			rule: &models.ForwardRule{Sender: "*@ci.example.com", Subject: "*passed*", MatchType: models.RuleMatchWildcard},
+			want: false,
+		},
+		{
+			name: "rule without conditions never matches",
+			rule: &models.ForwardRule{MatchType: models.RuleMatchWildcard},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchRule(tt.rule, message)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+)
+
+const (
+	// DefaultSessionTTL is the lifetime of a web session when
+	// server.http.session_ttl is not configured
+	DefaultSessionTTL = 24 * time.Hour
+	// DefaultSessionReapInterval is how often expired sessions are removed
+	// when server.http.session_reap_interval is not configured
+	DefaultSessionReapInterval = time.Hour
+)
+
+type SessionService struct {
+	core *Core
+}
+
+func NewSessionService(core *Core) SessionService {
+	return SessionService{core: core}
+}
+
+// TTL returns the configured lifetime of new sessions
+func (s *SessionService) TTL() time.Duration {
+	if ttl := s.core.Config.Server.HTTP.SessionTTL; ttl > 0 {
+		return ttl
+	}
+	return DefaultSessionTTL
+}
+
+// Login verifies a username and password and starts a new session for the
+// user. Only users allowed to log in with a password can start a session, API
+// tokens are not accepted. Returns ErrUnauthorized when the credentials do
+// not match.
+func (s *SessionService) Login(ctx context.Context, username, password, ipAddress, userAgent string) (*models.Session, *models.User, error) {
+	s.core.Logger.Debug("Logging in user: %s", username)
+
+	if username == "" || password == "" {
+		return nil, nil, ErrUnauthorized
+	}
+
+	user, err := s.core.Repository.GetUserByUsername(ctx, username)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch user: %v", err)
+		return nil, nil, err
+	}
+	if user == nil || !user.PasswordLogin || !checkPassword(user.Password, password) {
+		s.core.Logger.Info("Login failed for user: %s", username)
+		return nil, nil, ErrUnauthorized
+	}
+
+	sessionID, err := generateSecureTokenBase64()
+	if err != nil {
+		s.core.Logger.Error("Failed to generate session ID: %v", err)
+		return nil, nil, err
+	}
+
+	session := &models.Session{
+		SessionID: sessionID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.TTL()),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	if err := s.core.Repository.CreateSession(ctx, session); err != nil {
+		s.core.Logger.Error("Failed to create session: %v", err)
+		return nil, nil, err
+	}
+
+	if err := s.core.Repository.UpdateUserLoggedInAt(ctx, user.ID); err != nil {
+		s.core.Logger.Error("Failed to update login time of user %d: %v", user.ID, err)
+	}
+
+	s.core.Logger.Info("User %d logged in, session %d", user.ID, session.ID)
+	user.Password = ""
+	return session, user, nil
+}
+
+// Logout ends the session with the given session ID. Ending an unknown or
+// already ended session is not an error.
+func (s *SessionService) Logout(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	if err := s.core.Repository.DeactivateSession(ctx, sessionID); err != nil {
+		if errors.Is(err, storage.ErrNoRowsAffected) {
+			return nil
+		}
+		s.core.Logger.Error("Failed to end session: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Authenticate returns the user of an active, unexpired session. Returns
+// ErrUnauthorized for unknown, ended or expired sessions.
+func (s *SessionService) Authenticate(ctx context.Context, sessionID string) (*models.User, error) {
+	if sessionID == "" {
+		return nil, ErrUnauthorized
+	}
+
+	session, err := s.core.Repository.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.core.Logger.Info("Authentication failed, unknown session")
+			return nil, ErrUnauthorized
+		}
+		s.core.Logger.Error("Failed to fetch session: %v", err)
+		return nil, err
+	}
+
+	if !session.IsActive || session.ExpiresAt.Before(time.Now()) {
+		s.core.Logger.Info("Authentication failed, session %d ended", session.ID)
+		return nil, ErrUnauthorized
+	}
+
+	user, err := s.core.Repository.GetUser(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUnauthorized
+		}
+		s.core.Logger.Error("Failed to fetch user: %v", err)
+		return nil, err
+	}
+
+	// Failing to record the access must not lock the user out
+	if err := s.core.Repository.TouchSession(ctx, session.ID); err != nil {
+		s.core.Logger.Error("Failed to update last access of session %d: %v", session.ID, err)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// ListByUser returns a page of the active sessions of a user, most recently
+// used first
+func (s *SessionService) ListByUser(ctx context.Context, userID, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing sessions for user %d with limit: %d and offset: %d", userID, limit, offset)
+
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, total, err := s.core.Repository.ListSessionsByUser(ctx, userID, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list sessions for user %d: %v", userID, err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: sessions,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully retrieved %d sessions for user %d (total: %d)", len(sessions), userID, total)
+	return response, nil
+}
+
+// Revoke ends an active session of a user
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID int) error {
+	s.core.Logger.Info("Revoking session %d of user %d", sessionID, userID)
+
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.RevokeSession(ctx, sessionID, userID); err != nil {
+		s.core.Logger.Error("Failed to revoke session %d: %v", sessionID, err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully revoked session %d of user %d", sessionID, userID)
+	return nil
+}
+
+// RevokeAll ends every active session of a user, including the one of the
+// caller, and returns how many were ended
+func (s *SessionService) RevokeAll(ctx context.Context, userID int) (int, error) {
+	s.core.Logger.Info("Revoking all sessions of user %d", userID)
+
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.core.Repository.RevokeSessionsByUser(ctx, userID)
+	if err != nil {
+		s.core.Logger.Error("Failed to revoke sessions of user %d: %v", userID, err)
+		return 0, err
+	}
+
+	s.core.Logger.Info("Successfully revoked %d sessions of user %d", revoked, userID)
+	return revoked, nil
+}
+
+// ReapExpired removes the sessions that have expired, whether or not they
+// were ended before
+func (s *SessionService) ReapExpired(ctx context.Context) (int, error) {
+	deleted, err := s.core.Repository.DeleteExpiredSessions(ctx, time.Now())
+	if err != nil {
+		s.core.Logger.Error("Failed to delete expired sessions: %v", err)
+		return 0, err
+	}
+
+	if deleted > 0 {
+		s.core.Logger.Info("Deleted %d expired sessions", deleted)
+	}
+	return deleted, nil
+}
+
+// SessionReaper periodically removes expired sessions. It is run alongside
+// the servers and stopped with Shutdown.
+type SessionReaper struct {
+	core     *Core
+	interval time.Duration
+	done     chan struct{}
+}
+
+func NewSessionReaper(core *Core) *SessionReaper {
+	interval := core.Config.Server.HTTP.SessionReapInterval
+	if interval <= 0 {
+		interval = DefaultSessionReapInterval
+	}
+
+	return &SessionReaper{
+		core:     core,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// ListenAndServe removes expired sessions right away and then once per
+// interval until Shutdown is called
+func (r *SessionReaper) ListenAndServe() error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Failures are logged by ReapExpired and retried on the next tick
+		_, _ = r.core.SessionService.ReapExpired(WithSystemContext(context.Background()))
+
+		select {
+		case <-r.done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *SessionReaper) Shutdown(ctx context.Context) error {
+	close(r.done)
+	return nil
+}
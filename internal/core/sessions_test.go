@@ -0,0 +1,276 @@
+package core
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"inbox451/internal/config"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	core := &Core{
+		Config:     &config.Config{},
+		Logger:     logger,
+		Repository: mockRepo,
+	}
+	core.SessionService = NewSessionService(core)
+
+	return core, mockRepo
+}
+
+func TestSessionService_Login(t *testing.T) {
+	hash, err := HashPassword("secret")
+	require.NoError(t, err)
+
+	newUser := func(passwordLogin bool) *models.User {
+		return &models.User{
+			Base:          models.Base{ID: 1},
+			Username:      "testuser",
+			Password:      hash,
+			PasswordLogin: passwordLogin,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+	}{
+		{
+			name:     "valid password",
+			password: "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(true), nil)
+				m.On("CreateSession", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+					return s.UserID == 1 && s.SessionID != "" &&
+						s.IPAddress == "127.0.0.1" && s.UserAgent == "curl/8.0" &&
+						s.ExpiresAt.After(time.Now().Add(DefaultSessionTTL-time.Minute))
+				})).Return(nil)
+				m.On("UpdateUserLoggedInAt", mock.Anything, 1).Return(nil)
+			},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(true), nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "password login disabled",
+			password: "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(false), nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "unknown user",
+			password: "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(nil, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupSessionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			session, user, err := core.SessionService.Login(WithSystemContext(context.Background()),
+				"testuser", tt.password, "127.0.0.1", "curl/8.0")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, session)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, session.SessionID)
+				assert.Equal(t, 1, user.ID)
+				assert.Empty(t, user.Password)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionService_Authenticate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name: "active session",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetSession", mock.Anything, "secret").Return(&models.Session{
+					Base:      models.Base{ID: 5},
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+					IsActive:  true,
+				}, nil)
+				m.On("GetUser", mock.Anything, 1).
+					Return(&models.User{Base: models.Base{ID: 1}, Password: "$2a$10$hash"}, nil)
+				m.On("TouchSession", mock.Anything, 5).Return(nil)
+			},
+		},
+		{
+			name: "ended session",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetSession", mock.Anything, "secret").Return(&models.Session{
+					Base:      models.Base{ID: 5},
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+					IsActive:  false,
+				}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "expired session",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetSession", mock.Anything, "secret").Return(&models.Session{
+					Base:      models.Base{ID: 5},
+					UserID:    1,
+					ExpiresAt: time.Now().Add(-time.Hour),
+					IsActive:  true,
+				}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name: "unknown session",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetSession", mock.Anything, "secret").Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupSessionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			user, err := core.SessionService.Authenticate(WithSystemContext(context.Background()), "secret")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, user.ID)
+				assert.Empty(t, user.Password)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionService_Logout(t *testing.T) {
+	core, mockRepo := setupSessionTestCore(t)
+	mockRepo.On("DeactivateSession", mock.Anything, "secret").Return(nil)
+	mockRepo.On("DeactivateSession", mock.Anything, "unknown").Return(storage.ErrNoRowsAffected)
+
+	assert.NoError(t, core.SessionService.Logout(WithSystemContext(context.Background()), "secret"))
+	assert.NoError(t, core.SessionService.Logout(WithSystemContext(context.Background()), "unknown"))
+	assert.NoError(t, core.SessionService.Logout(WithSystemContext(context.Background()), ""))
+}
+
+func TestSessionService_ListByUser(t *testing.T) {
+	core, mockRepo := setupSessionTestCore(t)
+	sessions := []*models.Session{
+		{Base: models.Base{ID: 2}, UserID: 1, IsActive: true},
+		{Base: models.Base{ID: 1}, UserID: 1, IsActive: true},
+	}
+	mockRepo.On("ListSessionsByUser", mock.Anything, 1, 10, 0).Return(sessions, 2, nil)
+
+	got, err := core.SessionService.ListByUser(WithSystemContext(context.Background()), 1, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, sessions, got.Data)
+	assert.Equal(t, 2, got.Pagination.Total)
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name: "own session",
+			ctx:  WithUser(context.Background(), &models.User{Base: models.Base{ID: 1}, Role: RoleUser}),
+			mockFn: func(m *mocks.Repository) {
+				m.On("RevokeSession", mock.Anything, 5, 1).Return(nil)
+			},
+		},
+		{
+			name:    "session of another user",
+			ctx:     WithUser(context.Background(), &models.User{Base: models.Base{ID: 2}, Role: RoleUser}),
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: ErrForbidden,
+		},
+		{
+			name: "unknown session",
+			ctx:  WithSystemContext(context.Background()),
+			mockFn: func(m *mocks.Repository) {
+				m.On("RevokeSession", mock.Anything, 5, 1).Return(storage.ErrNoRowsAffected)
+			},
+			wantErr: storage.ErrNoRowsAffected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupSessionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.SessionService.Revoke(tt.ctx, 1, 5)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSessionService_RevokeAll(t *testing.T) {
+	core, mockRepo := setupSessionTestCore(t)
+	mockRepo.On("RevokeSessionsByUser", mock.Anything, 1).Return(3, nil)
+
+	revoked, err := core.SessionService.RevokeAll(WithSystemContext(context.Background()), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, revoked)
+}
+
+func TestSessionService_ReapExpired(t *testing.T) {
+	core, mockRepo := setupSessionTestCore(t)
+	mockRepo.On("DeleteExpiredSessions", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) < time.Minute
+	})).Return(4, nil)
+
+	deleted, err := core.SessionService.ReapExpired(WithSystemContext(context.Background()))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, deleted)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"time"
 
 	"inbox451/internal/models"
+	"inbox451/internal/storage"
 )
 
 // TokenService handles operations related to API tokens
@@ -32,6 +35,10 @@ func NewTokensService(core *Core) TokenService {
 func (s *TokenService) ListByUser(ctx context.Context, userId int, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Info("Listing tokens for userId %d with limit: %d and offset: %d", userId, limit, offset)
 
+	if err := s.core.authorizeUser(ctx, userId); err != nil {
+		return nil, err
+	}
+
 	tokens, total, err := s.core.Repository.ListTokensByUser(ctx, userId, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list tokens for userId %d: %v", userId, err)
@@ -65,6 +72,10 @@ func (s *TokenService) ListByUser(ctx context.Context, userId int, limit, offset
 func (s *TokenService) GetByUser(ctx context.Context, tokenID int, userID int) (*models.Token, error) {
 	s.core.Logger.Debug("Fetching token with ID: %d for userID: %d ", tokenID, userID)
 
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	token, err := s.core.Repository.GetTokenByUser(ctx, tokenID, userID)
 	if err != nil {
 		s.core.Logger.Error("Failed to fetch token: %v", err)
@@ -92,6 +103,10 @@ func (s *TokenService) GetByUser(ctx context.Context, tokenID int, userID int) (
 func (s *TokenService) CreateForUser(ctx context.Context, userID int, tokenData *models.Token) (*models.Token, error) {
 	s.core.Logger.Debug("Creating token for userId: %d", userID)
 
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	newToken := models.Token{}
 	newToken.UserID = userID
 
@@ -135,7 +150,7 @@ func (s *TokenService) DeleteByUser(ctx context.Context, userID int, tokenID int
 	s.core.Logger.Debug("Deleting token with ID: %d for userID %d", tokenID, userID)
 
 	// Check if token exists for this user
-	_, err := s.GetByUser(ctx, userID, tokenID)
+	_, err := s.GetByUser(ctx, tokenID, userID)
 	if err != nil {
 		return err
 	}
@@ -149,6 +164,55 @@ func (s *TokenService) DeleteByUser(ctx context.Context, userID int, tokenID int
 	return nil
 }
 
+// Authenticate resolves the user owning an API token, as presented in an
+// Authorization: Bearer header, and records that the token has been used
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - value: The token value
+//
+// Returns:
+//   - *models.User owning the token
+//   - ErrUnauthorized if the token is unknown or expired
+//   - error if the operation fails
+func (s *TokenService) Authenticate(ctx context.Context, value string) (*models.User, error) {
+	if value == "" {
+		return nil, ErrUnauthorized
+	}
+
+	token, err := s.core.Repository.GetTokenByValue(ctx, value)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.core.Logger.Info("Authentication failed, unknown token")
+			return nil, ErrUnauthorized
+		}
+		s.core.Logger.Error("Failed to fetch token: %v", err)
+		return nil, err
+	}
+
+	if token.ExpiresAt.Valid && token.ExpiresAt.Time.Before(time.Now()) {
+		s.core.Logger.Info("Authentication failed, token %d expired", token.ID)
+		return nil, ErrUnauthorized
+	}
+
+	user, err := s.core.Repository.GetUser(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUnauthorized
+		}
+		s.core.Logger.Error("Failed to fetch user: %v", err)
+		return nil, err
+	}
+
+	// Failing to record the usage must not lock the user out
+	if err := s.core.Repository.UpdateTokenLastUsed(ctx, token.ID); err != nil {
+		s.core.Logger.Error("Failed to update last use of token %d: %v", token.ID, err)
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
 // generateSecureTokenBase64 generates a cryptographically secure random token
 // encoded in URL-safe base64. It returns a string of approximately 43 characters
 // (for 32 bytes of entropy) that is safe for use in URLs and file names.
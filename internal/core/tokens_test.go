@@ -123,7 +123,7 @@ func TestTokenService_ListByUser(t *testing.T) {
 			core, mockRepo := setupTokenTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.TokenService.ListByUser(context.Background(), tt.userID, tt.limit, tt.offset)
+			got, err := core.TokenService.ListByUser(WithSystemContext(context.Background()), tt.userID, tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -193,7 +193,7 @@ func TestTokenService_GetByUser(t *testing.T) {
 			core, mockRepo := setupTokenTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.TokenService.GetByUser(context.Background(), tt.tokenID, tt.userID)
+			got, err := core.TokenService.GetByUser(WithSystemContext(context.Background()), tt.tokenID, tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -260,7 +260,7 @@ func TestTokenService_CreateForUser(t *testing.T) {
 			core, mockRepo := setupTokenTestCore(t)
 			tt.mockFn(mockRepo)
 
-			token, err := core.TokenService.CreateForUser(context.Background(), tt.userID, tt.tokenData)
+			token, err := core.TokenService.CreateForUser(WithSystemContext(context.Background()), tt.userID, tt.tokenData)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, token)
@@ -305,7 +305,7 @@ func TestTokenService_DeleteByUser(t *testing.T) {
 			tokenID: 999,
 			userID:  1,
 			mockFn: func(m *mocks.Repository) {
-				m.On("GetTokenByUser", mock.Anything, 1, 999).
+				m.On("GetTokenByUser", mock.Anything, 999, 1).
 					Return(nil, storage.ErrNotFound)
 			},
 			wantErr: true,
@@ -329,7 +329,7 @@ func TestTokenService_DeleteByUser(t *testing.T) {
 			core, mockRepo := setupTokenTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.TokenService.DeleteByUser(context.Background(), tt.userID, tt.tokenID)
+			err := core.TokenService.DeleteByUser(WithSystemContext(context.Background()), tt.userID, tt.tokenID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -340,3 +340,83 @@ func TestTokenService_DeleteByUser(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenService_Authenticate(t *testing.T) {
+	user := &models.User{Base: models.Base{ID: 1}, Username: "testuser"}
+
+	tests := []struct {
+		name    string
+		value   string
+		mockFn  func(*mocks.Repository)
+		want    *models.User
+		wantErr error
+	}{
+		{
+			name:  "valid token",
+			value: "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "secret").
+					Return(&models.Token{Base: models.Base{ID: 2}, UserID: 1}, nil)
+				m.On("GetUser", mock.Anything, 1).Return(user, nil)
+				m.On("UpdateTokenLastUsed", mock.Anything, 2).Return(nil)
+			},
+			want: user,
+		},
+		{
+			name:  "last use update failure does not reject the token",
+			value: "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "secret").
+					Return(&models.Token{Base: models.Base{ID: 2}, UserID: 1}, nil)
+				m.On("GetUser", mock.Anything, 1).Return(user, nil)
+				m.On("UpdateTokenLastUsed", mock.Anything, 2).Return(errors.New("database error"))
+			},
+			want: user,
+		},
+		{
+			name:    "empty token",
+			value:   "",
+			mockFn:  func(m *mocks.Repository) {},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:  "unknown token",
+			value: "unknown",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "unknown").
+					Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:  "expired token",
+			value: "expired",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "expired").
+					Return(&models.Token{
+						Base:      models.Base{ID: 2},
+						UserID:    1,
+						ExpiresAt: null.TimeFrom(time.Now().Add(-time.Hour)),
+					}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupTokenTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.TokenService.Authenticate(WithSystemContext(context.Background()), tt.value)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -2,8 +2,11 @@ package core
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"inbox451/internal/models"
+	"inbox451/internal/storage"
 )
 
 type UserService struct {
@@ -17,10 +20,23 @@ func NewUserService(core *Core) UserService {
 func (s *UserService) Create(ctx context.Context, user *models.User) error {
 	s.core.Logger.Info("Creating new user: %s", user.Name)
 
+	if err := s.core.authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
+	if user.Password != "" {
+		hash, err := HashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hash
+	}
+
 	if err := s.core.Repository.CreateUser(ctx, user); err != nil {
 		s.core.Logger.Error("Failed to create user: %v", err)
 		return err
 	}
+	user.Password = ""
 
 	s.core.Logger.Info("Successfully created user with ID: %d", user.ID)
 	return nil
@@ -29,6 +45,10 @@ func (s *UserService) Create(ctx context.Context, user *models.User) error {
 func (s *UserService) Get(ctx context.Context, userID int) (*models.User, error) {
 	s.core.Logger.Debug("Fetching user with ID: %d", userID)
 
+	if err := s.core.authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	user, err := s.core.Repository.GetUser(ctx, userID)
 	if err != nil {
 		s.core.Logger.Error("Failed to fetch user: %v", err)
@@ -40,16 +60,44 @@ func (s *UserService) Get(ctx context.Context, userID int) (*models.User, error)
 		return nil, ErrNotFound
 	}
 
+	user.Password = ""
 	return user, nil
 }
 
+// Update updates a user. Users may update themselves, but only admins can
+// change the role and status of a user. The password is only changed when a
+// new one is given.
 func (s *UserService) Update(ctx context.Context, user *models.User) error {
 	s.core.Logger.Info("Updating user with ID: %d", user.ID)
 
+	if err := s.core.authorizeUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if caller, ok := UserFromContext(ctx); ok && !isAdmin(caller) {
+		user.Role = caller.Role
+		user.Status = caller.Status
+	}
+
+	if user.Password == "" {
+		existing, err := s.core.Repository.GetUser(ctx, user.ID)
+		if err != nil {
+			s.core.Logger.Error("Failed to fetch user: %v", err)
+			return err
+		}
+		user.Password = existing.Password
+	} else {
+		hash, err := HashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hash
+	}
+
 	if err := s.core.Repository.UpdateUser(ctx, user); err != nil {
 		s.core.Logger.Error("Failed to update user: %v", err)
 		return err
 	}
+	user.Password = ""
 
 	s.core.Logger.Info("Successfully updated user with ID: %d", user.ID)
 	return nil
@@ -58,6 +106,10 @@ func (s *UserService) Update(ctx context.Context, user *models.User) error {
 func (s *UserService) Delete(ctx context.Context, id int) error {
 	s.core.Logger.Info("Deleting user with ID: %d", id)
 
+	if err := s.core.authorizeAdmin(ctx); err != nil {
+		return err
+	}
+
 	if err := s.core.Repository.DeleteUser(ctx, id); err != nil {
 		s.core.Logger.Error("Failed to delete user: %v", err)
 		return err
@@ -70,11 +122,18 @@ func (s *UserService) Delete(ctx context.Context, id int) error {
 func (s *UserService) List(ctx context.Context, limit, offset int) (*models.PaginatedResponse, error) {
 	s.core.Logger.Info("Listing users with limit: %d and offset: %d", limit, offset)
 
+	if err := s.core.authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
 	users, total, err := s.core.Repository.ListUsers(ctx, limit, offset)
 	if err != nil {
 		s.core.Logger.Error("Failed to list users: %v", err)
 		return nil, err
 	}
+	for _, user := range users {
+		user.Password = ""
+	}
 
 	response := &models.PaginatedResponse{
 		Data: users,
@@ -88,3 +147,48 @@ func (s *UserService) List(ctx context.Context, limit, offset int) (*models.Pagi
 	s.core.Logger.Info("Successfully retrieved %d users (total: %d)", len(users), total)
 	return response, nil
 }
+
+// Authenticate verifies a username together with either the user's password
+// or one of their API tokens. Mail protocols only carry a single secret, so
+// both are accepted in the same field. Returns ErrUnauthorized when neither
+// matches.
+func (s *UserService) Authenticate(ctx context.Context, username, secret string) (*models.User, error) {
+	s.core.Logger.Debug("Authenticating user: %s", username)
+
+	if username == "" || secret == "" {
+		return nil, ErrUnauthorized
+	}
+
+	user, err := s.core.Repository.GetUserByUsername(ctx, username)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch user: %v", err)
+		return nil, err
+	}
+	if user == nil {
+		s.core.Logger.Info("Authentication failed, unknown user: %s", username)
+		return nil, ErrUnauthorized
+	}
+
+	if user.PasswordLogin && checkPassword(user.Password, secret) {
+		user.Password = ""
+		return user, nil
+	}
+
+	token, err := s.core.Repository.GetTokenByValue(ctx, secret)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.core.Logger.Info("Authentication failed for user: %s", username)
+			return nil, ErrUnauthorized
+		}
+		s.core.Logger.Error("Failed to fetch token: %v", err)
+		return nil, err
+	}
+
+	if token.UserID != user.ID || (token.ExpiresAt.Valid && token.ExpiresAt.Time.Before(time.Now())) {
+		s.core.Logger.Info("Authentication failed for user: %s", username)
+		return nil, ErrUnauthorized
+	}
+
+	user.Password = ""
+	return user, nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	null "github.com/volatiletech/null/v9"
 )
 
@@ -43,12 +44,14 @@ func TestUserService_Create(t *testing.T) {
 				Name:     "Test User",
 				Username: "testuser",
 				Email:    "test@example.com",
+				Password: "secret",
 				Status:   "active",
 				Role:     "user",
 			},
 			mockFn: func(m *mocks.Repository) {
-				m.On("CreateUser", mock.Anything, mock.AnythingOfType("*models.User")).
-					Return(nil)
+				m.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+					return checkPassword(u.Password, "secret")
+				})).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -72,7 +75,7 @@ func TestUserService_Create(t *testing.T) {
 			core, mockRepo := setupTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.UserService.Create(context.Background(), tt.user)
+			err := core.UserService.Create(WithSystemContext(context.Background()), tt.user)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -138,7 +141,7 @@ func TestUserService_Get(t *testing.T) {
 			core, mockRepo := setupTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.UserService.Get(context.Background(), tt.userID)
+			got, err := core.UserService.Get(WithSystemContext(context.Background()), tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.errType != nil {
@@ -204,7 +207,7 @@ func TestUserService_List(t *testing.T) {
 			core, mockRepo := setupTestCore(t)
 			tt.mockFn(mockRepo)
 
-			got, err := core.UserService.List(context.Background(), tt.limit, tt.offset)
+			got, err := core.UserService.List(WithSystemContext(context.Background()), tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -231,8 +234,25 @@ func TestUserService_Update(t *testing.T) {
 				Name: "Updated Name",
 			},
 			mockFn: func(m *mocks.Repository) {
-				m.On("UpdateUser", mock.Anything, mock.AnythingOfType("*models.User")).
-					Return(nil)
+				m.On("GetUser", mock.Anything, 1).
+					Return(&models.User{Base: models.Base{ID: 1}, Password: "$2a$10$hash"}, nil)
+				m.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+					return u.Password == "$2a$10$hash"
+				})).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "new password is hashed",
+			user: &models.User{
+				Base:     models.Base{ID: 1},
+				Name:     "Updated Name",
+				Password: "new-secret",
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+					return checkPassword(u.Password, "new-secret")
+				})).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -243,8 +263,8 @@ func TestUserService_Update(t *testing.T) {
 				Name: "Updated Name",
 			},
 			mockFn: func(m *mocks.Repository) {
-				m.On("UpdateUser", mock.Anything, mock.AnythingOfType("*models.User")).
-					Return(storage.ErrNotFound)
+				m.On("GetUser", mock.Anything, 999).
+					Return(nil, storage.ErrNotFound)
 			},
 			wantErr: true,
 		},
@@ -255,11 +275,12 @@ func TestUserService_Update(t *testing.T) {
 			core, mockRepo := setupTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.UserService.Update(context.Background(), tt.user)
+			err := core.UserService.Update(WithSystemContext(context.Background()), tt.user)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Empty(t, tt.user.Password)
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -297,7 +318,7 @@ func TestUserService_Delete(t *testing.T) {
 			core, mockRepo := setupTestCore(t)
 			tt.mockFn(mockRepo)
 
-			err := core.UserService.Delete(context.Background(), tt.userID)
+			err := core.UserService.Delete(WithSystemContext(context.Background()), tt.userID)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -308,3 +329,116 @@ func TestUserService_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_Authenticate(t *testing.T) {
+	hash, err := HashPassword("secret")
+	require.NoError(t, err)
+
+	newUser := func() *models.User {
+		return &models.User{
+			Base:          models.Base{ID: 1},
+			Username:      "testuser",
+			Password:      hash,
+			PasswordLogin: true,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		secret   string
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+	}{
+		{
+			name:     "valid password",
+			username: "testuser",
+			secret:   "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(), nil)
+			},
+		},
+		{
+			name:     "valid token",
+			username: "testuser",
+			secret:   "api-token",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "api-token").
+					Return(&models.Token{UserID: 1, Token: "api-token"}, nil)
+			},
+		},
+		{
+			name:     "token of another user",
+			username: "testuser",
+			secret:   "api-token",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "api-token").
+					Return(&models.Token{UserID: 2, Token: "api-token"}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "expired token",
+			username: "testuser",
+			secret:   "api-token",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "api-token").
+					Return(&models.Token{
+						UserID:    1,
+						Token:     "api-token",
+						ExpiresAt: null.TimeFrom(time.Now().Add(-time.Hour)),
+					}, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "wrong secret",
+			username: "testuser",
+			secret:   "wrong",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "testuser").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "wrong").
+					Return(nil, storage.ErrNotFound)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "unknown user",
+			username: "nobody",
+			secret:   "secret",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "nobody").Return(nil, nil)
+			},
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "empty secret",
+			username: "testuser",
+			secret:   "",
+			mockFn:   func(m *mocks.Repository) {},
+			wantErr:  ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupTestCore(t)
+			tt.mockFn(mockRepo)
+
+			got, err := core.UserService.Authenticate(WithSystemContext(context.Background()), tt.username, tt.secret)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, got.ID)
+				assert.Empty(t, got.Password)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
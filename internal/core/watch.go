@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+// watchPageSize is the number of messages fetched per query while a watch
+// catches up with the inbox
+const watchPageSize = 100
+
+const (
+	// DefaultWaitTimeout is how long Wait blocks when no timeout is given
+	DefaultWaitTimeout = 30 * time.Second
+	// MaxWaitTimeout is the longest a client may ask Wait to block
+	MaxWaitTimeout = 5 * time.Minute
+)
+
+// Watch streams the messages of an inbox matching a filter as they are
+// received, in the order they were stored. The copies of the messages sent
+// from the inbox are left out. Messages stored after the message afterID are
+// sent first, a null afterID only streams the messages stored from now on.
+// The channel is closed once ctx is done.
+func (s *MessageService) Watch(ctx context.Context, filter models.MessageFilter, afterID null.Int) (<-chan *models.Message, error) {
+	s.core.Logger.Info("Watching messages of inbox %d after %d", filter.InboxID, afterID.Int)
+
+	if filter.InboxID == 0 {
+		return nil, ErrBadRequest
+	}
+	if err := s.core.authorizeInbox(ctx, filter.InboxID, RoleUser); err != nil {
+		return nil, err
+	}
+
+	// Subscribe before looking at the inbox so no message falls between
+	// catching up and waiting for events
+	notify := make(chan struct{}, 1)
+	unsubscribe := s.core.Events.Subscribe(func(ev events.Event) {
+		if ev.Type != events.MessageCreated || ev.Sent || ev.InboxID != filter.InboxID {
+			return
+		}
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	})
+
+	if !afterID.Valid {
+		last, err := s.core.Repository.GetLastMessageID(ctx, filter.InboxID)
+		if err != nil {
+			unsubscribe()
+			s.core.Logger.Error("Failed to fetch the last message of inbox %d: %v", filter.InboxID, err)
+			return nil, err
+		}
+		afterID = null.IntFrom(last)
+	}
+	filter.AfterID = afterID.Int
+	filter.ExcludeSent = true
+
+	messages := make(chan *models.Message)
+	go func() {
+		defer close(messages)
+		defer unsubscribe()
+
+		for {
+			for {
+				query := filter
+				page, _, err := s.core.Repository.ListMessagesByFilter(ctx, &query, watchPageSize, 0)
+				if err != nil {
+					if ctx.Err() == nil {
+						s.core.Logger.Error("Failed to list new messages of inbox %d: %v", filter.InboxID, err)
+					}
+					return
+				}
+
+				for _, message := range page {
+					select {
+					case messages <- message:
+						filter.AfterID = message.ID
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if len(page) < watchPageSize {
+					break
+				}
+			}
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// Wait returns the first message of an inbox matching a filter, either one
+// already stored or the next one to arrive. A zero timeout waits for
+// DefaultWaitTimeout; when no message matched in time Wait fails with a
+// 408 APIError.
+func (s *MessageService) Wait(ctx context.Context, filter models.MessageFilter, timeout time.Duration) (*models.Message, error) {
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+	if timeout < 0 || timeout > MaxWaitTimeout {
+		return nil, &APIError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("timeout must be between 0 and %s", MaxWaitTimeout),
+		}
+	}
+
+	s.core.Logger.Info("Waiting up to %s for a message in inbox %d", timeout, filter.InboxID)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	messages, err := s.Watch(waitCtx, filter, null.IntFrom(0))
+	if err != nil {
+		return nil, err
+	}
+
+	if message, ok := <-messages; ok {
+		s.core.Logger.Info("Message %d matched the wait in inbox %d", message.ID, filter.InboxID)
+		return message, nil
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case errors.Is(waitCtx.Err(), context.DeadlineExceeded):
+		return nil, &APIError{
+			Code:    http.StatusRequestTimeout,
+			Message: fmt.Sprintf("no matching message arrived within %s", timeout),
+		}
+	}
+	return nil, errors.New("message watch ended unexpectedly")
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func afterMessage(id int) interface{} {
+	return mock.MatchedBy(func(f *models.MessageFilter) bool {
+		return f.InboxID == 1 && f.AfterID == id && f.ExcludeSent
+	})
+}
+
+func receive(t *testing.T, messages <-chan *models.Message) *models.Message {
+	t.Helper()
+	select {
+	case message, ok := <-messages:
+		require.True(t, ok, "channel closed")
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func closed(t *testing.T, messages <-chan *models.Message) {
+	t.Helper()
+	select {
+	case _, ok := <-messages:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed")
+	}
+}
+
+func TestMessageService_Watch(t *testing.T) {
+	t.Run("new messages", func(t *testing.T) {
+		core, mockRepo := setupMessageTestCore(t)
+		ctx, cancel := context.WithCancel(WithSystemContext(context.Background()))
+
+		mockRepo.On("GetLastMessageID", mock.Anything, 1).Return(5, nil)
+		mockRepo.On("ListMessagesByFilter", mock.Anything, afterMessage(5), watchPageSize, 0).
+			Return([]*models.Message{}, 0, nil).Once()
+		mockRepo.On("ListMessagesByFilter", mock.Anything, afterMessage(5), watchPageSize, 0).
+			Return([]*models.Message{{Base: models.Base{ID: 6}, InboxID: 1}}, 1, nil).Once()
+		mockRepo.On("ListMessagesByFilter", mock.Anything, afterMessage(6), watchPageSize, 0).
+			Return([]*models.Message{}, 0, nil).Maybe()
+
+		messages, err := core.MessageService.Watch(ctx, models.MessageFilter{InboxID: 1}, null.Int{})
+		require.NoError(t, err)
+
+		core.Events.Publish(events.Event{Type: events.MessageCreated, InboxID: 2, MessageIDs: []int{7}})
+		core.Events.Publish(events.Event{Type: events.MessageCreated, InboxID: 1, MessageIDs: []int{6}})
+
+		assert.Equal(t, 6, receive(t, messages).ID)
+
+		cancel()
+		closed(t, messages)
+	})
+
+	t.Run("resume after a message", func(t *testing.T) {
+		core, mockRepo := setupMessageTestCore(t)
+		ctx, cancel := context.WithCancel(WithSystemContext(context.Background()))
+
+		mockRepo.On("ListMessagesByFilter", mock.Anything, afterMessage(3), watchPageSize, 0).
+			Return([]*models.Message{
+				{Base: models.Base{ID: 4}, InboxID: 1},
+				{Base: models.Base{ID: 5}, InboxID: 1},
+			}, 2, nil)
+		mockRepo.On("ListMessagesByFilter", mock.Anything, afterMessage(5), watchPageSize, 0).
+			Return([]*models.Message{}, 0, nil).Maybe()
+
+		messages, err := core.MessageService.Watch(ctx, models.MessageFilter{InboxID: 1}, null.IntFrom(3))
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, receive(t, messages).ID)
+		assert.Equal(t, 5, receive(t, messages).ID)
+
+		cancel()
+		closed(t, messages)
+	})
+
+	t.Run("without an inbox", func(t *testing.T) {
+		core, _ := setupMessageTestCore(t)
+
+		_, err := core.MessageService.Watch(WithSystemContext(context.Background()), models.MessageFilter{ProjectID: 1}, null.Int{})
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+}
+
+func TestMessageService_Wait(t *testing.T) {
+	subject := mock.MatchedBy(func(f *models.MessageFilter) bool {
+		return f.InboxID == 1 && f.Subject == "Welcome"
+	})
+
+	t.Run("message already stored", func(t *testing.T) {
+		core, mockRepo := setupMessageTestCore(t)
+
+		mockRepo.On("ListMessagesByFilter", mock.Anything, subject, watchPageSize, 0).
+			Return([]*models.Message{{Base: models.Base{ID: 3}, InboxID: 1, Subject: "Welcome"}}, 1, nil)
+
+		message, err := core.MessageService.Wait(WithSystemContext(context.Background()), models.MessageFilter{InboxID: 1, Subject: "Welcome"}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, message.ID)
+	})
+
+	t.Run("next message to arrive", func(t *testing.T) {
+		core, mockRepo := setupMessageTestCore(t)
+
+		listed := make(chan struct{})
+		mockRepo.On("ListMessagesByFilter", mock.Anything, subject, watchPageSize, 0).
+			Run(func(mock.Arguments) { close(listed) }).
+			Return([]*models.Message{}, 0, nil).Once()
+		mockRepo.On("ListMessagesByFilter", mock.Anything, subject, watchPageSize, 0).
+			Return([]*models.Message{{Base: models.Base{ID: 4}, InboxID: 1, Subject: "Welcome"}}, 1, nil).Once()
+
+		go func() {
+			<-listed
+			core.Events.Publish(events.Event{Type: events.MessageCreated, InboxID: 1, MessageIDs: []int{4}})
+		}()
+
+		message, err := core.MessageService.Wait(WithSystemContext(context.Background()), models.MessageFilter{InboxID: 1, Subject: "Welcome"}, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, 4, message.ID)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		core, mockRepo := setupMessageTestCore(t)
+
+		mockRepo.On("ListMessagesByFilter", mock.Anything, subject, watchPageSize, 0).
+			Return([]*models.Message{}, 0, nil)
+
+		_, err := core.MessageService.Wait(WithSystemContext(context.Background()), models.MessageFilter{InboxID: 1, Subject: "Welcome"}, 20*time.Millisecond)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusRequestTimeout, apiErr.Code)
+	})
+
+	t.Run("timeout too long", func(t *testing.T) {
+		core, _ := setupMessageTestCore(t)
+
+		_, err := core.MessageService.Wait(WithSystemContext(context.Background()), models.MessageFilter{InboxID: 1}, time.Hour)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Code)
+	})
+}
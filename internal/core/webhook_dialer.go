@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errWebhookDestination refuses a webhook connection to an address that is
+// not publicly routable
+var errWebhookDestination = errors.New("webhook destination is not a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// net.IP does not classify as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether webhooks may connect to ip. Loopback, private,
+// link-local (such as the 169.254.169.254 metadata services), multicast and
+// unspecified addresses are refused.
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// publicAddressOnly is the Control of the webhook dialer. It checks the
+// address actually dialed, after name resolution, so a host name resolving
+// to an internal address is refused as well, including when its DNS record
+// changes after the webhook was created.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errWebhookDestination, host)
+	}
+	return nil
+}
+
+// newWebhookClient returns the HTTP client of webhook deliveries, which only
+// connects to public addresses. Proxies are not used since the check would
+// apply to the proxy instead of the webhook.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicAddressOnly,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,290 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+const (
+	// DefaultWebhookTimeout bounds a delivery request when webhooks.timeout
+	// is not configured
+	DefaultWebhookTimeout = 10 * time.Second
+	// DefaultWebhookMaxAttempts is the number of attempts of a delivery when
+	// webhooks.max_attempts is not configured
+	DefaultWebhookMaxAttempts = 8
+	// DefaultWebhookRetryBackoff is the delay before the first retry when
+	// webhooks.retry_backoff is not configured
+	DefaultWebhookRetryBackoff = 30 * time.Second
+	// DefaultWebhookPollInterval is how often due deliveries are looked for
+	// when webhooks.poll_interval is not configured
+	DefaultWebhookPollInterval = 5 * time.Second
+
+	// maxWebhookBackoff caps the delay between two attempts
+	maxWebhookBackoff = 6 * time.Hour
+	// webhookBatchSize is the number of deliveries claimed and sent at once
+	webhookBatchSize = 20
+	// webhookEnqueueTimeout bounds the recording of the deliveries of an
+	// event, which holds up the publisher
+	webhookEnqueueTimeout = 10 * time.Second
+)
+
+// WebhookPayload is the JSON body posted to webhooks. Message is set for
+// message.created events, MessageIDs for message.deleted events.
+type WebhookPayload struct {
+	Event      events.Type     `json:"event"`
+	WebhookID  int             `json:"webhook_id"`
+	InboxID    int             `json:"inbox_id"`
+	FolderID   null.Int        `json:"folder_id"`
+	Message    *models.Message `json:"message,omitempty"`
+	MessageIDs []int           `json:"message_ids,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// WebhookDispatcher turns message events into webhook deliveries and sends
+// them. Deliveries are queued in the database as the events are published,
+// so none is lost while the dispatcher is busy sending, failed attempts are
+// retried with an exponential backoff and survive restarts. It is run
+// alongside the servers and stopped with Shutdown.
+type WebhookDispatcher struct {
+	core        *Core
+	client      *http.Client
+	worker      *queueWorker[*models.WebhookDelivery]
+	unsubscribe func()
+}
+
+func NewWebhookDispatcher(core *Core) *WebhookDispatcher {
+	cfg := core.Config.Webhooks
+	settings := queueSettings{
+		timeout:     cfg.Timeout,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.RetryBackoff,
+		interval:    cfg.PollInterval,
+	}.withDefaults(queueSettings{
+		timeout:     DefaultWebhookTimeout,
+		maxAttempts: DefaultWebhookMaxAttempts,
+		backoff:     DefaultWebhookRetryBackoff,
+		interval:    DefaultWebhookPollInterval,
+	})
+
+	d := &WebhookDispatcher{
+		core:   core,
+		client: newWebhookClient(settings.timeout),
+	}
+	d.worker = newQueueWorker(core.Logger, "webhook deliveries", settings, webhookBatchSize, maxWebhookBackoff,
+		core.Repository.ClaimWebhookDeliveries, d.deliver)
+	d.unsubscribe = core.Events.Subscribe(d.handle)
+	return d
+}
+
+// handle queues the deliveries of a message event in the publishing
+// goroutine and wakes the dispatcher to send them. Recording them only takes
+// database queries, the dispatcher goroutine does the HTTP requests. Events
+// relayed from other instances are skipped, the instance where the event
+// happened queues its deliveries. Copies of sent messages were not received
+// and are skipped as well.
+func (d *WebhookDispatcher) handle(ev events.Event) {
+	if ev.Instance != "" || ev.Sent {
+		return
+	}
+	if ev.Type != events.MessageCreated && ev.Type != events.MessagesDeleted {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(d.worker.ctx, webhookEnqueueTimeout)
+	defer cancel()
+
+	if d.enqueue(ctx, ev) {
+		d.Wake()
+	}
+}
+
+// Wake makes the dispatcher look for due deliveries right away
+func (d *WebhookDispatcher) Wake() {
+	if d == nil {
+		return
+	}
+	d.worker.Wake()
+}
+
+// ListenAndServe sends the due deliveries until Shutdown is called
+func (d *WebhookDispatcher) ListenAndServe() error {
+	return d.worker.ListenAndServe()
+}
+
+// Shutdown stops the dispatcher. Deliveries being sent are abandoned and
+// attempted again once their lease expires.
+func (d *WebhookDispatcher) Shutdown(ctx context.Context) error {
+	d.unsubscribe()
+	return d.worker.Shutdown(ctx)
+}
+
+// enqueue records a delivery of an event for every webhook subscribed to it
+// and reports whether any was recorded
+func (d *WebhookDispatcher) enqueue(ctx context.Context, ev events.Event) bool {
+	webhooks, err := d.core.Repository.ListActiveWebhooksByInbox(ctx, ev.InboxID)
+	if err != nil {
+		d.core.Logger.Error("Failed to list webhooks of inbox %d: %v", ev.InboxID, err)
+		return false
+	}
+
+	var subscribed []*models.Webhook
+	for _, webhook := range webhooks {
+		for _, eventType := range webhook.EventTypes {
+			if eventType == string(ev.Type) {
+				subscribed = append(subscribed, webhook)
+				break
+			}
+		}
+	}
+	if len(subscribed) == 0 {
+		return false
+	}
+
+	payload := WebhookPayload{
+		Event:      ev.Type,
+		InboxID:    ev.InboxID,
+		FolderID:   ev.FolderID,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	queued := false
+	if ev.Type == events.MessagesDeleted {
+		payload.MessageIDs = ev.MessageIDs
+		for _, webhook := range subscribed {
+			queued = d.queueDelivery(ctx, webhook, payload, null.Int{}) || queued
+		}
+		return queued
+	}
+
+	for _, id := range ev.MessageIDs {
+		message, err := d.core.Repository.GetMessage(ctx, id)
+		if err != nil {
+			// The message may already be gone again
+			if !errors.Is(err, storage.ErrNotFound) {
+				d.core.Logger.Error("Failed to fetch message %d for webhooks: %v", id, err)
+			}
+			continue
+		}
+
+		payload.Message = message
+		for _, webhook := range subscribed {
+			queued = d.queueDelivery(ctx, webhook, payload, null.IntFrom(id)) || queued
+		}
+	}
+	return queued
+}
+
+func (d *WebhookDispatcher) queueDelivery(ctx context.Context, webhook *models.Webhook, payload WebhookPayload, messageID null.Int) bool {
+	payload.WebhookID = webhook.ID
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.core.Logger.Error("Failed to encode webhook payload: %v", err)
+		return false
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventType: string(payload.Event),
+		MessageID: messageID,
+		Payload:   body,
+	}
+	if err := d.core.Repository.CreateWebhookDelivery(ctx, delivery); err != nil {
+		d.core.Logger.Error("Failed to queue delivery for webhook %d: %v", webhook.ID, err)
+		return false
+	}
+	return true
+}
+
+// deliver makes one attempt at a delivery and records its outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	webhook, err := d.core.Repository.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		// Deliveries of deleted webhooks are deleted with them, other
+		// failures are retried once the lease expires
+		if !errors.Is(err, storage.ErrNotFound) && ctx.Err() == nil {
+			d.core.Logger.Error("Failed to fetch webhook %d: %v", delivery.WebhookID, err)
+		}
+		return
+	}
+
+	if !webhook.IsActive {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = null.Time{}
+		delivery.LastError = null.StringFrom("webhook is disabled")
+		d.record(ctx, delivery)
+		return
+	}
+
+	status, err := d.send(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus = null.NewInt(status, status != 0)
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = null.Time{}
+		delivery.LastError = null.String{}
+	case delivery.Attempts >= d.worker.maxAttempts:
+		d.core.Logger.Info("Delivery %d to webhook %d failed after %d attempts: %v", delivery.ID, webhook.ID, delivery.Attempts, err)
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = null.Time{}
+		delivery.LastError = null.StringFrom(err.Error())
+	default:
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = null.TimeFrom(time.Now().Add(d.worker.retryDelay(delivery.Attempts)))
+		delivery.LastError = null.StringFrom(err.Error())
+	}
+
+	d.record(ctx, delivery)
+}
+
+func (d *WebhookDispatcher) record(ctx context.Context, delivery *models.WebhookDelivery) {
+	if err := d.core.Repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		d.core.Logger.Error("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send posts the payload of a delivery, signed with the secret of the
+// webhook. Any 2xx response is a success.
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "inbox451-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+
+	// The response body is not recorded, the delivery log would otherwise
+	// show what the URL returns to whoever manages the webhook
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("HTTP %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// WebhookSignatureHeader carries the HMAC-SHA256 of a delivery,
+	// computed over the timestamp header, a dot and the body
+	WebhookSignatureHeader = "X-Inbox451-Signature"
+	// WebhookTimestampHeader carries the Unix time a delivery was sent
+	WebhookTimestampHeader = "X-Inbox451-Timestamp"
+	// WebhookEventHeader and WebhookDeliveryHeader identify the event type
+	// and the delivery
+	WebhookEventHeader    = "X-Inbox451-Event"
+	WebhookDeliveryHeader = "X-Inbox451-Delivery"
+)
+
+type WebhookService struct {
+	core *Core
+}
+
+func NewWebhookService(core *Core) WebhookService {
+	return WebhookService{core: core}
+}
+
+// Create registers a webhook for a project, or for one of its inboxes. A
+// secret is generated when none is given.
+func (s *WebhookService) Create(ctx context.Context, webhook *models.Webhook) error {
+	s.core.Logger.Info("Creating webhook for project %d", webhook.ProjectID)
+
+	if err := s.core.authorizeProject(ctx, webhook.ProjectID, RoleAdmin); err != nil {
+		return err
+	}
+	if err := s.prepare(ctx, webhook); err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			s.core.Logger.Error("Failed to generate webhook secret: %v", err)
+			return err
+		}
+		webhook.Secret = secret
+	}
+
+	if err := s.core.Repository.CreateWebhook(ctx, webhook); err != nil {
+		s.core.Logger.Error("Failed to create webhook: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully created webhook with ID: %d", webhook.ID)
+	return nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int) (*models.Webhook, error) {
+	s.core.Logger.Debug("Fetching webhook with ID: %d", id)
+
+	webhook, err := s.core.Repository.GetWebhook(ctx, id)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch webhook: %v", err)
+		return nil, err
+	}
+
+	if err := s.core.authorizeProject(ctx, webhook.ProjectID, RoleAdmin); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) ListByProject(ctx context.Context, projectID, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing webhooks for project %d with limit: %d and offset: %d", projectID, limit, offset)
+
+	if err := s.core.authorizeProject(ctx, projectID, RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	webhooks, total, err := s.core.Repository.ListWebhooksByProject(ctx, projectID, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list webhooks: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: webhooks,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully retrieved %d webhooks (total: %d)", len(webhooks), total)
+	return response, nil
+}
+
+// Update replaces the settings of a webhook, an empty secret keeps the
+// current one
+func (s *WebhookService) Update(ctx context.Context, webhook *models.Webhook) error {
+	s.core.Logger.Info("Updating webhook with ID: %d", webhook.ID)
+
+	existing, err := s.Get(ctx, webhook.ID)
+	if err != nil {
+		return err
+	}
+	webhook.ProjectID = existing.ProjectID
+
+	if err := s.prepare(ctx, webhook); err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	if err := s.core.Repository.UpdateWebhook(ctx, webhook); err != nil {
+		s.core.Logger.Error("Failed to update webhook: %v", err)
+		return err
+	}
+
+	webhook.CreatedAt = existing.CreatedAt
+	s.core.Logger.Info("Successfully updated webhook with ID: %d", webhook.ID)
+	return nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int) error {
+	s.core.Logger.Info("Deleting webhook with ID: %d", id)
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.core.Repository.DeleteWebhook(ctx, id); err != nil {
+		s.core.Logger.Error("Failed to delete webhook: %v", err)
+		return err
+	}
+
+	s.core.Logger.Info("Successfully deleted webhook with ID: %d", id)
+	return nil
+}
+
+// ListDeliveries returns a page of the deliveries of a webhook, most recent
+// first, optionally only those with the given status
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) (*models.PaginatedResponse, error) {
+	s.core.Logger.Info("Listing deliveries of webhook %d with status %q, limit: %d and offset: %d", webhookID, status, limit, offset)
+
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.core.Repository.ListWebhookDeliveries(ctx, webhookID, status, limit, offset)
+	if err != nil {
+		s.core.Logger.Error("Failed to list webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	response := &models.PaginatedResponse{
+		Data: deliveries,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+
+	s.core.Logger.Info("Successfully retrieved %d webhook deliveries (total: %d)", len(deliveries), total)
+	return response, nil
+}
+
+// ReplayDelivery queues a finished delivery of a webhook again, with its
+// original payload and a fresh set of attempts
+func (s *WebhookService) ReplayDelivery(ctx context.Context, webhookID, deliveryID int) (*models.WebhookDelivery, error) {
+	s.core.Logger.Info("Replaying delivery %d of webhook %d", deliveryID, webhookID)
+
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.core.Repository.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch webhook delivery: %v", err)
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, ErrNotFound
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		return nil, &APIError{
+			Code:    http.StatusConflict,
+			Message: "delivery is still pending",
+		}
+	}
+
+	if err := s.core.Repository.ReplayWebhookDelivery(ctx, delivery); err != nil {
+		s.core.Logger.Error("Failed to replay webhook delivery: %v", err)
+		return nil, err
+	}
+
+	s.core.WebhookDispatcher.Wake()
+	s.core.Logger.Info("Successfully queued delivery %d again", deliveryID)
+	return delivery, nil
+}
+
+// prepare checks the URL and inbox of a webhook and applies the default
+// event types
+func (s *WebhookService) prepare(ctx context.Context, webhook *models.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "webhook URL must be an absolute http or https URL",
+		}
+	}
+	// Host names are checked when the deliveries connect
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return &APIError{
+			Code:    http.StatusBadRequest,
+			Message: "webhook URL must point to a public address",
+		}
+	}
+
+	if webhook.InboxID.Valid {
+		inbox, err := s.core.Repository.GetInbox(ctx, webhook.InboxID.Int)
+		if err != nil {
+			s.core.Logger.Error("Failed to fetch inbox: %v", err)
+			return err
+		}
+		if inbox.ProjectID != webhook.ProjectID {
+			return &APIError{
+				Code:    http.StatusBadRequest,
+				Message: "inbox does not belong to the project",
+			}
+		}
+	}
+
+	if len(webhook.EventTypes) == 0 {
+		webhook.EventTypes = pq.StringArray{string(events.MessageCreated)}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature of a delivery body sent at
+// timestamp, as carried by WebhookSignatureHeader
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
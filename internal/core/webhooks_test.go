@@ -0,0 +1,414 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"inbox451/internal/config"
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupWebhookTestCore(t *testing.T) (*Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	cfg := &config.Config{}
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.RetryBackoff = time.Minute
+
+	core := &Core{
+		Config:     cfg,
+		Logger:     logger,
+		Repository: mockRepo,
+		Events:     events.NewBus(),
+	}
+	core.WebhookService = NewWebhookService(core)
+	core.WebhookDispatcher = NewWebhookDispatcher(core)
+
+	return core, mockRepo
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhook  *models.Webhook
+		mockFn   func(*mocks.Repository)
+		wantCode int
+	}{
+		{
+			name:    "project webhook",
+			webhook: &models.Webhook{ProjectID: 1, URL: "https://ci.example.com/hooks/mail"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *models.Webhook) bool {
+					return len(w.Secret) == 64 && len(w.EventTypes) == 1 && w.EventTypes[0] == "message.created"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "inbox of the project",
+			webhook: &models.Webhook{ProjectID: 1, InboxID: null.IntFrom(2), URL: "http://ci.internal/hook", Secret: "s3cret"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 2).Return(&models.Inbox{ProjectID: 1}, nil)
+				m.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *models.Webhook) bool {
+					return w.Secret == "s3cret"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "inbox of another project",
+			webhook: &models.Webhook{ProjectID: 1, InboxID: null.IntFrom(2), URL: "https://ci.example.com/hooks/mail"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 2).Return(&models.Inbox{ProjectID: 3}, nil)
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "metadata service address",
+			webhook:  &models.Webhook{ProjectID: 1, URL: "http://169.254.169.254/latest/meta-data/"},
+			mockFn:   func(m *mocks.Repository) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "loopback address",
+			webhook:  &models.Webhook{ProjectID: 1, URL: "http://[::1]:8080/hook"},
+			mockFn:   func(m *mocks.Repository) {},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "not an HTTP URL",
+			webhook:  &models.Webhook{ProjectID: 1, URL: "ftp://ci.example.com/hook"},
+			mockFn:   func(m *mocks.Repository) {},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupWebhookTestCore(t)
+			tt.mockFn(mockRepo)
+
+			err := core.WebhookService.Create(WithSystemContext(context.Background()), tt.webhook)
+			if tt.wantCode != 0 {
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.wantCode, apiErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookService_ReplayDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		delivery *models.WebhookDelivery
+		replay   bool
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name:     "failed delivery",
+			delivery: &models.WebhookDelivery{Base: models.Base{ID: 9}, WebhookID: 3, Status: models.WebhookDeliveryFailed},
+			replay:   true,
+		},
+		{
+			name:     "pending delivery",
+			delivery: &models.WebhookDelivery{Base: models.Base{ID: 9}, WebhookID: 3, Status: models.WebhookDeliveryPending},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "delivery of another webhook",
+			delivery: &models.WebhookDelivery{Base: models.Base{ID: 9}, WebhookID: 4, Status: models.WebhookDeliveryFailed},
+			wantErr:  ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, mockRepo := setupWebhookTestCore(t)
+
+			mockRepo.On("GetWebhook", mock.Anything, 3).Return(&models.Webhook{Base: models.Base{ID: 3}, ProjectID: 1}, nil)
+			mockRepo.On("GetWebhookDelivery", mock.Anything, 9).Return(tt.delivery, nil)
+			if tt.replay {
+				mockRepo.On("ReplayWebhookDelivery", mock.Anything, tt.delivery).Return(nil)
+			}
+
+			_, err := core.WebhookService.ReplayDelivery(WithSystemContext(context.Background()), 3, 9)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.wantCode != 0:
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tt.wantCode, apiErr.Code)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher_Enqueue(t *testing.T) {
+	core, mockRepo := setupWebhookTestCore(t)
+
+	mockRepo.On("ListActiveWebhooksByInbox", mock.Anything, 2).Return([]*models.Webhook{
+		{Base: models.Base{ID: 3}, ProjectID: 1, EventTypes: pq.StringArray{"message.created"}},
+		{Base: models.Base{ID: 4}, ProjectID: 1, EventTypes: pq.StringArray{"message.deleted"}},
+	}, nil)
+	mockRepo.On("GetMessage", mock.Anything, 7).Return(&models.Message{Base: models.Base{ID: 7}, InboxID: 2, Subject: "Welcome"}, nil)
+
+	var payload WebhookPayload
+	mockRepo.On("CreateWebhookDelivery", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.WebhookID == 3 && d.EventType == "message.created" && d.MessageID == null.IntFrom(7)
+	})).Run(func(args mock.Arguments) {
+		require.NoError(t, json.Unmarshal(args.Get(1).(*models.WebhookDelivery).Payload, &payload))
+	}).Return(nil).Once()
+
+	queued := core.WebhookDispatcher.enqueue(WithSystemContext(context.Background()), events.Event{
+		Type:       events.MessageCreated,
+		InboxID:    2,
+		MessageIDs: []int{7},
+	})
+
+	assert.True(t, queued)
+	assert.Equal(t, events.MessageCreated, payload.Event)
+	assert.Equal(t, 3, payload.WebhookID)
+	require.NotNil(t, payload.Message)
+	assert.Equal(t, "Welcome", payload.Message.Subject)
+}
+
+func TestWebhookDispatcher_Handle(t *testing.T) {
+	core, mockRepo := setupWebhookTestCore(t)
+
+	mockRepo.On("ListActiveWebhooksByInbox", mock.Anything, 2).Return([]*models.Webhook{
+		{Base: models.Base{ID: 4}, ProjectID: 1, EventTypes: pq.StringArray{"message.deleted"}},
+	}, nil)
+	mockRepo.On("ListActiveWebhooksByInbox", mock.Anything, 3).Return([]*models.Webhook{}, nil)
+	mockRepo.On("CreateWebhookDelivery", mock.Anything, mock.MatchedBy(func(d *models.WebhookDelivery) bool {
+		return d.WebhookID == 4 && d.EventType == "message.deleted"
+	})).Return(nil).Once()
+
+	// The deliveries are recorded by the publisher, the dispatcher is only
+	// woken to send them
+	core.Events.Publish(events.Event{Type: events.MessagesDeleted, InboxID: 2, MessageIDs: []int{7}, Instance: "other"})
+	core.Events.Publish(events.Event{Type: events.MessagesDeleted, InboxID: 3, MessageIDs: []int{8}})
+	// Copies of sent messages do not fire webhooks
+	core.Events.Publish(events.Event{Type: events.MessageCreated, InboxID: 2, MessageIDs: []int{9}, Sent: true})
+	assert.Len(t, core.WebhookDispatcher.worker.wake, 0)
+
+	core.Events.Publish(events.Event{Type: events.MessagesDeleted, InboxID: 2, MessageIDs: []int{7}})
+	assert.Len(t, core.WebhookDispatcher.worker.wake, 1)
+}
+
+func TestWebhookDispatcher_Deliver(t *testing.T) {
+	payload := json.RawMessage(`{"event":"message.created"}`)
+
+	tests := []struct {
+		name         string
+		status       int
+		attempts     int
+		wantStatus   string
+		wantAttempts int
+		wantRetry    bool
+	}{
+		{
+			name:         "accepted",
+			status:       http.StatusNoContent,
+			wantStatus:   models.WebhookDeliverySucceeded,
+			wantAttempts: 1,
+		},
+		{
+			name:         "rejected",
+			status:       http.StatusServiceUnavailable,
+			attempts:     1,
+			wantStatus:   models.WebhookDeliveryPending,
+			wantAttempts: 2,
+			wantRetry:    true,
+		},
+		{
+			name:         "out of attempts",
+			status:       http.StatusInternalServerError,
+			attempts:     2,
+			wantStatus:   models.WebhookDeliveryFailed,
+			wantAttempts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			core, mockRepo := setupWebhookTestCore(t)
+			// The test server listens on loopback, which deliveries refuse
+			core.WebhookDispatcher.client = server.Client()
+			mockRepo.On("GetWebhook", mock.Anything, 3).
+				Return(&models.Webhook{Base: models.Base{ID: 3}, URL: server.URL, Secret: "s3cret", IsActive: true}, nil)
+
+			var recorded *models.WebhookDelivery
+			mockRepo.On("UpdateWebhookDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).
+				Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.WebhookDelivery) }).
+				Return(nil)
+
+			delivery := &models.WebhookDelivery{
+				Base:      models.Base{ID: 9},
+				WebhookID: 3,
+				EventType: "message.created",
+				Payload:   payload,
+				Status:    models.WebhookDeliveryPending,
+				Attempts:  tt.attempts,
+			}
+			before := time.Now()
+			core.WebhookDispatcher.deliver(WithSystemContext(context.Background()), delivery)
+
+			assert.JSONEq(t, string(payload), string(body))
+			assert.Equal(t, "message.created", header.Get(WebhookEventHeader))
+			assert.Equal(t, "9", header.Get(WebhookDeliveryHeader))
+			timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+			require.NoError(t, err)
+			assert.Equal(t, SignWebhookPayload("s3cret", timestamp, body), header.Get(WebhookSignatureHeader))
+
+			require.NotNil(t, recorded)
+			assert.Equal(t, tt.wantStatus, recorded.Status)
+			assert.Equal(t, tt.wantAttempts, recorded.Attempts)
+			assert.Equal(t, null.IntFrom(tt.status), recorded.ResponseStatus)
+			assert.Equal(t, tt.wantRetry, recorded.NextAttemptAt.Valid)
+			if tt.wantRetry {
+				assert.WithinDuration(t, before.Add(2*time.Minute), recorded.NextAttemptAt.Time, 5*time.Second)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcher_DeliverInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("instance credentials"))
+	}))
+	defer server.Close()
+
+	// A host name resolving to loopback is refused when connecting
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+
+	core, mockRepo := setupWebhookTestCore(t)
+	mockRepo.On("GetWebhook", mock.Anything, 3).
+		Return(&models.Webhook{Base: models.Base{ID: 3}, URL: "http://localhost:" + port, IsActive: true}, nil)
+
+	var recorded *models.WebhookDelivery
+	mockRepo.On("UpdateWebhookDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.WebhookDelivery) }).
+		Return(nil)
+
+	core.WebhookDispatcher.deliver(WithSystemContext(context.Background()), &models.WebhookDelivery{
+		Base:      models.Base{ID: 9},
+		WebhookID: 3,
+		EventType: "message.created",
+		Payload:   json.RawMessage(`{}`),
+	})
+
+	assert.False(t, called)
+	require.NotNil(t, recorded)
+	assert.Equal(t, models.WebhookDeliveryPending, recorded.Status)
+	assert.False(t, recorded.ResponseStatus.Valid)
+	assert.Contains(t, recorded.LastError.String, errWebhookDestination.Error())
+}
+
+func TestWebhookDispatcher_DeliverOmitsResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("secret page"))
+	}))
+	defer server.Close()
+
+	core, mockRepo := setupWebhookTestCore(t)
+	core.WebhookDispatcher.client = server.Client()
+	mockRepo.On("GetWebhook", mock.Anything, 3).
+		Return(&models.Webhook{Base: models.Base{ID: 3}, URL: server.URL, IsActive: true}, nil)
+
+	var recorded *models.WebhookDelivery
+	mockRepo.On("UpdateWebhookDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*models.WebhookDelivery) }).
+		Return(nil)
+
+	core.WebhookDispatcher.deliver(WithSystemContext(context.Background()), &models.WebhookDelivery{
+		Base:      models.Base{ID: 9},
+		WebhookID: 3,
+		Payload:   json.RawMessage(`{}`),
+	})
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, null.IntFrom(http.StatusForbidden), recorded.ResponseStatus)
+	assert.Equal(t, null.StringFrom("HTTP 403"), recorded.LastError)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "169.254.169.254"},
+		{ip: "100.64.0.1"},
+		{ip: "0.0.0.0"},
+		{ip: "fe80::1"},
+		{ip: "fd00::1"},
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "224.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, isPublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestWebhookDispatcher_RetryDelay(t *testing.T) {
+	core, _ := setupWebhookTestCore(t)
+	d := core.WebhookDispatcher
+
+	assert.Equal(t, time.Minute, d.worker.retryDelay(1))
+	assert.Equal(t, 2*time.Minute, d.worker.retryDelay(2))
+	assert.Equal(t, 8*time.Minute, d.worker.retryDelay(4))
+	assert.Equal(t, maxWebhookBackoff, d.worker.retryDelay(100))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac s3cret
+	assert.Equal(t,
+		"sha256=97926816e98fbb41ccb1673225ff29a2f35369099990e1b1561651e7bd097ebf",
+		SignWebhookPayload("s3cret", 1700000000, []byte("{}")))
+}
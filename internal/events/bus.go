@@ -0,0 +1,95 @@
+package events
+
+import (
+	"sync"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+// Type identifies what happened to the messages of an event
+type Type string
+
+const (
+	MessageCreated  Type = "message.created"
+	MessagesDeleted Type = "message.deleted"
+)
+
+// Event describes a change to the messages of an inbox. FolderID is the
+// folder of the messages, null for the top level of the inbox.
+type Event struct {
+	Type       Type     `json:"type"`
+	InboxID    int      `json:"inbox_id"`
+	FolderID   null.Int `json:"folder_id"`
+	MessageIDs []int    `json:"message_ids"`
+	// Sent is set on the MessageCreated event of the copy of a message sent
+	// from the inbox, which was not received
+	Sent bool `json:"sent,omitempty"`
+	// Instance is set on events received from another instance through
+	// the PostgreSQL bridge
+	Instance string `json:"instance,omitempty"`
+}
+
+// Handler receives published events. Handlers run synchronously in the
+// publishing goroutine, in subscription order, so they must not block for
+// long; consumers that do slow work should hand events off to their own
+// goroutine.
+type Handler func(Event)
+
+// Bus is an in-process publish/subscribe bus for message events. The zero
+// value is not usable, create one with NewBus. A nil *Bus discards events.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[int]Handler
+	order    []int
+	next     int
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[int]Handler)}
+}
+
+// Subscribe registers a handler and returns a function that removes it
+func (b *Bus) Subscribe(h Handler) func() {
+	if b == nil {
+		return func() {}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.handlers[id] = h
+	b.order = append(b.order, id)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.handlers, id)
+		for i, o := range b.order {
+			if o == id {
+				b.order = append(b.order[:i], b.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Publish delivers the event to every subscribed handler
+func (b *Bus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.order))
+	for _, id := range b.order {
+		handlers = append(handlers, b.handlers[id])
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(ev)
+	}
+}
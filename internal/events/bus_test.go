@@ -0,0 +1,33 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	bus := NewBus()
+
+	var got []string
+	bus.Subscribe(func(ev Event) { got = append(got, "first:"+string(ev.Type)) })
+	unsubscribe := bus.Subscribe(func(ev Event) { got = append(got, "second:"+string(ev.Type)) })
+
+	bus.Publish(Event{Type: MessageCreated, InboxID: 1, MessageIDs: []int{1}})
+	assert.Equal(t, []string{"first:message.created", "second:message.created"}, got)
+
+	got = nil
+	unsubscribe()
+	bus.Publish(Event{Type: MessagesDeleted, InboxID: 1, MessageIDs: []int{1}})
+	assert.Equal(t, []string{"first:message.deleted"}, got)
+}
+
+func TestBus_Nil(t *testing.T) {
+	var bus *Bus
+
+	assert.NotPanics(t, func() {
+		unsubscribe := bus.Subscribe(func(Event) {})
+		unsubscribe()
+		bus.Publish(Event{Type: MessageCreated})
+	})
+}
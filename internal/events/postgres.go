@@ -0,0 +1,142 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"inbox451/internal/logger"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// notifyChannel is the PostgreSQL channel events are exchanged on
+	notifyChannel = "inbox451_events"
+	// notifyQueueSize bounds the local events waiting to be sent, events
+	// published while the queue is full are dropped
+	notifyQueueSize = 1024
+)
+
+// PostgresBridge shares the events of a Bus between instances connected to
+// the same database using LISTEN/NOTIFY. Local events are sent with
+// pg_notify and notifications from other instances are published on the
+// local bus.
+type PostgresBridge struct {
+	db       *sqlx.DB
+	bus      *Bus
+	logger   *logger.Logger
+	listener *pq.Listener
+	instance string
+	// queue holds the local events until they are sent, by a single
+	// goroutine so publishers never wait for the database
+	queue chan Event
+	done  chan struct{}
+}
+
+func NewPostgresBridge(db *sqlx.DB, url string, bus *Bus, logger *logger.Logger) *PostgresBridge {
+	b := &PostgresBridge{
+		db:       db,
+		bus:      bus,
+		logger:   logger,
+		instance: newInstanceID(),
+		queue:    make(chan Event, notifyQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	b.listener = pq.NewListener(url, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Error("Event listener error: %v", err)
+		}
+	})
+
+	bus.Subscribe(b.forward)
+	go b.run()
+	return b
+}
+
+// ListenAndServe listens for notifications until Shutdown is called
+func (b *PostgresBridge) ListenAndServe() error {
+	if err := b.listener.Listen(notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-b.done:
+			return nil
+		case n := <-b.listener.Notify:
+			// A nil notification means the connection was re-established
+			// and notifications may have been lost
+			if n == nil {
+				continue
+			}
+
+			var ev Event
+			if err := json.Unmarshal([]byte(n.Extra), &ev); err != nil {
+				b.logger.Error("Invalid event notification: %v", err)
+				continue
+			}
+			if ev.Instance == b.instance {
+				continue
+			}
+			b.bus.Publish(ev)
+		}
+	}
+}
+
+func (b *PostgresBridge) Shutdown(ctx context.Context) error {
+	close(b.done)
+	return b.listener.Close()
+}
+
+// forward queues locally published events for the other instances
+func (b *PostgresBridge) forward(ev Event) {
+	if ev.Instance != "" {
+		return
+	}
+	ev.Instance = b.instance
+
+	select {
+	case b.queue <- ev:
+	default:
+		b.logger.Error("Event notification queue is full, dropping %s event of inbox %d", ev.Type, ev.InboxID)
+	}
+}
+
+// run sends the queued events, in the order they were published, until
+// Shutdown is called
+func (b *PostgresBridge) run() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case ev := <-b.queue:
+			b.notify(ev)
+		}
+	}
+}
+
+func (b *PostgresBridge) notify(ev Event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		b.logger.Error("Failed to encode event: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload)); err != nil {
+		b.logger.Error("Failed to notify event: %v", err)
+	}
+}
+
+func newInstanceID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
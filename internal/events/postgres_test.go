@@ -0,0 +1,92 @@
+package events
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"inbox451/internal/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notification matches the payload of the notification of a local event
+type notification struct {
+	inboxID  int
+	instance string
+}
+
+func (n notification) Match(v driver.Value) bool {
+	payload, ok := v.(string)
+	if !ok {
+		return false
+	}
+	var ev Event
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		return false
+	}
+	return ev.InboxID == n.inboxID && ev.Instance == n.instance
+}
+
+func setupBridgeTest(t *testing.T, queueSize int) (*PostgresBridge, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	b := &PostgresBridge{
+		db:       sqlx.NewDb(mockDB, "sqlmock"),
+		bus:      NewBus(),
+		logger:   logger.New(io.Discard, logger.DEBUG),
+		instance: "local",
+		queue:    make(chan Event, queueSize),
+		done:     make(chan struct{}),
+	}
+	b.bus.Subscribe(b.forward)
+	return b, mock
+}
+
+func TestPostgresBridge_Forward(t *testing.T) {
+	b, mock := setupBridgeTest(t, notifyQueueSize)
+
+	// The database is slow, publishers do not wait for it
+	mock.ExpectExec("SELECT pg_notify").
+		WithArgs(notifyChannel, notification{inboxID: 1, instance: "local"}).
+		WillDelayFor(100 * time.Millisecond).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_notify").
+		WithArgs(notifyChannel, notification{inboxID: 2, instance: "local"}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	go b.run()
+	defer close(b.done)
+
+	start := time.Now()
+	b.bus.Publish(Event{Type: MessageCreated, InboxID: 1, MessageIDs: []int{10}})
+	// Events from other instances are not sent back
+	b.bus.Publish(Event{Type: MessageCreated, InboxID: 3, MessageIDs: []int{11}, Instance: "remote"})
+	b.bus.Publish(Event{Type: MessagesDeleted, InboxID: 2, MessageIDs: []int{12}})
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	// Notifications are sent in the order the events were published
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPostgresBridge_ForwardQueueFull(t *testing.T) {
+	b, _ := setupBridgeTest(t, 1)
+
+	// Nothing sends the queued events, the second one is dropped
+	b.bus.Publish(Event{Type: MessageCreated, InboxID: 1, MessageIDs: []int{10}})
+	b.bus.Publish(Event{Type: MessageCreated, InboxID: 2, MessageIDs: []int{11}})
+
+	require.Len(t, b.queue, 1)
+	ev := <-b.queue
+	assert.Equal(t, 1, ev.InboxID)
+	assert.Equal(t, "local", ev.Instance)
+}
@@ -0,0 +1,276 @@
+package imap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/backendutil"
+	null "github.com/volatiletech/null/v9"
+)
+
+// ImapMailbox implements go-imap/backend.Mailbox interface on top of an inbox
+// or one of its folders. UIDs are message IDs, which are never reused, and
+// UIDVALIDITY is the inbox or folder ID so a recreated mailbox invalidates
+// client caches.
+type ImapMailbox struct {
+	name   string
+	user   *ImapUser
+	inbox  *models.Inbox
+	folder *models.Folder
+}
+
+// Name returns mailbox name
+func (m *ImapMailbox) Name() string {
+	return m.name
+}
+
+// Info returns mailbox info
+func (m *ImapMailbox) Info() (*imap.MailboxInfo, error) {
+	info := &imap.MailboxInfo{
+		Attributes: []string{},
+		Delimiter:  delimiter,
+		Name:       m.name,
+	}
+	return info, nil
+}
+
+// Status returns mailbox status, counted in the database
+func (m *ImapMailbox) Status(items []imap.StatusItem) (*imap.MailboxStatus, error) {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	counters, err := m.user.core.MessageService.StatusByFolder(ctx, m.inbox.ID, folderID(m.folder))
+	if err != nil {
+		return nil, err
+	}
+
+	status := imap.NewMailboxStatus(m.name, items)
+	status.Flags = systemFlags
+	status.PermanentFlags = append(append([]string{}, systemFlags...), imap.TryCreateFlag)
+	status.UnseenSeqNum = uint32(counters.FirstUnseen)
+
+	for _, item := range items {
+		switch item {
+		case imap.StatusMessages:
+			status.Messages = uint32(counters.Messages)
+		case imap.StatusUidNext:
+			status.UidNext = uint32(counters.UIDNext)
+		case imap.StatusUidValidity:
+			status.UidValidity = m.uidValidity()
+		case imap.StatusRecent:
+			status.Recent = 0
+		case imap.StatusUnseen:
+			status.Unseen = uint32(counters.Unseen)
+		}
+	}
+
+	return status, nil
+}
+
+// ListMessages returns a list of messages
+func (m *ImapMailbox) ListMessages(uid bool, seqSet *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
+	defer close(ch)
+
+	messages, err := m.messages()
+	if err != nil {
+		return err
+	}
+
+	for i, msg := range messages {
+		seqNum := uint32(i + 1)
+		if !seqSet.Contains(messageID(uid, seqNum, msg)) {
+			continue
+		}
+
+		fetched, err := m.fetch(msg, seqNum, items)
+		if err != nil {
+			m.user.core.Logger.Error("Failed to fetch message %d: %v", msg.ID, err)
+			continue
+		}
+
+		ch <- fetched
+	}
+	return nil
+}
+
+// SearchMessages runs the search in the database and maps the matching
+// message IDs back to UIDs or sequence numbers
+func (m *ImapMailbox) SearchMessages(uid bool, criteria *imap.SearchCriteria) ([]uint32, error) {
+	messages, err := m.messages()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	matches, err := m.user.core.MessageService.Search(ctx, m.inbox.ID, folderID(m.folder), searchFromCriteria(criteria, messages))
+	if err != nil {
+		return nil, err
+	}
+
+	seqNums := make(map[int]uint32, len(messages))
+	for i, msg := range messages {
+		seqNums[msg.ID] = uint32(i + 1)
+	}
+
+	ids := []uint32{}
+	for _, id := range matches {
+		seqNum, ok := seqNums[id]
+		if !ok {
+			// Stored after the mailbox view was loaded
+			continue
+		}
+		if uid {
+			ids = append(ids, uint32(id))
+		} else {
+			ids = append(ids, seqNum)
+		}
+	}
+	return ids, nil
+}
+
+func (m *ImapMailbox) Check() error {
+	return nil
+}
+
+// ExpungeMessages removes the given messages when they are flagged as
+// \Deleted (UID EXPUNGE)
+func (m *ImapMailbox) ExpungeMessages(uids []uint32) error {
+	ids := make([]int, len(uids))
+	for i, uid := range uids {
+		ids[i] = int(uid)
+	}
+	return m.expunge(ids)
+}
+
+// CopyMessages copies messages, with their flags, to another mailbox of the
+// user
+func (m *ImapMailbox) CopyMessages(uid bool, seqSet *imap.SeqSet, dest string) error {
+	return m.transfer(uid, seqSet, dest, m.user.core.MessageService.Copy)
+}
+
+// MoveMessages implements the MOVE extension. Moved messages get new UIDs in
+// the destination and are expunged from this mailbox.
+func (m *ImapMailbox) MoveMessages(uid bool, seqSet *imap.SeqSet, dest string) error {
+	return m.transfer(uid, seqSet, dest, m.user.core.MessageService.Move)
+}
+
+func (m *ImapMailbox) CreateMessage(flags []string, date time.Time, body imap.Literal) error {
+	return errors.New("message creation not supported")
+}
+
+// UpdateMessagesFlags applies a STORE command, \Seen is persisted as is_read
+func (m *ImapMailbox) UpdateMessagesFlags(uid bool, seqSet *imap.SeqSet, operation imap.FlagsOp, flags []string) error {
+	messages, err := m.messages()
+	if err != nil {
+		return err
+	}
+
+	for i, msg := range messages {
+		if !seqSet.Contains(messageID(uid, uint32(i+1), msg)) {
+			continue
+		}
+
+		setMessageFlags(msg, backendutil.UpdateFlags(messageFlags(msg), operation, flags))
+		if err := m.updateFlags(msg); err != nil {
+			return err
+		}
+
+		// The server leaves the FETCH responses of STORE to the backend
+		// updater, which also tells other sessions about the change
+		update := &backend.MessageUpdate{
+			Update:  backend.NewUpdate(m.user.Username(), m.name),
+			Message: imap.NewMessage(uint32(i+1), []imap.FetchItem{imap.FetchFlags, imap.FetchUid}),
+		}
+		update.Message.Flags = messageFlags(msg)
+		update.Message.Uid = uint32(msg.ID)
+		m.user.backend.notify(update)
+	}
+	return nil
+}
+
+// Expunge permanently removes every message flagged as \Deleted
+func (m *ImapMailbox) Expunge() error {
+	return m.expunge(nil)
+}
+
+func (m *ImapMailbox) SetSubscribed(subscribed bool) error {
+	return errors.New("subscription changes not supported")
+}
+
+// messages loads the current view of the mailbox, sequence numbers are the
+// positions in this list. Only the IDs, flags, sizes and dates are loaded,
+// sources are fetched message by message when a command needs them.
+func (m *ImapMailbox) messages() ([]*models.Message, error) {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	return m.user.core.MessageService.ListFlagsByFolder(ctx, m.inbox.ID, folderID(m.folder))
+}
+
+// transfer resolves the destination and the selected messages and hands them
+// to a copy or move operation
+func (m *ImapMailbox) transfer(uid bool, seqSet *imap.SeqSet, dest string,
+	op func(context.Context, []*models.Message, int, null.Int) ([]int, error)) error {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	target, err := m.user.getMailbox(ctx, dest)
+	if errors.Is(err, backend.ErrNoSuchMailbox) {
+		return &imap.ErrStatusResp{Resp: &imap.StatusResp{
+			Type: imap.StatusRespNo,
+			Code: imap.CodeTryCreate,
+			Info: err.Error(),
+		}}
+	} else if err != nil {
+		return err
+	}
+
+	messages, err := m.messages()
+	if err != nil {
+		return err
+	}
+
+	var selected []*models.Message
+	for i, msg := range messages {
+		if seqSet.Contains(messageID(uid, uint32(i+1), msg)) {
+			selected = append(selected, msg)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	_, err = op(ctx, selected, target.inbox.ID, folderID(target.folder))
+	m.user.backend.flush()
+	return err
+}
+
+func (m *ImapMailbox) expunge(ids []int) error {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	_, err := m.user.core.MessageService.Expunge(ctx, m.inbox.ID, folderID(m.folder), ids)
+	m.user.backend.flush()
+	return err
+}
+
+func (m *ImapMailbox) uidValidity() uint32 {
+	if m.folder != nil {
+		return uint32(m.folder.ID)
+	}
+	return uint32(m.inbox.ID)
+}
+
+func messageID(uid bool, seqNum uint32, msg *models.Message) uint32 {
+	if uid {
+		return uint32(msg.ID)
+	}
+	return seqNum
+}
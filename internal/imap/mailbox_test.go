@@ -0,0 +1,286 @@
+package imap
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+// setupMailboxTest returns the top level of inbox 1, see setupImapUserTest
+func setupMailboxTest(t *testing.T) (*ImapMailbox, *mocks.Repository, func() []backend.Update) {
+	user, mockRepo, updates := setupImapUserTest(t)
+	return user.newMailbox(imap.InboxName, testInboxes[0], nil), mockRepo, updates
+}
+
+func TestImapMailbox_Status(t *testing.T) {
+	mbox, mockRepo, _ := setupMailboxTest(t)
+	mockRepo.On("GetFolderStatus", mock.Anything, 1, null.Int{}).
+		Return(&models.FolderStatus{Messages: 12, Unseen: 3, FirstUnseen: 7, UIDNext: 58}, nil)
+
+	status, err := mbox.Status([]imap.StatusItem{imap.StatusMessages, imap.StatusUidNext, imap.StatusUidValidity, imap.StatusUnseen})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), status.Messages)
+	assert.Equal(t, uint32(58), status.UidNext)
+	assert.Equal(t, uint32(1), status.UidValidity)
+	assert.Equal(t, uint32(3), status.Unseen)
+	assert.Equal(t, uint32(7), status.UnseenSeqNum)
+}
+
+func TestImapMailbox_ListMessages(t *testing.T) {
+	now := time.Now()
+	view := []*models.Message{
+		{Base: models.Base{ID: 3, CreatedAt: null.TimeFrom(now)}, InboxID: 1, Size: 120},
+		{Base: models.Base{ID: 5, CreatedAt: null.TimeFrom(now)}, InboxID: 1, Size: 240, IsRead: true},
+		{Base: models.Base{ID: 9, CreatedAt: null.TimeFrom(now)}, InboxID: 1, Size: 360},
+	}
+
+	t.Run("flags are served from the mailbox view", func(t *testing.T) {
+		mbox, mockRepo, _ := setupMailboxTest(t)
+		mockRepo.On("ListMessageFlagsByFolder", mock.Anything, 1, null.Int{}).Return(view, nil)
+
+		seqSet, err := imap.ParseSeqSet("2:3")
+		require.NoError(t, err)
+
+		ch := make(chan *imap.Message, 10)
+		require.NoError(t, mbox.ListMessages(false, seqSet, []imap.FetchItem{imap.FetchFlags, imap.FetchUid, imap.FetchRFC822Size}, ch))
+
+		var fetched []*imap.Message
+		for msg := range ch {
+			fetched = append(fetched, msg)
+		}
+		require.Len(t, fetched, 2)
+		assert.Equal(t, uint32(2), fetched[0].SeqNum)
+		assert.Equal(t, uint32(5), fetched[0].Uid)
+		assert.Equal(t, []string{imap.SeenFlag}, fetched[0].Flags)
+		assert.Equal(t, uint32(240), fetched[0].Size)
+		assert.Equal(t, uint32(3), fetched[1].SeqNum)
+		assert.Equal(t, uint32(9), fetched[1].Uid)
+	})
+
+	t.Run("sources are loaded for the selected messages only", func(t *testing.T) {
+		mbox, mockRepo, _ := setupMailboxTest(t)
+		mockRepo.On("ListMessageFlagsByFolder", mock.Anything, 1, null.Int{}).Return(view, nil)
+		mockRepo.On("GetMessage", mock.Anything, 9).Return(view[2], nil)
+		mockRepo.On("GetMessageRaw", mock.Anything, 9).
+			Return([]byte("From: sender@example.net\r\nSubject: Logs\r\n\r\nBody\r\n"), nil)
+
+		seqSet, err := imap.ParseSeqSet("9")
+		require.NoError(t, err)
+
+		ch := make(chan *imap.Message, 10)
+		require.NoError(t, mbox.ListMessages(true, seqSet, []imap.FetchItem{imap.FetchEnvelope}, ch))
+
+		var fetched []*imap.Message
+		for msg := range ch {
+			fetched = append(fetched, msg)
+		}
+		require.Len(t, fetched, 1)
+		require.NotNil(t, fetched[0].Envelope)
+		assert.Equal(t, "Logs", fetched[0].Envelope.Subject)
+	})
+}
+
+func TestImapMailbox_Transfer(t *testing.T) {
+	view := []*models.Message{
+		{Base: models.Base{ID: 3}, InboxID: 1},
+		{Base: models.Base{ID: 5}, InboxID: 1},
+		{Base: models.Base{ID: 9}, InboxID: 1},
+	}
+	archive := &models.Folder{Base: models.Base{ID: 4}, InboxID: 1, Name: "Archive"}
+
+	tests := []struct {
+		name    string
+		move    bool
+		uid     bool
+		set     string
+		dest    string
+		mockFn  func(*mocks.Repository)
+		wantIDs []int
+	}{
+		{
+			name: "copy by sequence numbers",
+			set:  "2:3",
+			dest: "INBOX/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive, nil)
+				m.On("CopyMessages", mock.Anything, []int{5, 9}, 1, null.IntFrom(4)).Return([]int{20, 21}, nil)
+			},
+			wantIDs: []int{5, 9},
+		},
+		{
+			name: "copy by UIDs",
+			uid:  true,
+			set:  "3,6:9",
+			dest: "dev@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CopyMessages", mock.Anything, []int{3, 9}, 2, null.Int{}).Return([]int{20, 21}, nil)
+			},
+			wantIDs: []int{3, 9},
+		},
+		{
+			name: "move by sequence numbers",
+			move: true,
+			set:  "1",
+			dest: "INBOX/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive, nil)
+				m.On("MoveMessages", mock.Anything, []int{3}, 1, null.IntFrom(4)).Return([]int{20}, nil)
+			},
+			wantIDs: []int{3},
+		},
+		{
+			name: "move by UIDs",
+			move: true,
+			uid:  true,
+			set:  "5:*",
+			dest: "dev@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("MoveMessages", mock.Anything, []int{5, 9}, 2, null.Int{}).Return([]int{20, 21}, nil)
+			},
+			wantIDs: []int{5, 9},
+		},
+		{
+			name:   "no message selected",
+			uid:    true,
+			set:    "10:12",
+			dest:   "dev@example.com",
+			mockFn: func(*mocks.Repository) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mbox, mockRepo, _ := setupMailboxTest(t)
+			mockRepo.On("ListMessageFlagsByFolder", mock.Anything, 1, null.Int{}).Return(view, nil)
+			// Updates for the other session
+			mockRepo.On("GetFolder", mock.Anything, 4).Return(archive, nil).Maybe()
+			mockRepo.On("CountMessagesByFolder", mock.Anything, mock.Anything, mock.Anything).Return(2, nil).Maybe()
+			mockRepo.On("CountMessagesBelow", mock.Anything, 1, null.Int{}, tt.wantIDs).Return(make([]int, len(tt.wantIDs)), nil).Maybe()
+			tt.mockFn(mockRepo)
+
+			seqSet, err := imap.ParseSeqSet(tt.set)
+			require.NoError(t, err)
+
+			if tt.move {
+				err = mbox.MoveMessages(tt.uid, seqSet, tt.dest)
+			} else {
+				err = mbox.CopyMessages(tt.uid, seqSet, tt.dest)
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown destination", func(t *testing.T) {
+		mbox, mockRepo, _ := setupMailboxTest(t)
+		mockRepo.On("GetFolderByName", mock.Anything, 1, "Missing").Return(nil, storage.ErrNotFound)
+
+		seqSet, err := imap.ParseSeqSet("1:*")
+		require.NoError(t, err)
+
+		for _, transfer := range []func(bool, *imap.SeqSet, string) error{mbox.CopyMessages, mbox.MoveMessages} {
+			err := transfer(false, seqSet, "INBOX/Missing")
+
+			var statusErr *imap.ErrStatusResp
+			require.ErrorAs(t, err, &statusErr)
+			assert.Equal(t, imap.StatusRespNo, statusErr.Resp.Type)
+			assert.Equal(t, imap.CodeTryCreate, statusErr.Resp.Code)
+		}
+	})
+}
+
+func TestImapMailbox_UpdateMessagesFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		uid       bool
+		set       string
+		operation imap.FlagsOp
+		flags     []string
+		want      map[int][]string
+	}{
+		{
+			name:      "add seen",
+			set:       "1:2",
+			operation: imap.AddFlags,
+			flags:     []string{imap.SeenFlag},
+			want: map[int][]string{
+				3: {imap.SeenFlag},
+				5: {imap.SeenFlag, imap.FlaggedFlag},
+			},
+		},
+		{
+			name:      "remove seen",
+			uid:       true,
+			set:       "5",
+			operation: imap.RemoveFlags,
+			flags:     []string{imap.SeenFlag},
+			want: map[int][]string{
+				5: {imap.FlaggedFlag},
+			},
+		},
+		{
+			name:      "replace with seen",
+			set:       "2:3",
+			operation: imap.SetFlags,
+			flags:     []string{imap.SeenFlag},
+			want: map[int][]string{
+				5: {imap.SeenFlag},
+				9: {imap.SeenFlag},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mbox, mockRepo, updates := setupMailboxTest(t)
+			view := []*models.Message{
+				{Base: models.Base{ID: 3}, InboxID: 1},
+				{Base: models.Base{ID: 5}, InboxID: 1, IsRead: true, IsFlagged: true},
+				{Base: models.Base{ID: 9}, InboxID: 1, Keywords: []string{"$Important"}},
+			}
+			mockRepo.On("ListMessageFlagsByFolder", mock.Anything, 1, null.Int{}).Return(view, nil)
+
+			stored := map[int][]string{}
+			mockRepo.On("UpdateMessageFlags", mock.Anything, mock.AnythingOfType("*models.Message")).
+				Run(func(args mock.Arguments) {
+					msg := args.Get(1).(*models.Message)
+					stored[msg.ID] = messageFlags(msg)
+				}).
+				Return(nil)
+
+			seqSet, err := imap.ParseSeqSet(tt.set)
+			require.NoError(t, err)
+			require.NoError(t, mbox.UpdateMessagesFlags(tt.uid, seqSet, tt.operation, tt.flags))
+
+			// \Seen is persisted as is_read
+			assert.Equal(t, tt.want, stored)
+			for _, msg := range view {
+				if flags, ok := tt.want[msg.ID]; ok {
+					assert.Equal(t, slices.Contains(flags, imap.SeenFlag), msg.IsRead)
+				}
+			}
+
+			// Every session of the mailbox, this one included, gets the new
+			// flags as a FETCH response
+			received := map[int][]string{}
+			for _, update := range updates() {
+				msgUpdate, ok := update.(*backend.MessageUpdate)
+				require.True(t, ok)
+				assert.Equal(t, "admin", msgUpdate.Username())
+				assert.Equal(t, imap.InboxName, msgUpdate.Mailbox())
+				received[int(msgUpdate.Message.Uid)] = msgUpdate.Message.Flags
+			}
+			assert.Equal(t, tt.want, received)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package imap
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend/backendutil"
+	"github.com/emersion/go-message/textproto"
+	"github.com/lib/pq"
+)
+
+// systemFlags are the IMAP system flags persisted on messages
+var systemFlags = []string{imap.SeenFlag, imap.AnsweredFlag, imap.FlaggedFlag, imap.DeletedFlag}
+
+// fetch builds the FETCH response for a message. The raw source is only
+// loaded when an item needs the headers or the body.
+func (m *ImapMailbox) fetch(msg *models.Message, seqNum uint32, items []imap.FetchItem) (*imap.Message, error) {
+	var raw []byte
+	if needsRaw(msg, items) {
+		var err error
+		if raw, err = m.raw(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	fetched := imap.NewMessage(seqNum, items)
+	seen := false
+	for _, item := range items {
+		switch item {
+		case imap.FetchEnvelope:
+			hdr, _, err := headerAndBody(raw)
+			if err != nil {
+				return nil, err
+			}
+			fetched.Envelope, _ = backendutil.FetchEnvelope(hdr)
+		case imap.FetchBody, imap.FetchBodyStructure:
+			hdr, body, err := headerAndBody(raw)
+			if err != nil {
+				return nil, err
+			}
+			fetched.BodyStructure, _ = backendutil.FetchBodyStructure(hdr, body, item == imap.FetchBodyStructure)
+		case imap.FetchFlags:
+			fetched.Flags = messageFlags(msg)
+		case imap.FetchInternalDate:
+			fetched.InternalDate = msg.CreatedAt.Time
+		case imap.FetchRFC822Size:
+			fetched.Size = uint32(msg.Size)
+			if raw != nil {
+				fetched.Size = uint32(len(raw))
+			}
+		case imap.FetchUid:
+			fetched.Uid = uint32(msg.ID)
+		default:
+			section, err := imap.ParseBodySectionName(item)
+			if err != nil {
+				break
+			}
+			seen = seen || !section.Peek
+
+			hdr, body, err := headerAndBody(raw)
+			if err != nil {
+				return nil, err
+			}
+
+			l, _ := backendutil.FetchBodySection(hdr, body, section)
+			fetched.Body[section] = l
+		}
+	}
+
+	// Fetching a body section without PEEK implicitly sets \Seen, the new
+	// flags are sent back as part of the same FETCH response
+	if seen && !msg.IsRead {
+		msg.IsRead = true
+		if err := m.updateFlags(msg); err != nil {
+			return nil, err
+		}
+		fetched.Items[imap.FetchFlags] = nil
+		fetched.Flags = messageFlags(msg)
+	}
+
+	return fetched, nil
+}
+
+func (m *ImapMailbox) raw(msg *models.Message) ([]byte, error) {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	return m.user.core.MessageService.GetRaw(ctx, msg.ID)
+}
+
+func (m *ImapMailbox) updateFlags(msg *models.Message) error {
+	ctx, cancel := m.user.context()
+	defer cancel()
+
+	return m.user.core.MessageService.UpdateFlags(ctx, msg)
+}
+
+func messageFlags(msg *models.Message) []string {
+	flags := []string{}
+	if msg.IsRead {
+		flags = append(flags, imap.SeenFlag)
+	}
+	if msg.IsAnswered {
+		flags = append(flags, imap.AnsweredFlag)
+	}
+	if msg.IsFlagged {
+		flags = append(flags, imap.FlaggedFlag)
+	}
+	if msg.IsDeleted {
+		flags = append(flags, imap.DeletedFlag)
+	}
+	return append(flags, msg.Keywords...)
+}
+
+// setMessageFlags replaces the flags of a message. \Recent and \Draft are not
+// persisted, any other flag without a backslash is stored as a keyword.
+func setMessageFlags(msg *models.Message, flags []string) {
+	msg.IsRead, msg.IsAnswered, msg.IsFlagged, msg.IsDeleted = false, false, false, false
+	msg.Keywords = pq.StringArray{}
+
+	for _, flag := range flags {
+		switch imap.CanonicalFlag(flag) {
+		case imap.SeenFlag:
+			msg.IsRead = true
+		case imap.AnsweredFlag:
+			msg.IsAnswered = true
+		case imap.FlaggedFlag:
+			msg.IsFlagged = true
+		case imap.DeletedFlag:
+			msg.IsDeleted = true
+		default:
+			if !strings.HasPrefix(flag, "\\") {
+				msg.Keywords = append(msg.Keywords, flag)
+			}
+		}
+	}
+}
+
+func needsRaw(msg *models.Message, items []imap.FetchItem) bool {
+	for _, item := range items {
+		switch item {
+		case imap.FetchFlags, imap.FetchInternalDate, imap.FetchUid:
+		case imap.FetchRFC822Size:
+			// Messages stored before sizes were recorded report 0
+			if msg.Size == 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func headerAndBody(raw []byte) (textproto.Header, *bufio.Reader, error) {
+	body := bufio.NewReader(bytes.NewReader(raw))
+	hdr, err := textproto.ReadHeader(body)
+	return hdr, body, err
+}
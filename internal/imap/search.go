@@ -0,0 +1,164 @@
+package imap
+
+import (
+	"net/textproto"
+
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+)
+
+// searchFromCriteria translates IMAP SEARCH criteria into a models.MessageSearch
+// that the repository compiles into SQL. Sequence numbers only exist in the
+// mailbox view, so they are resolved to message IDs using messages.
+func searchFromCriteria(c *imap.SearchCriteria, messages []*models.Message) *models.MessageSearch {
+	s := &models.MessageSearch{
+		Body:   c.Body,
+		Text:   c.Text,
+		Since:  c.Since,
+		Before: c.Before,
+	}
+
+	// The Date header is not stored on its own, the received date is the
+	// closest approximation
+	if !c.SentSince.IsZero() && (s.Since.IsZero() || c.SentSince.After(s.Since)) {
+		s.Since = c.SentSince
+	}
+	if !c.SentBefore.IsZero() && (s.Before.IsZero() || c.SentBefore.Before(s.Before)) {
+		s.Before = c.SentBefore
+	}
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			switch textproto.CanonicalMIMEHeaderKey(key) {
+			case "From":
+				s.From = append(s.From, value)
+			case "To":
+				s.To = append(s.To, value)
+			case "Subject":
+				s.Subject = append(s.Subject, value)
+			default:
+				// Other headers are only matched against the raw source
+				if value == "" {
+					value = key + ":"
+				}
+				s.Source = append(s.Source, value)
+			}
+		}
+	}
+
+	s.Larger = int(c.Larger)
+	s.Smaller = int(c.Smaller)
+
+	applyFlags(s, c.WithFlags, true)
+	applyFlags(s, c.WithoutFlags, false)
+
+	switch {
+	case c.SeqNum != nil:
+		var uids []models.IDRange
+		if c.Uid != nil {
+			uids = uidRanges(c.Uid, messages)
+		}
+
+		// Consecutive sequence numbers are merged into a single range so
+		// large sets stay within the parameter limit of a query. IDs in
+		// between are not in the mailbox view and never reported.
+		ids := []models.IDRange{}
+		extend := false
+		for i, msg := range messages {
+			match := c.SeqNum.Contains(uint32(i+1)) && (c.Uid == nil || inRanges(uids, msg.ID))
+			switch {
+			case match && extend:
+				ids[len(ids)-1].Stop = msg.ID
+			case match:
+				ids = append(ids, models.IDRange{Start: msg.ID, Stop: msg.ID})
+			}
+			extend = match
+		}
+		restrictIDs(s, ids)
+	case c.Uid != nil:
+		restrictIDs(s, uidRanges(c.Uid, messages))
+	}
+
+	for _, not := range c.Not {
+		s.Not = append(s.Not, searchFromCriteria(not, messages))
+	}
+	for _, or := range c.Or {
+		s.Or = append(s.Or, [2]*models.MessageSearch{
+			searchFromCriteria(or[0], messages),
+			searchFromCriteria(or[1], messages),
+		})
+	}
+
+	return s
+}
+
+func applyFlags(s *models.MessageSearch, flags []string, set bool) {
+	value := set
+	for _, flag := range flags {
+		switch imap.CanonicalFlag(flag) {
+		case imap.SeenFlag:
+			s.IsRead = &value
+		case imap.AnsweredFlag:
+			s.IsAnswered = &value
+		case imap.FlaggedFlag:
+			s.IsFlagged = &value
+		case imap.DeletedFlag:
+			s.IsDeleted = &value
+		case imap.RecentFlag, imap.DraftFlag:
+			// Never set on stored messages
+			if set {
+				s.MatchNone = true
+			}
+		default:
+			if set {
+				s.Keywords = append(s.Keywords, flag)
+			} else {
+				s.NotKeywords = append(s.NotKeywords, flag)
+			}
+		}
+	}
+}
+
+// restrictIDs adds an ID restriction, an empty restriction matches nothing
+func restrictIDs(s *models.MessageSearch, ids []models.IDRange) {
+	if len(ids) == 0 {
+		s.MatchNone = true
+		return
+	}
+	s.IDs = ids
+}
+
+func inRanges(ranges []models.IDRange, id int) bool {
+	for _, r := range ranges {
+		if r.Start <= id && id <= r.Stop {
+			return true
+		}
+	}
+	return false
+}
+
+// uidRanges converts a UID set, where "*" stands for the highest UID of the
+// mailbox, into ID ranges
+func uidRanges(set *imap.SeqSet, messages []*models.Message) []models.IDRange {
+	if len(messages) == 0 {
+		return nil
+	}
+	last := messages[len(messages)-1].ID
+
+	ranges := make([]models.IDRange, 0, len(set.Set))
+	for _, seq := range set.Set {
+		start, stop := int(seq.Start), int(seq.Stop)
+		if start == 0 {
+			start = last
+		}
+		if stop == 0 {
+			stop = last
+		}
+		if start > stop {
+			start, stop = stop, start
+		}
+		ranges = append(ranges, models.IDRange{Start: start, Stop: stop})
+	}
+	return ranges
+}
@@ -0,0 +1,66 @@
+package imap
+
+import (
+	"testing"
+
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFromCriteria_SeqNums(t *testing.T) {
+	messages := []*models.Message{
+		{Base: models.Base{ID: 3}},
+		{Base: models.Base{ID: 5}},
+		{Base: models.Base{ID: 6}},
+		{Base: models.Base{ID: 9}},
+		{Base: models.Base{ID: 12}},
+	}
+
+	tests := []struct {
+		name   string
+		seqSet string
+		uidSet string
+		want   []models.IDRange
+	}{
+		{
+			name:   "consecutive sequence numbers share a range",
+			seqSet: "1:3,5",
+			want:   []models.IDRange{{Start: 3, Stop: 6}, {Start: 12, Stop: 12}},
+		},
+		{
+			name:   "whole mailbox",
+			seqSet: "1:*",
+			want:   []models.IDRange{{Start: 3, Stop: 12}},
+		},
+		{
+			name:   "UIDs split a range",
+			seqSet: "1:5",
+			uidSet: "1:5,9:*",
+			want:   []models.IDRange{{Start: 3, Stop: 5}, {Start: 9, Stop: 12}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &imap.SearchCriteria{}
+			c.SeqNum, _ = imap.ParseSeqSet(tt.seqSet)
+			if tt.uidSet != "" {
+				c.Uid, _ = imap.ParseSeqSet(tt.uidSet)
+			}
+
+			s := searchFromCriteria(c, messages)
+			assert.Equal(t, tt.want, s.IDs)
+			assert.False(t, s.MatchNone)
+		})
+	}
+}
+
+func TestSearchFromCriteria_NoMatch(t *testing.T) {
+	c := &imap.SearchCriteria{}
+	c.SeqNum, _ = imap.ParseSeqSet("4:9")
+
+	s := searchFromCriteria(c, []*models.Message{{Base: models.Base{ID: 3}}})
+	assert.True(t, s.MatchNone)
+}
@@ -2,181 +2,137 @@ package imap
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"inbox451/internal/core"
+	"inbox451/internal/logger"
+	"inbox451/internal/tlsconfig"
 
 	"github.com/emersion/go-imap"
 	"github.com/emersion/go-imap/backend"
 	"github.com/emersion/go-imap/server"
 )
 
+// requestTimeout bounds every repository call made on behalf of an IMAP
+// command, go-imap does not carry a context of its own
+const requestTimeout = 30 * time.Second
+
 // ImapBackend implements go-imap/backend interface
 type ImapBackend struct {
-	core *core.Core
-}
-
-// Login handles user authentication
-func (be *ImapBackend) Login(connInfo *imap.ConnInfo, username string, password string) (backend.User, error) {
-	be.core.Logger.Info("Login attempt starting")
-	be.core.Logger.Info("Login username=[%s] password=[%s]", username, password)
-	if username == "test@example.com" && password == "password" {
-		return &ImapUser{username: username, core: be.core}, nil
+	core    *core.Core
+	updates chan backend.Update
+	// events holds the message events until run turns them into updates
+	events      chan queuedEvent
+	stop        chan struct{}
+	unsubscribe func()
+
+	mu       sync.Mutex
+	sessions map[*ImapUser]struct{}
+}
+
+func newBackend(core *core.Core) *ImapBackend {
+	be := &ImapBackend{
+		core:     core,
+		updates:  make(chan backend.Update),
+		events:   make(chan queuedEvent, eventQueueSize),
+		stop:     make(chan struct{}),
+		sessions: make(map[*ImapUser]struct{}),
 	}
-	return nil, errors.New("invalid credentials")
-}
-
-// ImapUser implements go-imap/backend.User interface
-type ImapUser struct {
-	core     *core.Core
-	username string
-}
-
-// Username returns user's email
-func (u *ImapUser) Username() string {
-	return u.username
-}
-
-// ListMailboxes returns a list of mailboxes for the user
-func (u *ImapUser) ListMailboxes(subscribed bool) ([]backend.Mailbox, error) {
-	// Return list of available mailboxes (folders)
-	return []backend.Mailbox{
-		&ImapMailbox{name: "INBOX", user: u},
-	}, nil
-}
-
-// GetMailbox returns a specific mailbox
-func (u *ImapUser) GetMailbox(name string) (backend.Mailbox, error) {
-	if name == "INBOX" {
-		return &ImapMailbox{name: name, user: u}, nil
-	}
-	return nil, errors.New("mailbox not found")
-}
-
-func (u *ImapUser) CreateMailbox(name string) error {
-	return errors.New("mailbox creation not supported")
-}
-
-func (u *ImapUser) DeleteMailbox(name string) error {
-	return errors.New("mailbox deletion not supported")
-}
-
-func (u *ImapUser) RenameMailbox(existingName, newName string) error {
-	return errors.New("mailbox renaming not supported")
-}
-
-// ImapMailbox implements go-imap/backend.Mailbox interface
-type ImapMailbox struct {
-	name     string
-	user     *ImapUser
-	messages []*imap.Message
-}
-
-// Name returns mailbox name
-func (m *ImapMailbox) Name() string {
-	return m.name
-}
-
-// Info returns mailbox info
-func (m *ImapMailbox) Info() (*imap.MailboxInfo, error) {
-	info := &imap.MailboxInfo{
-		Attributes: []string{},
-		Delimiter:  "/",
-		Name:       m.name,
-	}
-	return info, nil
-}
-
-func (u *ImapUser) Logout() error {
-	return nil
+	be.unsubscribe = core.Events.Subscribe(be.enqueueEvent)
+	go be.run()
+	return be
 }
 
-// Status returns mailbox status
-func (m *ImapMailbox) Status(items []imap.StatusItem) (*imap.MailboxStatus, error) {
-	status := imap.NewMailboxStatus(m.name, items)
-	status.Messages = uint32(len(m.messages))
-	status.Unseen = 0
-	return status, nil
-}
-
-// ListMessages returns a list of messages
-func (m *ImapMailbox) ListMessages(uid bool, seqSet *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
-	defer close(ch)
-	for _, msg := range m.messages {
-		if seqSet.Contains(msg.SeqNum) {
-			ch <- msg
+// Login authenticates the user against the users table, accepting either the
+// account password or one of the user's API tokens
+func (be *ImapBackend) Login(connInfo *imap.ConnInfo, username string, password string) (backend.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	user, err := be.core.UserService.Authenticate(ctx, username, password)
+	if err != nil {
+		if errors.Is(err, core.ErrUnauthorized) {
+			be.core.Logger.Info("IMAP login failed for %s", username)
+			return nil, backend.ErrInvalidCredentials
 		}
+		return nil, err
 	}
-	return nil
-}
-
-// SearchMessages searches for messages matching the given criteria
-func (m *ImapMailbox) SearchMessages(uid bool, criteria *imap.SearchCriteria) ([]uint32, error) {
-	// Implement search logic
-	return []uint32{}, nil
-}
-
-func (m *ImapMailbox) Check() error {
-	return nil
-}
 
-func (m *ImapMailbox) ExpungeMessages(uids []uint32) error {
-	return errors.New("expunge not supported")
-}
-
-func (m *ImapMailbox) CopyMessages(uid bool, seqSet *imap.SeqSet, dest string) error {
-	return errors.New("copy not supported")
-}
-
-func (m *ImapMailbox) MoveMessages(uid bool, seqSet *imap.SeqSet, dest string) error {
-	return errors.New("move not supported")
-}
-
-func (m *ImapMailbox) CreateMessage(flags []string, date time.Time, body imap.Literal) error {
-	return errors.New("message creation not supported")
-}
-
-func (m *ImapMailbox) UpdateMessagesFlags(uid bool, seqSet *imap.SeqSet, operation imap.FlagsOp, flags []string) error {
-	return errors.New("flag updates not supported")
-}
-
-func (m *ImapMailbox) Expunge() error {
-	return errors.New("expunge not supported")
-}
-
-func (m *ImapMailbox) SetSubscribed(subscribed bool) error {
-	return errors.New("subscription changes not supported")
+	be.core.Logger.Info("IMAP login succeeded for %s", username)
+	u := &ImapUser{core: be.core, backend: be, user: user}
+	be.addSession(u)
+	return u, nil
 }
 
 type ImapServer struct {
-	core *core.Core
-	imap *server.Server
+	core    *core.Core
+	imap    *server.Server
+	backend *ImapBackend
 }
 
+// ListenAndServe serves the plain port and, when configured, the implicit
+// TLS port until the server is shut down
 func (s *ImapServer) ListenAndServe() error {
-	return s.imap.ListenAndServe()
+	implicitPort := s.core.Config.Server.IMAP.TLS.ImplicitPort
+	if s.imap.TLSConfig == nil || implicitPort == "" {
+		return s.imap.ListenAndServe()
+	}
+
+	errc := make(chan error, 2)
+	go func() { errc <- s.imap.ListenAndServe() }()
+	go func() {
+		l, err := tls.Listen("tcp", implicitPort, s.imap.TLSConfig)
+		if err != nil {
+			errc <- err
+			return
+		}
+		s.core.Logger.Info("IMAP implicit TLS listening on %s", implicitPort)
+		errc <- s.imap.Serve(l)
+	}()
+	return <-errc
 }
 
 // Add Shutdown method to ImapServer struct
 func (s *ImapServer) Shutdown(ctx context.Context) error {
-	return s.imap.Close()
+	err := s.imap.Close()
+	s.backend.close()
+	return err
 }
 
-func NewServer(core *core.Core) *ImapServer {
+func NewServer(core *core.Core) (*ImapServer, error) {
 	core.Logger.Info("IMAP Server initializing")
+	cfg := core.Config.Server.IMAP
+
+	tlsConfig, err := tlsconfig.Load(cfg.TLS, cfg.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("IMAP: %w", err)
+	}
 
-	be := &ImapBackend{core: core}
+	be := newBackend(core)
 	s := server.New(be)
-	s.Addr = ":1143"
+	s.Addr = cfg.Port
+	if s.Addr == "" {
+		s.Addr = ":1143"
+	}
 
-	s.Debug = core.Logger.Writer()
+	// The protocol trace includes the credentials of LOGIN and AUTHENTICATE,
+	// it is only written at the debug level
+	if core.Config.Logging.Level == logger.DEBUG {
+		s.Debug = core.Logger.Writer()
+	}
 
-	// Allow unencrypted plain text authentication
-	s.AllowInsecureAuth = true
+	// STARTTLS is offered whenever a TLS configuration is set, plain text
+	// authentication over unencrypted connections unless refused
+	s.TLSConfig = tlsConfig
+	s.AllowInsecureAuth = !cfg.TLS.AuthRequiresTLS
 
 	return &ImapServer{
-		core: core,
-		imap: s,
-	}
+		core:    core,
+		imap:    s,
+		backend: be,
+	}, nil
 }
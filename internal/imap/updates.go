@@ -0,0 +1,264 @@
+package imap
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"inbox451/internal/core"
+	"inbox451/internal/events"
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+)
+
+const (
+	// updateTimeout bounds how long the backend waits for an update to be
+	// handed to every connected client
+	updateTimeout = 5 * time.Second
+	// eventQueueSize bounds the events waiting to be turned into updates,
+	// events published while the queue is full are dropped
+	eventQueueSize = 1024
+)
+
+// queuedEvent is an event waiting to be turned into updates. Markers queued
+// by flush carry done instead, it is closed once they are reached.
+type queuedEvent struct {
+	ev   events.Event
+	done chan struct{}
+}
+
+// Updates implements backend.BackendUpdater, which enables unilateral
+// EXISTS/EXPUNGE/FETCH responses and IDLE notifications
+func (be *ImapBackend) Updates() <-chan backend.Update {
+	return be.updates
+}
+
+// enqueueEvent is subscribed to the event bus. Events are only queued, so
+// publishers never wait for the database or for IMAP clients.
+func (be *ImapBackend) enqueueEvent(ev events.Event) {
+	select {
+	case be.events <- queuedEvent{ev: ev}:
+	default:
+		be.core.Logger.Error("IMAP update queue is full, dropping %s event of inbox %d", ev.Type, ev.InboxID)
+	}
+}
+
+// run turns the queued events into updates, in the order they were
+// published, until the backend is closed
+func (be *ImapBackend) run() {
+	for {
+		select {
+		case <-be.stop:
+			return
+		case q := <-be.events:
+			// Deletions still waiting in the queue have already happened
+			// in the database, they are taken into account when counting
+			// the sequence numbers of the expunged messages
+			batch := []queuedEvent{q}
+			for more := true; more; {
+				select {
+				case q := <-be.events:
+					batch = append(batch, q)
+				default:
+					more = false
+				}
+			}
+
+			for i, q := range batch {
+				if q.done != nil {
+					close(q.done)
+					continue
+				}
+				be.handleEvent(q.ev, batch[i+1:])
+			}
+		}
+	}
+}
+
+// flush waits until the events published so far have been turned into
+// updates, so that the updates caused by an IMAP command (EXPUNGE, MOVE)
+// reach the client before the command completes
+func (be *ImapBackend) flush() {
+	done := make(chan struct{})
+	timeout := time.NewTimer(updateTimeout)
+	defer timeout.Stop()
+
+	select {
+	case be.events <- queuedEvent{done: done}:
+	case <-timeout.C:
+		be.core.Logger.Error("Timed out queueing IMAP update flush")
+		return
+	}
+
+	select {
+	case <-done:
+	case <-timeout.C:
+		be.core.Logger.Error("Timed out waiting for IMAP updates")
+	}
+}
+
+// close stops turning events into updates
+func (be *ImapBackend) close() {
+	be.unsubscribe()
+	close(be.stop)
+}
+
+// handleEvent turns a message event into updates for every logged in user
+// who can reach the inbox. later holds the events queued after it.
+func (be *ImapBackend) handleEvent(ev events.Event, later []queuedEvent) {
+	users := be.activeUsers()
+	if len(users) == 0 {
+		return
+	}
+
+	// The mailbox is looked at once for every user, who only get updates for
+	// the inboxes they can reach
+	ctx, cancel := context.WithTimeout(core.WithSystemContext(context.Background()), requestTimeout)
+	defer cancel()
+
+	var folder *models.Folder
+	if ev.FolderID.Valid {
+		f, err := be.core.FolderService.Get(ctx, ev.FolderID.Int)
+		if err != nil {
+			// The folder is gone together with its messages
+			return
+		}
+		folder = f
+	}
+
+	var exists int
+	var seqNums []uint32
+	switch ev.Type {
+	case events.MessageCreated:
+		total, err := be.core.MessageService.CountByFolder(ctx, ev.InboxID, ev.FolderID)
+		if err != nil {
+			be.core.Logger.Error("Failed to count messages of inbox %d for IMAP updates: %v", ev.InboxID, err)
+			return
+		}
+		exists = total
+	case events.MessagesDeleted:
+		ids := append([]int{}, ev.MessageIDs...)
+		sort.Ints(ids)
+
+		below, err := be.core.MessageService.CountBelow(ctx, ev.InboxID, ev.FolderID, ids)
+		if err != nil {
+			be.core.Logger.Error("Failed to count messages of inbox %d for IMAP updates: %v", ev.InboxID, err)
+			return
+		}
+		for _, q := range later {
+			if q.done == nil && q.ev.Type == events.MessagesDeleted &&
+				q.ev.InboxID == ev.InboxID && q.ev.FolderID == ev.FolderID {
+				restoreDeleted(below, ids, q.ev.MessageIDs)
+			}
+		}
+		seqNums = expungedSeqNums(below)
+	default:
+		return
+	}
+
+	for _, u := range users {
+		name, ok := u.mailboxNameFor(ctx, ev.InboxID, folder)
+		if !ok {
+			continue
+		}
+
+		switch ev.Type {
+		case events.MessageCreated:
+			status := imap.NewMailboxStatus(name, []imap.StatusItem{imap.StatusMessages})
+			status.Messages = uint32(exists)
+			be.notify(&backend.MailboxUpdate{
+				Update:        backend.NewUpdate(u.Username(), name),
+				MailboxStatus: status,
+			})
+		case events.MessagesDeleted:
+			for _, seqNum := range seqNums {
+				be.notify(&backend.ExpungeUpdate{
+					Update: backend.NewUpdate(u.Username(), name),
+					SeqNum: seqNum,
+				})
+			}
+		}
+	}
+}
+
+// notify hands an update to the server and waits until it has been queued
+// for every matching connection
+func (be *ImapBackend) notify(update backend.Update) {
+	timeout := time.NewTimer(updateTimeout)
+	defer timeout.Stop()
+
+	// The channel is created on first use, it must exist before the server
+	// gets hold of the update
+	done := update.Done()
+
+	select {
+	case be.updates <- update:
+	case <-timeout.C:
+		be.core.Logger.Error("Timed out sending IMAP update")
+		return
+	}
+
+	select {
+	case <-done:
+	case <-timeout.C:
+		be.core.Logger.Error("Timed out waiting for IMAP update delivery")
+	}
+}
+
+// activeUsers returns one logged in session per username
+func (be *ImapBackend) activeUsers() []*ImapUser {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	seen := make(map[string]bool, len(be.sessions))
+	users := make([]*ImapUser, 0, len(be.sessions))
+	for u := range be.sessions {
+		if !seen[u.Username()] {
+			seen[u.Username()] = true
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+func (be *ImapBackend) addSession(u *ImapUser) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	be.sessions[u] = struct{}{}
+}
+
+func (be *ImapBackend) removeSession(u *ImapUser) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	delete(be.sessions, u)
+}
+
+// restoreDeleted adds to below, the number of messages below each of ids,
+// the messages of deleted that are still present from the point of view of
+// the event being handled
+func restoreDeleted(below []int, ids []int, deleted []int) {
+	for i, id := range ids {
+		for _, d := range deleted {
+			if d < id {
+				below[i]++
+			}
+		}
+	}
+}
+
+// expungedSeqNums returns the sequence numbers deleted messages had before
+// they were removed, in descending order so that each EXPUNGE leaves the
+// following ones valid. below holds, in ascending order of the deleted
+// messages, the number of remaining messages with a lower ID.
+func expungedSeqNums(below []int) []uint32 {
+	seqNums := make([]uint32, 0, len(below))
+	for i, n := range below {
+		// Everything still present before it, plus the deleted ones before it
+		seqNums = append(seqNums, uint32(n+i+1))
+	}
+
+	sort.Slice(seqNums, func(i, j int) bool { return seqNums[i] > seqNums[j] })
+	return seqNums
+}
@@ -0,0 +1,180 @@
+package imap
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"inbox451/internal/config"
+	"inbox451/internal/core"
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupUpdatesTestBackend(t *testing.T) (*ImapBackend, *mocks.Repository, func() []backend.Update) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	c := &core.Core{
+		Config:     &config.Config{},
+		Logger:     logger,
+		Repository: mockRepo,
+		Events:     events.NewBus(),
+	}
+	c.InboxService = core.NewInboxService(c)
+	c.FolderService = core.NewFolderService(c)
+	c.MessageService = core.NewMessageService(c)
+
+	be := newBackend(c)
+	t.Cleanup(be.close)
+	be.addSession(&ImapUser{core: c, backend: be, user: &models.User{Base: models.Base{ID: 7}, Username: "qa"}})
+
+	// Stands in for the server, which acknowledges every update
+	var mu sync.Mutex
+	var received []backend.Update
+	go func() {
+		for update := range be.updates {
+			mu.Lock()
+			received = append(received, update)
+			mu.Unlock()
+			close(update.Done())
+		}
+	}()
+
+	return be, mockRepo, func() []backend.Update {
+		be.flush()
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func TestImapBackend_HandleEvent(t *testing.T) {
+	inboxes := []*models.Inbox{
+		{Base: models.Base{ID: 1}, Email: "qa@example.com"},
+		{Base: models.Base{ID: 2}, Email: "dev@example.com"},
+	}
+
+	t.Run("new message", func(t *testing.T) {
+		be, mockRepo, updates := setupUpdatesTestBackend(t)
+		mockRepo.On("GetFolder", mock.Anything, 4).
+			Return(&models.Folder{Base: models.Base{ID: 4}, InboxID: 2, Name: "Archive"}, nil)
+		mockRepo.On("CountMessagesByFolder", mock.Anything, 2, null.IntFrom(4)).Return(12, nil)
+		mockRepo.On("ListInboxesByUser", mock.Anything, 7).Return(inboxes, nil)
+
+		be.core.Events.Publish(events.Event{
+			Type:       events.MessageCreated,
+			InboxID:    2,
+			FolderID:   null.IntFrom(4),
+			MessageIDs: []int{30},
+		})
+
+		received := updates()
+		require.Len(t, received, 1)
+		update, ok := received[0].(*backend.MailboxUpdate)
+		require.True(t, ok)
+		assert.Equal(t, "qa", update.Username())
+		assert.Equal(t, "dev@example.com/Archive", update.Mailbox())
+		assert.Equal(t, uint32(12), update.MailboxStatus.Messages)
+	})
+
+	t.Run("deleted messages", func(t *testing.T) {
+		be, mockRepo, updates := setupUpdatesTestBackend(t)
+		mockRepo.On("CountMessagesBelow", mock.Anything, 1, null.Int{}, []int{3, 8}).Return([]int{1, 4}, nil)
+		mockRepo.On("ListInboxesByUser", mock.Anything, 7).Return(inboxes, nil)
+
+		be.core.Events.Publish(events.Event{
+			Type:       events.MessagesDeleted,
+			InboxID:    1,
+			MessageIDs: []int{8, 3},
+		})
+
+		received := updates()
+		require.Len(t, received, 2)
+		for i, seqNum := range []uint32{6, 2} {
+			update, ok := received[i].(*backend.ExpungeUpdate)
+			require.True(t, ok)
+			assert.Equal(t, imap.InboxName, update.Mailbox())
+			assert.Equal(t, seqNum, update.SeqNum)
+		}
+	})
+
+	t.Run("inbox out of reach", func(t *testing.T) {
+		be, mockRepo, updates := setupUpdatesTestBackend(t)
+		mockRepo.On("CountMessagesByFolder", mock.Anything, 9, null.Int{}).Return(1, nil)
+		mockRepo.On("ListInboxesByUser", mock.Anything, 7).Return(inboxes, nil)
+
+		be.core.Events.Publish(events.Event{
+			Type:       events.MessageCreated,
+			InboxID:    9,
+			MessageIDs: []int{31},
+		})
+
+		assert.Empty(t, updates())
+	})
+}
+
+func TestImapBackend_HandleEventLaterDeletions(t *testing.T) {
+	be, mockRepo, updates := setupUpdatesTestBackend(t)
+	mockRepo.On("CountMessagesBelow", mock.Anything, 1, null.Int{}, []int{5}).Return([]int{1}, nil)
+	mockRepo.On("ListInboxesByUser", mock.Anything, 7).Return([]*models.Inbox{{Base: models.Base{ID: 1}}}, nil)
+
+	// Message 2 was deleted in the database before the event of message 5
+	// was handled, the client still sees it. The deletion in another folder
+	// does not matter.
+	be.handleEvent(events.Event{Type: events.MessagesDeleted, InboxID: 1, MessageIDs: []int{5}}, []queuedEvent{
+		{ev: events.Event{Type: events.MessagesDeleted, InboxID: 1, MessageIDs: []int{2}}},
+		{ev: events.Event{Type: events.MessagesDeleted, InboxID: 1, FolderID: null.IntFrom(4), MessageIDs: []int{1}}},
+	})
+
+	received := updates()
+	require.Len(t, received, 1)
+	assert.Equal(t, uint32(3), received[0].(*backend.ExpungeUpdate).SeqNum)
+}
+
+func TestExpungedSeqNums(t *testing.T) {
+	tests := []struct {
+		name  string
+		below []int
+		want  []uint32
+	}{
+		{
+			name:  "nothing deleted",
+			below: nil,
+			want:  []uint32{},
+		},
+		{
+			// 1 2 3 4 5, deleting 1, 2 and 5 leaves 3 4
+			name:  "leading and trailing messages",
+			below: []int{0, 0, 2},
+			want:  []uint32{5, 2, 1},
+		},
+		{
+			// 1 2 3 4, deleting 2 and 4 leaves 1 3
+			name:  "interleaved messages",
+			below: []int{1, 2},
+			want:  []uint32{4, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, expungedSeqNums(tt.below))
+		})
+	}
+}
+
+func TestRestoreDeleted(t *testing.T) {
+	below := []int{0, 3}
+	restoreDeleted(below, []int{4, 10}, []int{2, 6, 12})
+	assert.Equal(t, []int{1, 5}, below)
+}
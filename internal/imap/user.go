@@ -0,0 +1,230 @@
+package imap
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"inbox451/internal/core"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	null "github.com/volatiletech/null/v9"
+)
+
+// delimiter separates the inbox mailbox from its folders and the levels of
+// the folder hierarchy
+const delimiter = "/"
+
+// ImapUser implements go-imap/backend.User interface
+type ImapUser struct {
+	core    *core.Core
+	backend *ImapBackend
+	user    *models.User
+}
+
+// Username returns the authenticated username
+func (u *ImapUser) Username() string {
+	return u.user.Username
+}
+
+// context returns the context of a request made on behalf of the user, the
+// services restrict it to the inboxes the user can reach
+func (u *ImapUser) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(core.WithUser(context.Background(), u.user), requestTimeout)
+}
+
+// ListMailboxes exposes every inbox the user can reach through its projects,
+// followed by the folders of the inbox. The first inbox is presented as
+// INBOX, the others are named after their email address, and folders are
+// nested below their inbox, e.g. INBOX/Archive.
+func (u *ImapUser) ListMailboxes(subscribed bool) ([]backend.Mailbox, error) {
+	ctx, cancel := u.context()
+	defer cancel()
+
+	inboxes, err := u.core.InboxService.ListByUser(ctx, u.user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	mailboxes := make([]backend.Mailbox, 0, len(inboxes))
+	for i, inbox := range inboxes {
+		name := mailboxName(i, inbox)
+		mailboxes = append(mailboxes, u.newMailbox(name, inbox, nil))
+
+		folders, err := u.core.FolderService.ListAllByInbox(ctx, inbox.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range folders {
+			mailboxes = append(mailboxes, u.newMailbox(name+delimiter+folder.Name, inbox, folder))
+		}
+	}
+	return mailboxes, nil
+}
+
+// GetMailbox returns a specific mailbox
+func (u *ImapUser) GetMailbox(name string) (backend.Mailbox, error) {
+	ctx, cancel := u.context()
+	defer cancel()
+
+	return u.getMailbox(ctx, name)
+}
+
+// CreateMailbox creates a folder. Only names below an inbox mailbox can be
+// created, inboxes themselves are managed through the API.
+func (u *ImapUser) CreateMailbox(name string) error {
+	ctx, cancel := u.context()
+	defer cancel()
+
+	// A trailing delimiter only announces that children will be created
+	name = strings.TrimSuffix(name, delimiter)
+
+	inbox, _, folderName, err := u.resolve(ctx, name)
+	if err != nil {
+		return err
+	}
+	if inbox == nil {
+		return errors.New("mailboxes can only be created inside an inbox mailbox")
+	}
+	if folderName == "" {
+		return backend.ErrMailboxAlreadyExists
+	}
+
+	err = u.core.FolderService.Create(ctx, &models.Folder{InboxID: inbox.ID, Name: folderName})
+	if errors.Is(err, storage.ErrConflict) {
+		return backend.ErrMailboxAlreadyExists
+	}
+	return err
+}
+
+// DeleteMailbox deletes a folder and the messages filed in it
+func (u *ImapUser) DeleteMailbox(name string) error {
+	ctx, cancel := u.context()
+	defer cancel()
+
+	mailbox, err := u.getMailbox(ctx, name)
+	if err != nil {
+		return err
+	}
+	if mailbox.folder == nil {
+		return errors.New("inbox mailboxes cannot be deleted")
+	}
+
+	return u.core.FolderService.Delete(ctx, mailbox.folder.ID)
+}
+
+// RenameMailbox renames a folder and the folders below it. Folders cannot be
+// moved to another inbox and inbox mailboxes cannot be renamed.
+func (u *ImapUser) RenameMailbox(existingName, newName string) error {
+	ctx, cancel := u.context()
+	defer cancel()
+
+	mailbox, err := u.getMailbox(ctx, existingName)
+	if err != nil {
+		return err
+	}
+	if mailbox.folder == nil {
+		return errors.New("inbox mailboxes cannot be renamed")
+	}
+
+	inbox, _, folderName, err := u.resolve(ctx, strings.TrimSuffix(newName, delimiter))
+	if err != nil {
+		return err
+	}
+	if inbox == nil || inbox.ID != mailbox.inbox.ID || folderName == "" {
+		return errors.New("folders can only be renamed within their inbox")
+	}
+
+	err = u.core.FolderService.Rename(ctx, mailbox.folder, folderName)
+	if errors.Is(err, storage.ErrConflict) {
+		return backend.ErrMailboxAlreadyExists
+	}
+	return err
+}
+
+func (u *ImapUser) Logout() error {
+	u.backend.removeSession(u)
+	return nil
+}
+
+func (u *ImapUser) newMailbox(name string, inbox *models.Inbox, folder *models.Folder) *ImapMailbox {
+	return &ImapMailbox{name: name, user: u, inbox: inbox, folder: folder}
+}
+
+func (u *ImapUser) getMailbox(ctx context.Context, name string) (*ImapMailbox, error) {
+	inbox, root, folderName, err := u.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if inbox == nil {
+		return nil, backend.ErrNoSuchMailbox
+	}
+	if folderName == "" {
+		return u.newMailbox(root, inbox, nil), nil
+	}
+
+	folder, err := u.core.FolderService.GetByName(ctx, inbox.ID, folderName)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, backend.ErrNoSuchMailbox
+	} else if err != nil {
+		return nil, err
+	}
+	return u.newMailbox(root+delimiter+folder.Name, inbox, folder), nil
+}
+
+// resolve splits a mailbox name into the inbox it belongs to, the canonical
+// name of that inbox mailbox and the folder name below it, which is empty
+// for the inbox mailbox itself. A nil inbox means no inbox matches.
+func (u *ImapUser) resolve(ctx context.Context, name string) (*models.Inbox, string, string, error) {
+	inboxes, err := u.core.InboxService.ListByUser(ctx, u.user.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	for i, inbox := range inboxes {
+		root := mailboxName(i, inbox)
+		if strings.EqualFold(name, root) {
+			return inbox, root, "", nil
+		}
+		if len(name) > len(root) && strings.EqualFold(name[:len(root)+1], root+delimiter) {
+			return inbox, root, name[len(root)+1:], nil
+		}
+	}
+	return nil, "", "", nil
+}
+
+// mailboxNameFor returns the name under which the user sees a folder of an
+// inbox, a nil folder names the inbox mailbox itself
+func (u *ImapUser) mailboxNameFor(ctx context.Context, inboxID int, folder *models.Folder) (string, bool) {
+	inboxes, err := u.core.InboxService.ListByUser(ctx, u.user.ID)
+	if err != nil {
+		return "", false
+	}
+
+	for i, inbox := range inboxes {
+		if inbox.ID == inboxID {
+			if folder != nil {
+				return mailboxName(i, inbox) + delimiter + folder.Name, true
+			}
+			return mailboxName(i, inbox), true
+		}
+	}
+	return "", false
+}
+
+func mailboxName(i int, inbox *models.Inbox) string {
+	if i == 0 {
+		return imap.InboxName
+	}
+	return inbox.Email
+}
+
+func folderID(folder *models.Folder) null.Int {
+	if folder == nil {
+		return null.Int{}
+	}
+	return null.IntFrom(folder.ID)
+}
@@ -0,0 +1,246 @@
+package imap
+
+import (
+	"testing"
+
+	"inbox451/internal/core"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/emersion/go-imap/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testInboxes are the inboxes reached by every user of the tests, the first
+// one is presented as INBOX
+var testInboxes = []*models.Inbox{
+	{Base: models.Base{ID: 1}, Email: "qa@example.com"},
+	{Base: models.Base{ID: 2}, Email: "dev@example.com"},
+}
+
+// setupImapUserTest returns an admin session, so no membership lookups are
+// made. The user logged in by setupUpdatesTestBackend receives the updates
+// of both test inboxes.
+func setupImapUserTest(t *testing.T) (*ImapUser, *mocks.Repository, func() []backend.Update) {
+	be, mockRepo, updates := setupUpdatesTestBackend(t)
+	mockRepo.On("ListInboxesByUser", mock.Anything, 1).Return(testInboxes, nil).Maybe()
+	mockRepo.On("ListInboxesByUser", mock.Anything, 7).Return(testInboxes, nil).Maybe()
+
+	user := &ImapUser{core: be.core, backend: be, user: &models.User{Base: models.Base{ID: 1}, Username: "admin", Role: core.RoleAdmin}}
+	return user, mockRepo, updates
+}
+
+func TestImapUser_CreateMailbox(t *testing.T) {
+	tests := []struct {
+		name     string
+		mailbox  string
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantFail bool
+	}{
+		{
+			name:    "folder of the first inbox",
+			mailbox: "INBOX/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateFolder", mock.Anything, &models.Folder{InboxID: 1, Name: "Archive"}).Return(nil)
+			},
+		},
+		{
+			name:    "nested folder with a trailing delimiter",
+			mailbox: "dev@example.com/Builds/2024/",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateFolder", mock.Anything, &models.Folder{InboxID: 2, Name: "Builds/2024"}).Return(nil)
+			},
+		},
+		{
+			name:    "existing folder",
+			mailbox: "inbox/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateFolder", mock.Anything, &models.Folder{InboxID: 1, Name: "Archive"}).Return(storage.ErrConflict)
+			},
+			wantErr: backend.ErrMailboxAlreadyExists,
+		},
+		{
+			name:    "inbox mailbox",
+			mailbox: "dev@example.com",
+			mockFn:  func(*mocks.Repository) {},
+			wantErr: backend.ErrMailboxAlreadyExists,
+		},
+		{
+			name:     "outside of any inbox",
+			mailbox:  "Archive",
+			mockFn:   func(*mocks.Repository) {},
+			wantFail: true,
+		},
+		{
+			name:     "invalid folder name",
+			mailbox:  "INBOX/Archive//2024",
+			mockFn:   func(*mocks.Repository) {},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, mockRepo, _ := setupImapUserTest(t)
+			tt.mockFn(mockRepo)
+
+			err := user.CreateMailbox(tt.mailbox)
+			switch {
+			case tt.wantErr != nil:
+				assert.Equal(t, tt.wantErr, err)
+			case tt.wantFail:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestImapUser_RenameMailbox(t *testing.T) {
+	// Renaming updates the folder, every case gets its own
+	archive := func() *models.Folder {
+		return &models.Folder{Base: models.Base{ID: 4}, InboxID: 1, Name: "Archive"}
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		newName  string
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantFail bool
+	}{
+		{
+			name:     "folder within its inbox",
+			existing: "INBOX/Archive",
+			newName:  "INBOX/Old/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive(), nil)
+				m.On("RenameFolder", mock.Anything, 1, "Archive", "Old/Archive").Return(nil)
+			},
+		},
+		{
+			name:     "name taken",
+			existing: "INBOX/Archive",
+			newName:  "INBOX/Builds",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive(), nil)
+				m.On("RenameFolder", mock.Anything, 1, "Archive", "Builds").Return(storage.ErrConflict)
+			},
+			wantErr: backend.ErrMailboxAlreadyExists,
+		},
+		{
+			name:     "unknown folder",
+			existing: "INBOX/Missing",
+			newName:  "INBOX/Found",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Missing").Return(nil, storage.ErrNotFound)
+			},
+			wantErr: backend.ErrNoSuchMailbox,
+		},
+		{
+			name:     "folder to another inbox",
+			existing: "INBOX/Archive",
+			newName:  "dev@example.com/Archive",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive(), nil)
+			},
+			wantFail: true,
+		},
+		{
+			name:     "folder to an inbox mailbox",
+			existing: "INBOX/Archive",
+			newName:  "INBOX",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 1, "Archive").Return(archive(), nil)
+			},
+			wantFail: true,
+		},
+		{
+			name:     "inbox mailbox",
+			existing: "INBOX",
+			newName:  "INBOX/Archive",
+			mockFn:   func(*mocks.Repository) {},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, mockRepo, _ := setupImapUserTest(t)
+			tt.mockFn(mockRepo)
+
+			err := user.RenameMailbox(tt.existing, tt.newName)
+			switch {
+			case tt.wantErr != nil:
+				assert.Equal(t, tt.wantErr, err)
+			case tt.wantFail:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestImapUser_DeleteMailbox(t *testing.T) {
+	tests := []struct {
+		name     string
+		mailbox  string
+		mockFn   func(*mocks.Repository)
+		wantErr  error
+		wantFail bool
+	}{
+		{
+			name:    "folder",
+			mailbox: "dev@example.com/Builds",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 2, "Builds").
+					Return(&models.Folder{Base: models.Base{ID: 5}, InboxID: 2, Name: "Builds"}, nil)
+				m.On("DeleteFolder", mock.Anything, 5).Return(nil)
+			},
+		},
+		{
+			name:    "unknown folder",
+			mailbox: "dev@example.com/Missing",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolderByName", mock.Anything, 2, "Missing").Return(nil, storage.ErrNotFound)
+			},
+			wantErr: backend.ErrNoSuchMailbox,
+		},
+		{
+			name:    "unknown inbox",
+			mailbox: "ops@example.com/Builds",
+			mockFn:  func(*mocks.Repository) {},
+			wantErr: backend.ErrNoSuchMailbox,
+		},
+		{
+			name:     "inbox mailbox",
+			mailbox:  "INBOX",
+			mockFn:   func(*mocks.Repository) {},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, mockRepo, _ := setupImapUserTest(t)
+			tt.mockFn(mockRepo)
+
+			err := user.DeleteMailbox(tt.mailbox)
+			switch {
+			case tt.wantErr != nil:
+				assert.Equal(t, tt.wantErr, err)
+			case tt.wantFail:
+				require.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
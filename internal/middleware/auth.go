@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"inbox451/internal/core"
+	"inbox451/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UserKey is the echo context key holding the authenticated *models.User
+const UserKey = "user"
+
+// SessionCookieName is the cookie carrying the session ID of the web UI
+const SessionCookieName = "inbox451_session"
+
+// AuthMiddleware requires either an Authorization: Bearer <token> header
+// carrying a valid API token, or the session cookie of a logged in user of
+// the web UI. The header takes precedence when both are sent. The user is
+// stored in the echo context under UserKey and in the request context, see
+// core.UserFromContext.
+func AuthMiddleware(c *core.Core) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			reqCtx := ctx.Request().Context()
+
+			var user *models.User
+			var err error
+			if token, ok := bearerToken(ctx.Request()); ok {
+				user, err = c.TokenService.Authenticate(reqCtx, token)
+			} else if cookie, cerr := ctx.Cookie(SessionCookieName); cerr == nil && cookie.Value != "" {
+				user, err = c.SessionService.Authenticate(reqCtx, cookie.Value)
+			} else {
+				ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="inbox451"`)
+				return echo.NewHTTPError(http.StatusUnauthorized, core.ErrUnauthorized)
+			}
+			if err != nil {
+				if errors.Is(err, core.ErrUnauthorized) {
+					ctx.Response().Header().Set(echo.HeaderWWWAuthenticate,
+						`Bearer realm="inbox451", error="invalid_token"`)
+					return echo.NewHTTPError(http.StatusUnauthorized, core.ErrUnauthorized)
+				}
+				return c.HandleError(err, http.StatusInternalServerError)
+			}
+
+			ctx.Set(UserKey, user)
+			ctx.SetRequest(ctx.Request().WithContext(core.WithUser(ctx.Request().Context(), user)))
+
+			return next(ctx)
+		}
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get(echo.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
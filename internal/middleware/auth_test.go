@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"inbox451/internal/core"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantToken string
+		wantOK    bool
+	}{
+		{name: "bearer token", header: "Bearer token-value", wantToken: "token-value", wantOK: true},
+		{name: "scheme is case insensitive", header: "bearer token-value", wantToken: "token-value", wantOK: true},
+		{name: "surrounding spaces", header: "Bearer   token-value ", wantToken: "token-value", wantOK: true},
+		{name: "missing header"},
+		{name: "other scheme", header: "Basic cWE6c2VjcmV0"},
+		{name: "scheme only", header: "Bearer"},
+		{name: "empty token", header: "Bearer  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+
+			token, ok := bearerToken(req)
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	errDatabase := errors.New("connection refused")
+	tokenUser := &models.User{Base: models.Base{ID: 7}, Username: "qa", Password: "hash"}
+	sessionUser := &models.User{Base: models.Base{ID: 8}, Username: "dev", Password: "hash"}
+
+	expectToken := func(m *mocks.Repository) {
+		m.On("GetTokenByValue", mock.Anything, "token-value").
+			Return(&models.Token{Base: models.Base{ID: 3}, UserID: 7, Token: "token-value"}, nil)
+		m.On("GetUser", mock.Anything, 7).Return(tokenUser, nil)
+		m.On("UpdateTokenLastUsed", mock.Anything, 3).Return(nil)
+	}
+	expectSession := func(m *mocks.Repository) {
+		m.On("GetSession", mock.Anything, "session-id").
+			Return(&models.Session{Base: models.Base{ID: 4}, SessionID: "session-id", UserID: 8, IsActive: true, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		m.On("GetUser", mock.Anything, 8).Return(sessionUser, nil)
+		m.On("TouchSession", mock.Anything, 4).Return(nil)
+	}
+
+	tests := []struct {
+		name                string
+		header              string
+		cookie              string
+		mockFn              func(*mocks.Repository)
+		wantStatus          int
+		wantWWWAuthenticate string
+		wantUserID          int
+	}{
+		{
+			name:                "missing credentials",
+			mockFn:              func(*mocks.Repository) {},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451"`,
+		},
+		{
+			name:                "malformed scheme",
+			header:              "Basic cWE6c2VjcmV0",
+			mockFn:              func(*mocks.Repository) {},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451"`,
+		},
+		{
+			name:   "unknown token",
+			header: "Bearer token-value",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "token-value").Return(nil, storage.ErrNotFound)
+			},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451", error="invalid_token"`,
+		},
+		{
+			name:   "expired token",
+			header: "Bearer token-value",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "token-value").
+					Return(&models.Token{Base: models.Base{ID: 3}, UserID: 7, Token: "token-value", ExpiresAt: null.TimeFrom(time.Now().Add(-time.Hour))}, nil)
+			},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451", error="invalid_token"`,
+		},
+		{
+			name:   "token lookup failure",
+			header: "Bearer token-value",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "token-value").Return(nil, errDatabase)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "valid token",
+			header:     "Bearer token-value",
+			mockFn:     expectToken,
+			wantUserID: 7,
+		},
+		{
+			name:       "session cookie fallback",
+			cookie:     "session-id",
+			mockFn:     expectSession,
+			wantUserID: 8,
+		},
+		{
+			name:   "unknown session",
+			cookie: "session-id",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetSession", mock.Anything, "session-id").Return(nil, storage.ErrNotFound)
+			},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451", error="invalid_token"`,
+		},
+		{
+			name:       "header takes precedence over the cookie",
+			header:     "Bearer token-value",
+			cookie:     "session-id",
+			mockFn:     expectToken,
+			wantUserID: 7,
+		},
+		{
+			name:   "invalid header is not rescued by the cookie",
+			header: "Bearer token-value",
+			cookie: "session-id",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetTokenByValue", mock.Anything, "token-value").Return(nil, storage.ErrNotFound)
+			},
+			wantStatus:          http.StatusUnauthorized,
+			wantWWWAuthenticate: `Bearer realm="inbox451", error="invalid_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupMiddlewareTestCore(t)
+			tt.mockFn(mockRepo)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			var got *models.User
+			err := AuthMiddleware(c)(func(ctx echo.Context) error {
+				user, ok := core.UserFromContext(ctx.Request().Context())
+				require.True(t, ok)
+				assert.Same(t, user, ctx.Get(UserKey))
+				got = user
+				return nil
+			})(ctx)
+
+			if tt.wantStatus != 0 {
+				var httpErr *echo.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, tt.wantStatus, httpErr.Code)
+				assert.Equal(t, tt.wantWWWAuthenticate, rec.Header().Get(echo.HeaderWWWAuthenticate))
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantUserID, got.ID)
+			assert.Empty(t, got.Password)
+		})
+	}
+}
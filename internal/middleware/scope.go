@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"inbox451/internal/core"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScopeMiddleware verifies that the resources named by a nested route belong
+// to each other, e.g. that :inboxId is an inbox of :projectId and :messageId
+// a message of :inboxId, or that :domainId and :webhookId belong to
+// :projectId. Mismatches are reported as not found. Access to the
+// resources themselves is checked by the core services.
+func ScopeMiddleware(c *core.Core) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if err := checkScope(ctx, c); err != nil {
+				return err
+			}
+			return next(ctx)
+		}
+	}
+}
+
+func checkScope(ctx echo.Context, c *core.Core) error {
+	reqCtx := ctx.Request().Context()
+
+	if domainID, ok := intParam(ctx, "domainId"); ok {
+		projectID, _ := intParam(ctx, "projectId")
+		domain, err := c.DomainService.Get(reqCtx, domainID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if domain.ProjectID != projectID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	if webhookID, ok := intParam(ctx, "webhookId"); ok {
+		projectID, _ := intParam(ctx, "projectId")
+		webhook, err := c.WebhookService.Get(reqCtx, webhookID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if webhook.ProjectID != projectID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	inboxID, ok := intParam(ctx, "inboxId")
+	if !ok {
+		return nil
+	}
+
+	if projectID, ok := intParam(ctx, "projectId"); ok {
+		inbox, err := c.InboxService.Get(reqCtx, inboxID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if inbox.ProjectID != projectID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	if ruleID, ok := intParam(ctx, "ruleId"); ok {
+		rule, err := c.RuleService.Get(reqCtx, ruleID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if rule.InboxID != inboxID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	if messageID, ok := intParam(ctx, "messageId"); ok {
+		message, err := c.MessageService.Get(reqCtx, messageID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if message.InboxID != inboxID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	if folderID, ok := intParam(ctx, "folderId"); ok {
+		folder, err := c.FolderService.Get(reqCtx, folderID)
+		if err != nil {
+			return c.HandleError(err, http.StatusInternalServerError)
+		}
+		if folder.InboxID != inboxID {
+			return c.HandleError(nil, http.StatusNotFound)
+		}
+	}
+
+	return nil
+}
+
+// intParam returns a numeric path parameter, a malformed value is reported
+// as absent and left to the handler
+func intParam(ctx echo.Context, name string) (int, bool) {
+	value := ctx.Param(name)
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.Atoi(value)
+	return id, err == nil
+}
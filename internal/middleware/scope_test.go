@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"inbox451/internal/config"
+	"inbox451/internal/core"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMiddlewareTestCore(t *testing.T) (*core.Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	c := &core.Core{
+		Config:     &config.Config{},
+		Logger:     logger.New(io.Discard, logger.DEBUG),
+		Repository: mockRepo,
+	}
+	c.InboxService = core.NewInboxService(c)
+	c.MessageService = core.NewMessageService(c)
+	c.RuleService = core.NewRuleService(c)
+	c.FolderService = core.NewFolderService(c)
+	c.DomainService = core.NewDomainService(c)
+	c.WebhookService = core.NewWebhookService(c)
+	c.TokenService = core.NewTokensService(c)
+	c.SessionService = core.NewSessionService(c)
+	return c, mockRepo
+}
+
+func TestScopeMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     map[string]string
+		mockFn     func(*mocks.Repository)
+		wantStatus int
+	}{
+		{
+			name:   "no nested resources",
+			params: map[string]string{"projectId": "2"},
+			mockFn: func(*mocks.Repository) {},
+		},
+		{
+			name:   "inbox of the project",
+			params: map[string]string{"projectId": "2", "inboxId": "1"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 1).Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2}, nil)
+			},
+		},
+		{
+			name:   "inbox of another project",
+			params: map[string]string{"projectId": "2", "inboxId": "1"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 1).Return(&models.Inbox{Base: models.Base{ID: 1}, ProjectID: 3}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "unknown inbox",
+			params: map[string]string{"projectId": "2", "inboxId": "1"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInbox", mock.Anything, 1).Return(nil, storage.ErrNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "message of the inbox",
+			params: map[string]string{"inboxId": "1", "messageId": "5"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 5).Return(&models.Message{Base: models.Base{ID: 5}, InboxID: 1}, nil)
+			},
+		},
+		{
+			name:   "message of another inbox",
+			params: map[string]string{"inboxId": "1", "messageId": "5"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetMessage", mock.Anything, 5).Return(&models.Message{Base: models.Base{ID: 5}, InboxID: 4}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "rule of the inbox",
+			params: map[string]string{"inboxId": "1", "ruleId": "6"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetRule", mock.Anything, 6).Return(&models.ForwardRule{Base: models.Base{ID: 6}, InboxID: 1}, nil)
+			},
+		},
+		{
+			name:   "rule of another inbox",
+			params: map[string]string{"inboxId": "1", "ruleId": "6"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetRule", mock.Anything, 6).Return(&models.ForwardRule{Base: models.Base{ID: 6}, InboxID: 4}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "folder of the inbox",
+			params: map[string]string{"inboxId": "1", "folderId": "7"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolder", mock.Anything, 7).Return(&models.Folder{Base: models.Base{ID: 7}, InboxID: 1, Name: "Archive"}, nil)
+			},
+		},
+		{
+			name:   "folder of another inbox",
+			params: map[string]string{"inboxId": "1", "folderId": "7"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetFolder", mock.Anything, 7).Return(&models.Folder{Base: models.Base{ID: 7}, InboxID: 4, Name: "Archive"}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "domain of the project",
+			params: map[string]string{"projectId": "2", "domainId": "8"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 8).Return(&models.Domain{Base: models.Base{ID: 8}, ProjectID: 2, Name: "example.com"}, nil)
+			},
+		},
+		{
+			name:   "domain of another project",
+			params: map[string]string{"projectId": "2", "domainId": "8"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetDomain", mock.Anything, 8).Return(&models.Domain{Base: models.Base{ID: 8}, ProjectID: 3, Name: "example.com"}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "webhook of the project",
+			params: map[string]string{"projectId": "2", "webhookId": "9"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetWebhook", mock.Anything, 9).Return(&models.Webhook{Base: models.Base{ID: 9}, ProjectID: 2}, nil)
+			},
+		},
+		{
+			name:   "webhook of another project",
+			params: map[string]string{"projectId": "2", "webhookId": "9"},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetWebhook", mock.Anything, 9).Return(&models.Webhook{Base: models.Base{ID: 9}, ProjectID: 3}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "malformed identifier is left to the handler",
+			params: map[string]string{"projectId": "2", "inboxId": "abc"},
+			mockFn: func(*mocks.Repository) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupMiddlewareTestCore(t)
+			tt.mockFn(mockRepo)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(core.WithSystemContext(req.Context()))
+			ctx := e.NewContext(req, httptest.NewRecorder())
+			var names, values []string
+			for name, value := range tt.params {
+				names = append(names, name)
+				values = append(values, value)
+			}
+			ctx.SetParamNames(names...)
+			ctx.SetParamValues(values...)
+
+			called := false
+			err := ScopeMiddleware(c)(func(echo.Context) error {
+				called = true
+				return nil
+			})(ctx)
+
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.wantStatus, httpErr.Code)
+			assert.False(t, called)
+		})
+	}
+}
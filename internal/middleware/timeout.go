@@ -5,11 +5,22 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
 )
 
-func TimeoutMiddleware(timeout time.Duration) echo.MiddlewareFunc {
+// TimeoutMiddleware fails requests running longer than timeout. Requests the
+// skipper selects, such as long-lived event streams, run without a timeout.
+func TimeoutMiddleware(timeout time.Duration, skipper echomiddleware.Skipper) echo.MiddlewareFunc {
+	if skipper == nil {
+		skipper = echomiddleware.DefaultSkipper
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+
 			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
 			defer cancel()
 
@@ -0,0 +1,198 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"inbox451/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func V0_2_0(db *sqlx.DB, config *config.Config, log *log.Logger) error {
+	log.Print("Running migration v0.2.0")
+	schema := []string{
+		`ALTER TABLE forward_rules
+			ADD COLUMN IF NOT EXISTS match_type VARCHAR(20) NOT NULL DEFAULT 'exact',
+			ADD COLUMN IF NOT EXISTS forward_to VARCHAR(255) NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS hit_count INTEGER NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS last_hit_at TIMESTAMP WITH TIME ZONE`,
+
+		`ALTER TABLE messages
+			ADD COLUMN IF NOT EXISTS raw BYTEA,
+			ADD COLUMN IF NOT EXISTS size INTEGER NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS html_body TEXT NOT NULL DEFAULT '',
+			ADD COLUMN IF NOT EXISTS is_flagged BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS is_answered BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false,
+			ADD COLUMN IF NOT EXISTS keywords TEXT[] NOT NULL DEFAULT '{}'`,
+
+		`CREATE TABLE IF NOT EXISTS attachments (
+			id SERIAL PRIMARY KEY,
+			message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+			filename VARCHAR(255) NOT NULL,
+			content_type VARCHAR(255) NOT NULL,
+			content_id VARCHAR(255) NOT NULL DEFAULT '',
+			size INTEGER NOT NULL,
+			content BYTEA NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id)`,
+
+		`CREATE TABLE IF NOT EXISTS folders (
+			id SERIAL PRIMARY KEY,
+			inbox_id INTEGER NOT NULL REFERENCES inboxes(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (inbox_id, name)
+		)`,
+
+		`ALTER TABLE messages
+			ADD COLUMN IF NOT EXISTS folder_id INTEGER REFERENCES folders(id) ON DELETE CASCADE`,
+
+		`CREATE INDEX IF NOT EXISTS idx_messages_inbox_folder ON messages(inbox_id, folder_id)`,
+
+		`ALTER TABLE projects
+			ADD COLUMN IF NOT EXISTS smtp_require_auth BOOLEAN NOT NULL DEFAULT false`,
+
+		`CREATE TABLE IF NOT EXISTS domains (
+			id SERIAL PRIMARY KEY,
+			project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			name VARCHAR(253) NOT NULL,
+			verification_token VARCHAR(64) NOT NULL,
+			verified_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (project_id, name)
+		)`,
+
+		// Any project may claim a domain, only one of them can verify it
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_domains_verified_name
+			ON domains(name) WHERE verified_at IS NOT NULL`,
+
+		// The domains of existing inboxes are kept working: they are verified
+		// for the first project using them and claimed by the others
+		`INSERT INTO domains (project_id, name, verification_token, verified_at)
+			SELECT project_id, name, md5(random()::text), CURRENT_TIMESTAMP
+			FROM (
+				SELECT DISTINCT ON (name) project_id, name
+				FROM (SELECT project_id, lower(split_part(email, '@', 2)) AS name FROM inboxes) i
+				WHERE name <> '' AND strpos(name, '*') = 0
+				ORDER BY name, project_id
+			) d
+			ON CONFLICT DO NOTHING`,
+
+		`INSERT INTO domains (project_id, name, verification_token)
+			SELECT project_id, name, md5(random()::text)
+			FROM (
+				SELECT DISTINCT project_id, lower(split_part(email, '@', 2)) AS name FROM inboxes
+			) d
+			WHERE name <> '' AND strpos(name, '*') = 0
+			ON CONFLICT DO NOTHING`,
+	}
+
+	// Start a transaction
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Ensure proper rollback handling
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// We can only log this error since we can't return it
+			fmt.Printf("failed to rollback transaction: %v\n", err)
+		}
+	}()
+
+	// Execute the schema
+	for _, query := range schema {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("Failed to execute schema: %w", err)
+		}
+	}
+
+	if err := checkInboxDomains(tx, log); err != nil {
+		return err
+	}
+
+	if err := hashPlaintextPasswords(tx); err != nil {
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// hashPlaintextPasswords replaces the passwords stored in plain text by
+// earlier versions with their bcrypt hash
+func hashPlaintextPasswords(tx *sqlx.Tx) error {
+	var users []struct {
+		ID       int    `db:"id"`
+		Password string `db:"password"`
+	}
+	if err := tx.Select(&users,
+		`SELECT id, password FROM users WHERE password <> '' AND password NOT LIKE '$2%'`); err != nil {
+		return fmt.Errorf("Failed to fetch passwords: %w", err)
+	}
+
+	for _, user := range users {
+		hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("Failed to hash password of user %d: %w", user.ID, err)
+		}
+		if _, err := tx.Exec(`UPDATE users SET password = $1 WHERE id = $2`, string(hash), user.ID); err != nil {
+			return fmt.Errorf("Failed to update password of user %d: %w", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// checkInboxDomains makes sure every project owning inboxes on a domain has
+// claimed it, and logs the projects whose inboxes stop receiving mail because
+// another project verified their domain
+func checkInboxDomains(tx *sqlx.Tx, log *log.Logger) error {
+	var missing []struct {
+		ProjectID int    `db:"project_id"`
+		Name      string `db:"name"`
+	}
+	if err := tx.Select(&missing, `
+		SELECT DISTINCT i.project_id, i.name
+		FROM (SELECT project_id, lower(split_part(email, '@', 2)) AS name FROM inboxes) i
+		WHERE i.name <> '' AND strpos(i.name, '*') = 0
+			AND NOT EXISTS (SELECT 1 FROM domains d WHERE d.project_id = i.project_id AND d.name = i.name)
+		ORDER BY i.name, i.project_id`); err != nil {
+		return fmt.Errorf("Failed to check inbox domains: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Failed to claim domain %s for project %d", missing[0].Name, missing[0].ProjectID)
+	}
+
+	var unverified []struct {
+		ProjectID  int    `db:"project_id"`
+		Name       string `db:"name"`
+		VerifiedBy int    `db:"verified_by"`
+	}
+	if err := tx.Select(&unverified, `
+		SELECT d.project_id, d.name, v.project_id AS verified_by
+		FROM domains d
+		JOIN domains v ON v.name = d.name AND v.verified_at IS NOT NULL
+		WHERE d.verified_at IS NULL
+		ORDER BY d.name, d.project_id`); err != nil {
+		return fmt.Errorf("Failed to check inbox domains: %w", err)
+	}
+	for _, domain := range unverified {
+		log.Printf("Inboxes of project %d on %s no longer receive mail, the domain is verified by project %d",
+			domain.ProjectID, domain.Name, domain.VerifiedBy)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"inbox451/internal/config"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func V0_3_0(db *sqlx.DB, config *config.Config, log *log.Logger) error {
+	log.Print("Running migration v0.3.0")
+	schema := []string{
+		// Full-text search over the subject, addresses and text body. The body
+		// is truncated as a tsvector is limited to 1MB.
+		`ALTER TABLE messages
+			ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+				setweight(to_tsvector('english'::regconfig, COALESCE(subject, '')), 'A') ||
+				setweight(to_tsvector('english'::regconfig, COALESCE(sender, '') || ' ' || COALESCE(receiver, '')), 'B') ||
+				setweight(to_tsvector('english'::regconfig, left(COALESCE(body, ''), 262144)), 'C')
+			) STORED`,
+
+		`CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at)`,
+
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id SERIAL PRIMARY KEY,
+			project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			inbox_id INTEGER REFERENCES inboxes(id) ON DELETE CASCADE,
+			url TEXT NOT NULL,
+			secret VARCHAR(255) NOT NULL,
+			event_types TEXT[] NOT NULL DEFAULT '{message.created}',
+			is_active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_webhooks_project_id ON webhooks(project_id)`,
+
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id SERIAL PRIMARY KEY,
+			webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event_type VARCHAR(50) NOT NULL,
+			message_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			response_status INTEGER,
+			last_error TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, id)`,
+
+		// Due deliveries are claimed by the dispatcher ordered by their
+		// next attempt
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at)
+			WHERE status = 'pending'`,
+
+		// A project has at most one policy of its own and one per inbox
+		`CREATE TABLE IF NOT EXISTS retention_policies (
+			id SERIAL PRIMARY KEY,
+			project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			inbox_id INTEGER REFERENCES inboxes(id) ON DELETE CASCADE,
+			max_age_hours INTEGER CHECK (max_age_hours > 0),
+			max_messages INTEGER CHECK (max_messages > 0),
+			max_size_bytes BIGINT CHECK (max_size_bytes > 0),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_project ON retention_policies(project_id)
+			WHERE inbox_id IS NULL`,
+
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_inbox ON retention_policies(inbox_id)
+			WHERE inbox_id IS NOT NULL`,
+
+		// Quotas bound what an inbox accepts over SMTP, null means unlimited
+		`ALTER TABLE inboxes
+			ADD COLUMN IF NOT EXISTS max_messages_per_hour INTEGER CHECK (max_messages_per_hour > 0),
+			ADD COLUMN IF NOT EXISTS max_total_bytes BIGINT CHECK (max_total_bytes > 0),
+			ADD COLUMN IF NOT EXISTS max_message_bytes INTEGER CHECK (max_message_bytes > 0)`,
+
+		// Counting the messages received in the last hour
+		`CREATE INDEX IF NOT EXISTS idx_messages_inbox_created_at ON messages(inbox_id, created_at)`,
+
+		// Messages sent from an inbox wait here until the relay accepts
+		// them, message_id is the copy filed in the Sent folder
+		`CREATE TABLE IF NOT EXISTS outbound_messages (
+			id SERIAL PRIMARY KEY,
+			inbox_id INTEGER NOT NULL REFERENCES inboxes(id) ON DELETE CASCADE,
+			message_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+			sender VARCHAR(255) NOT NULL,
+			recipients TEXT[] NOT NULL,
+			raw BYTEA NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'queued',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			last_error TEXT,
+			sent_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_outbound_messages_inbox_id ON outbound_messages(inbox_id, id)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_outbound_messages_message_id ON outbound_messages(message_id)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_outbound_messages_queued ON outbound_messages(next_attempt_at)
+			WHERE status = 'queued'`,
+	}
+
+	// Start a transaction
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Ensure proper rollback handling
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// We can only log this error since we can't return it
+			fmt.Printf("failed to rollback transaction: %v\n", err)
+		}
+	}()
+
+	// Execute the schema
+	for _, query := range schema {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("Failed to execute schema: %w", err)
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
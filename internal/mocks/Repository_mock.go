@@ -7,6 +7,9 @@ import (
 	models "inbox451/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
+	null "github.com/volatiletech/null/v9"
+
+	time "time"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -22,252 +25,317 @@ func (_m *Repository) EXPECT() *Repository_Expecter {
 	return &Repository_Expecter{mock: &_m.Mock}
 }
 
-// CreateInbox provides a mock function with given fields: ctx, inbox
-func (_m *Repository) CreateInbox(ctx context.Context, inbox *models.Inbox) error {
-	ret := _m.Called(ctx, inbox)
+// ClaimOutboundMessages provides a mock function with given fields: ctx, limit, lease
+func (_m *Repository) ClaimOutboundMessages(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboundMessage, error) {
+	ret := _m.Called(ctx, limit, lease)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateInbox")
+		panic("no return value specified for ClaimOutboundMessages")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Inbox) error); ok {
-		r0 = rf(ctx, inbox)
+	var r0 []*models.OutboundMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]*models.OutboundMessage, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []*models.OutboundMessage); ok {
+		r0 = rf(ctx, limit, lease)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.OutboundMessage)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Repository_CreateInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateInbox'
-type Repository_CreateInbox_Call struct {
+// Repository_ClaimOutboundMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimOutboundMessages'
+type Repository_ClaimOutboundMessages_Call struct {
 	*mock.Call
 }
 
-// CreateInbox is a helper method to define mock.On call
+// ClaimOutboundMessages is a helper method to define mock.On call
 //   - ctx context.Context
-//   - inbox *models.Inbox
-func (_e *Repository_Expecter) CreateInbox(ctx interface{}, inbox interface{}) *Repository_CreateInbox_Call {
-	return &Repository_CreateInbox_Call{Call: _e.mock.On("CreateInbox", ctx, inbox)}
+//   - limit int
+//   - lease time.Duration
+func (_e *Repository_Expecter) ClaimOutboundMessages(ctx interface{}, limit interface{}, lease interface{}) *Repository_ClaimOutboundMessages_Call {
+	return &Repository_ClaimOutboundMessages_Call{Call: _e.mock.On("ClaimOutboundMessages", ctx, limit, lease)}
 }
 
-func (_c *Repository_CreateInbox_Call) Run(run func(ctx context.Context, inbox *models.Inbox)) *Repository_CreateInbox_Call {
+func (_c *Repository_ClaimOutboundMessages_Call) Run(run func(ctx context.Context, limit int, lease time.Duration)) *Repository_ClaimOutboundMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Inbox))
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Duration))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateInbox_Call) Return(_a0 error) *Repository_CreateInbox_Call {
-	_c.Call.Return(_a0)
+func (_c *Repository_ClaimOutboundMessages_Call) Return(_a0 []*models.OutboundMessage, _a1 error) *Repository_ClaimOutboundMessages_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_CreateInbox_Call) RunAndReturn(run func(context.Context, *models.Inbox) error) *Repository_CreateInbox_Call {
+func (_c *Repository_ClaimOutboundMessages_Call) RunAndReturn(run func(context.Context, int, time.Duration) ([]*models.OutboundMessage, error)) *Repository_ClaimOutboundMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateMessage provides a mock function with given fields: ctx, message
-func (_m *Repository) CreateMessage(ctx context.Context, message *models.Message) error {
-	ret := _m.Called(ctx, message)
+// ClaimWebhookDeliveries provides a mock function with given fields: ctx, limit, lease
+func (_m *Repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	ret := _m.Called(ctx, limit, lease)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateMessage")
+		panic("no return value specified for ClaimWebhookDeliveries")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Message) error); ok {
-		r0 = rf(ctx, message)
+	var r0 []*models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]*models.WebhookDelivery, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []*models.WebhookDelivery); ok {
+		r0 = rf(ctx, limit, lease)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.WebhookDelivery)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Repository_CreateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMessage'
-type Repository_CreateMessage_Call struct {
+// Repository_ClaimWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimWebhookDeliveries'
+type Repository_ClaimWebhookDeliveries_Call struct {
 	*mock.Call
 }
 
-// CreateMessage is a helper method to define mock.On call
+// ClaimWebhookDeliveries is a helper method to define mock.On call
 //   - ctx context.Context
-//   - message *models.Message
-func (_e *Repository_Expecter) CreateMessage(ctx interface{}, message interface{}) *Repository_CreateMessage_Call {
-	return &Repository_CreateMessage_Call{Call: _e.mock.On("CreateMessage", ctx, message)}
+//   - limit int
+//   - lease time.Duration
+func (_e *Repository_Expecter) ClaimWebhookDeliveries(ctx interface{}, limit interface{}, lease interface{}) *Repository_ClaimWebhookDeliveries_Call {
+	return &Repository_ClaimWebhookDeliveries_Call{Call: _e.mock.On("ClaimWebhookDeliveries", ctx, limit, lease)}
 }
 
-func (_c *Repository_CreateMessage_Call) Run(run func(ctx context.Context, message *models.Message)) *Repository_CreateMessage_Call {
+func (_c *Repository_ClaimWebhookDeliveries_Call) Run(run func(ctx context.Context, limit int, lease time.Duration)) *Repository_ClaimWebhookDeliveries_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Message))
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Duration))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateMessage_Call) Return(_a0 error) *Repository_CreateMessage_Call {
-	_c.Call.Return(_a0)
+func (_c *Repository_ClaimWebhookDeliveries_Call) Return(_a0 []*models.WebhookDelivery, _a1 error) *Repository_ClaimWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_CreateMessage_Call) RunAndReturn(run func(context.Context, *models.Message) error) *Repository_CreateMessage_Call {
+func (_c *Repository_ClaimWebhookDeliveries_Call) RunAndReturn(run func(context.Context, int, time.Duration) ([]*models.WebhookDelivery, error)) *Repository_ClaimWebhookDeliveries_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateProject provides a mock function with given fields: ctx, project
-func (_m *Repository) CreateProject(ctx context.Context, project *models.Project) error {
-	ret := _m.Called(ctx, project)
+// CopyMessages provides a mock function with given fields: ctx, ids, inboxID, folderID
+func (_m *Repository) CopyMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error) {
+	ret := _m.Called(ctx, ids, inboxID, folderID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateProject")
+		panic("no return value specified for CopyMessages")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Project) error); ok {
-		r0 = rf(ctx, project)
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int, null.Int) ([]int, error)); ok {
+		return rf(ctx, ids, inboxID, folderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int, null.Int) []int); ok {
+		r0 = rf(ctx, ids, inboxID, folderID)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, []int, int, null.Int) error); ok {
+		r1 = rf(ctx, ids, inboxID, folderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Repository_CreateProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateProject'
-type Repository_CreateProject_Call struct {
+// Repository_CopyMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CopyMessages'
+type Repository_CopyMessages_Call struct {
 	*mock.Call
 }
 
-// CreateProject is a helper method to define mock.On call
+// CopyMessages is a helper method to define mock.On call
 //   - ctx context.Context
-//   - project *models.Project
-func (_e *Repository_Expecter) CreateProject(ctx interface{}, project interface{}) *Repository_CreateProject_Call {
-	return &Repository_CreateProject_Call{Call: _e.mock.On("CreateProject", ctx, project)}
+//   - ids []int
+//   - inboxID int
+//   - folderID null.Int
+func (_e *Repository_Expecter) CopyMessages(ctx interface{}, ids interface{}, inboxID interface{}, folderID interface{}) *Repository_CopyMessages_Call {
+	return &Repository_CopyMessages_Call{Call: _e.mock.On("CopyMessages", ctx, ids, inboxID, folderID)}
 }
 
-func (_c *Repository_CreateProject_Call) Run(run func(ctx context.Context, project *models.Project)) *Repository_CreateProject_Call {
+func (_c *Repository_CopyMessages_Call) Run(run func(ctx context.Context, ids []int, inboxID int, folderID null.Int)) *Repository_CopyMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Project))
+		run(args[0].(context.Context), args[1].([]int), args[2].(int), args[3].(null.Int))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateProject_Call) Return(_a0 error) *Repository_CreateProject_Call {
-	_c.Call.Return(_a0)
+func (_c *Repository_CopyMessages_Call) Return(_a0 []int, _a1 error) *Repository_CopyMessages_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_CreateProject_Call) RunAndReturn(run func(context.Context, *models.Project) error) *Repository_CreateProject_Call {
+func (_c *Repository_CopyMessages_Call) RunAndReturn(run func(context.Context, []int, int, null.Int) ([]int, error)) *Repository_CopyMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateRule provides a mock function with given fields: ctx, rule
-func (_m *Repository) CreateRule(ctx context.Context, rule *models.ForwardRule) error {
-	ret := _m.Called(ctx, rule)
+// CountMessagesBelow provides a mock function with given fields: ctx, inboxID, folderID, ids
+func (_m *Repository) CountMessagesBelow(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	ret := _m.Called(ctx, inboxID, folderID, ids)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateRule")
+		panic("no return value specified for CountMessagesBelow")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.ForwardRule) error); ok {
-		r0 = rf(ctx, rule)
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, []int) ([]int, error)); ok {
+		return rf(ctx, inboxID, folderID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, []int) []int); ok {
+		r0 = rf(ctx, inboxID, folderID, ids)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int, []int) error); ok {
+		r1 = rf(ctx, inboxID, folderID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Repository_CreateRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRule'
-type Repository_CreateRule_Call struct {
+// Repository_CountMessagesBelow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountMessagesBelow'
+type Repository_CountMessagesBelow_Call struct {
 	*mock.Call
 }
 
-// CreateRule is a helper method to define mock.On call
+// CountMessagesBelow is a helper method to define mock.On call
 //   - ctx context.Context
-//   - rule *models.ForwardRule
-func (_e *Repository_Expecter) CreateRule(ctx interface{}, rule interface{}) *Repository_CreateRule_Call {
-	return &Repository_CreateRule_Call{Call: _e.mock.On("CreateRule", ctx, rule)}
+//   - inboxID int
+//   - folderID null.Int
+//   - ids []int
+func (_e *Repository_Expecter) CountMessagesBelow(ctx interface{}, inboxID interface{}, folderID interface{}, ids interface{}) *Repository_CountMessagesBelow_Call {
+	return &Repository_CountMessagesBelow_Call{Call: _e.mock.On("CountMessagesBelow", ctx, inboxID, folderID, ids)}
 }
 
-func (_c *Repository_CreateRule_Call) Run(run func(ctx context.Context, rule *models.ForwardRule)) *Repository_CreateRule_Call {
+func (_c *Repository_CountMessagesBelow_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int, ids []int)) *Repository_CountMessagesBelow_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.ForwardRule))
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int), args[3].([]int))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateRule_Call) Return(_a0 error) *Repository_CreateRule_Call {
-	_c.Call.Return(_a0)
+func (_c *Repository_CountMessagesBelow_Call) Return(_a0 []int, _a1 error) *Repository_CountMessagesBelow_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_CreateRule_Call) RunAndReturn(run func(context.Context, *models.ForwardRule) error) *Repository_CreateRule_Call {
+func (_c *Repository_CountMessagesBelow_Call) RunAndReturn(run func(context.Context, int, null.Int, []int) ([]int, error)) *Repository_CountMessagesBelow_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateToken provides a mock function with given fields: ctx, token
-func (_m *Repository) CreateToken(ctx context.Context, token *models.Token) error {
-	ret := _m.Called(ctx, token)
+// CountMessagesByFolder provides a mock function with given fields: ctx, inboxID, folderID
+func (_m *Repository) CountMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int) (int, error) {
+	ret := _m.Called(ctx, inboxID, folderID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateToken")
+		panic("no return value specified for CountMessagesByFolder")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Token) error); ok {
-		r0 = rf(ctx, token)
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) (int, error)); ok {
+		return rf(ctx, inboxID, folderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) int); ok {
+		r0 = rf(ctx, inboxID, folderID)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int) error); ok {
+		r1 = rf(ctx, inboxID, folderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Repository_CreateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateToken'
-type Repository_CreateToken_Call struct {
+// Repository_CountMessagesByFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountMessagesByFolder'
+type Repository_CountMessagesByFolder_Call struct {
 	*mock.Call
 }
 
-// CreateToken is a helper method to define mock.On call
+// CountMessagesByFolder is a helper method to define mock.On call
 //   - ctx context.Context
-//   - token *models.Token
-func (_e *Repository_Expecter) CreateToken(ctx interface{}, token interface{}) *Repository_CreateToken_Call {
-	return &Repository_CreateToken_Call{Call: _e.mock.On("CreateToken", ctx, token)}
+//   - inboxID int
+//   - folderID null.Int
+func (_e *Repository_Expecter) CountMessagesByFolder(ctx interface{}, inboxID interface{}, folderID interface{}) *Repository_CountMessagesByFolder_Call {
+	return &Repository_CountMessagesByFolder_Call{Call: _e.mock.On("CountMessagesByFolder", ctx, inboxID, folderID)}
 }
 
-func (_c *Repository_CreateToken_Call) Run(run func(ctx context.Context, token *models.Token)) *Repository_CreateToken_Call {
+func (_c *Repository_CountMessagesByFolder_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int)) *Repository_CountMessagesByFolder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Token))
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateToken_Call) Return(_a0 error) *Repository_CreateToken_Call {
-	_c.Call.Return(_a0)
+func (_c *Repository_CountMessagesByFolder_Call) Return(_a0 int, _a1 error) *Repository_CountMessagesByFolder_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_CreateToken_Call) RunAndReturn(run func(context.Context, *models.Token) error) *Repository_CreateToken_Call {
+func (_c *Repository_CountMessagesByFolder_Call) RunAndReturn(run func(context.Context, int, null.Int) (int, error)) *Repository_CountMessagesByFolder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// CreateUser provides a mock function with given fields: ctx, user
-func (_m *Repository) CreateUser(ctx context.Context, user *models.User) error {
-	ret := _m.Called(ctx, user)
+// CreateDomain provides a mock function with given fields: ctx, domain
+func (_m *Repository) CreateDomain(ctx context.Context, domain *models.Domain) error {
+	ret := _m.Called(ctx, domain)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateUser")
+		panic("no return value specified for CreateDomain")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Domain) error); ok {
+		r0 = rf(ctx, domain)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -275,46 +343,46 @@ func (_m *Repository) CreateUser(ctx context.Context, user *models.User) error {
 	return r0
 }
 
-// Repository_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
-type Repository_CreateUser_Call struct {
+// Repository_CreateDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDomain'
+type Repository_CreateDomain_Call struct {
 	*mock.Call
 }
 
-// CreateUser is a helper method to define mock.On call
+// CreateDomain is a helper method to define mock.On call
 //   - ctx context.Context
-//   - user *models.User
-func (_e *Repository_Expecter) CreateUser(ctx interface{}, user interface{}) *Repository_CreateUser_Call {
-	return &Repository_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, user)}
+//   - domain *models.Domain
+func (_e *Repository_Expecter) CreateDomain(ctx interface{}, domain interface{}) *Repository_CreateDomain_Call {
+	return &Repository_CreateDomain_Call{Call: _e.mock.On("CreateDomain", ctx, domain)}
 }
 
-func (_c *Repository_CreateUser_Call) Run(run func(ctx context.Context, user *models.User)) *Repository_CreateUser_Call {
+func (_c *Repository_CreateDomain_Call) Run(run func(ctx context.Context, domain *models.Domain)) *Repository_CreateDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.User))
+		run(args[0].(context.Context), args[1].(*models.Domain))
 	})
 	return _c
 }
 
-func (_c *Repository_CreateUser_Call) Return(_a0 error) *Repository_CreateUser_Call {
+func (_c *Repository_CreateDomain_Call) Return(_a0 error) *Repository_CreateDomain_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_CreateUser_Call) RunAndReturn(run func(context.Context, *models.User) error) *Repository_CreateUser_Call {
+func (_c *Repository_CreateDomain_Call) RunAndReturn(run func(context.Context, *models.Domain) error) *Repository_CreateDomain_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteInbox provides a mock function with given fields: ctx, id
-func (_m *Repository) DeleteInbox(ctx context.Context, id int) error {
-	ret := _m.Called(ctx, id)
+// CreateFolder provides a mock function with given fields: ctx, folder
+func (_m *Repository) CreateFolder(ctx context.Context, folder *models.Folder) error {
+	ret := _m.Called(ctx, folder)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteInbox")
+		panic("no return value specified for CreateFolder")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Folder) error); ok {
+		r0 = rf(ctx, folder)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -322,46 +390,46 @@ func (_m *Repository) DeleteInbox(ctx context.Context, id int) error {
 	return r0
 }
 
-// Repository_DeleteInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteInbox'
-type Repository_DeleteInbox_Call struct {
+// Repository_CreateFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateFolder'
+type Repository_CreateFolder_Call struct {
 	*mock.Call
 }
 
-// DeleteInbox is a helper method to define mock.On call
+// CreateFolder is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) DeleteInbox(ctx interface{}, id interface{}) *Repository_DeleteInbox_Call {
-	return &Repository_DeleteInbox_Call{Call: _e.mock.On("DeleteInbox", ctx, id)}
+//   - folder *models.Folder
+func (_e *Repository_Expecter) CreateFolder(ctx interface{}, folder interface{}) *Repository_CreateFolder_Call {
+	return &Repository_CreateFolder_Call{Call: _e.mock.On("CreateFolder", ctx, folder)}
 }
 
-func (_c *Repository_DeleteInbox_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteInbox_Call {
+func (_c *Repository_CreateFolder_Call) Run(run func(ctx context.Context, folder *models.Folder)) *Repository_CreateFolder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Folder))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteInbox_Call) Return(_a0 error) *Repository_DeleteInbox_Call {
+func (_c *Repository_CreateFolder_Call) Return(_a0 error) *Repository_CreateFolder_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteInbox_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteInbox_Call {
+func (_c *Repository_CreateFolder_Call) RunAndReturn(run func(context.Context, *models.Folder) error) *Repository_CreateFolder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteMessage provides a mock function with given fields: ctx, messageID
-func (_m *Repository) DeleteMessage(ctx context.Context, messageID int) error {
-	ret := _m.Called(ctx, messageID)
+// CreateInbox provides a mock function with given fields: ctx, inbox
+func (_m *Repository) CreateInbox(ctx context.Context, inbox *models.Inbox) error {
+	ret := _m.Called(ctx, inbox)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteMessage")
+		panic("no return value specified for CreateInbox")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, messageID)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Inbox) error); ok {
+		r0 = rf(ctx, inbox)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -369,46 +437,46 @@ func (_m *Repository) DeleteMessage(ctx context.Context, messageID int) error {
 	return r0
 }
 
-// Repository_DeleteMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessage'
-type Repository_DeleteMessage_Call struct {
+// Repository_CreateInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateInbox'
+type Repository_CreateInbox_Call struct {
 	*mock.Call
 }
 
-// DeleteMessage is a helper method to define mock.On call
+// CreateInbox is a helper method to define mock.On call
 //   - ctx context.Context
-//   - messageID int
-func (_e *Repository_Expecter) DeleteMessage(ctx interface{}, messageID interface{}) *Repository_DeleteMessage_Call {
-	return &Repository_DeleteMessage_Call{Call: _e.mock.On("DeleteMessage", ctx, messageID)}
+//   - inbox *models.Inbox
+func (_e *Repository_Expecter) CreateInbox(ctx interface{}, inbox interface{}) *Repository_CreateInbox_Call {
+	return &Repository_CreateInbox_Call{Call: _e.mock.On("CreateInbox", ctx, inbox)}
 }
 
-func (_c *Repository_DeleteMessage_Call) Run(run func(ctx context.Context, messageID int)) *Repository_DeleteMessage_Call {
+func (_c *Repository_CreateInbox_Call) Run(run func(ctx context.Context, inbox *models.Inbox)) *Repository_CreateInbox_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Inbox))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteMessage_Call) Return(_a0 error) *Repository_DeleteMessage_Call {
+func (_c *Repository_CreateInbox_Call) Return(_a0 error) *Repository_CreateInbox_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteMessage_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteMessage_Call {
+func (_c *Repository_CreateInbox_Call) RunAndReturn(run func(context.Context, *models.Inbox) error) *Repository_CreateInbox_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteProject provides a mock function with given fields: ctx, id
-func (_m *Repository) DeleteProject(ctx context.Context, id int) error {
-	ret := _m.Called(ctx, id)
+// CreateMessage provides a mock function with given fields: ctx, message
+func (_m *Repository) CreateMessage(ctx context.Context, message *models.Message) error {
+	ret := _m.Called(ctx, message)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteProject")
+		panic("no return value specified for CreateMessage")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Message) error); ok {
+		r0 = rf(ctx, message)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -416,46 +484,46 @@ func (_m *Repository) DeleteProject(ctx context.Context, id int) error {
 	return r0
 }
 
-// Repository_DeleteProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProject'
-type Repository_DeleteProject_Call struct {
+// Repository_CreateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMessage'
+type Repository_CreateMessage_Call struct {
 	*mock.Call
 }
 
-// DeleteProject is a helper method to define mock.On call
+// CreateMessage is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) DeleteProject(ctx interface{}, id interface{}) *Repository_DeleteProject_Call {
-	return &Repository_DeleteProject_Call{Call: _e.mock.On("DeleteProject", ctx, id)}
+//   - message *models.Message
+func (_e *Repository_Expecter) CreateMessage(ctx interface{}, message interface{}) *Repository_CreateMessage_Call {
+	return &Repository_CreateMessage_Call{Call: _e.mock.On("CreateMessage", ctx, message)}
 }
 
-func (_c *Repository_DeleteProject_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteProject_Call {
+func (_c *Repository_CreateMessage_Call) Run(run func(ctx context.Context, message *models.Message)) *Repository_CreateMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Message))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteProject_Call) Return(_a0 error) *Repository_DeleteProject_Call {
+func (_c *Repository_CreateMessage_Call) Return(_a0 error) *Repository_CreateMessage_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteProject_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteProject_Call {
+func (_c *Repository_CreateMessage_Call) RunAndReturn(run func(context.Context, *models.Message) error) *Repository_CreateMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteRule provides a mock function with given fields: ctx, id
-func (_m *Repository) DeleteRule(ctx context.Context, id int) error {
-	ret := _m.Called(ctx, id)
+// CreateMessages provides a mock function with given fields: ctx, messages
+func (_m *Repository) CreateMessages(ctx context.Context, messages []*models.Message) error {
+	ret := _m.Called(ctx, messages)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteRule")
+		panic("no return value specified for CreateMessages")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, []*models.Message) error); ok {
+		r0 = rf(ctx, messages)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -463,46 +531,46 @@ func (_m *Repository) DeleteRule(ctx context.Context, id int) error {
 	return r0
 }
 
-// Repository_DeleteRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRule'
-type Repository_DeleteRule_Call struct {
+// Repository_CreateMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMessages'
+type Repository_CreateMessages_Call struct {
 	*mock.Call
 }
 
-// DeleteRule is a helper method to define mock.On call
+// CreateMessages is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) DeleteRule(ctx interface{}, id interface{}) *Repository_DeleteRule_Call {
-	return &Repository_DeleteRule_Call{Call: _e.mock.On("DeleteRule", ctx, id)}
+//   - messages []*models.Message
+func (_e *Repository_Expecter) CreateMessages(ctx interface{}, messages interface{}) *Repository_CreateMessages_Call {
+	return &Repository_CreateMessages_Call{Call: _e.mock.On("CreateMessages", ctx, messages)}
 }
 
-func (_c *Repository_DeleteRule_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteRule_Call {
+func (_c *Repository_CreateMessages_Call) Run(run func(ctx context.Context, messages []*models.Message)) *Repository_CreateMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].([]*models.Message))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteRule_Call) Return(_a0 error) *Repository_DeleteRule_Call {
+func (_c *Repository_CreateMessages_Call) Return(_a0 error) *Repository_CreateMessages_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteRule_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteRule_Call {
+func (_c *Repository_CreateMessages_Call) RunAndReturn(run func(context.Context, []*models.Message) error) *Repository_CreateMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteToken provides a mock function with given fields: ctx, tokenID
-func (_m *Repository) DeleteToken(ctx context.Context, tokenID int) error {
-	ret := _m.Called(ctx, tokenID)
+// CreateProject provides a mock function with given fields: ctx, project
+func (_m *Repository) CreateProject(ctx context.Context, project *models.Project) error {
+	ret := _m.Called(ctx, project)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteToken")
+		panic("no return value specified for CreateProject")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, tokenID)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Project) error); ok {
+		r0 = rf(ctx, project)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -510,46 +578,46 @@ func (_m *Repository) DeleteToken(ctx context.Context, tokenID int) error {
 	return r0
 }
 
-// Repository_DeleteToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteToken'
-type Repository_DeleteToken_Call struct {
+// Repository_CreateProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateProject'
+type Repository_CreateProject_Call struct {
 	*mock.Call
 }
 
-// DeleteToken is a helper method to define mock.On call
+// CreateProject is a helper method to define mock.On call
 //   - ctx context.Context
-//   - tokenID int
-func (_e *Repository_Expecter) DeleteToken(ctx interface{}, tokenID interface{}) *Repository_DeleteToken_Call {
-	return &Repository_DeleteToken_Call{Call: _e.mock.On("DeleteToken", ctx, tokenID)}
+//   - project *models.Project
+func (_e *Repository_Expecter) CreateProject(ctx interface{}, project interface{}) *Repository_CreateProject_Call {
+	return &Repository_CreateProject_Call{Call: _e.mock.On("CreateProject", ctx, project)}
 }
 
-func (_c *Repository_DeleteToken_Call) Run(run func(ctx context.Context, tokenID int)) *Repository_DeleteToken_Call {
+func (_c *Repository_CreateProject_Call) Run(run func(ctx context.Context, project *models.Project)) *Repository_CreateProject_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Project))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteToken_Call) Return(_a0 error) *Repository_DeleteToken_Call {
+func (_c *Repository_CreateProject_Call) Return(_a0 error) *Repository_CreateProject_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteToken_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteToken_Call {
+func (_c *Repository_CreateProject_Call) RunAndReturn(run func(context.Context, *models.Project) error) *Repository_CreateProject_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteUser provides a mock function with given fields: ctx, userId
-func (_m *Repository) DeleteUser(ctx context.Context, userId int) error {
-	ret := _m.Called(ctx, userId)
+// CreateRule provides a mock function with given fields: ctx, rule
+func (_m *Repository) CreateRule(ctx context.Context, rule *models.ForwardRule) error {
+	ret := _m.Called(ctx, rule)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteUser")
+		panic("no return value specified for CreateRule")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ForwardRule) error); ok {
+		r0 = rf(ctx, rule)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -557,472 +625,385 @@ func (_m *Repository) DeleteUser(ctx context.Context, userId int) error {
 	return r0
 }
 
-// Repository_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
-type Repository_DeleteUser_Call struct {
+// Repository_CreateRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRule'
+type Repository_CreateRule_Call struct {
 	*mock.Call
 }
 
-// DeleteUser is a helper method to define mock.On call
+// CreateRule is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userId int
-func (_e *Repository_Expecter) DeleteUser(ctx interface{}, userId interface{}) *Repository_DeleteUser_Call {
-	return &Repository_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, userId)}
+//   - rule *models.ForwardRule
+func (_e *Repository_Expecter) CreateRule(ctx interface{}, rule interface{}) *Repository_CreateRule_Call {
+	return &Repository_CreateRule_Call{Call: _e.mock.On("CreateRule", ctx, rule)}
 }
 
-func (_c *Repository_DeleteUser_Call) Run(run func(ctx context.Context, userId int)) *Repository_DeleteUser_Call {
+func (_c *Repository_CreateRule_Call) Run(run func(ctx context.Context, rule *models.ForwardRule)) *Repository_CreateRule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.ForwardRule))
 	})
 	return _c
 }
 
-func (_c *Repository_DeleteUser_Call) Return(_a0 error) *Repository_DeleteUser_Call {
+func (_c *Repository_CreateRule_Call) Return(_a0 error) *Repository_CreateRule_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_DeleteUser_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteUser_Call {
+func (_c *Repository_CreateRule_Call) RunAndReturn(run func(context.Context, *models.ForwardRule) error) *Repository_CreateRule_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetInbox provides a mock function with given fields: ctx, id
-func (_m *Repository) GetInbox(ctx context.Context, id int) (*models.Inbox, error) {
-	ret := _m.Called(ctx, id)
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *Repository) CreateSession(ctx context.Context, session *models.Session) error {
+	ret := _m.Called(ctx, session)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetInbox")
-	}
-
-	var r0 *models.Inbox
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Inbox, error)); ok {
-		return rf(ctx, id)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Inbox); ok {
-		r0 = rf(ctx, id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Inbox)
-		}
+		panic("no return value specified for CreateSession")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Session) error); ok {
+		r0 = rf(ctx, session)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInbox'
-type Repository_GetInbox_Call struct {
+// Repository_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
+type Repository_CreateSession_Call struct {
 	*mock.Call
 }
 
-// GetInbox is a helper method to define mock.On call
+// CreateSession is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) GetInbox(ctx interface{}, id interface{}) *Repository_GetInbox_Call {
-	return &Repository_GetInbox_Call{Call: _e.mock.On("GetInbox", ctx, id)}
+//   - session *models.Session
+func (_e *Repository_Expecter) CreateSession(ctx interface{}, session interface{}) *Repository_CreateSession_Call {
+	return &Repository_CreateSession_Call{Call: _e.mock.On("CreateSession", ctx, session)}
 }
 
-func (_c *Repository_GetInbox_Call) Run(run func(ctx context.Context, id int)) *Repository_GetInbox_Call {
+func (_c *Repository_CreateSession_Call) Run(run func(ctx context.Context, session *models.Session)) *Repository_CreateSession_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Session))
 	})
 	return _c
 }
 
-func (_c *Repository_GetInbox_Call) Return(_a0 *models.Inbox, _a1 error) *Repository_GetInbox_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_CreateSession_Call) Return(_a0 error) *Repository_CreateSession_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetInbox_Call) RunAndReturn(run func(context.Context, int) (*models.Inbox, error)) *Repository_GetInbox_Call {
+func (_c *Repository_CreateSession_Call) RunAndReturn(run func(context.Context, *models.Session) error) *Repository_CreateSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetInboxByEmail provides a mock function with given fields: ctx, email
-func (_m *Repository) GetInboxByEmail(ctx context.Context, email string) (*models.Inbox, error) {
-	ret := _m.Called(ctx, email)
+// CreateToken provides a mock function with given fields: ctx, token
+func (_m *Repository) CreateToken(ctx context.Context, token *models.Token) error {
+	ret := _m.Called(ctx, token)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetInboxByEmail")
-	}
-
-	var r0 *models.Inbox
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Inbox, error)); ok {
-		return rf(ctx, email)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Inbox); ok {
-		r0 = rf(ctx, email)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Inbox)
-		}
+		panic("no return value specified for CreateToken")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, email)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Token) error); ok {
+		r0 = rf(ctx, token)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetInboxByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInboxByEmail'
-type Repository_GetInboxByEmail_Call struct {
+// Repository_CreateToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateToken'
+type Repository_CreateToken_Call struct {
 	*mock.Call
 }
 
-// GetInboxByEmail is a helper method to define mock.On call
+// CreateToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - email string
-func (_e *Repository_Expecter) GetInboxByEmail(ctx interface{}, email interface{}) *Repository_GetInboxByEmail_Call {
-	return &Repository_GetInboxByEmail_Call{Call: _e.mock.On("GetInboxByEmail", ctx, email)}
+//   - token *models.Token
+func (_e *Repository_Expecter) CreateToken(ctx interface{}, token interface{}) *Repository_CreateToken_Call {
+	return &Repository_CreateToken_Call{Call: _e.mock.On("CreateToken", ctx, token)}
 }
 
-func (_c *Repository_GetInboxByEmail_Call) Run(run func(ctx context.Context, email string)) *Repository_GetInboxByEmail_Call {
+func (_c *Repository_CreateToken_Call) Run(run func(ctx context.Context, token *models.Token)) *Repository_CreateToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(*models.Token))
 	})
 	return _c
 }
 
-func (_c *Repository_GetInboxByEmail_Call) Return(_a0 *models.Inbox, _a1 error) *Repository_GetInboxByEmail_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_CreateToken_Call) Return(_a0 error) *Repository_CreateToken_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetInboxByEmail_Call) RunAndReturn(run func(context.Context, string) (*models.Inbox, error)) *Repository_GetInboxByEmail_Call {
+func (_c *Repository_CreateToken_Call) RunAndReturn(run func(context.Context, *models.Token) error) *Repository_CreateToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetMessage provides a mock function with given fields: ctx, id
-func (_m *Repository) GetMessage(ctx context.Context, id int) (*models.Message, error) {
-	ret := _m.Called(ctx, id)
+// CreateUser provides a mock function with given fields: ctx, user
+func (_m *Repository) CreateUser(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetMessage")
-	}
-
-	var r0 *models.Message
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Message, error)); ok {
-		return rf(ctx, id)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Message); ok {
-		r0 = rf(ctx, id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Message)
-		}
+		panic("no return value specified for CreateUser")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessage'
-type Repository_GetMessage_Call struct {
+// Repository_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
+type Repository_CreateUser_Call struct {
 	*mock.Call
 }
 
-// GetMessage is a helper method to define mock.On call
+// CreateUser is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) GetMessage(ctx interface{}, id interface{}) *Repository_GetMessage_Call {
-	return &Repository_GetMessage_Call{Call: _e.mock.On("GetMessage", ctx, id)}
+//   - user *models.User
+func (_e *Repository_Expecter) CreateUser(ctx interface{}, user interface{}) *Repository_CreateUser_Call {
+	return &Repository_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, user)}
 }
 
-func (_c *Repository_GetMessage_Call) Run(run func(ctx context.Context, id int)) *Repository_GetMessage_Call {
+func (_c *Repository_CreateUser_Call) Run(run func(ctx context.Context, user *models.User)) *Repository_CreateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.User))
 	})
 	return _c
 }
 
-func (_c *Repository_GetMessage_Call) Return(_a0 *models.Message, _a1 error) *Repository_GetMessage_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_CreateUser_Call) Return(_a0 error) *Repository_CreateUser_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetMessage_Call) RunAndReturn(run func(context.Context, int) (*models.Message, error)) *Repository_GetMessage_Call {
+func (_c *Repository_CreateUser_Call) RunAndReturn(run func(context.Context, *models.User) error) *Repository_CreateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetProject provides a mock function with given fields: ctx, id
-func (_m *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
-	ret := _m.Called(ctx, id)
+// CreateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *Repository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ret := _m.Called(ctx, webhook)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetProject")
-	}
-
-	var r0 *models.Project
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Project, error)); ok {
-		return rf(ctx, id)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Project); ok {
-		r0 = rf(ctx, id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Project)
-		}
+		panic("no return value specified for CreateWebhook")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProject'
-type Repository_GetProject_Call struct {
+// Repository_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type Repository_CreateWebhook_Call struct {
 	*mock.Call
 }
 
-// GetProject is a helper method to define mock.On call
+// CreateWebhook is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) GetProject(ctx interface{}, id interface{}) *Repository_GetProject_Call {
-	return &Repository_GetProject_Call{Call: _e.mock.On("GetProject", ctx, id)}
+//   - webhook *models.Webhook
+func (_e *Repository_Expecter) CreateWebhook(ctx interface{}, webhook interface{}) *Repository_CreateWebhook_Call {
+	return &Repository_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", ctx, webhook)}
 }
 
-func (_c *Repository_GetProject_Call) Run(run func(ctx context.Context, id int)) *Repository_GetProject_Call {
+func (_c *Repository_CreateWebhook_Call) Run(run func(ctx context.Context, webhook *models.Webhook)) *Repository_CreateWebhook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.Webhook))
 	})
 	return _c
 }
 
-func (_c *Repository_GetProject_Call) Return(_a0 *models.Project, _a1 error) *Repository_GetProject_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_CreateWebhook_Call) Return(_a0 error) *Repository_CreateWebhook_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetProject_Call) RunAndReturn(run func(context.Context, int) (*models.Project, error)) *Repository_GetProject_Call {
+func (_c *Repository_CreateWebhook_Call) RunAndReturn(run func(context.Context, *models.Webhook) error) *Repository_CreateWebhook_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetRule provides a mock function with given fields: ctx, id
-func (_m *Repository) GetRule(ctx context.Context, id int) (*models.ForwardRule, error) {
-	ret := _m.Called(ctx, id)
+// CreateWebhookDelivery provides a mock function with given fields: ctx, delivery
+func (_m *Repository) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetRule")
+		panic("no return value specified for CreateWebhookDelivery")
 	}
 
-	var r0 *models.ForwardRule
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.ForwardRule, error)); ok {
-		return rf(ctx, id)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *models.ForwardRule); ok {
-		r0 = rf(ctx, id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.ForwardRule)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, id)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRule'
-type Repository_GetRule_Call struct {
+// Repository_CreateWebhookDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhookDelivery'
+type Repository_CreateWebhookDelivery_Call struct {
 	*mock.Call
 }
 
-// GetRule is a helper method to define mock.On call
+// CreateWebhookDelivery is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id int
-func (_e *Repository_Expecter) GetRule(ctx interface{}, id interface{}) *Repository_GetRule_Call {
-	return &Repository_GetRule_Call{Call: _e.mock.On("GetRule", ctx, id)}
+//   - delivery *models.WebhookDelivery
+func (_e *Repository_Expecter) CreateWebhookDelivery(ctx interface{}, delivery interface{}) *Repository_CreateWebhookDelivery_Call {
+	return &Repository_CreateWebhookDelivery_Call{Call: _e.mock.On("CreateWebhookDelivery", ctx, delivery)}
 }
 
-func (_c *Repository_GetRule_Call) Run(run func(ctx context.Context, id int)) *Repository_GetRule_Call {
+func (_c *Repository_CreateWebhookDelivery_Call) Run(run func(ctx context.Context, delivery *models.WebhookDelivery)) *Repository_CreateWebhookDelivery_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(*models.WebhookDelivery))
 	})
 	return _c
 }
 
-func (_c *Repository_GetRule_Call) Return(_a0 *models.ForwardRule, _a1 error) *Repository_GetRule_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_CreateWebhookDelivery_Call) Return(_a0 error) *Repository_CreateWebhookDelivery_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetRule_Call) RunAndReturn(run func(context.Context, int) (*models.ForwardRule, error)) *Repository_GetRule_Call {
+func (_c *Repository_CreateWebhookDelivery_Call) RunAndReturn(run func(context.Context, *models.WebhookDelivery) error) *Repository_CreateWebhookDelivery_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetTokenByUser provides a mock function with given fields: ctx, userID, tokenID
-func (_m *Repository) GetTokenByUser(ctx context.Context, userID int, tokenID int) (*models.Token, error) {
-	ret := _m.Called(ctx, userID, tokenID)
+// DeactivateSession provides a mock function with given fields: ctx, sessionID
+func (_m *Repository) DeactivateSession(ctx context.Context, sessionID string) error {
+	ret := _m.Called(ctx, sessionID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetTokenByUser")
-	}
-
-	var r0 *models.Token
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.Token, error)); ok {
-		return rf(ctx, userID, tokenID)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.Token); ok {
-		r0 = rf(ctx, userID, tokenID)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Token)
-		}
+		panic("no return value specified for DeactivateSession")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = rf(ctx, userID, tokenID)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, sessionID)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetTokenByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenByUser'
-type Repository_GetTokenByUser_Call struct {
+// Repository_DeactivateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeactivateSession'
+type Repository_DeactivateSession_Call struct {
 	*mock.Call
 }
 
-// GetTokenByUser is a helper method to define mock.On call
+// DeactivateSession is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userID int
-//   - tokenID int
-func (_e *Repository_Expecter) GetTokenByUser(ctx interface{}, userID interface{}, tokenID interface{}) *Repository_GetTokenByUser_Call {
-	return &Repository_GetTokenByUser_Call{Call: _e.mock.On("GetTokenByUser", ctx, userID, tokenID)}
+//   - sessionID string
+func (_e *Repository_Expecter) DeactivateSession(ctx interface{}, sessionID interface{}) *Repository_DeactivateSession_Call {
+	return &Repository_DeactivateSession_Call{Call: _e.mock.On("DeactivateSession", ctx, sessionID)}
 }
 
-func (_c *Repository_GetTokenByUser_Call) Run(run func(ctx context.Context, userID int, tokenID int)) *Repository_GetTokenByUser_Call {
+func (_c *Repository_DeactivateSession_Call) Run(run func(ctx context.Context, sessionID string)) *Repository_DeactivateSession_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Repository_GetTokenByUser_Call) Return(_a0 *models.Token, _a1 error) *Repository_GetTokenByUser_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_DeactivateSession_Call) Return(_a0 error) *Repository_DeactivateSession_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetTokenByUser_Call) RunAndReturn(run func(context.Context, int, int) (*models.Token, error)) *Repository_GetTokenByUser_Call {
+func (_c *Repository_DeactivateSession_Call) RunAndReturn(run func(context.Context, string) error) *Repository_DeactivateSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUser provides a mock function with given fields: ctx, id
-func (_m *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
+// DeleteDomain provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteDomain(ctx context.Context, id int) error {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUser")
+		panic("no return value specified for DeleteDomain")
 	}
 
-	var r0 *models.User
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.User, error)); ok {
-		return rf(ctx, id)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *models.User); ok {
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
 		r0 = rf(ctx, id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, id)
-	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// Repository_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
-type Repository_GetUser_Call struct {
+// Repository_DeleteDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDomain'
+type Repository_DeleteDomain_Call struct {
 	*mock.Call
 }
 
-// GetUser is a helper method to define mock.On call
+// DeleteDomain is a helper method to define mock.On call
 //   - ctx context.Context
 //   - id int
-func (_e *Repository_Expecter) GetUser(ctx interface{}, id interface{}) *Repository_GetUser_Call {
-	return &Repository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, id)}
+func (_e *Repository_Expecter) DeleteDomain(ctx interface{}, id interface{}) *Repository_DeleteDomain_Call {
+	return &Repository_DeleteDomain_Call{Call: _e.mock.On("DeleteDomain", ctx, id)}
 }
 
-func (_c *Repository_GetUser_Call) Run(run func(ctx context.Context, id int)) *Repository_GetUser_Call {
+func (_c *Repository_DeleteDomain_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_GetUser_Call) Return(_a0 *models.User, _a1 error) *Repository_GetUser_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *Repository_DeleteDomain_Call) Return(_a0 error) *Repository_DeleteDomain_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_GetUser_Call) RunAndReturn(run func(context.Context, int) (*models.User, error)) *Repository_GetUser_Call {
+func (_c *Repository_DeleteDomain_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteDomain_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserByUsername provides a mock function with given fields: ctx, username
-func (_m *Repository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	ret := _m.Called(ctx, username)
+// DeleteExpiredSessions provides a mock function with given fields: ctx, before
+func (_m *Repository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int, error) {
+	ret := _m.Called(ctx, before)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserByUsername")
+		panic("no return value specified for DeleteExpiredSessions")
 	}
 
-	var r0 *models.User
+	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
-		return rf(ctx, username)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(ctx, before)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
-		r0 = rf(ctx, username)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, before)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
-		}
+		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, username)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1030,206 +1011,2913 @@ func (_m *Repository) GetUserByUsername(ctx context.Context, username string) (*
 	return r0, r1
 }
 
-// Repository_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
-type Repository_GetUserByUsername_Call struct {
+// Repository_DeleteExpiredSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredSessions'
+type Repository_DeleteExpiredSessions_Call struct {
 	*mock.Call
 }
 
-// GetUserByUsername is a helper method to define mock.On call
+// DeleteExpiredSessions is a helper method to define mock.On call
 //   - ctx context.Context
-//   - username string
-func (_e *Repository_Expecter) GetUserByUsername(ctx interface{}, username interface{}) *Repository_GetUserByUsername_Call {
-	return &Repository_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", ctx, username)}
+//   - before time.Time
+func (_e *Repository_Expecter) DeleteExpiredSessions(ctx interface{}, before interface{}) *Repository_DeleteExpiredSessions_Call {
+	return &Repository_DeleteExpiredSessions_Call{Call: _e.mock.On("DeleteExpiredSessions", ctx, before)}
 }
 
-func (_c *Repository_GetUserByUsername_Call) Run(run func(ctx context.Context, username string)) *Repository_GetUserByUsername_Call {
+func (_c *Repository_DeleteExpiredSessions_Call) Run(run func(ctx context.Context, before time.Time)) *Repository_DeleteExpiredSessions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *Repository_GetUserByUsername_Call) Return(_a0 *models.User, _a1 error) *Repository_GetUserByUsername_Call {
+func (_c *Repository_DeleteExpiredSessions_Call) Return(_a0 int, _a1 error) *Repository_DeleteExpiredSessions_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_GetUserByUsername_Call) RunAndReturn(run func(context.Context, string) (*models.User, error)) *Repository_GetUserByUsername_Call {
+func (_c *Repository_DeleteExpiredSessions_Call) RunAndReturn(run func(context.Context, time.Time) (int, error)) *Repository_DeleteExpiredSessions_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListInboxesByProject provides a mock function with given fields: ctx, projectID, limit, offset
-func (_m *Repository) ListInboxesByProject(ctx context.Context, projectID int, limit int, offset int) ([]*models.Inbox, int, error) {
-	ret := _m.Called(ctx, projectID, limit, offset)
+// DeleteFolder provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteFolder(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListInboxesByProject")
-	}
-
-	var r0 []*models.Inbox
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Inbox, int, error)); ok {
-		return rf(ctx, projectID, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Inbox); ok {
-		r0 = rf(ctx, projectID, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Inbox)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
-		r1 = rf(ctx, projectID, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for DeleteFolder")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
-		r2 = rf(ctx, projectID, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListInboxesByProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInboxesByProject'
-type Repository_ListInboxesByProject_Call struct {
+// Repository_DeleteFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteFolder'
+type Repository_DeleteFolder_Call struct {
 	*mock.Call
 }
 
-// ListInboxesByProject is a helper method to define mock.On call
+// DeleteFolder is a helper method to define mock.On call
 //   - ctx context.Context
-//   - projectID int
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListInboxesByProject(ctx interface{}, projectID interface{}, limit interface{}, offset interface{}) *Repository_ListInboxesByProject_Call {
-	return &Repository_ListInboxesByProject_Call{Call: _e.mock.On("ListInboxesByProject", ctx, projectID, limit, offset)}
+//   - id int
+func (_e *Repository_Expecter) DeleteFolder(ctx interface{}, id interface{}) *Repository_DeleteFolder_Call {
+	return &Repository_DeleteFolder_Call{Call: _e.mock.On("DeleteFolder", ctx, id)}
 }
 
-func (_c *Repository_ListInboxesByProject_Call) Run(run func(ctx context.Context, projectID int, limit int, offset int)) *Repository_ListInboxesByProject_Call {
+func (_c *Repository_DeleteFolder_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteFolder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_ListInboxesByProject_Call) Return(_a0 []*models.Inbox, _a1 int, _a2 error) *Repository_ListInboxesByProject_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_DeleteFolder_Call) Return(_a0 error) *Repository_DeleteFolder_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListInboxesByProject_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Inbox, int, error)) *Repository_ListInboxesByProject_Call {
+func (_c *Repository_DeleteFolder_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteFolder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListMessagesByInbox provides a mock function with given fields: ctx, inboxID, limit, offset
-func (_m *Repository) ListMessagesByInbox(ctx context.Context, inboxID int, limit int, offset int) ([]*models.Message, int, error) {
-	ret := _m.Called(ctx, inboxID, limit, offset)
+// DeleteInbox provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteInbox(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListMessagesByInbox")
-	}
-
-	var r0 []*models.Message
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Message, int, error)); ok {
-		return rf(ctx, inboxID, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Message); ok {
-		r0 = rf(ctx, inboxID, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Message)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
-		r1 = rf(ctx, inboxID, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for DeleteInbox")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
-		r2 = rf(ctx, inboxID, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListMessagesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessagesByInbox'
-type Repository_ListMessagesByInbox_Call struct {
+// Repository_DeleteInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteInbox'
+type Repository_DeleteInbox_Call struct {
 	*mock.Call
 }
 
-// ListMessagesByInbox is a helper method to define mock.On call
+// DeleteInbox is a helper method to define mock.On call
 //   - ctx context.Context
-//   - inboxID int
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListMessagesByInbox(ctx interface{}, inboxID interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByInbox_Call {
-	return &Repository_ListMessagesByInbox_Call{Call: _e.mock.On("ListMessagesByInbox", ctx, inboxID, limit, offset)}
+//   - id int
+func (_e *Repository_Expecter) DeleteInbox(ctx interface{}, id interface{}) *Repository_DeleteInbox_Call {
+	return &Repository_DeleteInbox_Call{Call: _e.mock.On("DeleteInbox", ctx, id)}
 }
 
-func (_c *Repository_ListMessagesByInbox_Call) Run(run func(ctx context.Context, inboxID int, limit int, offset int)) *Repository_ListMessagesByInbox_Call {
+func (_c *Repository_DeleteInbox_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteInbox_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_ListMessagesByInbox_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByInbox_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_DeleteInbox_Call) Return(_a0 error) *Repository_DeleteInbox_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListMessagesByInbox_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByInbox_Call {
+func (_c *Repository_DeleteInbox_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteInbox_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListMessagesByInboxWithFilter provides a mock function with given fields: ctx, inboxID, isRead, limit, offset
-func (_m *Repository) ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, limit int, offset int) ([]*models.Message, int, error) {
-	ret := _m.Called(ctx, inboxID, isRead, limit, offset)
+// DeleteMessage provides a mock function with given fields: ctx, messageID
+func (_m *Repository) DeleteMessage(ctx context.Context, messageID int) error {
+	ret := _m.Called(ctx, messageID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListMessagesByInboxWithFilter")
-	}
-
-	var r0 []*models.Message
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, *bool, int, int) ([]*models.Message, int, error)); ok {
-		return rf(ctx, inboxID, isRead, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, *bool, int, int) []*models.Message); ok {
-		r0 = rf(ctx, inboxID, isRead, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Message)
-		}
+		panic("no return value specified for DeleteMessage")
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, *bool, int, int) int); ok {
-		r1 = rf(ctx, inboxID, isRead, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, messageID)
 	} else {
-		r1 = ret.Get(1).(int)
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, *bool, int, int) error); ok {
-		r2 = rf(ctx, inboxID, isRead, limit, offset)
-	} else {
-		r2 = ret.Error(2)
-	}
+	return r0
+}
 
-	return r0, r1, r2
+// Repository_DeleteMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessage'
+type Repository_DeleteMessage_Call struct {
+	*mock.Call
+}
+
+// DeleteMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - messageID int
+func (_e *Repository_Expecter) DeleteMessage(ctx interface{}, messageID interface{}) *Repository_DeleteMessage_Call {
+	return &Repository_DeleteMessage_Call{Call: _e.mock.On("DeleteMessage", ctx, messageID)}
+}
+
+func (_c *Repository_DeleteMessage_Call) Run(run func(ctx context.Context, messageID int)) *Repository_DeleteMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteMessage_Call) Return(_a0 error) *Repository_DeleteMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteMessage_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteProject provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteProject(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteProject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteProject'
+type Repository_DeleteProject_Call struct {
+	*mock.Call
+}
+
+// DeleteProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) DeleteProject(ctx interface{}, id interface{}) *Repository_DeleteProject_Call {
+	return &Repository_DeleteProject_Call{Call: _e.mock.On("DeleteProject", ctx, id)}
+}
+
+func (_c *Repository_DeleteProject_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteProject_Call) Return(_a0 error) *Repository_DeleteProject_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteProject_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRule provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteRule(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRule")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRule'
+type Repository_DeleteRule_Call struct {
+	*mock.Call
+}
+
+// DeleteRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) DeleteRule(ctx interface{}, id interface{}) *Repository_DeleteRule_Call {
+	return &Repository_DeleteRule_Call{Call: _e.mock.On("DeleteRule", ctx, id)}
+}
+
+func (_c *Repository_DeleteRule_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteRule_Call) Return(_a0 error) *Repository_DeleteRule_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteRule_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteToken provides a mock function with given fields: ctx, tokenID
+func (_m *Repository) DeleteToken(ctx context.Context, tokenID int) error {
+	ret := _m.Called(ctx, tokenID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, tokenID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteToken'
+type Repository_DeleteToken_Call struct {
+	*mock.Call
+}
+
+// DeleteToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenID int
+func (_e *Repository_Expecter) DeleteToken(ctx interface{}, tokenID interface{}) *Repository_DeleteToken_Call {
+	return &Repository_DeleteToken_Call{Call: _e.mock.On("DeleteToken", ctx, tokenID)}
+}
+
+func (_c *Repository_DeleteToken_Call) Run(run func(ctx context.Context, tokenID int)) *Repository_DeleteToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteToken_Call) Return(_a0 error) *Repository_DeleteToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteToken_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUser provides a mock function with given fields: ctx, userId
+func (_m *Repository) DeleteUser(ctx context.Context, userId int) error {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type Repository_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userId int
+func (_e *Repository_Expecter) DeleteUser(ctx interface{}, userId interface{}) *Repository_DeleteUser_Call {
+	return &Repository_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, userId)}
+}
+
+func (_c *Repository_DeleteUser_Call) Run(run func(ctx context.Context, userId int)) *Repository_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteUser_Call) Return(_a0 error) *Repository_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteUser_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteWebhook(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type Repository_DeleteWebhook_Call struct {
+	*mock.Call
+}
+
+// DeleteWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) DeleteWebhook(ctx interface{}, id interface{}) *Repository_DeleteWebhook_Call {
+	return &Repository_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", ctx, id)}
+}
+
+func (_c *Repository_DeleteWebhook_Call) Run(run func(ctx context.Context, id int)) *Repository_DeleteWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_DeleteWebhook_Call) Return(_a0 error) *Repository_DeleteWebhook_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_DeleteWebhook_Call) RunAndReturn(run func(context.Context, int) error) *Repository_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExpungeMessages provides a mock function with given fields: ctx, inboxID, folderID, ids
+func (_m *Repository) ExpungeMessages(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	ret := _m.Called(ctx, inboxID, folderID, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpungeMessages")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, []int) ([]int, error)); ok {
+		return rf(ctx, inboxID, folderID, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, []int) []int); ok {
+		r0 = rf(ctx, inboxID, folderID, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int, []int) error); ok {
+		r1 = rf(ctx, inboxID, folderID, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ExpungeMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExpungeMessages'
+type Repository_ExpungeMessages_Call struct {
+	*mock.Call
+}
+
+// ExpungeMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - folderID null.Int
+//   - ids []int
+func (_e *Repository_Expecter) ExpungeMessages(ctx interface{}, inboxID interface{}, folderID interface{}, ids interface{}) *Repository_ExpungeMessages_Call {
+	return &Repository_ExpungeMessages_Call{Call: _e.mock.On("ExpungeMessages", ctx, inboxID, folderID, ids)}
+}
+
+func (_c *Repository_ExpungeMessages_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int, ids []int)) *Repository_ExpungeMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int), args[3].([]int))
+	})
+	return _c
+}
+
+func (_c *Repository_ExpungeMessages_Call) Return(_a0 []int, _a1 error) *Repository_ExpungeMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ExpungeMessages_Call) RunAndReturn(run func(context.Context, int, null.Int, []int) ([]int, error)) *Repository_ExpungeMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAttachment provides a mock function with given fields: ctx, id
+func (_m *Repository) GetAttachment(ctx context.Context, id int) (*models.Attachment, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachment")
+	}
+
+	var r0 *models.Attachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Attachment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Attachment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetAttachment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAttachment'
+type Repository_GetAttachment_Call struct {
+	*mock.Call
+}
+
+// GetAttachment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetAttachment(ctx interface{}, id interface{}) *Repository_GetAttachment_Call {
+	return &Repository_GetAttachment_Call{Call: _e.mock.On("GetAttachment", ctx, id)}
+}
+
+func (_c *Repository_GetAttachment_Call) Run(run func(ctx context.Context, id int)) *Repository_GetAttachment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetAttachment_Call) Return(_a0 *models.Attachment, _a1 error) *Repository_GetAttachment_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetAttachment_Call) RunAndReturn(run func(context.Context, int) (*models.Attachment, error)) *Repository_GetAttachment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDomain provides a mock function with given fields: ctx, id
+func (_m *Repository) GetDomain(ctx context.Context, id int) (*models.Domain, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDomain")
+	}
+
+	var r0 *models.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Domain, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Domain); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomain'
+type Repository_GetDomain_Call struct {
+	*mock.Call
+}
+
+// GetDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetDomain(ctx interface{}, id interface{}) *Repository_GetDomain_Call {
+	return &Repository_GetDomain_Call{Call: _e.mock.On("GetDomain", ctx, id)}
+}
+
+func (_c *Repository_GetDomain_Call) Run(run func(ctx context.Context, id int)) *Repository_GetDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetDomain_Call) Return(_a0 *models.Domain, _a1 error) *Repository_GetDomain_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetDomain_Call) RunAndReturn(run func(context.Context, int) (*models.Domain, error)) *Repository_GetDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFolder provides a mock function with given fields: ctx, id
+func (_m *Repository) GetFolder(ctx context.Context, id int) (*models.Folder, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFolder")
+	}
+
+	var r0 *models.Folder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Folder, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Folder); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Folder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFolder'
+type Repository_GetFolder_Call struct {
+	*mock.Call
+}
+
+// GetFolder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetFolder(ctx interface{}, id interface{}) *Repository_GetFolder_Call {
+	return &Repository_GetFolder_Call{Call: _e.mock.On("GetFolder", ctx, id)}
+}
+
+func (_c *Repository_GetFolder_Call) Run(run func(ctx context.Context, id int)) *Repository_GetFolder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetFolder_Call) Return(_a0 *models.Folder, _a1 error) *Repository_GetFolder_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetFolder_Call) RunAndReturn(run func(context.Context, int) (*models.Folder, error)) *Repository_GetFolder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFolderByName provides a mock function with given fields: ctx, inboxID, name
+func (_m *Repository) GetFolderByName(ctx context.Context, inboxID int, name string) (*models.Folder, error) {
+	ret := _m.Called(ctx, inboxID, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFolderByName")
+	}
+
+	var r0 *models.Folder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*models.Folder, error)); ok {
+		return rf(ctx, inboxID, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *models.Folder); ok {
+		r0 = rf(ctx, inboxID, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Folder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, inboxID, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetFolderByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFolderByName'
+type Repository_GetFolderByName_Call struct {
+	*mock.Call
+}
+
+// GetFolderByName is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - name string
+func (_e *Repository_Expecter) GetFolderByName(ctx interface{}, inboxID interface{}, name interface{}) *Repository_GetFolderByName_Call {
+	return &Repository_GetFolderByName_Call{Call: _e.mock.On("GetFolderByName", ctx, inboxID, name)}
+}
+
+func (_c *Repository_GetFolderByName_Call) Run(run func(ctx context.Context, inboxID int, name string)) *Repository_GetFolderByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetFolderByName_Call) Return(_a0 *models.Folder, _a1 error) *Repository_GetFolderByName_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetFolderByName_Call) RunAndReturn(run func(context.Context, int, string) (*models.Folder, error)) *Repository_GetFolderByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFolderStatus provides a mock function with given fields: ctx, inboxID, folderID
+func (_m *Repository) GetFolderStatus(ctx context.Context, inboxID int, folderID null.Int) (*models.FolderStatus, error) {
+	ret := _m.Called(ctx, inboxID, folderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFolderStatus")
+	}
+
+	var r0 *models.FolderStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) (*models.FolderStatus, error)); ok {
+		return rf(ctx, inboxID, folderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) *models.FolderStatus); ok {
+		r0 = rf(ctx, inboxID, folderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FolderStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int) error); ok {
+		r1 = rf(ctx, inboxID, folderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetFolderStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFolderStatus'
+type Repository_GetFolderStatus_Call struct {
+	*mock.Call
+}
+
+// GetFolderStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - folderID null.Int
+func (_e *Repository_Expecter) GetFolderStatus(ctx interface{}, inboxID interface{}, folderID interface{}) *Repository_GetFolderStatus_Call {
+	return &Repository_GetFolderStatus_Call{Call: _e.mock.On("GetFolderStatus", ctx, inboxID, folderID)}
+}
+
+func (_c *Repository_GetFolderStatus_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int)) *Repository_GetFolderStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetFolderStatus_Call) Return(_a0 *models.FolderStatus, _a1 error) *Repository_GetFolderStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetFolderStatus_Call) RunAndReturn(run func(context.Context, int, null.Int) (*models.FolderStatus, error)) *Repository_GetFolderStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInbox provides a mock function with given fields: ctx, id
+func (_m *Repository) GetInbox(ctx context.Context, id int) (*models.Inbox, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInbox")
+	}
+
+	var r0 *models.Inbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Inbox, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Inbox); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Inbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInbox'
+type Repository_GetInbox_Call struct {
+	*mock.Call
+}
+
+// GetInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetInbox(ctx interface{}, id interface{}) *Repository_GetInbox_Call {
+	return &Repository_GetInbox_Call{Call: _e.mock.On("GetInbox", ctx, id)}
+}
+
+func (_c *Repository_GetInbox_Call) Run(run func(ctx context.Context, id int)) *Repository_GetInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetInbox_Call) Return(_a0 *models.Inbox, _a1 error) *Repository_GetInbox_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetInbox_Call) RunAndReturn(run func(context.Context, int) (*models.Inbox, error)) *Repository_GetInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInboxByAddress provides a mock function with given fields: ctx, address, baseAddress
+func (_m *Repository) GetInboxByAddress(ctx context.Context, address string, baseAddress string) (*models.Inbox, error) {
+	ret := _m.Called(ctx, address, baseAddress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInboxByAddress")
+	}
+
+	var r0 *models.Inbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Inbox, error)); ok {
+		return rf(ctx, address, baseAddress)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Inbox); ok {
+		r0 = rf(ctx, address, baseAddress)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Inbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, address, baseAddress)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetInboxByAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInboxByAddress'
+type Repository_GetInboxByAddress_Call struct {
+	*mock.Call
+}
+
+// GetInboxByAddress is a helper method to define mock.On call
+//   - ctx context.Context
+//   - address string
+//   - baseAddress string
+func (_e *Repository_Expecter) GetInboxByAddress(ctx interface{}, address interface{}, baseAddress interface{}) *Repository_GetInboxByAddress_Call {
+	return &Repository_GetInboxByAddress_Call{Call: _e.mock.On("GetInboxByAddress", ctx, address, baseAddress)}
+}
+
+func (_c *Repository_GetInboxByAddress_Call) Run(run func(ctx context.Context, address string, baseAddress string)) *Repository_GetInboxByAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetInboxByAddress_Call) Return(_a0 *models.Inbox, _a1 error) *Repository_GetInboxByAddress_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetInboxByAddress_Call) RunAndReturn(run func(context.Context, string, string) (*models.Inbox, error)) *Repository_GetInboxByAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInboxByEmail provides a mock function with given fields: ctx, email
+func (_m *Repository) GetInboxByEmail(ctx context.Context, email string) (*models.Inbox, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInboxByEmail")
+	}
+
+	var r0 *models.Inbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Inbox, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Inbox); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Inbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetInboxByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInboxByEmail'
+type Repository_GetInboxByEmail_Call struct {
+	*mock.Call
+}
+
+// GetInboxByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *Repository_Expecter) GetInboxByEmail(ctx interface{}, email interface{}) *Repository_GetInboxByEmail_Call {
+	return &Repository_GetInboxByEmail_Call{Call: _e.mock.On("GetInboxByEmail", ctx, email)}
+}
+
+func (_c *Repository_GetInboxByEmail_Call) Run(run func(ctx context.Context, email string)) *Repository_GetInboxByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetInboxByEmail_Call) Return(_a0 *models.Inbox, _a1 error) *Repository_GetInboxByEmail_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetInboxByEmail_Call) RunAndReturn(run func(context.Context, string) (*models.Inbox, error)) *Repository_GetInboxByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInboxRetentionPolicy provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) GetInboxRetentionPolicy(ctx context.Context, inboxID int) (*models.RetentionPolicy, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInboxRetentionPolicy")
+	}
+
+	var r0 *models.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.RetentionPolicy, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.RetentionPolicy); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetInboxRetentionPolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInboxRetentionPolicy'
+type Repository_GetInboxRetentionPolicy_Call struct {
+	*mock.Call
+}
+
+// GetInboxRetentionPolicy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) GetInboxRetentionPolicy(ctx interface{}, inboxID interface{}) *Repository_GetInboxRetentionPolicy_Call {
+	return &Repository_GetInboxRetentionPolicy_Call{Call: _e.mock.On("GetInboxRetentionPolicy", ctx, inboxID)}
+}
+
+func (_c *Repository_GetInboxRetentionPolicy_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_GetInboxRetentionPolicy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetInboxRetentionPolicy_Call) Return(_a0 *models.RetentionPolicy, _a1 error) *Repository_GetInboxRetentionPolicy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetInboxRetentionPolicy_Call) RunAndReturn(run func(context.Context, int) (*models.RetentionPolicy, error)) *Repository_GetInboxRetentionPolicy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInboxUsage provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) GetInboxUsage(ctx context.Context, inboxID int) (*models.InboxUsage, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInboxUsage")
+	}
+
+	var r0 *models.InboxUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.InboxUsage, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.InboxUsage); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InboxUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetInboxUsage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInboxUsage'
+type Repository_GetInboxUsage_Call struct {
+	*mock.Call
+}
+
+// GetInboxUsage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) GetInboxUsage(ctx interface{}, inboxID interface{}) *Repository_GetInboxUsage_Call {
+	return &Repository_GetInboxUsage_Call{Call: _e.mock.On("GetInboxUsage", ctx, inboxID)}
+}
+
+func (_c *Repository_GetInboxUsage_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_GetInboxUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetInboxUsage_Call) Return(_a0 *models.InboxUsage, _a1 error) *Repository_GetInboxUsage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetInboxUsage_Call) RunAndReturn(run func(context.Context, int) (*models.InboxUsage, error)) *Repository_GetInboxUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLastMessageID provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) GetLastMessageID(ctx context.Context, inboxID int) (int, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastMessageID")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetLastMessageID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLastMessageID'
+type Repository_GetLastMessageID_Call struct {
+	*mock.Call
+}
+
+// GetLastMessageID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) GetLastMessageID(ctx interface{}, inboxID interface{}) *Repository_GetLastMessageID_Call {
+	return &Repository_GetLastMessageID_Call{Call: _e.mock.On("GetLastMessageID", ctx, inboxID)}
+}
+
+func (_c *Repository_GetLastMessageID_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_GetLastMessageID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetLastMessageID_Call) Return(_a0 int, _a1 error) *Repository_GetLastMessageID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetLastMessageID_Call) RunAndReturn(run func(context.Context, int) (int, error)) *Repository_GetLastMessageID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessage provides a mock function with given fields: ctx, id
+func (_m *Repository) GetMessage(ctx context.Context, id int) (*models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 *models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessage'
+type Repository_GetMessage_Call struct {
+	*mock.Call
+}
+
+// GetMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetMessage(ctx interface{}, id interface{}) *Repository_GetMessage_Call {
+	return &Repository_GetMessage_Call{Call: _e.mock.On("GetMessage", ctx, id)}
+}
+
+func (_c *Repository_GetMessage_Call) Run(run func(ctx context.Context, id int)) *Repository_GetMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetMessage_Call) Return(_a0 *models.Message, _a1 error) *Repository_GetMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetMessage_Call) RunAndReturn(run func(context.Context, int) (*models.Message, error)) *Repository_GetMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessageRaw provides a mock function with given fields: ctx, id
+func (_m *Repository) GetMessageRaw(ctx context.Context, id int) ([]byte, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageRaw")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]byte, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []byte); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetMessageRaw_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageRaw'
+type Repository_GetMessageRaw_Call struct {
+	*mock.Call
+}
+
+// GetMessageRaw is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetMessageRaw(ctx interface{}, id interface{}) *Repository_GetMessageRaw_Call {
+	return &Repository_GetMessageRaw_Call{Call: _e.mock.On("GetMessageRaw", ctx, id)}
+}
+
+func (_c *Repository_GetMessageRaw_Call) Run(run func(ctx context.Context, id int)) *Repository_GetMessageRaw_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetMessageRaw_Call) Return(_a0 []byte, _a1 error) *Repository_GetMessageRaw_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetMessageRaw_Call) RunAndReturn(run func(context.Context, int) ([]byte, error)) *Repository_GetMessageRaw_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOutboundMessage provides a mock function with given fields: ctx, id
+func (_m *Repository) GetOutboundMessage(ctx context.Context, id int) (*models.OutboundMessage, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOutboundMessage")
+	}
+
+	var r0 *models.OutboundMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.OutboundMessage, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.OutboundMessage); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OutboundMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetOutboundMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOutboundMessage'
+type Repository_GetOutboundMessage_Call struct {
+	*mock.Call
+}
+
+// GetOutboundMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetOutboundMessage(ctx interface{}, id interface{}) *Repository_GetOutboundMessage_Call {
+	return &Repository_GetOutboundMessage_Call{Call: _e.mock.On("GetOutboundMessage", ctx, id)}
+}
+
+func (_c *Repository_GetOutboundMessage_Call) Run(run func(ctx context.Context, id int)) *Repository_GetOutboundMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetOutboundMessage_Call) Return(_a0 *models.OutboundMessage, _a1 error) *Repository_GetOutboundMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetOutboundMessage_Call) RunAndReturn(run func(context.Context, int) (*models.OutboundMessage, error)) *Repository_GetOutboundMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProject provides a mock function with given fields: ctx, id
+func (_m *Repository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProject")
+	}
+
+	var r0 *models.Project
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Project, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Project); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProject'
+type Repository_GetProject_Call struct {
+	*mock.Call
+}
+
+// GetProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetProject(ctx interface{}, id interface{}) *Repository_GetProject_Call {
+	return &Repository_GetProject_Call{Call: _e.mock.On("GetProject", ctx, id)}
+}
+
+func (_c *Repository_GetProject_Call) Run(run func(ctx context.Context, id int)) *Repository_GetProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetProject_Call) Return(_a0 *models.Project, _a1 error) *Repository_GetProject_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetProject_Call) RunAndReturn(run func(context.Context, int) (*models.Project, error)) *Repository_GetProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProjectRetentionPolicy provides a mock function with given fields: ctx, projectID
+func (_m *Repository) GetProjectRetentionPolicy(ctx context.Context, projectID int) (*models.RetentionPolicy, error) {
+	ret := _m.Called(ctx, projectID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectRetentionPolicy")
+	}
+
+	var r0 *models.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.RetentionPolicy, error)); ok {
+		return rf(ctx, projectID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.RetentionPolicy); ok {
+		r0 = rf(ctx, projectID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, projectID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetProjectRetentionPolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProjectRetentionPolicy'
+type Repository_GetProjectRetentionPolicy_Call struct {
+	*mock.Call
+}
+
+// GetProjectRetentionPolicy is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+func (_e *Repository_Expecter) GetProjectRetentionPolicy(ctx interface{}, projectID interface{}) *Repository_GetProjectRetentionPolicy_Call {
+	return &Repository_GetProjectRetentionPolicy_Call{Call: _e.mock.On("GetProjectRetentionPolicy", ctx, projectID)}
+}
+
+func (_c *Repository_GetProjectRetentionPolicy_Call) Run(run func(ctx context.Context, projectID int)) *Repository_GetProjectRetentionPolicy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetProjectRetentionPolicy_Call) Return(_a0 *models.RetentionPolicy, _a1 error) *Repository_GetProjectRetentionPolicy_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetProjectRetentionPolicy_Call) RunAndReturn(run func(context.Context, int) (*models.RetentionPolicy, error)) *Repository_GetProjectRetentionPolicy_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProjectUser provides a mock function with given fields: ctx, projectID, userID
+func (_m *Repository) GetProjectUser(ctx context.Context, projectID int, userID int) (*models.ProjectUser, error) {
+	ret := _m.Called(ctx, projectID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProjectUser")
+	}
+
+	var r0 *models.ProjectUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.ProjectUser, error)); ok {
+		return rf(ctx, projectID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.ProjectUser); ok {
+		r0 = rf(ctx, projectID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ProjectUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, projectID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetProjectUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProjectUser'
+type Repository_GetProjectUser_Call struct {
+	*mock.Call
+}
+
+// GetProjectUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+//   - userID int
+func (_e *Repository_Expecter) GetProjectUser(ctx interface{}, projectID interface{}, userID interface{}) *Repository_GetProjectUser_Call {
+	return &Repository_GetProjectUser_Call{Call: _e.mock.On("GetProjectUser", ctx, projectID, userID)}
+}
+
+func (_c *Repository_GetProjectUser_Call) Run(run func(ctx context.Context, projectID int, userID int)) *Repository_GetProjectUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetProjectUser_Call) Return(_a0 *models.ProjectUser, _a1 error) *Repository_GetProjectUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetProjectUser_Call) RunAndReturn(run func(context.Context, int, int) (*models.ProjectUser, error)) *Repository_GetProjectUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRule provides a mock function with given fields: ctx, id
+func (_m *Repository) GetRule(ctx context.Context, id int) (*models.ForwardRule, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRule")
+	}
+
+	var r0 *models.ForwardRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.ForwardRule, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.ForwardRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ForwardRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRule'
+type Repository_GetRule_Call struct {
+	*mock.Call
+}
+
+// GetRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetRule(ctx interface{}, id interface{}) *Repository_GetRule_Call {
+	return &Repository_GetRule_Call{Call: _e.mock.On("GetRule", ctx, id)}
+}
+
+func (_c *Repository_GetRule_Call) Run(run func(ctx context.Context, id int)) *Repository_GetRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetRule_Call) Return(_a0 *models.ForwardRule, _a1 error) *Repository_GetRule_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetRule_Call) RunAndReturn(run func(context.Context, int) (*models.ForwardRule, error)) *Repository_GetRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSession provides a mock function with given fields: ctx, sessionID
+func (_m *Repository) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSession")
+	}
+
+	var r0 *models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Session, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Session); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSession'
+type Repository_GetSession_Call struct {
+	*mock.Call
+}
+
+// GetSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sessionID string
+func (_e *Repository_Expecter) GetSession(ctx interface{}, sessionID interface{}) *Repository_GetSession_Call {
+	return &Repository_GetSession_Call{Call: _e.mock.On("GetSession", ctx, sessionID)}
+}
+
+func (_c *Repository_GetSession_Call) Run(run func(ctx context.Context, sessionID string)) *Repository_GetSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetSession_Call) Return(_a0 *models.Session, _a1 error) *Repository_GetSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetSession_Call) RunAndReturn(run func(context.Context, string) (*models.Session, error)) *Repository_GetSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTokenByUser provides a mock function with given fields: ctx, tokenID, userID
+func (_m *Repository) GetTokenByUser(ctx context.Context, tokenID int, userID int) (*models.Token, error) {
+	ret := _m.Called(ctx, tokenID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenByUser")
+	}
+
+	var r0 *models.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*models.Token, error)); ok {
+		return rf(ctx, tokenID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *models.Token); ok {
+		r0 = rf(ctx, tokenID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, tokenID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetTokenByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenByUser'
+type Repository_GetTokenByUser_Call struct {
+	*mock.Call
+}
+
+// GetTokenByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenID int
+//   - userID int
+func (_e *Repository_Expecter) GetTokenByUser(ctx interface{}, tokenID interface{}, userID interface{}) *Repository_GetTokenByUser_Call {
+	return &Repository_GetTokenByUser_Call{Call: _e.mock.On("GetTokenByUser", ctx, tokenID, userID)}
+}
+
+func (_c *Repository_GetTokenByUser_Call) Run(run func(ctx context.Context, tokenID int, userID int)) *Repository_GetTokenByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetTokenByUser_Call) Return(_a0 *models.Token, _a1 error) *Repository_GetTokenByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetTokenByUser_Call) RunAndReturn(run func(context.Context, int, int) (*models.Token, error)) *Repository_GetTokenByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTokenByValue provides a mock function with given fields: ctx, value
+func (_m *Repository) GetTokenByValue(ctx context.Context, value string) (*models.Token, error) {
+	ret := _m.Called(ctx, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenByValue")
+	}
+
+	var r0 *models.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Token, error)); ok {
+		return rf(ctx, value)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Token); ok {
+		r0 = rf(ctx, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetTokenByValue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTokenByValue'
+type Repository_GetTokenByValue_Call struct {
+	*mock.Call
+}
+
+// GetTokenByValue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - value string
+func (_e *Repository_Expecter) GetTokenByValue(ctx interface{}, value interface{}) *Repository_GetTokenByValue_Call {
+	return &Repository_GetTokenByValue_Call{Call: _e.mock.On("GetTokenByValue", ctx, value)}
+}
+
+func (_c *Repository_GetTokenByValue_Call) Run(run func(ctx context.Context, value string)) *Repository_GetTokenByValue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetTokenByValue_Call) Return(_a0 *models.Token, _a1 error) *Repository_GetTokenByValue_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetTokenByValue_Call) RunAndReturn(run func(context.Context, string) (*models.Token, error)) *Repository_GetTokenByValue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, id
+func (_m *Repository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type Repository_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetUser(ctx interface{}, id interface{}) *Repository_GetUser_Call {
+	return &Repository_GetUser_Call{Call: _e.mock.On("GetUser", ctx, id)}
+}
+
+func (_c *Repository_GetUser_Call) Run(run func(ctx context.Context, id int)) *Repository_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetUser_Call) Return(_a0 *models.User, _a1 error) *Repository_GetUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetUser_Call) RunAndReturn(run func(context.Context, int) (*models.User, error)) *Repository_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByUsername provides a mock function with given fields: ctx, username
+func (_m *Repository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByUsername")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
+type Repository_GetUserByUsername_Call struct {
+	*mock.Call
+}
+
+// GetUserByUsername is a helper method to define mock.On call
+//   - ctx context.Context
+//   - username string
+func (_e *Repository_Expecter) GetUserByUsername(ctx interface{}, username interface{}) *Repository_GetUserByUsername_Call {
+	return &Repository_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", ctx, username)}
+}
+
+func (_c *Repository_GetUserByUsername_Call) Run(run func(ctx context.Context, username string)) *Repository_GetUserByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetUserByUsername_Call) Return(_a0 *models.User, _a1 error) *Repository_GetUserByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetUserByUsername_Call) RunAndReturn(run func(context.Context, string) (*models.User, error)) *Repository_GetUserByUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVerifiedDomainForHost provides a mock function with given fields: ctx, host
+func (_m *Repository) GetVerifiedDomainForHost(ctx context.Context, host string) (*models.Domain, error) {
+	ret := _m.Called(ctx, host)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVerifiedDomainForHost")
+	}
+
+	var r0 *models.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Domain, error)); ok {
+		return rf(ctx, host)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Domain); ok {
+		r0 = rf(ctx, host)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetVerifiedDomainForHost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVerifiedDomainForHost'
+type Repository_GetVerifiedDomainForHost_Call struct {
+	*mock.Call
+}
+
+// GetVerifiedDomainForHost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - host string
+func (_e *Repository_Expecter) GetVerifiedDomainForHost(ctx interface{}, host interface{}) *Repository_GetVerifiedDomainForHost_Call {
+	return &Repository_GetVerifiedDomainForHost_Call{Call: _e.mock.On("GetVerifiedDomainForHost", ctx, host)}
+}
+
+func (_c *Repository_GetVerifiedDomainForHost_Call) Run(run func(ctx context.Context, host string)) *Repository_GetVerifiedDomainForHost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_GetVerifiedDomainForHost_Call) Return(_a0 *models.Domain, _a1 error) *Repository_GetVerifiedDomainForHost_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetVerifiedDomainForHost_Call) RunAndReturn(run func(context.Context, string) (*models.Domain, error)) *Repository_GetVerifiedDomainForHost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWebhook provides a mock function with given fields: ctx, id
+func (_m *Repository) GetWebhook(ctx context.Context, id int) (*models.Webhook, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 *models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Webhook, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Webhook); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWebhook'
+type Repository_GetWebhook_Call struct {
+	*mock.Call
+}
+
+// GetWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetWebhook(ctx interface{}, id interface{}) *Repository_GetWebhook_Call {
+	return &Repository_GetWebhook_Call{Call: _e.mock.On("GetWebhook", ctx, id)}
+}
+
+func (_c *Repository_GetWebhook_Call) Run(run func(ctx context.Context, id int)) *Repository_GetWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetWebhook_Call) Return(_a0 *models.Webhook, _a1 error) *Repository_GetWebhook_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetWebhook_Call) RunAndReturn(run func(context.Context, int) (*models.Webhook, error)) *Repository_GetWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWebhookDelivery provides a mock function with given fields: ctx, id
+func (_m *Repository) GetWebhookDelivery(ctx context.Context, id int) (*models.WebhookDelivery, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhookDelivery")
+	}
+
+	var r0 *models.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.WebhookDelivery, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_GetWebhookDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWebhookDelivery'
+type Repository_GetWebhookDelivery_Call struct {
+	*mock.Call
+}
+
+// GetWebhookDelivery is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) GetWebhookDelivery(ctx interface{}, id interface{}) *Repository_GetWebhookDelivery_Call {
+	return &Repository_GetWebhookDelivery_Call{Call: _e.mock.On("GetWebhookDelivery", ctx, id)}
+}
+
+func (_c *Repository_GetWebhookDelivery_Call) Run(run func(ctx context.Context, id int)) *Repository_GetWebhookDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_GetWebhookDelivery_Call) Return(_a0 *models.WebhookDelivery, _a1 error) *Repository_GetWebhookDelivery_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_GetWebhookDelivery_Call) RunAndReturn(run func(context.Context, int) (*models.WebhookDelivery, error)) *Repository_GetWebhookDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementRuleHitCount provides a mock function with given fields: ctx, id
+func (_m *Repository) IncrementRuleHitCount(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementRuleHitCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_IncrementRuleHitCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementRuleHitCount'
+type Repository_IncrementRuleHitCount_Call struct {
+	*mock.Call
+}
+
+// IncrementRuleHitCount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *Repository_Expecter) IncrementRuleHitCount(ctx interface{}, id interface{}) *Repository_IncrementRuleHitCount_Call {
+	return &Repository_IncrementRuleHitCount_Call{Call: _e.mock.On("IncrementRuleHitCount", ctx, id)}
+}
+
+func (_c *Repository_IncrementRuleHitCount_Call) Run(run func(ctx context.Context, id int)) *Repository_IncrementRuleHitCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_IncrementRuleHitCount_Call) Return(_a0 error) *Repository_IncrementRuleHitCount_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_IncrementRuleHitCount_Call) RunAndReturn(run func(context.Context, int) error) *Repository_IncrementRuleHitCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActiveWebhooksByInbox provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) ListActiveWebhooksByInbox(ctx context.Context, inboxID int) ([]*models.Webhook, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActiveWebhooksByInbox")
+	}
+
+	var r0 []*models.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.Webhook, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.Webhook); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListActiveWebhooksByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActiveWebhooksByInbox'
+type Repository_ListActiveWebhooksByInbox_Call struct {
+	*mock.Call
+}
+
+// ListActiveWebhooksByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) ListActiveWebhooksByInbox(ctx interface{}, inboxID interface{}) *Repository_ListActiveWebhooksByInbox_Call {
+	return &Repository_ListActiveWebhooksByInbox_Call{Call: _e.mock.On("ListActiveWebhooksByInbox", ctx, inboxID)}
+}
+
+func (_c *Repository_ListActiveWebhooksByInbox_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_ListActiveWebhooksByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListActiveWebhooksByInbox_Call) Return(_a0 []*models.Webhook, _a1 error) *Repository_ListActiveWebhooksByInbox_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListActiveWebhooksByInbox_Call) RunAndReturn(run func(context.Context, int) ([]*models.Webhook, error)) *Repository_ListActiveWebhooksByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAllFoldersByInbox provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) ListAllFoldersByInbox(ctx context.Context, inboxID int) ([]*models.Folder, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAllFoldersByInbox")
+	}
+
+	var r0 []*models.Folder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.Folder, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.Folder); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListAllFoldersByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAllFoldersByInbox'
+type Repository_ListAllFoldersByInbox_Call struct {
+	*mock.Call
+}
+
+// ListAllFoldersByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) ListAllFoldersByInbox(ctx interface{}, inboxID interface{}) *Repository_ListAllFoldersByInbox_Call {
+	return &Repository_ListAllFoldersByInbox_Call{Call: _e.mock.On("ListAllFoldersByInbox", ctx, inboxID)}
+}
+
+func (_c *Repository_ListAllFoldersByInbox_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_ListAllFoldersByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListAllFoldersByInbox_Call) Return(_a0 []*models.Folder, _a1 error) *Repository_ListAllFoldersByInbox_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListAllFoldersByInbox_Call) RunAndReturn(run func(context.Context, int) ([]*models.Folder, error)) *Repository_ListAllFoldersByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAllRulesByInbox provides a mock function with given fields: ctx, inboxID
+func (_m *Repository) ListAllRulesByInbox(ctx context.Context, inboxID int) ([]*models.ForwardRule, error) {
+	ret := _m.Called(ctx, inboxID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAllRulesByInbox")
+	}
+
+	var r0 []*models.ForwardRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.ForwardRule, error)); ok {
+		return rf(ctx, inboxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.ForwardRule); ok {
+		r0 = rf(ctx, inboxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ForwardRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, inboxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListAllRulesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAllRulesByInbox'
+type Repository_ListAllRulesByInbox_Call struct {
+	*mock.Call
+}
+
+// ListAllRulesByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+func (_e *Repository_Expecter) ListAllRulesByInbox(ctx interface{}, inboxID interface{}) *Repository_ListAllRulesByInbox_Call {
+	return &Repository_ListAllRulesByInbox_Call{Call: _e.mock.On("ListAllRulesByInbox", ctx, inboxID)}
+}
+
+func (_c *Repository_ListAllRulesByInbox_Call) Run(run func(ctx context.Context, inboxID int)) *Repository_ListAllRulesByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListAllRulesByInbox_Call) Return(_a0 []*models.ForwardRule, _a1 error) *Repository_ListAllRulesByInbox_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListAllRulesByInbox_Call) RunAndReturn(run func(context.Context, int) ([]*models.ForwardRule, error)) *Repository_ListAllRulesByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAttachmentsByMessage provides a mock function with given fields: ctx, messageID
+func (_m *Repository) ListAttachmentsByMessage(ctx context.Context, messageID int) ([]*models.Attachment, error) {
+	ret := _m.Called(ctx, messageID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAttachmentsByMessage")
+	}
+
+	var r0 []*models.Attachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.Attachment, error)); ok {
+		return rf(ctx, messageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.Attachment); ok {
+		r0 = rf(ctx, messageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, messageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListAttachmentsByMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAttachmentsByMessage'
+type Repository_ListAttachmentsByMessage_Call struct {
+	*mock.Call
+}
+
+// ListAttachmentsByMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - messageID int
+func (_e *Repository_Expecter) ListAttachmentsByMessage(ctx interface{}, messageID interface{}) *Repository_ListAttachmentsByMessage_Call {
+	return &Repository_ListAttachmentsByMessage_Call{Call: _e.mock.On("ListAttachmentsByMessage", ctx, messageID)}
+}
+
+func (_c *Repository_ListAttachmentsByMessage_Call) Run(run func(ctx context.Context, messageID int)) *Repository_ListAttachmentsByMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListAttachmentsByMessage_Call) Return(_a0 []*models.Attachment, _a1 error) *Repository_ListAttachmentsByMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListAttachmentsByMessage_Call) RunAndReturn(run func(context.Context, int) ([]*models.Attachment, error)) *Repository_ListAttachmentsByMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDomainsByProject provides a mock function with given fields: ctx, projectID, limit, offset
+func (_m *Repository) ListDomainsByProject(ctx context.Context, projectID int, limit int, offset int) ([]*models.Domain, int, error) {
+	ret := _m.Called(ctx, projectID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDomainsByProject")
+	}
+
+	var r0 []*models.Domain
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Domain, int, error)); ok {
+		return rf(ctx, projectID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Domain); ok {
+		r0 = rf(ctx, projectID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, projectID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, projectID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListDomainsByProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDomainsByProject'
+type Repository_ListDomainsByProject_Call struct {
+	*mock.Call
+}
+
+// ListDomainsByProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListDomainsByProject(ctx interface{}, projectID interface{}, limit interface{}, offset interface{}) *Repository_ListDomainsByProject_Call {
+	return &Repository_ListDomainsByProject_Call{Call: _e.mock.On("ListDomainsByProject", ctx, projectID, limit, offset)}
+}
+
+func (_c *Repository_ListDomainsByProject_Call) Run(run func(ctx context.Context, projectID int, limit int, offset int)) *Repository_ListDomainsByProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListDomainsByProject_Call) Return(_a0 []*models.Domain, _a1 int, _a2 error) *Repository_ListDomainsByProject_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListDomainsByProject_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Domain, int, error)) *Repository_ListDomainsByProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListEffectiveRetentionPolicies provides a mock function with given fields: ctx
+func (_m *Repository) ListEffectiveRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEffectiveRetentionPolicies")
+	}
+
+	var r0 []*models.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.RetentionPolicy, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.RetentionPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListEffectiveRetentionPolicies_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListEffectiveRetentionPolicies'
+type Repository_ListEffectiveRetentionPolicies_Call struct {
+	*mock.Call
+}
+
+// ListEffectiveRetentionPolicies is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Repository_Expecter) ListEffectiveRetentionPolicies(ctx interface{}) *Repository_ListEffectiveRetentionPolicies_Call {
+	return &Repository_ListEffectiveRetentionPolicies_Call{Call: _e.mock.On("ListEffectiveRetentionPolicies", ctx)}
+}
+
+func (_c *Repository_ListEffectiveRetentionPolicies_Call) Run(run func(ctx context.Context)) *Repository_ListEffectiveRetentionPolicies_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Repository_ListEffectiveRetentionPolicies_Call) Return(_a0 []*models.RetentionPolicy, _a1 error) *Repository_ListEffectiveRetentionPolicies_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListEffectiveRetentionPolicies_Call) RunAndReturn(run func(context.Context) ([]*models.RetentionPolicy, error)) *Repository_ListEffectiveRetentionPolicies_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFoldersByInbox provides a mock function with given fields: ctx, inboxID, limit, offset
+func (_m *Repository) ListFoldersByInbox(ctx context.Context, inboxID int, limit int, offset int) ([]*models.Folder, int, error) {
+	ret := _m.Called(ctx, inboxID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFoldersByInbox")
+	}
+
+	var r0 []*models.Folder
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Folder, int, error)); ok {
+		return rf(ctx, inboxID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Folder); ok {
+		r0 = rf(ctx, inboxID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Folder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListFoldersByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFoldersByInbox'
+type Repository_ListFoldersByInbox_Call struct {
+	*mock.Call
+}
+
+// ListFoldersByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListFoldersByInbox(ctx interface{}, inboxID interface{}, limit interface{}, offset interface{}) *Repository_ListFoldersByInbox_Call {
+	return &Repository_ListFoldersByInbox_Call{Call: _e.mock.On("ListFoldersByInbox", ctx, inboxID, limit, offset)}
+}
+
+func (_c *Repository_ListFoldersByInbox_Call) Run(run func(ctx context.Context, inboxID int, limit int, offset int)) *Repository_ListFoldersByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListFoldersByInbox_Call) Return(_a0 []*models.Folder, _a1 int, _a2 error) *Repository_ListFoldersByInbox_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListFoldersByInbox_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Folder, int, error)) *Repository_ListFoldersByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListInboxesByProject provides a mock function with given fields: ctx, projectID, limit, offset
+func (_m *Repository) ListInboxesByProject(ctx context.Context, projectID int, limit int, offset int) ([]*models.Inbox, int, error) {
+	ret := _m.Called(ctx, projectID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInboxesByProject")
+	}
+
+	var r0 []*models.Inbox
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Inbox, int, error)); ok {
+		return rf(ctx, projectID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Inbox); ok {
+		r0 = rf(ctx, projectID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Inbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, projectID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, projectID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListInboxesByProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInboxesByProject'
+type Repository_ListInboxesByProject_Call struct {
+	*mock.Call
+}
+
+// ListInboxesByProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListInboxesByProject(ctx interface{}, projectID interface{}, limit interface{}, offset interface{}) *Repository_ListInboxesByProject_Call {
+	return &Repository_ListInboxesByProject_Call{Call: _e.mock.On("ListInboxesByProject", ctx, projectID, limit, offset)}
+}
+
+func (_c *Repository_ListInboxesByProject_Call) Run(run func(ctx context.Context, projectID int, limit int, offset int)) *Repository_ListInboxesByProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListInboxesByProject_Call) Return(_a0 []*models.Inbox, _a1 int, _a2 error) *Repository_ListInboxesByProject_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListInboxesByProject_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Inbox, int, error)) *Repository_ListInboxesByProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListInboxesByUser provides a mock function with given fields: ctx, userID
+func (_m *Repository) ListInboxesByUser(ctx context.Context, userID int) ([]*models.Inbox, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInboxesByUser")
+	}
+
+	var r0 []*models.Inbox
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*models.Inbox, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*models.Inbox); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Inbox)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListInboxesByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInboxesByUser'
+type Repository_ListInboxesByUser_Call struct {
+	*mock.Call
+}
+
+// ListInboxesByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *Repository_Expecter) ListInboxesByUser(ctx interface{}, userID interface{}) *Repository_ListInboxesByUser_Call {
+	return &Repository_ListInboxesByUser_Call{Call: _e.mock.On("ListInboxesByUser", ctx, userID)}
+}
+
+func (_c *Repository_ListInboxesByUser_Call) Run(run func(ctx context.Context, userID int)) *Repository_ListInboxesByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListInboxesByUser_Call) Return(_a0 []*models.Inbox, _a1 error) *Repository_ListInboxesByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListInboxesByUser_Call) RunAndReturn(run func(context.Context, int) ([]*models.Inbox, error)) *Repository_ListInboxesByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessageFlagsByFolder provides a mock function with given fields: ctx, inboxID, folderID
+func (_m *Repository) ListMessageFlagsByFolder(ctx context.Context, inboxID int, folderID null.Int) ([]*models.Message, error) {
+	ret := _m.Called(ctx, inboxID, folderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessageFlagsByFolder")
+	}
+
+	var r0 []*models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) ([]*models.Message, error)); ok {
+		return rf(ctx, inboxID, folderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int) []*models.Message); ok {
+		r0 = rf(ctx, inboxID, folderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int) error); ok {
+		r1 = rf(ctx, inboxID, folderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_ListMessageFlagsByFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessageFlagsByFolder'
+type Repository_ListMessageFlagsByFolder_Call struct {
+	*mock.Call
+}
+
+// ListMessageFlagsByFolder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - folderID null.Int
+func (_e *Repository_Expecter) ListMessageFlagsByFolder(ctx interface{}, inboxID interface{}, folderID interface{}) *Repository_ListMessageFlagsByFolder_Call {
+	return &Repository_ListMessageFlagsByFolder_Call{Call: _e.mock.On("ListMessageFlagsByFolder", ctx, inboxID, folderID)}
+}
+
+func (_c *Repository_ListMessageFlagsByFolder_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int)) *Repository_ListMessageFlagsByFolder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListMessageFlagsByFolder_Call) Return(_a0 []*models.Message, _a1 error) *Repository_ListMessageFlagsByFolder_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_ListMessageFlagsByFolder_Call) RunAndReturn(run func(context.Context, int, null.Int) ([]*models.Message, error)) *Repository_ListMessageFlagsByFolder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessagesByFilter provides a mock function with given fields: ctx, filter, limit, offset
+func (_m *Repository) ListMessagesByFilter(ctx context.Context, filter *models.MessageFilter, limit int, offset int) ([]*models.Message, int, error) {
+	ret := _m.Called(ctx, filter, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessagesByFilter")
+	}
+
+	var r0 []*models.Message
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.MessageFilter, int, int) ([]*models.Message, int, error)); ok {
+		return rf(ctx, filter, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.MessageFilter, int, int) []*models.Message); ok {
+		r0 = rf(ctx, filter, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.MessageFilter, int, int) int); ok {
+		r1 = rf(ctx, filter, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *models.MessageFilter, int, int) error); ok {
+		r2 = rf(ctx, filter, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListMessagesByFilter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessagesByFilter'
+type Repository_ListMessagesByFilter_Call struct {
+	*mock.Call
+}
+
+// ListMessagesByFilter is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter *models.MessageFilter
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListMessagesByFilter(ctx interface{}, filter interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByFilter_Call {
+	return &Repository_ListMessagesByFilter_Call{Call: _e.mock.On("ListMessagesByFilter", ctx, filter, limit, offset)}
+}
+
+func (_c *Repository_ListMessagesByFilter_Call) Run(run func(ctx context.Context, filter *models.MessageFilter, limit int, offset int)) *Repository_ListMessagesByFilter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.MessageFilter), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListMessagesByFilter_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByFilter_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListMessagesByFilter_Call) RunAndReturn(run func(context.Context, *models.MessageFilter, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByFilter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessagesByFolder provides a mock function with given fields: ctx, inboxID, folderID, limit, offset
+func (_m *Repository) ListMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int, limit int, offset int) ([]*models.Message, int, error) {
+	ret := _m.Called(ctx, inboxID, folderID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessagesByFolder")
+	}
+
+	var r0 []*models.Message
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, int, int) ([]*models.Message, int, error)); ok {
+		return rf(ctx, inboxID, folderID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, int, int) []*models.Message); ok {
+		r0 = rf(ctx, inboxID, folderID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int, int, int) int); ok {
+		r1 = rf(ctx, inboxID, folderID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, null.Int, int, int) error); ok {
+		r2 = rf(ctx, inboxID, folderID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListMessagesByFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessagesByFolder'
+type Repository_ListMessagesByFolder_Call struct {
+	*mock.Call
+}
+
+// ListMessagesByFolder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - folderID null.Int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListMessagesByFolder(ctx interface{}, inboxID interface{}, folderID interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByFolder_Call {
+	return &Repository_ListMessagesByFolder_Call{Call: _e.mock.On("ListMessagesByFolder", ctx, inboxID, folderID, limit, offset)}
+}
+
+func (_c *Repository_ListMessagesByFolder_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int, limit int, offset int)) *Repository_ListMessagesByFolder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListMessagesByFolder_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByFolder_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListMessagesByFolder_Call) RunAndReturn(run func(context.Context, int, null.Int, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByFolder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessagesByInbox provides a mock function with given fields: ctx, inboxID, limit, offset
+func (_m *Repository) ListMessagesByInbox(ctx context.Context, inboxID int, limit int, offset int) ([]*models.Message, int, error) {
+	ret := _m.Called(ctx, inboxID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessagesByInbox")
+	}
+
+	var r0 []*models.Message
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Message, int, error)); ok {
+		return rf(ctx, inboxID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Message); ok {
+		r0 = rf(ctx, inboxID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListMessagesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessagesByInbox'
+type Repository_ListMessagesByInbox_Call struct {
+	*mock.Call
+}
+
+// ListMessagesByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListMessagesByInbox(ctx interface{}, inboxID interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByInbox_Call {
+	return &Repository_ListMessagesByInbox_Call{Call: _e.mock.On("ListMessagesByInbox", ctx, inboxID, limit, offset)}
+}
+
+func (_c *Repository_ListMessagesByInbox_Call) Run(run func(ctx context.Context, inboxID int, limit int, offset int)) *Repository_ListMessagesByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListMessagesByInbox_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByInbox_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListMessagesByInbox_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessagesByInboxWithFilter provides a mock function with given fields: ctx, inboxID, isRead, receiver, limit, offset
+func (_m *Repository) ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, receiver string, limit int, offset int) ([]*models.Message, int, error) {
+	ret := _m.Called(ctx, inboxID, isRead, receiver, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessagesByInboxWithFilter")
+	}
+
+	var r0 []*models.Message
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *bool, string, int, int) ([]*models.Message, int, error)); ok {
+		return rf(ctx, inboxID, isRead, receiver, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *bool, string, int, int) []*models.Message); ok {
+		r0 = rf(ctx, inboxID, isRead, receiver, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *bool, string, int, int) int); ok {
+		r1 = rf(ctx, inboxID, isRead, receiver, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, *bool, string, int, int) error); ok {
+		r2 = rf(ctx, inboxID, isRead, receiver, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // Repository_ListMessagesByInboxWithFilter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessagesByInboxWithFilter'
@@ -1237,449 +3925,1581 @@ type Repository_ListMessagesByInboxWithFilter_Call struct {
 	*mock.Call
 }
 
-// ListMessagesByInboxWithFilter is a helper method to define mock.On call
+// ListMessagesByInboxWithFilter is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - isRead *bool
+//   - receiver string
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListMessagesByInboxWithFilter(ctx interface{}, inboxID interface{}, isRead interface{}, receiver interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByInboxWithFilter_Call {
+	return &Repository_ListMessagesByInboxWithFilter_Call{Call: _e.mock.On("ListMessagesByInboxWithFilter", ctx, inboxID, isRead, receiver, limit, offset)}
+}
+
+func (_c *Repository_ListMessagesByInboxWithFilter_Call) Run(run func(ctx context.Context, inboxID int, isRead *bool, receiver string, limit int, offset int)) *Repository_ListMessagesByInboxWithFilter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(*bool), args[3].(string), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListMessagesByInboxWithFilter_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByInboxWithFilter_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListMessagesByInboxWithFilter_Call) RunAndReturn(run func(context.Context, int, *bool, string, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByInboxWithFilter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOutboundMessagesByInbox provides a mock function with given fields: ctx, inboxID, status, limit, offset
+func (_m *Repository) ListOutboundMessagesByInbox(ctx context.Context, inboxID int, status string, limit int, offset int) ([]*models.OutboundMessage, int, error) {
+	ret := _m.Called(ctx, inboxID, status, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOutboundMessagesByInbox")
+	}
+
+	var r0 []*models.OutboundMessage
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, int) ([]*models.OutboundMessage, int, error)); ok {
+		return rf(ctx, inboxID, status, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, int) []*models.OutboundMessage); ok {
+		r0 = rf(ctx, inboxID, status, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.OutboundMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int, int) int); ok {
+		r1 = rf(ctx, inboxID, status, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, int, int) error); ok {
+		r2 = rf(ctx, inboxID, status, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListOutboundMessagesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOutboundMessagesByInbox'
+type Repository_ListOutboundMessagesByInbox_Call struct {
+	*mock.Call
+}
+
+// ListOutboundMessagesByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - status string
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListOutboundMessagesByInbox(ctx interface{}, inboxID interface{}, status interface{}, limit interface{}, offset interface{}) *Repository_ListOutboundMessagesByInbox_Call {
+	return &Repository_ListOutboundMessagesByInbox_Call{Call: _e.mock.On("ListOutboundMessagesByInbox", ctx, inboxID, status, limit, offset)}
+}
+
+func (_c *Repository_ListOutboundMessagesByInbox_Call) Run(run func(ctx context.Context, inboxID int, status string, limit int, offset int)) *Repository_ListOutboundMessagesByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListOutboundMessagesByInbox_Call) Return(_a0 []*models.OutboundMessage, _a1 int, _a2 error) *Repository_ListOutboundMessagesByInbox_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListOutboundMessagesByInbox_Call) RunAndReturn(run func(context.Context, int, string, int, int) ([]*models.OutboundMessage, int, error)) *Repository_ListOutboundMessagesByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListProjects provides a mock function with given fields: ctx, limit, offset
+func (_m *Repository) ListProjects(ctx context.Context, limit int, offset int) ([]*models.Project, int, error) {
+	ret := _m.Called(ctx, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProjects")
+	}
+
+	var r0 []*models.Project
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.Project, int, error)); ok {
+		return rf(ctx, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.Project); ok {
+		r0 = rf(ctx, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = rf(ctx, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListProjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProjects'
+type Repository_ListProjects_Call struct {
+	*mock.Call
+}
+
+// ListProjects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListProjects(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListProjects_Call {
+	return &Repository_ListProjects_Call{Call: _e.mock.On("ListProjects", ctx, limit, offset)}
+}
+
+func (_c *Repository_ListProjects_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListProjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListProjects_Call) Return(_a0 []*models.Project, _a1 int, _a2 error) *Repository_ListProjects_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListProjects_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.Project, int, error)) *Repository_ListProjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListProjectsByUser provides a mock function with given fields: ctx, userID, limit, offset
+func (_m *Repository) ListProjectsByUser(ctx context.Context, userID int, limit int, offset int) ([]*models.Project, int, error) {
+	ret := _m.Called(ctx, userID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListProjectsByUser")
+	}
+
+	var r0 []*models.Project
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Project, int, error)); ok {
+		return rf(ctx, userID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Project); ok {
+		r0 = rf(ctx, userID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Project)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, userID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, userID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListProjectsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProjectsByUser'
+type Repository_ListProjectsByUser_Call struct {
+	*mock.Call
+}
+
+// ListProjectsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListProjectsByUser(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *Repository_ListProjectsByUser_Call {
+	return &Repository_ListProjectsByUser_Call{Call: _e.mock.On("ListProjectsByUser", ctx, userID, limit, offset)}
+}
+
+func (_c *Repository_ListProjectsByUser_Call) Run(run func(ctx context.Context, userID int, limit int, offset int)) *Repository_ListProjectsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListProjectsByUser_Call) Return(_a0 []*models.Project, _a1 int, _a2 error) *Repository_ListProjectsByUser_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListProjectsByUser_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Project, int, error)) *Repository_ListProjectsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRules provides a mock function with given fields: ctx, limit, offset
+func (_m *Repository) ListRules(ctx context.Context, limit int, offset int) ([]*models.ForwardRule, int, error) {
+	ret := _m.Called(ctx, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRules")
+	}
+
+	var r0 []*models.ForwardRule
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.ForwardRule, int, error)); ok {
+		return rf(ctx, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.ForwardRule); ok {
+		r0 = rf(ctx, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ForwardRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = rf(ctx, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRules'
+type Repository_ListRules_Call struct {
+	*mock.Call
+}
+
+// ListRules is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListRules(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListRules_Call {
+	return &Repository_ListRules_Call{Call: _e.mock.On("ListRules", ctx, limit, offset)}
+}
+
+func (_c *Repository_ListRules_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListRules_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListRules_Call) Return(_a0 []*models.ForwardRule, _a1 int, _a2 error) *Repository_ListRules_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListRules_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.ForwardRule, int, error)) *Repository_ListRules_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRulesByInbox provides a mock function with given fields: ctx, inboxID, limit, offset
+func (_m *Repository) ListRulesByInbox(ctx context.Context, inboxID int, limit int, offset int) ([]*models.ForwardRule, int, error) {
+	ret := _m.Called(ctx, inboxID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRulesByInbox")
+	}
+
+	var r0 []*models.ForwardRule
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.ForwardRule, int, error)); ok {
+		return rf(ctx, inboxID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.ForwardRule); ok {
+		r0 = rf(ctx, inboxID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ForwardRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, inboxID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListRulesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRulesByInbox'
+type Repository_ListRulesByInbox_Call struct {
+	*mock.Call
+}
+
+// ListRulesByInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListRulesByInbox(ctx interface{}, inboxID interface{}, limit interface{}, offset interface{}) *Repository_ListRulesByInbox_Call {
+	return &Repository_ListRulesByInbox_Call{Call: _e.mock.On("ListRulesByInbox", ctx, inboxID, limit, offset)}
+}
+
+func (_c *Repository_ListRulesByInbox_Call) Run(run func(ctx context.Context, inboxID int, limit int, offset int)) *Repository_ListRulesByInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListRulesByInbox_Call) Return(_a0 []*models.ForwardRule, _a1 int, _a2 error) *Repository_ListRulesByInbox_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListRulesByInbox_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.ForwardRule, int, error)) *Repository_ListRulesByInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessionsByUser provides a mock function with given fields: ctx, userID, limit, offset
+func (_m *Repository) ListSessionsByUser(ctx context.Context, userID int, limit int, offset int) ([]*models.Session, int, error) {
+	ret := _m.Called(ctx, userID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessionsByUser")
+	}
+
+	var r0 []*models.Session
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Session, int, error)); ok {
+		return rf(ctx, userID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Session); ok {
+		r0 = rf(ctx, userID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, userID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, userID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListSessionsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessionsByUser'
+type Repository_ListSessionsByUser_Call struct {
+	*mock.Call
+}
+
+// ListSessionsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListSessionsByUser(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *Repository_ListSessionsByUser_Call {
+	return &Repository_ListSessionsByUser_Call{Call: _e.mock.On("ListSessionsByUser", ctx, userID, limit, offset)}
+}
+
+func (_c *Repository_ListSessionsByUser_Call) Run(run func(ctx context.Context, userID int, limit int, offset int)) *Repository_ListSessionsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListSessionsByUser_Call) Return(_a0 []*models.Session, _a1 int, _a2 error) *Repository_ListSessionsByUser_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListSessionsByUser_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Session, int, error)) *Repository_ListSessionsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTokensByUser provides a mock function with given fields: ctx, userID, limit, offset
+func (_m *Repository) ListTokensByUser(ctx context.Context, userID int, limit int, offset int) ([]*models.Token, int, error) {
+	ret := _m.Called(ctx, userID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTokensByUser")
+	}
+
+	var r0 []*models.Token
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Token, int, error)); ok {
+		return rf(ctx, userID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Token); ok {
+		r0 = rf(ctx, userID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, userID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, userID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListTokensByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTokensByUser'
+type Repository_ListTokensByUser_Call struct {
+	*mock.Call
+}
+
+// ListTokensByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListTokensByUser(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *Repository_ListTokensByUser_Call {
+	return &Repository_ListTokensByUser_Call{Call: _e.mock.On("ListTokensByUser", ctx, userID, limit, offset)}
+}
+
+func (_c *Repository_ListTokensByUser_Call) Run(run func(ctx context.Context, userID int, limit int, offset int)) *Repository_ListTokensByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListTokensByUser_Call) Return(_a0 []*models.Token, _a1 int, _a2 error) *Repository_ListTokensByUser_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListTokensByUser_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Token, int, error)) *Repository_ListTokensByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: ctx, limit, offset
+func (_m *Repository) ListUsers(ctx context.Context, limit int, offset int) ([]*models.User, int, error) {
+	ret := _m.Called(ctx, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []*models.User
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.User, int, error)); ok {
+		return rf(ctx, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.User); ok {
+		r0 = rf(ctx, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = rf(ctx, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type Repository_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListUsers(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListUsers_Call {
+	return &Repository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, limit, offset)}
+}
+
+func (_c *Repository_ListUsers_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListUsers_Call) Return(_a0 []*models.User, _a1 int, _a2 error) *Repository_ListUsers_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListUsers_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.User, int, error)) *Repository_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookDeliveries provides a mock function with given fields: ctx, webhookID, status, limit, offset
+func (_m *Repository) ListWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int, offset int) ([]*models.WebhookDelivery, int, error) {
+	ret := _m.Called(ctx, webhookID, status, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookDeliveries")
+	}
+
+	var r0 []*models.WebhookDelivery
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, int) ([]*models.WebhookDelivery, int, error)); ok {
+		return rf(ctx, webhookID, status, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, int) []*models.WebhookDelivery); ok {
+		r0 = rf(ctx, webhookID, status, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int, int) int); ok {
+		r1 = rf(ctx, webhookID, status, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, int, int) error); ok {
+		r2 = rf(ctx, webhookID, status, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookDeliveries'
+type Repository_ListWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// ListWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID int
+//   - status string
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListWebhookDeliveries(ctx interface{}, webhookID interface{}, status interface{}, limit interface{}, offset interface{}) *Repository_ListWebhookDeliveries_Call {
+	return &Repository_ListWebhookDeliveries_Call{Call: _e.mock.On("ListWebhookDeliveries", ctx, webhookID, status, limit, offset)}
+}
+
+func (_c *Repository_ListWebhookDeliveries_Call) Run(run func(ctx context.Context, webhookID int, status string, limit int, offset int)) *Repository_ListWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListWebhookDeliveries_Call) Return(_a0 []*models.WebhookDelivery, _a1 int, _a2 error) *Repository_ListWebhookDeliveries_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListWebhookDeliveries_Call) RunAndReturn(run func(context.Context, int, string, int, int) ([]*models.WebhookDelivery, int, error)) *Repository_ListWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooksByProject provides a mock function with given fields: ctx, projectID, limit, offset
+func (_m *Repository) ListWebhooksByProject(ctx context.Context, projectID int, limit int, offset int) ([]*models.Webhook, int, error) {
+	ret := _m.Called(ctx, projectID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooksByProject")
+	}
+
+	var r0 []*models.Webhook
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Webhook, int, error)); ok {
+		return rf(ctx, projectID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Webhook); ok {
+		r0 = rf(ctx, projectID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
+		r1 = rf(ctx, projectID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, projectID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Repository_ListWebhooksByProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooksByProject'
+type Repository_ListWebhooksByProject_Call struct {
+	*mock.Call
+}
+
+// ListWebhooksByProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+//   - limit int
+//   - offset int
+func (_e *Repository_Expecter) ListWebhooksByProject(ctx interface{}, projectID interface{}, limit interface{}, offset interface{}) *Repository_ListWebhooksByProject_Call {
+	return &Repository_ListWebhooksByProject_Call{Call: _e.mock.On("ListWebhooksByProject", ctx, projectID, limit, offset)}
+}
+
+func (_c *Repository_ListWebhooksByProject_Call) Run(run func(ctx context.Context, projectID int, limit int, offset int)) *Repository_ListWebhooksByProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ListWebhooksByProject_Call) Return(_a0 []*models.Webhook, _a1 int, _a2 error) *Repository_ListWebhooksByProject_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *Repository_ListWebhooksByProject_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Webhook, int, error)) *Repository_ListWebhooksByProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MoveMessages provides a mock function with given fields: ctx, ids, inboxID, folderID
+func (_m *Repository) MoveMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error) {
+	ret := _m.Called(ctx, ids, inboxID, folderID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MoveMessages")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int, null.Int) ([]int, error)); ok {
+		return rf(ctx, ids, inboxID, folderID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int, int, null.Int) []int); ok {
+		r0 = rf(ctx, ids, inboxID, folderID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int, int, null.Int) error); ok {
+		r1 = rf(ctx, ids, inboxID, folderID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_MoveMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MoveMessages'
+type Repository_MoveMessages_Call struct {
+	*mock.Call
+}
+
+// MoveMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []int
+//   - inboxID int
+//   - folderID null.Int
+func (_e *Repository_Expecter) MoveMessages(ctx interface{}, ids interface{}, inboxID interface{}, folderID interface{}) *Repository_MoveMessages_Call {
+	return &Repository_MoveMessages_Call{Call: _e.mock.On("MoveMessages", ctx, ids, inboxID, folderID)}
+}
+
+func (_c *Repository_MoveMessages_Call) Run(run func(ctx context.Context, ids []int, inboxID int, folderID null.Int)) *Repository_MoveMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int), args[2].(int), args[3].(null.Int))
+	})
+	return _c
+}
+
+func (_c *Repository_MoveMessages_Call) Return(_a0 []int, _a1 error) *Repository_MoveMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_MoveMessages_Call) RunAndReturn(run func(context.Context, []int, int, null.Int) ([]int, error)) *Repository_MoveMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ProjectAddUser provides a mock function with given fields: ctx, projectUser
+func (_m *Repository) ProjectAddUser(ctx context.Context, projectUser *models.ProjectUser) error {
+	ret := _m.Called(ctx, projectUser)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProjectAddUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ProjectUser) error); ok {
+		r0 = rf(ctx, projectUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_ProjectAddUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProjectAddUser'
+type Repository_ProjectAddUser_Call struct {
+	*mock.Call
+}
+
+// ProjectAddUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectUser *models.ProjectUser
+func (_e *Repository_Expecter) ProjectAddUser(ctx interface{}, projectUser interface{}) *Repository_ProjectAddUser_Call {
+	return &Repository_ProjectAddUser_Call{Call: _e.mock.On("ProjectAddUser", ctx, projectUser)}
+}
+
+func (_c *Repository_ProjectAddUser_Call) Run(run func(ctx context.Context, projectUser *models.ProjectUser)) *Repository_ProjectAddUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.ProjectUser))
+	})
+	return _c
+}
+
+func (_c *Repository_ProjectAddUser_Call) Return(_a0 error) *Repository_ProjectAddUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_ProjectAddUser_Call) RunAndReturn(run func(context.Context, *models.ProjectUser) error) *Repository_ProjectAddUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ProjectRemoveUser provides a mock function with given fields: ctx, projectID, userID
+func (_m *Repository) ProjectRemoveUser(ctx context.Context, projectID int, userID int) error {
+	ret := _m.Called(ctx, projectID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProjectRemoveUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, projectID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_ProjectRemoveUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProjectRemoveUser'
+type Repository_ProjectRemoveUser_Call struct {
+	*mock.Call
+}
+
+// ProjectRemoveUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - projectID int
+//   - userID int
+func (_e *Repository_Expecter) ProjectRemoveUser(ctx interface{}, projectID interface{}, userID interface{}) *Repository_ProjectRemoveUser_Call {
+	return &Repository_ProjectRemoveUser_Call{Call: _e.mock.On("ProjectRemoveUser", ctx, projectID, userID)}
+}
+
+func (_c *Repository_ProjectRemoveUser_Call) Run(run func(ctx context.Context, projectID int, userID int)) *Repository_ProjectRemoveUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_ProjectRemoveUser_Call) Return(_a0 error) *Repository_ProjectRemoveUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_ProjectRemoveUser_Call) RunAndReturn(run func(context.Context, int, int) error) *Repository_ProjectRemoveUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeMessages provides a mock function with given fields: ctx, policy, now, limit
+func (_m *Repository) PurgeMessages(ctx context.Context, policy *models.RetentionPolicy, now time.Time, limit int) ([]*models.Message, error) {
+	ret := _m.Called(ctx, policy, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeMessages")
+	}
+
+	var r0 []*models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RetentionPolicy, time.Time, int) ([]*models.Message, error)); ok {
+		return rf(ctx, policy, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RetentionPolicy, time.Time, int) []*models.Message); ok {
+		r0 = rf(ctx, policy, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.RetentionPolicy, time.Time, int) error); ok {
+		r1 = rf(ctx, policy, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_PurgeMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeMessages'
+type Repository_PurgeMessages_Call struct {
+	*mock.Call
+}
+
+// PurgeMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - policy *models.RetentionPolicy
+//   - now time.Time
+//   - limit int
+func (_e *Repository_Expecter) PurgeMessages(ctx interface{}, policy interface{}, now interface{}, limit interface{}) *Repository_PurgeMessages_Call {
+	return &Repository_PurgeMessages_Call{Call: _e.mock.On("PurgeMessages", ctx, policy, now, limit)}
+}
+
+func (_c *Repository_PurgeMessages_Call) Run(run func(ctx context.Context, policy *models.RetentionPolicy, now time.Time, limit int)) *Repository_PurgeMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.RetentionPolicy), args[2].(time.Time), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_PurgeMessages_Call) Return(_a0 []*models.Message, _a1 error) *Repository_PurgeMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_PurgeMessages_Call) RunAndReturn(run func(context.Context, *models.RetentionPolicy, time.Time, int) ([]*models.Message, error)) *Repository_PurgeMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QueueOutboundMessage provides a mock function with given fields: ctx, outbound, sent
+func (_m *Repository) QueueOutboundMessage(ctx context.Context, outbound *models.OutboundMessage, sent *models.Message) error {
+	ret := _m.Called(ctx, outbound, sent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueueOutboundMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OutboundMessage, *models.Message) error); ok {
+		r0 = rf(ctx, outbound, sent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_QueueOutboundMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueueOutboundMessage'
+type Repository_QueueOutboundMessage_Call struct {
+	*mock.Call
+}
+
+// QueueOutboundMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - outbound *models.OutboundMessage
+//   - sent *models.Message
+func (_e *Repository_Expecter) QueueOutboundMessage(ctx interface{}, outbound interface{}, sent interface{}) *Repository_QueueOutboundMessage_Call {
+	return &Repository_QueueOutboundMessage_Call{Call: _e.mock.On("QueueOutboundMessage", ctx, outbound, sent)}
+}
+
+func (_c *Repository_QueueOutboundMessage_Call) Run(run func(ctx context.Context, outbound *models.OutboundMessage, sent *models.Message)) *Repository_QueueOutboundMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.OutboundMessage), args[2].(*models.Message))
+	})
+	return _c
+}
+
+func (_c *Repository_QueueOutboundMessage_Call) Return(_a0 error) *Repository_QueueOutboundMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_QueueOutboundMessage_Call) RunAndReturn(run func(context.Context, *models.OutboundMessage, *models.Message) error) *Repository_QueueOutboundMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenameFolder provides a mock function with given fields: ctx, inboxID, oldName, newName
+func (_m *Repository) RenameFolder(ctx context.Context, inboxID int, oldName string, newName string) error {
+	ret := _m.Called(ctx, inboxID, oldName, newName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameFolder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
+		r0 = rf(ctx, inboxID, oldName, newName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_RenameFolder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenameFolder'
+type Repository_RenameFolder_Call struct {
+	*mock.Call
+}
+
+// RenameFolder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inboxID int
+//   - oldName string
+//   - newName string
+func (_e *Repository_Expecter) RenameFolder(ctx interface{}, inboxID interface{}, oldName interface{}, newName interface{}) *Repository_RenameFolder_Call {
+	return &Repository_RenameFolder_Call{Call: _e.mock.On("RenameFolder", ctx, inboxID, oldName, newName)}
+}
+
+func (_c *Repository_RenameFolder_Call) Run(run func(ctx context.Context, inboxID int, oldName string, newName string)) *Repository_RenameFolder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *Repository_RenameFolder_Call) Return(_a0 error) *Repository_RenameFolder_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_RenameFolder_Call) RunAndReturn(run func(context.Context, int, string, string) error) *Repository_RenameFolder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplayWebhookDelivery provides a mock function with given fields: ctx, delivery
+func (_m *Repository) ReplayWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplayWebhookDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_ReplayWebhookDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplayWebhookDelivery'
+type Repository_ReplayWebhookDelivery_Call struct {
+	*mock.Call
+}
+
+// ReplayWebhookDelivery is a helper method to define mock.On call
+//   - ctx context.Context
+//   - delivery *models.WebhookDelivery
+func (_e *Repository_Expecter) ReplayWebhookDelivery(ctx interface{}, delivery interface{}) *Repository_ReplayWebhookDelivery_Call {
+	return &Repository_ReplayWebhookDelivery_Call{Call: _e.mock.On("ReplayWebhookDelivery", ctx, delivery)}
+}
+
+func (_c *Repository_ReplayWebhookDelivery_Call) Run(run func(ctx context.Context, delivery *models.WebhookDelivery)) *Repository_ReplayWebhookDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.WebhookDelivery))
+	})
+	return _c
+}
+
+func (_c *Repository_ReplayWebhookDelivery_Call) Return(_a0 error) *Repository_ReplayWebhookDelivery_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_ReplayWebhookDelivery_Call) RunAndReturn(run func(context.Context, *models.WebhookDelivery) error) *Repository_ReplayWebhookDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function with given fields: ctx, id, userID
+func (_m *Repository) RevokeSession(ctx context.Context, id int, userID int) error {
+	ret := _m.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Repository_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type Repository_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - userID int
+func (_e *Repository_Expecter) RevokeSession(ctx interface{}, id interface{}, userID interface{}) *Repository_RevokeSession_Call {
+	return &Repository_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, id, userID)}
+}
+
+func (_c *Repository_RevokeSession_Call) Run(run func(ctx context.Context, id int, userID int)) *Repository_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_RevokeSession_Call) Return(_a0 error) *Repository_RevokeSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_RevokeSession_Call) RunAndReturn(run func(context.Context, int, int) error) *Repository_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSessionsByUser provides a mock function with given fields: ctx, userID
+func (_m *Repository) RevokeSessionsByUser(ctx context.Context, userID int) (int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessionsByUser")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_RevokeSessionsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSessionsByUser'
+type Repository_RevokeSessionsByUser_Call struct {
+	*mock.Call
+}
+
+// RevokeSessionsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *Repository_Expecter) RevokeSessionsByUser(ctx interface{}, userID interface{}) *Repository_RevokeSessionsByUser_Call {
+	return &Repository_RevokeSessionsByUser_Call{Call: _e.mock.On("RevokeSessionsByUser", ctx, userID)}
+}
+
+func (_c *Repository_RevokeSessionsByUser_Call) Run(run func(ctx context.Context, userID int)) *Repository_RevokeSessionsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Repository_RevokeSessionsByUser_Call) Return(_a0 int, _a1 error) *Repository_RevokeSessionsByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Repository_RevokeSessionsByUser_Call) RunAndReturn(run func(context.Context, int) (int, error)) *Repository_RevokeSessionsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchMessages provides a mock function with given fields: ctx, inboxID, folderID, search
+func (_m *Repository) SearchMessages(ctx context.Context, inboxID int, folderID null.Int, search *models.MessageSearch) ([]int, error) {
+	ret := _m.Called(ctx, inboxID, folderID, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchMessages")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, *models.MessageSearch) ([]int, error)); ok {
+		return rf(ctx, inboxID, folderID, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, null.Int, *models.MessageSearch) []int); ok {
+		r0 = rf(ctx, inboxID, folderID, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, null.Int, *models.MessageSearch) error); ok {
+		r1 = rf(ctx, inboxID, folderID, search)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Repository_SearchMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchMessages'
+type Repository_SearchMessages_Call struct {
+	*mock.Call
+}
+
+// SearchMessages is a helper method to define mock.On call
 //   - ctx context.Context
 //   - inboxID int
-//   - isRead *bool
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListMessagesByInboxWithFilter(ctx interface{}, inboxID interface{}, isRead interface{}, limit interface{}, offset interface{}) *Repository_ListMessagesByInboxWithFilter_Call {
-	return &Repository_ListMessagesByInboxWithFilter_Call{Call: _e.mock.On("ListMessagesByInboxWithFilter", ctx, inboxID, isRead, limit, offset)}
+//   - folderID null.Int
+//   - search *models.MessageSearch
+func (_e *Repository_Expecter) SearchMessages(ctx interface{}, inboxID interface{}, folderID interface{}, search interface{}) *Repository_SearchMessages_Call {
+	return &Repository_SearchMessages_Call{Call: _e.mock.On("SearchMessages", ctx, inboxID, folderID, search)}
 }
 
-func (_c *Repository_ListMessagesByInboxWithFilter_Call) Run(run func(ctx context.Context, inboxID int, isRead *bool, limit int, offset int)) *Repository_ListMessagesByInboxWithFilter_Call {
+func (_c *Repository_SearchMessages_Call) Run(run func(ctx context.Context, inboxID int, folderID null.Int, search *models.MessageSearch)) *Repository_SearchMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(*bool), args[3].(int), args[4].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(null.Int), args[3].(*models.MessageSearch))
 	})
 	return _c
 }
 
-func (_c *Repository_ListMessagesByInboxWithFilter_Call) Return(_a0 []*models.Message, _a1 int, _a2 error) *Repository_ListMessagesByInboxWithFilter_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_SearchMessages_Call) Return(_a0 []int, _a1 error) *Repository_SearchMessages_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Repository_ListMessagesByInboxWithFilter_Call) RunAndReturn(run func(context.Context, int, *bool, int, int) ([]*models.Message, int, error)) *Repository_ListMessagesByInboxWithFilter_Call {
+func (_c *Repository_SearchMessages_Call) RunAndReturn(run func(context.Context, int, null.Int, *models.MessageSearch) ([]int, error)) *Repository_SearchMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListProjects provides a mock function with given fields: ctx, limit, offset
-func (_m *Repository) ListProjects(ctx context.Context, limit int, offset int) ([]*models.Project, int, error) {
-	ret := _m.Called(ctx, limit, offset)
+// TouchSession provides a mock function with given fields: ctx, id
+func (_m *Repository) TouchSession(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListProjects")
-	}
-
-	var r0 []*models.Project
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.Project, int, error)); ok {
-		return rf(ctx, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.Project); ok {
-		r0 = rf(ctx, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Project)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
-		r1 = rf(ctx, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for TouchSession")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
-		r2 = rf(ctx, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListProjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProjects'
-type Repository_ListProjects_Call struct {
+// Repository_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type Repository_TouchSession_Call struct {
 	*mock.Call
 }
 
-// ListProjects is a helper method to define mock.On call
+// TouchSession is a helper method to define mock.On call
 //   - ctx context.Context
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListProjects(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListProjects_Call {
-	return &Repository_ListProjects_Call{Call: _e.mock.On("ListProjects", ctx, limit, offset)}
+//   - id int
+func (_e *Repository_Expecter) TouchSession(ctx interface{}, id interface{}) *Repository_TouchSession_Call {
+	return &Repository_TouchSession_Call{Call: _e.mock.On("TouchSession", ctx, id)}
 }
 
-func (_c *Repository_ListProjects_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListProjects_Call {
+func (_c *Repository_TouchSession_Call) Run(run func(ctx context.Context, id int)) *Repository_TouchSession_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_ListProjects_Call) Return(_a0 []*models.Project, _a1 int, _a2 error) *Repository_ListProjects_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_TouchSession_Call) Return(_a0 error) *Repository_TouchSession_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListProjects_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.Project, int, error)) *Repository_ListProjects_Call {
+func (_c *Repository_TouchSession_Call) RunAndReturn(run func(context.Context, int) error) *Repository_TouchSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListProjectsByUser provides a mock function with given fields: ctx, userID, limit, offset
-func (_m *Repository) ListProjectsByUser(ctx context.Context, userID int, limit int, offset int) ([]*models.Project, int, error) {
-	ret := _m.Called(ctx, userID, limit, offset)
+// UpdateInbox provides a mock function with given fields: ctx, inbox
+func (_m *Repository) UpdateInbox(ctx context.Context, inbox *models.Inbox) error {
+	ret := _m.Called(ctx, inbox)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListProjectsByUser")
-	}
-
-	var r0 []*models.Project
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Project, int, error)); ok {
-		return rf(ctx, userID, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Project); ok {
-		r0 = rf(ctx, userID, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Project)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
-		r1 = rf(ctx, userID, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for UpdateInbox")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
-		r2 = rf(ctx, userID, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Inbox) error); ok {
+		r0 = rf(ctx, inbox)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListProjectsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListProjectsByUser'
-type Repository_ListProjectsByUser_Call struct {
+// Repository_UpdateInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateInbox'
+type Repository_UpdateInbox_Call struct {
 	*mock.Call
 }
 
-// ListProjectsByUser is a helper method to define mock.On call
+// UpdateInbox is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userID int
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListProjectsByUser(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *Repository_ListProjectsByUser_Call {
-	return &Repository_ListProjectsByUser_Call{Call: _e.mock.On("ListProjectsByUser", ctx, userID, limit, offset)}
+//   - inbox *models.Inbox
+func (_e *Repository_Expecter) UpdateInbox(ctx interface{}, inbox interface{}) *Repository_UpdateInbox_Call {
+	return &Repository_UpdateInbox_Call{Call: _e.mock.On("UpdateInbox", ctx, inbox)}
 }
 
-func (_c *Repository_ListProjectsByUser_Call) Run(run func(ctx context.Context, userID int, limit int, offset int)) *Repository_ListProjectsByUser_Call {
+func (_c *Repository_UpdateInbox_Call) Run(run func(ctx context.Context, inbox *models.Inbox)) *Repository_UpdateInbox_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(*models.Inbox))
 	})
 	return _c
 }
 
-func (_c *Repository_ListProjectsByUser_Call) Return(_a0 []*models.Project, _a1 int, _a2 error) *Repository_ListProjectsByUser_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_UpdateInbox_Call) Return(_a0 error) *Repository_UpdateInbox_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListProjectsByUser_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Project, int, error)) *Repository_ListProjectsByUser_Call {
+func (_c *Repository_UpdateInbox_Call) RunAndReturn(run func(context.Context, *models.Inbox) error) *Repository_UpdateInbox_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListRules provides a mock function with given fields: ctx, limit, offset
-func (_m *Repository) ListRules(ctx context.Context, limit int, offset int) ([]*models.ForwardRule, int, error) {
-	ret := _m.Called(ctx, limit, offset)
+// UpdateMessageFlags provides a mock function with given fields: ctx, message
+func (_m *Repository) UpdateMessageFlags(ctx context.Context, message *models.Message) error {
+	ret := _m.Called(ctx, message)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListRules")
-	}
-
-	var r0 []*models.ForwardRule
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.ForwardRule, int, error)); ok {
-		return rf(ctx, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.ForwardRule); ok {
-		r0 = rf(ctx, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.ForwardRule)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
-		r1 = rf(ctx, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for UpdateMessageFlags")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
-		r2 = rf(ctx, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Message) error); ok {
+		r0 = rf(ctx, message)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListRules_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRules'
-type Repository_ListRules_Call struct {
+// Repository_UpdateMessageFlags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessageFlags'
+type Repository_UpdateMessageFlags_Call struct {
 	*mock.Call
 }
 
-// ListRules is a helper method to define mock.On call
+// UpdateMessageFlags is a helper method to define mock.On call
 //   - ctx context.Context
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListRules(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListRules_Call {
-	return &Repository_ListRules_Call{Call: _e.mock.On("ListRules", ctx, limit, offset)}
+//   - message *models.Message
+func (_e *Repository_Expecter) UpdateMessageFlags(ctx interface{}, message interface{}) *Repository_UpdateMessageFlags_Call {
+	return &Repository_UpdateMessageFlags_Call{Call: _e.mock.On("UpdateMessageFlags", ctx, message)}
 }
 
-func (_c *Repository_ListRules_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListRules_Call {
+func (_c *Repository_UpdateMessageFlags_Call) Run(run func(ctx context.Context, message *models.Message)) *Repository_UpdateMessageFlags_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(*models.Message))
 	})
 	return _c
 }
 
-func (_c *Repository_ListRules_Call) Return(_a0 []*models.ForwardRule, _a1 int, _a2 error) *Repository_ListRules_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_UpdateMessageFlags_Call) Return(_a0 error) *Repository_UpdateMessageFlags_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListRules_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.ForwardRule, int, error)) *Repository_ListRules_Call {
+func (_c *Repository_UpdateMessageFlags_Call) RunAndReturn(run func(context.Context, *models.Message) error) *Repository_UpdateMessageFlags_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListRulesByInbox provides a mock function with given fields: ctx, inboxID, limit, offset
-func (_m *Repository) ListRulesByInbox(ctx context.Context, inboxID int, limit int, offset int) ([]*models.ForwardRule, int, error) {
-	ret := _m.Called(ctx, inboxID, limit, offset)
+// UpdateMessageReadStatus provides a mock function with given fields: ctx, messageID, isRead
+func (_m *Repository) UpdateMessageReadStatus(ctx context.Context, messageID int, isRead bool) error {
+	ret := _m.Called(ctx, messageID, isRead)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListRulesByInbox")
-	}
-
-	var r0 []*models.ForwardRule
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.ForwardRule, int, error)); ok {
-		return rf(ctx, inboxID, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.ForwardRule); ok {
-		r0 = rf(ctx, inboxID, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.ForwardRule)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
-		r1 = rf(ctx, inboxID, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for UpdateMessageReadStatus")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
-		r2 = rf(ctx, inboxID, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, messageID, isRead)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListRulesByInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRulesByInbox'
-type Repository_ListRulesByInbox_Call struct {
+// Repository_UpdateMessageReadStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessageReadStatus'
+type Repository_UpdateMessageReadStatus_Call struct {
 	*mock.Call
 }
 
-// ListRulesByInbox is a helper method to define mock.On call
+// UpdateMessageReadStatus is a helper method to define mock.On call
 //   - ctx context.Context
-//   - inboxID int
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListRulesByInbox(ctx interface{}, inboxID interface{}, limit interface{}, offset interface{}) *Repository_ListRulesByInbox_Call {
-	return &Repository_ListRulesByInbox_Call{Call: _e.mock.On("ListRulesByInbox", ctx, inboxID, limit, offset)}
+//   - messageID int
+//   - isRead bool
+func (_e *Repository_Expecter) UpdateMessageReadStatus(ctx interface{}, messageID interface{}, isRead interface{}) *Repository_UpdateMessageReadStatus_Call {
+	return &Repository_UpdateMessageReadStatus_Call{Call: _e.mock.On("UpdateMessageReadStatus", ctx, messageID, isRead)}
 }
 
-func (_c *Repository_ListRulesByInbox_Call) Run(run func(ctx context.Context, inboxID int, limit int, offset int)) *Repository_ListRulesByInbox_Call {
+func (_c *Repository_UpdateMessageReadStatus_Call) Run(run func(ctx context.Context, messageID int, isRead bool)) *Repository_UpdateMessageReadStatus_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(bool))
 	})
 	return _c
 }
 
-func (_c *Repository_ListRulesByInbox_Call) Return(_a0 []*models.ForwardRule, _a1 int, _a2 error) *Repository_ListRulesByInbox_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_UpdateMessageReadStatus_Call) Return(_a0 error) *Repository_UpdateMessageReadStatus_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListRulesByInbox_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.ForwardRule, int, error)) *Repository_ListRulesByInbox_Call {
+func (_c *Repository_UpdateMessageReadStatus_Call) RunAndReturn(run func(context.Context, int, bool) error) *Repository_UpdateMessageReadStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListTokensByUser provides a mock function with given fields: ctx, userID, limit, offset
-func (_m *Repository) ListTokensByUser(ctx context.Context, userID int, limit int, offset int) ([]*models.Token, int, error) {
-	ret := _m.Called(ctx, userID, limit, offset)
+// UpdateOutboundMessage provides a mock function with given fields: ctx, outbound
+func (_m *Repository) UpdateOutboundMessage(ctx context.Context, outbound *models.OutboundMessage) error {
+	ret := _m.Called(ctx, outbound)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListTokensByUser")
-	}
-
-	var r0 []*models.Token
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]*models.Token, int, error)); ok {
-		return rf(ctx, userID, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []*models.Token); ok {
-		r0 = rf(ctx, userID, limit, offset)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Token)
-		}
-	}
-
-	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int); ok {
-		r1 = rf(ctx, userID, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+		panic("no return value specified for UpdateOutboundMessage")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
-		r2 = rf(ctx, userID, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OutboundMessage) error); ok {
+		r0 = rf(ctx, outbound)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListTokensByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTokensByUser'
-type Repository_ListTokensByUser_Call struct {
+// Repository_UpdateOutboundMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOutboundMessage'
+type Repository_UpdateOutboundMessage_Call struct {
 	*mock.Call
 }
 
-// ListTokensByUser is a helper method to define mock.On call
+// UpdateOutboundMessage is a helper method to define mock.On call
 //   - ctx context.Context
-//   - userID int
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListTokensByUser(ctx interface{}, userID interface{}, limit interface{}, offset interface{}) *Repository_ListTokensByUser_Call {
-	return &Repository_ListTokensByUser_Call{Call: _e.mock.On("ListTokensByUser", ctx, userID, limit, offset)}
+//   - outbound *models.OutboundMessage
+func (_e *Repository_Expecter) UpdateOutboundMessage(ctx interface{}, outbound interface{}) *Repository_UpdateOutboundMessage_Call {
+	return &Repository_UpdateOutboundMessage_Call{Call: _e.mock.On("UpdateOutboundMessage", ctx, outbound)}
 }
 
-func (_c *Repository_ListTokensByUser_Call) Run(run func(ctx context.Context, userID int, limit int, offset int)) *Repository_ListTokensByUser_Call {
+func (_c *Repository_UpdateOutboundMessage_Call) Run(run func(ctx context.Context, outbound *models.OutboundMessage)) *Repository_UpdateOutboundMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(*models.OutboundMessage))
 	})
 	return _c
 }
 
-func (_c *Repository_ListTokensByUser_Call) Return(_a0 []*models.Token, _a1 int, _a2 error) *Repository_ListTokensByUser_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_UpdateOutboundMessage_Call) Return(_a0 error) *Repository_UpdateOutboundMessage_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListTokensByUser_Call) RunAndReturn(run func(context.Context, int, int, int) ([]*models.Token, int, error)) *Repository_ListTokensByUser_Call {
+func (_c *Repository_UpdateOutboundMessage_Call) RunAndReturn(run func(context.Context, *models.OutboundMessage) error) *Repository_UpdateOutboundMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListUsers provides a mock function with given fields: ctx, limit, offset
-func (_m *Repository) ListUsers(ctx context.Context, limit int, offset int) ([]*models.User, int, error) {
-	ret := _m.Called(ctx, limit, offset)
+// UpdateProject provides a mock function with given fields: ctx, project
+func (_m *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
+	ret := _m.Called(ctx, project)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListUsers")
+		panic("no return value specified for UpdateProject")
 	}
 
-	var r0 []*models.User
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]*models.User, int, error)); ok {
-		return rf(ctx, limit, offset)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*models.User); ok {
-		r0 = rf(ctx, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Project) error); ok {
+		r0 = rf(ctx, project)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.User)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
-		r1 = rf(ctx, limit, offset)
-	} else {
-		r1 = ret.Get(1).(int)
+	return r0
+}
+
+// Repository_UpdateProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProject'
+type Repository_UpdateProject_Call struct {
+	*mock.Call
+}
+
+// UpdateProject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - project *models.Project
+func (_e *Repository_Expecter) UpdateProject(ctx interface{}, project interface{}) *Repository_UpdateProject_Call {
+	return &Repository_UpdateProject_Call{Call: _e.mock.On("UpdateProject", ctx, project)}
+}
+
+func (_c *Repository_UpdateProject_Call) Run(run func(ctx context.Context, project *models.Project)) *Repository_UpdateProject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.Project))
+	})
+	return _c
+}
+
+func (_c *Repository_UpdateProject_Call) Return(_a0 error) *Repository_UpdateProject_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Repository_UpdateProject_Call) RunAndReturn(run func(context.Context, *models.Project) error) *Repository_UpdateProject_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateRule provides a mock function with given fields: ctx, rule
+func (_m *Repository) UpdateRule(ctx context.Context, rule *models.ForwardRule) error {
+	ret := _m.Called(ctx, rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRule")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
-		r2 = rf(ctx, limit, offset)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ForwardRule) error); ok {
+		r0 = rf(ctx, rule)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// Repository_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
-type Repository_ListUsers_Call struct {
+// Repository_UpdateRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateRule'
+type Repository_UpdateRule_Call struct {
 	*mock.Call
 }
 
-// ListUsers is a helper method to define mock.On call
+// UpdateRule is a helper method to define mock.On call
 //   - ctx context.Context
-//   - limit int
-//   - offset int
-func (_e *Repository_Expecter) ListUsers(ctx interface{}, limit interface{}, offset interface{}) *Repository_ListUsers_Call {
-	return &Repository_ListUsers_Call{Call: _e.mock.On("ListUsers", ctx, limit, offset)}
+//   - rule *models.ForwardRule
+func (_e *Repository_Expecter) UpdateRule(ctx interface{}, rule interface{}) *Repository_UpdateRule_Call {
+	return &Repository_UpdateRule_Call{Call: _e.mock.On("UpdateRule", ctx, rule)}
 }
 
-func (_c *Repository_ListUsers_Call) Run(run func(ctx context.Context, limit int, offset int)) *Repository_ListUsers_Call {
+func (_c *Repository_UpdateRule_Call) Run(run func(ctx context.Context, rule *models.ForwardRule)) *Repository_UpdateRule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(*models.ForwardRule))
 	})
 	return _c
 }
 
-func (_c *Repository_ListUsers_Call) Return(_a0 []*models.User, _a1 int, _a2 error) *Repository_ListUsers_Call {
-	_c.Call.Return(_a0, _a1, _a2)
+func (_c *Repository_UpdateRule_Call) Return(_a0 error) *Repository_UpdateRule_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ListUsers_Call) RunAndReturn(run func(context.Context, int, int) ([]*models.User, int, error)) *Repository_ListUsers_Call {
+func (_c *Repository_UpdateRule_Call) RunAndReturn(run func(context.Context, *models.ForwardRule) error) *Repository_UpdateRule_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ProjectAddUser provides a mock function with given fields: ctx, projectUser
-func (_m *Repository) ProjectAddUser(ctx context.Context, projectUser *models.ProjectUser) error {
-	ret := _m.Called(ctx, projectUser)
+// UpdateTokenLastUsed provides a mock function with given fields: ctx, tokenID
+func (_m *Repository) UpdateTokenLastUsed(ctx context.Context, tokenID int) error {
+	ret := _m.Called(ctx, tokenID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ProjectAddUser")
+		panic("no return value specified for UpdateTokenLastUsed")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.ProjectUser) error); ok {
-		r0 = rf(ctx, projectUser)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, tokenID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1687,46 +5507,46 @@ func (_m *Repository) ProjectAddUser(ctx context.Context, projectUser *models.Pr
 	return r0
 }
 
-// Repository_ProjectAddUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProjectAddUser'
-type Repository_ProjectAddUser_Call struct {
+// Repository_UpdateTokenLastUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateTokenLastUsed'
+type Repository_UpdateTokenLastUsed_Call struct {
 	*mock.Call
 }
 
-// ProjectAddUser is a helper method to define mock.On call
+// UpdateTokenLastUsed is a helper method to define mock.On call
 //   - ctx context.Context
-//   - projectUser *models.ProjectUser
-func (_e *Repository_Expecter) ProjectAddUser(ctx interface{}, projectUser interface{}) *Repository_ProjectAddUser_Call {
-	return &Repository_ProjectAddUser_Call{Call: _e.mock.On("ProjectAddUser", ctx, projectUser)}
+//   - tokenID int
+func (_e *Repository_Expecter) UpdateTokenLastUsed(ctx interface{}, tokenID interface{}) *Repository_UpdateTokenLastUsed_Call {
+	return &Repository_UpdateTokenLastUsed_Call{Call: _e.mock.On("UpdateTokenLastUsed", ctx, tokenID)}
 }
 
-func (_c *Repository_ProjectAddUser_Call) Run(run func(ctx context.Context, projectUser *models.ProjectUser)) *Repository_ProjectAddUser_Call {
+func (_c *Repository_UpdateTokenLastUsed_Call) Run(run func(ctx context.Context, tokenID int)) *Repository_UpdateTokenLastUsed_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.ProjectUser))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_ProjectAddUser_Call) Return(_a0 error) *Repository_ProjectAddUser_Call {
+func (_c *Repository_UpdateTokenLastUsed_Call) Return(_a0 error) *Repository_UpdateTokenLastUsed_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ProjectAddUser_Call) RunAndReturn(run func(context.Context, *models.ProjectUser) error) *Repository_ProjectAddUser_Call {
+func (_c *Repository_UpdateTokenLastUsed_Call) RunAndReturn(run func(context.Context, int) error) *Repository_UpdateTokenLastUsed_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ProjectRemoveUser provides a mock function with given fields: ctx, projectID, userID
-func (_m *Repository) ProjectRemoveUser(ctx context.Context, projectID int, userID int) error {
-	ret := _m.Called(ctx, projectID, userID)
+// UpdateUser provides a mock function with given fields: ctx, user
+func (_m *Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ProjectRemoveUser")
+		panic("no return value specified for UpdateUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
-		r0 = rf(ctx, projectID, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1734,47 +5554,46 @@ func (_m *Repository) ProjectRemoveUser(ctx context.Context, projectID int, user
 	return r0
 }
 
-// Repository_ProjectRemoveUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProjectRemoveUser'
-type Repository_ProjectRemoveUser_Call struct {
+// Repository_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
+type Repository_UpdateUser_Call struct {
 	*mock.Call
 }
 
-// ProjectRemoveUser is a helper method to define mock.On call
+// UpdateUser is a helper method to define mock.On call
 //   - ctx context.Context
-//   - projectID int
-//   - userID int
-func (_e *Repository_Expecter) ProjectRemoveUser(ctx interface{}, projectID interface{}, userID interface{}) *Repository_ProjectRemoveUser_Call {
-	return &Repository_ProjectRemoveUser_Call{Call: _e.mock.On("ProjectRemoveUser", ctx, projectID, userID)}
+//   - user *models.User
+func (_e *Repository_Expecter) UpdateUser(ctx interface{}, user interface{}) *Repository_UpdateUser_Call {
+	return &Repository_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, user)}
 }
 
-func (_c *Repository_ProjectRemoveUser_Call) Run(run func(ctx context.Context, projectID int, userID int)) *Repository_ProjectRemoveUser_Call {
+func (_c *Repository_UpdateUser_Call) Run(run func(ctx context.Context, user *models.User)) *Repository_UpdateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(*models.User))
 	})
 	return _c
 }
 
-func (_c *Repository_ProjectRemoveUser_Call) Return(_a0 error) *Repository_ProjectRemoveUser_Call {
+func (_c *Repository_UpdateUser_Call) Return(_a0 error) *Repository_UpdateUser_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_ProjectRemoveUser_Call) RunAndReturn(run func(context.Context, int, int) error) *Repository_ProjectRemoveUser_Call {
+func (_c *Repository_UpdateUser_Call) RunAndReturn(run func(context.Context, *models.User) error) *Repository_UpdateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateInbox provides a mock function with given fields: ctx, inbox
-func (_m *Repository) UpdateInbox(ctx context.Context, inbox *models.Inbox) error {
-	ret := _m.Called(ctx, inbox)
+// UpdateUserLoggedInAt provides a mock function with given fields: ctx, userID
+func (_m *Repository) UpdateUserLoggedInAt(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateInbox")
+		panic("no return value specified for UpdateUserLoggedInAt")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Inbox) error); ok {
-		r0 = rf(ctx, inbox)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1782,46 +5601,46 @@ func (_m *Repository) UpdateInbox(ctx context.Context, inbox *models.Inbox) erro
 	return r0
 }
 
-// Repository_UpdateInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateInbox'
-type Repository_UpdateInbox_Call struct {
+// Repository_UpdateUserLoggedInAt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserLoggedInAt'
+type Repository_UpdateUserLoggedInAt_Call struct {
 	*mock.Call
 }
 
-// UpdateInbox is a helper method to define mock.On call
+// UpdateUserLoggedInAt is a helper method to define mock.On call
 //   - ctx context.Context
-//   - inbox *models.Inbox
-func (_e *Repository_Expecter) UpdateInbox(ctx interface{}, inbox interface{}) *Repository_UpdateInbox_Call {
-	return &Repository_UpdateInbox_Call{Call: _e.mock.On("UpdateInbox", ctx, inbox)}
+//   - userID int
+func (_e *Repository_Expecter) UpdateUserLoggedInAt(ctx interface{}, userID interface{}) *Repository_UpdateUserLoggedInAt_Call {
+	return &Repository_UpdateUserLoggedInAt_Call{Call: _e.mock.On("UpdateUserLoggedInAt", ctx, userID)}
 }
 
-func (_c *Repository_UpdateInbox_Call) Run(run func(ctx context.Context, inbox *models.Inbox)) *Repository_UpdateInbox_Call {
+func (_c *Repository_UpdateUserLoggedInAt_Call) Run(run func(ctx context.Context, userID int)) *Repository_UpdateUserLoggedInAt_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Inbox))
+		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *Repository_UpdateInbox_Call) Return(_a0 error) *Repository_UpdateInbox_Call {
+func (_c *Repository_UpdateUserLoggedInAt_Call) Return(_a0 error) *Repository_UpdateUserLoggedInAt_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_UpdateInbox_Call) RunAndReturn(run func(context.Context, *models.Inbox) error) *Repository_UpdateInbox_Call {
+func (_c *Repository_UpdateUserLoggedInAt_Call) RunAndReturn(run func(context.Context, int) error) *Repository_UpdateUserLoggedInAt_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateMessageReadStatus provides a mock function with given fields: ctx, messageID, isRead
-func (_m *Repository) UpdateMessageReadStatus(ctx context.Context, messageID int, isRead bool) error {
-	ret := _m.Called(ctx, messageID, isRead)
+// UpdateWebhook provides a mock function with given fields: ctx, webhook
+func (_m *Repository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	ret := _m.Called(ctx, webhook)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateMessageReadStatus")
+		panic("no return value specified for UpdateWebhook")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
-		r0 = rf(ctx, messageID, isRead)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Webhook) error); ok {
+		r0 = rf(ctx, webhook)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1829,47 +5648,46 @@ func (_m *Repository) UpdateMessageReadStatus(ctx context.Context, messageID int
 	return r0
 }
 
-// Repository_UpdateMessageReadStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessageReadStatus'
-type Repository_UpdateMessageReadStatus_Call struct {
+// Repository_UpdateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateWebhook'
+type Repository_UpdateWebhook_Call struct {
 	*mock.Call
 }
 
-// UpdateMessageReadStatus is a helper method to define mock.On call
+// UpdateWebhook is a helper method to define mock.On call
 //   - ctx context.Context
-//   - messageID int
-//   - isRead bool
-func (_e *Repository_Expecter) UpdateMessageReadStatus(ctx interface{}, messageID interface{}, isRead interface{}) *Repository_UpdateMessageReadStatus_Call {
-	return &Repository_UpdateMessageReadStatus_Call{Call: _e.mock.On("UpdateMessageReadStatus", ctx, messageID, isRead)}
+//   - webhook *models.Webhook
+func (_e *Repository_Expecter) UpdateWebhook(ctx interface{}, webhook interface{}) *Repository_UpdateWebhook_Call {
+	return &Repository_UpdateWebhook_Call{Call: _e.mock.On("UpdateWebhook", ctx, webhook)}
 }
 
-func (_c *Repository_UpdateMessageReadStatus_Call) Run(run func(ctx context.Context, messageID int, isRead bool)) *Repository_UpdateMessageReadStatus_Call {
+func (_c *Repository_UpdateWebhook_Call) Run(run func(ctx context.Context, webhook *models.Webhook)) *Repository_UpdateWebhook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(bool))
+		run(args[0].(context.Context), args[1].(*models.Webhook))
 	})
 	return _c
 }
 
-func (_c *Repository_UpdateMessageReadStatus_Call) Return(_a0 error) *Repository_UpdateMessageReadStatus_Call {
+func (_c *Repository_UpdateWebhook_Call) Return(_a0 error) *Repository_UpdateWebhook_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_UpdateMessageReadStatus_Call) RunAndReturn(run func(context.Context, int, bool) error) *Repository_UpdateMessageReadStatus_Call {
+func (_c *Repository_UpdateWebhook_Call) RunAndReturn(run func(context.Context, *models.Webhook) error) *Repository_UpdateWebhook_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateProject provides a mock function with given fields: ctx, project
-func (_m *Repository) UpdateProject(ctx context.Context, project *models.Project) error {
-	ret := _m.Called(ctx, project)
+// UpdateWebhookDelivery provides a mock function with given fields: ctx, delivery
+func (_m *Repository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	ret := _m.Called(ctx, delivery)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateProject")
+		panic("no return value specified for UpdateWebhookDelivery")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.Project) error); ok {
-		r0 = rf(ctx, project)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.WebhookDelivery) error); ok {
+		r0 = rf(ctx, delivery)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1877,46 +5695,46 @@ func (_m *Repository) UpdateProject(ctx context.Context, project *models.Project
 	return r0
 }
 
-// Repository_UpdateProject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProject'
-type Repository_UpdateProject_Call struct {
+// Repository_UpdateWebhookDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateWebhookDelivery'
+type Repository_UpdateWebhookDelivery_Call struct {
 	*mock.Call
 }
 
-// UpdateProject is a helper method to define mock.On call
+// UpdateWebhookDelivery is a helper method to define mock.On call
 //   - ctx context.Context
-//   - project *models.Project
-func (_e *Repository_Expecter) UpdateProject(ctx interface{}, project interface{}) *Repository_UpdateProject_Call {
-	return &Repository_UpdateProject_Call{Call: _e.mock.On("UpdateProject", ctx, project)}
+//   - delivery *models.WebhookDelivery
+func (_e *Repository_Expecter) UpdateWebhookDelivery(ctx interface{}, delivery interface{}) *Repository_UpdateWebhookDelivery_Call {
+	return &Repository_UpdateWebhookDelivery_Call{Call: _e.mock.On("UpdateWebhookDelivery", ctx, delivery)}
 }
 
-func (_c *Repository_UpdateProject_Call) Run(run func(ctx context.Context, project *models.Project)) *Repository_UpdateProject_Call {
+func (_c *Repository_UpdateWebhookDelivery_Call) Run(run func(ctx context.Context, delivery *models.WebhookDelivery)) *Repository_UpdateWebhookDelivery_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.Project))
+		run(args[0].(context.Context), args[1].(*models.WebhookDelivery))
 	})
 	return _c
 }
 
-func (_c *Repository_UpdateProject_Call) Return(_a0 error) *Repository_UpdateProject_Call {
+func (_c *Repository_UpdateWebhookDelivery_Call) Return(_a0 error) *Repository_UpdateWebhookDelivery_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_UpdateProject_Call) RunAndReturn(run func(context.Context, *models.Project) error) *Repository_UpdateProject_Call {
+func (_c *Repository_UpdateWebhookDelivery_Call) RunAndReturn(run func(context.Context, *models.WebhookDelivery) error) *Repository_UpdateWebhookDelivery_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateRule provides a mock function with given fields: ctx, rule
-func (_m *Repository) UpdateRule(ctx context.Context, rule *models.ForwardRule) error {
-	ret := _m.Called(ctx, rule)
+// UpsertRetentionPolicy provides a mock function with given fields: ctx, policy
+func (_m *Repository) UpsertRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) error {
+	ret := _m.Called(ctx, policy)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateRule")
+		panic("no return value specified for UpsertRetentionPolicy")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.ForwardRule) error); ok {
-		r0 = rf(ctx, rule)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RetentionPolicy) error); ok {
+		r0 = rf(ctx, policy)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1924,46 +5742,46 @@ func (_m *Repository) UpdateRule(ctx context.Context, rule *models.ForwardRule)
 	return r0
 }
 
-// Repository_UpdateRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateRule'
-type Repository_UpdateRule_Call struct {
+// Repository_UpsertRetentionPolicy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertRetentionPolicy'
+type Repository_UpsertRetentionPolicy_Call struct {
 	*mock.Call
 }
 
-// UpdateRule is a helper method to define mock.On call
+// UpsertRetentionPolicy is a helper method to define mock.On call
 //   - ctx context.Context
-//   - rule *models.ForwardRule
-func (_e *Repository_Expecter) UpdateRule(ctx interface{}, rule interface{}) *Repository_UpdateRule_Call {
-	return &Repository_UpdateRule_Call{Call: _e.mock.On("UpdateRule", ctx, rule)}
+//   - policy *models.RetentionPolicy
+func (_e *Repository_Expecter) UpsertRetentionPolicy(ctx interface{}, policy interface{}) *Repository_UpsertRetentionPolicy_Call {
+	return &Repository_UpsertRetentionPolicy_Call{Call: _e.mock.On("UpsertRetentionPolicy", ctx, policy)}
 }
 
-func (_c *Repository_UpdateRule_Call) Run(run func(ctx context.Context, rule *models.ForwardRule)) *Repository_UpdateRule_Call {
+func (_c *Repository_UpsertRetentionPolicy_Call) Run(run func(ctx context.Context, policy *models.RetentionPolicy)) *Repository_UpsertRetentionPolicy_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.ForwardRule))
+		run(args[0].(context.Context), args[1].(*models.RetentionPolicy))
 	})
 	return _c
 }
 
-func (_c *Repository_UpdateRule_Call) Return(_a0 error) *Repository_UpdateRule_Call {
+func (_c *Repository_UpsertRetentionPolicy_Call) Return(_a0 error) *Repository_UpsertRetentionPolicy_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_UpdateRule_Call) RunAndReturn(run func(context.Context, *models.ForwardRule) error) *Repository_UpdateRule_Call {
+func (_c *Repository_UpsertRetentionPolicy_Call) RunAndReturn(run func(context.Context, *models.RetentionPolicy) error) *Repository_UpsertRetentionPolicy_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateUser provides a mock function with given fields: ctx, user
-func (_m *Repository) UpdateUser(ctx context.Context, user *models.User) error {
-	ret := _m.Called(ctx, user)
+// VerifyDomain provides a mock function with given fields: ctx, domain
+func (_m *Repository) VerifyDomain(ctx context.Context, domain *models.Domain) error {
+	ret := _m.Called(ctx, domain)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateUser")
+		panic("no return value specified for VerifyDomain")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Domain) error); ok {
+		r0 = rf(ctx, domain)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1971,31 +5789,31 @@ func (_m *Repository) UpdateUser(ctx context.Context, user *models.User) error {
 	return r0
 }
 
-// Repository_UpdateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUser'
-type Repository_UpdateUser_Call struct {
+// Repository_VerifyDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyDomain'
+type Repository_VerifyDomain_Call struct {
 	*mock.Call
 }
 
-// UpdateUser is a helper method to define mock.On call
+// VerifyDomain is a helper method to define mock.On call
 //   - ctx context.Context
-//   - user *models.User
-func (_e *Repository_Expecter) UpdateUser(ctx interface{}, user interface{}) *Repository_UpdateUser_Call {
-	return &Repository_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, user)}
+//   - domain *models.Domain
+func (_e *Repository_Expecter) VerifyDomain(ctx interface{}, domain interface{}) *Repository_VerifyDomain_Call {
+	return &Repository_VerifyDomain_Call{Call: _e.mock.On("VerifyDomain", ctx, domain)}
 }
 
-func (_c *Repository_UpdateUser_Call) Run(run func(ctx context.Context, user *models.User)) *Repository_UpdateUser_Call {
+func (_c *Repository_VerifyDomain_Call) Run(run func(ctx context.Context, domain *models.Domain)) *Repository_VerifyDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*models.User))
+		run(args[0].(context.Context), args[1].(*models.Domain))
 	})
 	return _c
 }
 
-func (_c *Repository_UpdateUser_Call) Return(_a0 error) *Repository_UpdateUser_Call {
+func (_c *Repository_VerifyDomain_Call) Return(_a0 error) *Repository_VerifyDomain_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Repository_UpdateUser_Call) RunAndReturn(run func(context.Context, *models.User) error) *Repository_UpdateUser_Call {
+func (_c *Repository_VerifyDomain_Call) RunAndReturn(run func(context.Context, *models.Domain) error) *Repository_VerifyDomain_Call {
 	_c.Call.Return(run)
 	return _c
 }
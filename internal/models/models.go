@@ -2,7 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 
+	"github.com/lib/pq"
 	null "github.com/volatiletech/null/v9"
 )
 
@@ -15,19 +17,65 @@ type Base struct {
 type Project struct {
 	Base
 	Name string `json:"name" db:"name" validate:"required,min=2,max=100"`
+	// SMTPRequireAuth rejects mail for the project's inboxes unless the
+	// SMTP client has authenticated
+	SMTPRequireAuth bool `json:"smtp_require_auth" db:"smtp_require_auth"`
 }
 
 type Inbox struct {
 	Base
 	ProjectID int    `json:"project_id" db:"project_id" validate:"required"`
 	Email     string `json:"email" db:"email" validate:"required,email"`
+	// MaxMessagesPerHour, MaxTotalBytes and MaxMessageBytes are the quotas
+	// enforced when mail is received over SMTP, null for no limit
+	MaxMessagesPerHour null.Int   `json:"max_messages_per_hour" db:"max_messages_per_hour"`
+	MaxTotalBytes      null.Int64 `json:"max_total_bytes" db:"max_total_bytes"`
+	MaxMessageBytes    null.Int   `json:"max_message_bytes" db:"max_message_bytes"`
+}
+
+// FolderStatus are the counters of an inbox folder reported over IMAP.
+// FirstUnseen is the position of the first unread message, 0 when every
+// message is read, and UIDNext the ID the next stored message will get at the
+// earliest.
+type FolderStatus struct {
+	Messages    int `db:"messages"`
+	Unseen      int `db:"unseen"`
+	FirstUnseen int `db:"first_unseen"`
+	UIDNext     int `db:"uid_next"`
+}
+
+// InboxUsage is what an inbox currently holds, next to its quotas
+type InboxUsage struct {
+	InboxID          int   `json:"inbox_id" db:"-"`
+	Messages         int   `json:"messages" db:"messages"`
+	TotalBytes       int64 `json:"total_bytes" db:"total_bytes"`
+	MessagesLastHour int   `json:"messages_last_hour" db:"messages_last_hour"`
+
+	MaxMessagesPerHour null.Int   `json:"max_messages_per_hour" db:"-"`
+	MaxTotalBytes      null.Int64 `json:"max_total_bytes" db:"-"`
+	MaxMessageBytes    null.Int   `json:"max_message_bytes" db:"-"`
+}
+
+// Domain is a mail domain claimed by a project. Inboxes can only be created
+// on, and mail is only accepted for, domains the project has verified by
+// publishing VerificationToken in a DNS TXT record.
+type Domain struct {
+	Base
+	ProjectID         int       `json:"project_id" db:"project_id" validate:"required"`
+	Name              string    `json:"name" db:"name" validate:"required,fqdn,max=253"`
+	VerificationToken string    `json:"verification_token" db:"verification_token"`
+	VerifiedAt        null.Time `json:"verified_at" db:"verified_at"`
+	// VerificationRecord and VerificationValue describe the TXT record to
+	// publish, they are filled in by the DomainService
+	VerificationRecord string `json:"verification_record" db:"-"`
+	VerificationValue  string `json:"verification_value" db:"-"`
 }
 
 type User struct {
 	Base
 	Name          string    `json:"name" db:"name"`
 	Username      string    `json:"username" db:"username"`
-	Password      string    `json:"password" db:"password"`
+	Password      string    `json:"password,omitempty" db:"password"`
 	Email         string    `json:"email" db:"email"`
 	Status        string    `json:"status" db:"status"`
 	Role          string    `json:"role" db:"role"`
@@ -39,40 +87,192 @@ type ProjectUser struct {
 	Base
 	ProjectID int    `json:"project_id" db:"project_id" validate:"required"`
 	UserID    int    `json:"user_id" db:"user_id" validate:"required"`
-	Role      string `json:"role" db:"role" validate:"required"`
+	Role      string `json:"role" db:"role" validate:"required,oneof=user admin"`
 }
 
 type Token struct {
 	Base
-	UserID    int       `json:"user_id" db:"user_id" validate:"required"`
-	Token     string    `json:"token" db:"token" validate:"required"`
-	Name      string    `json:"name" db:"name" validate:"required"`
-	ExpiresAt null.Time `json:"expires_at" db:"expires_at"`
+	UserID     int       `json:"user_id" db:"user_id" validate:"required"`
+	Token      string    `json:"token" db:"token" validate:"required"`
+	Name       string    `json:"name" db:"name" validate:"required"`
+	ExpiresAt  null.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt null.Time `json:"last_used_at" db:"last_used_at"`
 }
 
+// Match types supported by ForwardRule
+const (
+	RuleMatchExact    = "exact"
+	RuleMatchWildcard = "wildcard"
+	RuleMatchRegex    = "regex"
+)
+
 type ForwardRule struct {
 	Base
-	InboxID  int    `json:"inbox_id" db:"inbox_id" validate:"required"`
-	Sender   string `json:"sender" db:"sender" validate:"omitempty,email"`
-	Receiver string `json:"receiver" db:"receiver" validate:"omitempty,email"`
-	Subject  string `json:"subject" db:"subject" validate:"omitempty,max=200"`
+	InboxID   int       `json:"inbox_id" db:"inbox_id" validate:"required"`
+	Sender    string    `json:"sender" db:"sender" validate:"omitempty,max=255"`
+	Receiver  string    `json:"receiver" db:"receiver" validate:"omitempty,max=255"`
+	Subject   string    `json:"subject" db:"subject" validate:"omitempty,max=200"`
+	MatchType string    `json:"match_type" db:"match_type" validate:"omitempty,oneof=exact wildcard regex"`
+	ForwardTo string    `json:"forward_to" db:"forward_to" validate:"omitempty,email"`
+	HitCount  int       `json:"hit_count" db:"hit_count"`
+	LastHitAt null.Time `json:"last_hit_at" db:"last_hit_at"`
+}
+
+// Folder is a user-defined mailbox inside an inbox. Names may contain "/"
+// to build a hierarchy, e.g. "Archive/2024".
+type Folder struct {
+	Base
+	InboxID int    `json:"inbox_id" db:"inbox_id" validate:"required"`
+	Name    string `json:"name" db:"name" validate:"required,max=255"`
 }
 
 type Message struct {
 	Base
-	InboxID  int    `json:"inbox_id" db:"inbox_id" validate:"required"`
-	Sender   string `json:"sender" db:"sender" validate:"required,email"`
-	Receiver string `json:"receiver" db:"receiver" validate:"required,email"`
-	Subject  string `json:"subject" db:"subject" validate:"required,max=200"`
-	Body     string `json:"body" db:"body" validate:"required"`
-	IsRead   bool   `json:"is_read" db:"is_read"`
+	InboxID int `json:"inbox_id" db:"inbox_id" validate:"required"`
+	// FolderID is the folder the message is filed in, null for the top level
+	// of the inbox
+	FolderID null.Int `json:"folder_id" db:"folder_id"`
+	Sender   string   `json:"sender" db:"sender" validate:"required,email"`
+	Receiver string   `json:"receiver" db:"receiver" validate:"required,email"`
+	Subject  string   `json:"subject" db:"subject" validate:"required,max=200"`
+	Body     string   `json:"body" db:"body" validate:"required"`
+	HTMLBody string   `json:"html_body" db:"html_body"`
+	IsRead   bool     `json:"is_read" db:"is_read"`
+	// IsFlagged, IsAnswered, IsDeleted and Keywords back the IMAP \Flagged,
+	// \Answered and \Deleted flags and custom keywords. \Seen is IsRead.
+	IsFlagged  bool           `json:"is_flagged" db:"is_flagged"`
+	IsAnswered bool           `json:"is_answered" db:"is_answered"`
+	IsDeleted  bool           `json:"is_deleted" db:"is_deleted"`
+	Keywords   pq.StringArray `json:"keywords" db:"keywords"`
+	Size       int            `json:"size" db:"size"`
+	// Raw is the original RFC 5322 source as received. It is only loaded
+	// through Repository.GetMessageRaw.
+	Raw []byte `json:"-" db:"raw"`
+	// Attachments are persisted together with the message by
+	// Repository.CreateMessage and listed separately through the API.
+	Attachments []*Attachment `json:"-" db:"-"`
+}
+
+type Attachment struct {
+	Base
+	MessageID   int    `json:"message_id" db:"message_id"`
+	Filename    string `json:"filename" db:"filename"`
+	ContentType string `json:"content_type" db:"content_type"`
+	ContentID   string `json:"content_id" db:"content_id"`
+	Size        int    `json:"size" db:"size"`
+	Content     []byte `json:"-" db:"content"`
+}
+
+// RetentionPolicy bounds the messages kept in the inboxes of a project, or
+// in a single inbox. Unset limits do not apply and the limits set on an
+// inbox take precedence over those of its project. The oldest messages are
+// purged first.
+type RetentionPolicy struct {
+	Base
+	ProjectID    int        `json:"project_id" db:"project_id"`
+	InboxID      null.Int   `json:"inbox_id" db:"inbox_id"`
+	MaxAgeHours  null.Int   `json:"max_age_hours" db:"max_age_hours"`
+	MaxMessages  null.Int   `json:"max_messages" db:"max_messages"`
+	MaxSizeBytes null.Int64 `json:"max_size_bytes" db:"max_size_bytes"`
+}
+
+// IsEmpty reports whether the policy sets no limit
+func (p *RetentionPolicy) IsEmpty() bool {
+	return !p.MaxAgeHours.Valid && !p.MaxMessages.Valid && !p.MaxSizeBytes.Valid
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook posts the message events of a project to an HTTP endpoint. A null
+// InboxID subscribes to every inbox of the project.
+type Webhook struct {
+	Base
+	ProjectID int      `json:"project_id" db:"project_id" validate:"required"`
+	InboxID   null.Int `json:"inbox_id" db:"inbox_id"`
+	URL       string   `json:"url" db:"url" validate:"required,url,max=2048"`
+	// Secret keys the HMAC signature of every delivery, one is generated
+	// when left empty
+	Secret string `json:"secret" db:"secret" validate:"max=255"`
+	// EventTypes lists the events delivered, message.created by default
+	EventTypes pq.StringArray `json:"event_types" db:"event_types" validate:"dive,oneof=message.created message.deleted"`
+	IsActive   bool           `json:"is_active" db:"is_active"`
+}
+
+// WebhookDelivery is an event queued for, or sent to, a webhook. Pending
+// deliveries are attempted at NextAttemptAt until they succeed or run out
+// of attempts.
+type WebhookDelivery struct {
+	Base
+	WebhookID int      `json:"webhook_id" db:"webhook_id"`
+	EventType string   `json:"event_type" db:"event_type"`
+	MessageID null.Int `json:"message_id" db:"message_id"`
+	// Payload is the JSON body posted, replays send it unchanged
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	NextAttemptAt null.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	// ResponseStatus and LastError describe the outcome of the last attempt
+	ResponseStatus null.Int    `json:"response_status" db:"response_status"`
+	LastError      null.String `json:"last_error" db:"last_error"`
+}
+
+// Outbound message statuses
+const (
+	OutboundQueued = "queued"
+	OutboundSent   = "sent"
+	OutboundFailed = "failed"
+)
+
+// OutboundMessage is a message sent from an inbox. It stays queued until the
+// relay accepts it, failed attempts are retried with a backoff. MessageID is
+// the copy filed in the Sent folder of the inbox.
+type OutboundMessage struct {
+	Base
+	InboxID    int            `json:"inbox_id" db:"inbox_id"`
+	MessageID  null.Int       `json:"message_id" db:"message_id"`
+	Sender     string         `json:"sender" db:"sender"`
+	Recipients pq.StringArray `json:"recipients" db:"recipients"`
+	// Raw is the composed message handed to the relay
+	Raw           []byte    `json:"-" db:"raw"`
+	Status        string    `json:"status" db:"status"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	NextAttemptAt null.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	// LastError describes the last failed attempt
+	LastError null.String `json:"last_error" db:"last_error"`
+	SentAt    null.Time   `json:"sent_at" db:"sent_at"`
+}
+
+// OutgoingMessage is a message composed through the API, it needs a text or
+// an HTML body, or both
+type OutgoingMessage struct {
+	To          []string              `json:"to" validate:"required,min=1,max=50,dive,email"`
+	Cc          []string              `json:"cc" validate:"max=50,dive,email"`
+	Subject     string                `json:"subject" validate:"max=200"`
+	Text        string                `json:"text"`
+	HTML        string                `json:"html"`
+	Attachments []*OutgoingAttachment `json:"attachments" validate:"max=20,dive"`
+}
+
+// OutgoingAttachment is a file attached to an OutgoingMessage, its content is
+// base64 encoded in JSON
+type OutgoingAttachment struct {
+	Filename    string `json:"filename" validate:"required,max=255"`
+	ContentType string `json:"content_type" validate:"max=255"`
+	Content     []byte `json:"content" validate:"required"`
 }
 
 type Session struct {
 	Base
-	SessionID      string          `db:"session_id" json:"session_id"`
-	UserID         int64           `db:"user_id" json:"user_id"`
+	// SessionID is the secret carried by the session cookie
+	SessionID      string          `db:"session_id" json:"-"`
+	UserID         int             `db:"user_id" json:"user_id"`
 	Data           json.RawMessage `db:"data" json:"data"`
+	ExpiresAt      time.Time       `db:"expires_at" json:"expires_at"`
 	LastAccessedAt null.Time       `db:"last_accessed_at" json:"last_accessed_at"`
 	IPAddress      string          `db:"ip_address" json:"ip_address"`
 	UserAgent      string          `db:"user_agent" json:"user_agent"`
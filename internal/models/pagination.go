@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strconv"
+	"time"
+)
+
 type PaginationQuery struct {
 	Limit  int `query:"limit" validate:"min=1,max=100"`
 	Offset int `query:"offset" validate:"min=0"`
@@ -18,5 +23,112 @@ type PaginatedResponse struct {
 
 type MessageQuery struct {
 	PaginationQuery
+	MessageFilterQuery
+}
+
+// MessageFilterQuery holds the message filters accepted as query parameters
+type MessageFilterQuery struct {
 	IsRead *bool `query:"is_read"`
+	// To filters on the original recipient, * matches any characters
+	To string `query:"to" validate:"max=255"`
+	// Q is a full-text query: words, "quoted phrases", OR and -excluded words
+	Q string `query:"q" validate:"max=255"`
+	// From filters on the sender like To, Subject on a part of the subject
+	From           string    `query:"from" validate:"max=255"`
+	Subject        string    `query:"subject" validate:"max=200"`
+	Since          QueryTime `query:"since"`
+	Before         QueryTime `query:"before"`
+	HasAttachments *bool     `query:"has_attachments"`
+	// Sort orders the results by ID, the order they were received, unless
+	// set to relevance to put the best matches of Q first
+	Sort string `query:"sort" validate:"omitempty,oneof=id relevance"`
+}
+
+// IsSearch reports whether the query uses more than the read status and
+// recipient filters
+func (q *MessageFilterQuery) IsSearch() bool {
+	return q.Q != "" || q.From != "" || q.Subject != "" ||
+		!q.Since.IsZero() || !q.Before.IsZero() || q.HasAttachments != nil
+}
+
+// Filter returns the MessageFilter described by the query
+func (q *MessageFilterQuery) Filter() *MessageFilter {
+	return &MessageFilter{
+		Query:          q.Q,
+		From:           q.From,
+		To:             q.To,
+		Subject:        q.Subject,
+		Since:          q.Since.Time,
+		Before:         q.Before.Time,
+		IsRead:         q.IsRead,
+		HasAttachments: q.HasAttachments,
+		Sort:           q.Sort,
+	}
+}
+
+// MessageWaitQuery selects the message to wait for with the filters of a
+// MessageQuery
+type MessageWaitQuery struct {
+	MessageFilterQuery
+	// Timeout bounds the wait, the server default applies when unset
+	Timeout QueryDuration `query:"timeout"`
+}
+
+// WebhookDeliveryQuery pages through the delivery log of a webhook,
+// optionally only the deliveries with the given status
+type WebhookDeliveryQuery struct {
+	PaginationQuery
+	Status string `query:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
+
+// OutboundMessageQuery pages through the messages sent from an inbox,
+// optionally only those with the given delivery status
+type OutboundMessageQuery struct {
+	PaginationQuery
+	Status string `query:"status" validate:"omitempty,oneof=queued sent failed"`
+}
+
+// QueryTime is a time bound from a query parameter, given either as an
+// RFC 3339 timestamp or as a date, which stands for midnight UTC
+type QueryTime struct {
+	time.Time
+}
+
+func (t *QueryTime) UnmarshalParam(value string) error {
+	if value == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// QueryDuration is a duration from a query parameter, given either in Go
+// syntax such as 30s or 1m30s, or as a number of seconds
+type QueryDuration struct {
+	time.Duration
+}
+
+func (d *QueryDuration) UnmarshalParam(value string) error {
+	if value == "" {
+		d.Duration = 0
+		return nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d.Duration = time.Duration(seconds) * time.Second
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
 }
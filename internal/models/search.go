@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// MessageSearch describes a search over the messages of an inbox. Every set
+// field must match; string fields are case-insensitive substring matches and
+// each value of a slice must match on its own. It mirrors the IMAP SEARCH
+// keys without depending on the IMAP library.
+type MessageSearch struct {
+	From    []string
+	To      []string
+	Subject []string
+	// Body matches the text and HTML bodies, Text also matches the sender,
+	// receiver and subject
+	Body []string
+	Text []string
+	// Source matches anywhere in the raw RFC 5322 source, used for headers
+	// that are not stored in their own column
+	Source []string
+
+	// Since and Before compare the date the message was received
+	Since  time.Time
+	Before time.Time
+
+	Larger  int
+	Smaller int
+
+	IsRead      *bool
+	IsFlagged   *bool
+	IsAnswered  *bool
+	IsDeleted   *bool
+	Keywords    []string
+	NotKeywords []string
+
+	// IDs restricts the search to message IDs within any of the ranges
+	IDs []IDRange
+
+	// MatchNone is set for criteria that can never match, such as flags
+	// that are not persisted
+	MatchNone bool
+
+	Not []*MessageSearch
+	Or  [][2]*MessageSearch
+}
+
+// IDRange is an inclusive range of message IDs, a zero Stop means unbounded
+type IDRange struct {
+	Start int
+	Stop  int
+}
+
+// Orders of the messages listed through a MessageFilter
+const (
+	// MessageSortID lists messages in the order they were received
+	MessageSortID = "id"
+	// MessageSortRelevance lists the best matches of the full-text query
+	// first
+	MessageSortRelevance = "relevance"
+)
+
+// MessageFilter narrows the messages listed through the HTTP API. ProjectID
+// searches every inbox of a project, InboxID a single inbox. Every other set
+// field must match.
+type MessageFilter struct {
+	ProjectID int
+	InboxID   int
+
+	// Query is a full-text query in web search syntax, matched against the
+	// subject, the addresses and the text body
+	Query string
+	// From and To match the whole sender and recipient address
+	// case-insensitively, * matches any characters
+	From string
+	To   string
+	// Subject is a case-insensitive substring of the subject
+	Subject string
+
+	// Since and Before compare the date the message was received
+	Since  time.Time
+	Before time.Time
+
+	IsRead         *bool
+	HasAttachments *bool
+
+	// AfterID only matches messages stored after the message with this ID
+	AfterID int
+
+	// ExcludeSent leaves out the copies of the messages sent from the inbox
+	ExcludeSent bool
+
+	// Sort is MessageSortID unless set to MessageSortRelevance
+	Sort string
+}
@@ -0,0 +1,101 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+
+	"inbox451/internal/config"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+)
+
+// Sender delivers a raw RFC 5322 message to a list of recipients
+type Sender interface {
+	Send(ctx context.Context, from string, to []string, msg []byte) error
+}
+
+// Client is a Sender backed by an upstream SMTP relay
+type Client struct {
+	cfg config.RelayConfig
+}
+
+func New(cfg config.RelayConfig) *Client {
+	return &Client{cfg: cfg}
+}
+
+// Send connects to the relay (optionally over TLS or STARTTLS), authenticates
+// when credentials are configured and submits the message. When relay.from is
+// configured it replaces from as the envelope sender.
+func (c *Client) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	if c.cfg.From != "" {
+		from = c.cfg.From
+	}
+
+	host, _, err := net.SplitHostPort(c.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid relay address %q: %w", c.cfg.Addr, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to relay: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	var client *smtp.Client
+	switch {
+	case c.cfg.TLS:
+		client = smtp.NewClient(tls.Client(conn, &tls.Config{ServerName: host}))
+	case c.cfg.StartTLS:
+		client, err = smtp.NewClientStartTLS(conn, &tls.Config{ServerName: host})
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("relay STARTTLS failed: %w", err)
+		}
+	default:
+		client = smtp.NewClient(conn)
+	}
+	defer client.Close()
+
+	if c.cfg.Username != "" {
+		auth := sasl.NewPlainClient("", c.cfg.Username, c.cfg.Password)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("relay authentication failed: %w", err)
+		}
+	}
+
+	if err := client.SendMail(from, to, bytes.NewReader(msg)); err != nil {
+		return rejectionError{fmt.Errorf("relay rejected message: %w", err)}
+	}
+
+	return client.Quit()
+}
+
+// rejectionError marks the relay refusing a message, as opposed to failing
+// to reach the relay or to log in
+type rejectionError struct {
+	error
+}
+
+func (e rejectionError) Unwrap() error {
+	return e.error
+}
+
+// IsPermanent reports whether the relay refused a message with a permanent
+// (5xx) reply, so that sending it again would fail the same way
+func IsPermanent(err error) bool {
+	var rejection rejectionError
+	var smtpErr *smtp.SMTPError
+	return errors.As(err, &rejection) && errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
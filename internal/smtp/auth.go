@@ -0,0 +1,162 @@
+package smtp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"inbox451/internal/core"
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+)
+
+var (
+	errAuthRequired = &smtp.SMTPError{
+		Code:         530,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 0},
+		Message:      "Authentication required",
+	}
+
+	errDeliveryNotAllowed = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+		Message:      "Recipient address rejected: not allowed to deliver to this inbox",
+	}
+
+	errDomainNotVerified = &smtp.SMTPError{
+		Code:         550,
+		EnhancedCode: smtp.EnhancedCode{5, 7, 1},
+		Message:      "Recipient address rejected: domain not verified",
+	}
+)
+
+// AuthMechanisms lists the SASL mechanisms offered in the EHLO response
+func (s *SmtpSession) AuthMechanisms() []string {
+	return []string{sasl.Plain, sasl.Login}
+}
+
+func (s *SmtpSession) Auth(mech string) (sasl.Server, error) {
+	switch mech {
+	case sasl.Plain:
+		return sasl.NewPlainServer(func(identity, username, password string) error {
+			if identity != "" && identity != username {
+				return smtp.ErrAuthFailed
+			}
+			return s.authenticate(username, password)
+		}), nil
+	case sasl.Login:
+		return &loginServer{authenticate: s.authenticate}, nil
+	}
+	return nil, smtp.ErrAuthUnknownMechanism
+}
+
+// authenticate accepts the credentials configured in server.smtp.username
+// and server.smtp.password, or a username together with the user's password
+// or one of their API tokens
+func (s *SmtpSession) authenticate(username, password string) error {
+	cfg := s.core.Config.Server.SMTP
+	if cfg.Username != "" && cfg.Password != "" &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(cfg.Username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(cfg.Password)) == 1 {
+		s.core.Logger.Info("SMTP login succeeded with the configured credentials")
+		s.authenticated = true
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.core.UserService.Authenticate(ctx, username, password)
+	if err != nil {
+		if errors.Is(err, core.ErrUnauthorized) {
+			s.core.Logger.Info("SMTP login failed for %s", username)
+			return smtp.ErrAuthFailed
+		}
+		return err
+	}
+
+	s.core.Logger.Info("SMTP login succeeded for %s", username)
+	s.authenticated = true
+	s.user = user
+	return nil
+}
+
+// authorizeDelivery checks whether the session may deliver mail for a
+// recipient to an inbox. Only addresses on the verified domains of the
+// inbox's project are accepted. Projects requiring authentication only accept
+// mail from authenticated sessions: the configured credentials may deliver to
+// any of them, users only to the projects they are members of.
+func (s *SmtpSession) authorizeDelivery(ctx context.Context, to string, inbox *models.Inbox) error {
+	verified, err := s.core.DomainService.AcceptsAddress(ctx, inbox.ProjectID, to)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		s.core.Logger.Info("Rejecting recipient %s on an unverified domain", to)
+		return errDomainNotVerified
+	}
+
+	project, err := s.core.Repository.GetProject(ctx, inbox.ProjectID)
+	if err != nil {
+		s.core.Logger.Error("Failed to fetch project %d: %v", inbox.ProjectID, err)
+		return err
+	}
+	if !project.SMTPRequireAuth {
+		return nil
+	}
+
+	if !s.authenticated {
+		s.core.Logger.Info("Rejecting unauthenticated delivery to %s", inbox.Email)
+		return errAuthRequired
+	}
+	if s.user == nil {
+		return nil
+	}
+
+	if _, err := s.core.ProjectService.Get(core.WithUser(ctx, s.user), project.ID); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			s.core.Logger.Info("Rejecting delivery by %s to %s", s.user.Username, inbox.Email)
+			return errDeliveryNotAllowed
+		}
+		return err
+	}
+	return nil
+}
+
+// loginServer implements the obsolete but still widely used LOGIN mechanism,
+// which go-sasl no longer provides
+type loginServer struct {
+	state        int
+	username     string
+	authenticate func(username, password string) error
+}
+
+const (
+	loginStart = iota
+	loginUsername
+	loginPassword
+	loginDone
+)
+
+func (a *loginServer) Next(response []byte) (challenge []byte, done bool, err error) {
+	switch a.state {
+	case loginStart:
+		a.state = loginUsername
+		// The username may be sent as the initial response
+		if response == nil {
+			return []byte("Username:"), false, nil
+		}
+		fallthrough
+	case loginUsername:
+		a.username = string(response)
+		a.state = loginPassword
+		return []byte("Password:"), false, nil
+	case loginPassword:
+		a.state = loginDone
+		return nil, true, a.authenticate(a.username, string(response))
+	}
+	return nil, true, sasl.ErrUnexpectedClientResponse
+}
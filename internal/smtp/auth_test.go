@@ -0,0 +1,317 @@
+package smtp
+
+import (
+	"errors"
+	"testing"
+
+	"inbox451/internal/core"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+	"inbox451/internal/storage"
+
+	"github.com/emersion/go-sasl"
+	"github.com/emersion/go-smtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// saslExchange feeds the client responses to a SASL server and returns the
+// challenges it sent and the outcome of the last step
+func saslExchange(server sasl.Server, responses ...[]byte) (challenges []string, done bool, err error) {
+	for _, response := range responses {
+		var challenge []byte
+		challenge, done, err = server.Next(response)
+		if challenge != nil {
+			challenges = append(challenges, string(challenge))
+		}
+		if done || err != nil {
+			return challenges, done, err
+		}
+	}
+	return challenges, done, err
+}
+
+func TestLoginServer_Next(t *testing.T) {
+	errDenied := errors.New("denied")
+
+	tests := []struct {
+		name           string
+		responses      [][]byte
+		authErr        error
+		wantChallenges []string
+		wantDone       bool
+		wantErr        error
+	}{
+		{
+			name:           "username prompted",
+			responses:      [][]byte{nil, []byte("qa"), []byte("secret")},
+			wantChallenges: []string{"Username:", "Password:"},
+			wantDone:       true,
+		},
+		{
+			name:           "username as initial response",
+			responses:      [][]byte{[]byte("qa"), []byte("secret")},
+			wantChallenges: []string{"Password:"},
+			wantDone:       true,
+		},
+		{
+			name:           "password pending",
+			responses:      [][]byte{nil, []byte("qa")},
+			wantChallenges: []string{"Username:", "Password:"},
+		},
+		{
+			name:           "credentials refused",
+			responses:      [][]byte{[]byte("qa"), []byte("secret")},
+			authErr:        errDenied,
+			wantChallenges: []string{"Password:"},
+			wantDone:       true,
+			wantErr:        errDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUsername, gotPassword string
+			server := &loginServer{authenticate: func(username, password string) error {
+				gotUsername, gotPassword = username, password
+				return tt.authErr
+			}}
+
+			challenges, done, err := saslExchange(server, tt.responses...)
+			assert.Equal(t, tt.wantChallenges, challenges)
+			assert.Equal(t, tt.wantDone, done)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantDone {
+				assert.Equal(t, "qa", gotUsername)
+				assert.Equal(t, "secret", gotPassword)
+			}
+		})
+	}
+
+	t.Run("response after completion", func(t *testing.T) {
+		server := &loginServer{authenticate: func(string, string) error { return nil }}
+		_, done, err := saslExchange(server, []byte("qa"), []byte("secret"))
+		require.True(t, done)
+		require.NoError(t, err)
+
+		_, done, err = server.Next([]byte("again"))
+		assert.True(t, done)
+		assert.Equal(t, sasl.ErrUnexpectedClientResponse, err)
+	})
+}
+
+func TestSmtpSession_Auth(t *testing.T) {
+	hash, err := core.HashPassword("secret")
+	require.NoError(t, err)
+	newUser := func() *models.User {
+		return &models.User{Base: models.Base{ID: 7}, Username: "qa", Password: hash, PasswordLogin: true}
+	}
+
+	tests := []struct {
+		name      string
+		mech      string
+		responses [][]byte
+		mockFn    func(*mocks.Repository)
+		wantErr   error
+		wantUser  bool
+	}{
+		{
+			name:      "plain with the configured credentials",
+			mech:      sasl.Plain,
+			responses: [][]byte{[]byte("\x00relay\x00relay-secret")},
+			mockFn:    func(*mocks.Repository) {},
+		},
+		{
+			name:      "plain with a user password",
+			mech:      sasl.Plain,
+			responses: [][]byte{[]byte("\x00qa\x00secret")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "qa").Return(newUser(), nil)
+			},
+			wantUser: true,
+		},
+		{
+			name:      "plain with a wrong password",
+			mech:      sasl.Plain,
+			responses: [][]byte{[]byte("\x00qa\x00wrong")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "qa").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "wrong").Return(nil, storage.ErrNotFound)
+			},
+			wantErr: smtp.ErrAuthFailed,
+		},
+		{
+			name:      "plain for another identity",
+			mech:      sasl.Plain,
+			responses: [][]byte{[]byte("admin\x00qa\x00secret")},
+			mockFn:    func(*mocks.Repository) {},
+			wantErr:   smtp.ErrAuthFailed,
+		},
+		{
+			name:      "login with the configured credentials",
+			mech:      sasl.Login,
+			responses: [][]byte{nil, []byte("relay"), []byte("relay-secret")},
+			mockFn:    func(*mocks.Repository) {},
+		},
+		{
+			name:      "login with a user password",
+			mech:      sasl.Login,
+			responses: [][]byte{[]byte("qa"), []byte("secret")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "qa").Return(newUser(), nil)
+			},
+			wantUser: true,
+		},
+		{
+			name:      "login with an API token",
+			mech:      sasl.Login,
+			responses: [][]byte{[]byte("qa"), []byte("token-value")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "qa").Return(newUser(), nil)
+				m.On("GetTokenByValue", mock.Anything, "token-value").
+					Return(&models.Token{UserID: 7, Token: "token-value"}, nil)
+			},
+			wantUser: true,
+		},
+		{
+			name:      "login for an unknown user",
+			mech:      sasl.Login,
+			responses: [][]byte{[]byte("nobody"), []byte("secret")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "nobody").Return(nil, nil)
+			},
+			wantErr: smtp.ErrAuthFailed,
+		},
+		{
+			name:      "login with a wrong configured password",
+			mech:      sasl.Login,
+			responses: [][]byte{[]byte("relay"), []byte("wrong")},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetUserByUsername", mock.Anything, "relay").Return(nil, nil)
+			},
+			wantErr: smtp.ErrAuthFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			c.Config.Server.SMTP.Username = "relay"
+			c.Config.Server.SMTP.Password = "relay-secret"
+			tt.mockFn(mockRepo)
+
+			session := &SmtpSession{core: c}
+			server, err := session.Auth(tt.mech)
+			require.NoError(t, err)
+
+			_, done, err := saslExchange(server, tt.responses...)
+			assert.True(t, done)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.False(t, session.authenticated)
+				assert.Nil(t, session.user)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, session.authenticated)
+			if tt.wantUser {
+				require.NotNil(t, session.user)
+				assert.Equal(t, 7, session.user.ID)
+				assert.Empty(t, session.user.Password)
+			} else {
+				assert.Nil(t, session.user)
+			}
+		})
+	}
+
+	t.Run("unknown mechanism", func(t *testing.T) {
+		c, _ := setupSessionTestCore(t)
+		session := &SmtpSession{core: c}
+		_, err := session.Auth("CRAM-MD5")
+		assert.Equal(t, smtp.ErrAuthUnknownMechanism, err)
+	})
+}
+
+func TestSmtpSession_RcptRequireAuth(t *testing.T) {
+	inbox := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}
+	user := &models.User{Base: models.Base{ID: 7}, Username: "qa", Role: core.RoleUser}
+
+	tests := []struct {
+		name    string
+		session func(*core.Core) *SmtpSession
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name: "unauthenticated",
+			session: func(c *core.Core) *SmtpSession {
+				return &SmtpSession{core: c}
+			},
+			mockFn:  func(*mocks.Repository) {},
+			wantErr: errAuthRequired,
+		},
+		{
+			name: "configured credentials",
+			session: func(c *core.Core) *SmtpSession {
+				return &SmtpSession{core: c, authenticated: true}
+			},
+			mockFn: func(*mocks.Repository) {},
+		},
+		{
+			name: "project member",
+			session: func(c *core.Core) *SmtpSession {
+				return &SmtpSession{core: c, authenticated: true, user: user}
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 2, 7).
+					Return(&models.ProjectUser{ProjectID: 2, UserID: 7, Role: core.RoleUser}, nil)
+			},
+		},
+		{
+			name: "user of another project",
+			session: func(c *core.Core) *SmtpSession {
+				return &SmtpSession{core: c, authenticated: true, user: user}
+			},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetProjectUser", mock.Anything, 2, 7).Return(nil, storage.ErrNotFound)
+			},
+			wantErr: errDeliveryNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			mockRepo.On("GetInboxByAddress", mock.Anything, "qa@example.com", "qa@example.com").Return(inbox, nil)
+			mockRepo.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+				Return(&models.Domain{ProjectID: 2, Name: "example.com"}, nil)
+			mockRepo.On("GetProject", mock.Anything, 2).
+				Return(&models.Project{Base: models.Base{ID: 2}, SMTPRequireAuth: true}, nil)
+			tt.mockFn(mockRepo)
+
+			session := tt.session(c)
+			err := session.Rcpt("qa@example.com", nil)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Empty(t, session.inboxes)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []*models.Inbox{inbox}, session.inboxes)
+		})
+	}
+}
+
+func TestSmtpSession_MailRequireAuth(t *testing.T) {
+	c, _ := setupSessionTestCore(t)
+	c.Config.Server.SMTP.RequireAuth = true
+
+	session := &SmtpSession{core: c}
+	assert.Equal(t, errAuthRequired, session.Mail("sender@example.net", nil))
+
+	session.authenticated = true
+	assert.NoError(t, session.Mail("sender@example.net", nil))
+}
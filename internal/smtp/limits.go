@@ -0,0 +1,154 @@
+package smtp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"inbox451/internal/logger"
+
+	"github.com/emersion/go-smtp"
+)
+
+// tooManyConnections is sent to clients over their connection limit before
+// the connection is closed
+const tooManyConnections = "421 4.7.0 Too many connections from your address, try again later\r\n"
+
+// connectionLimiter counts the open connections of every client address
+type connectionLimiter struct {
+	max    int
+	logger *logger.Logger
+
+	mu    sync.Mutex
+	conns map[string]int
+}
+
+func newConnectionLimiter(max int, logger *logger.Logger) *connectionLimiter {
+	return &connectionLimiter{
+		max:    max,
+		logger: logger,
+		conns:  make(map[string]int),
+	}
+}
+
+func (l *connectionLimiter) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[ip] >= l.max {
+		return false
+	}
+	l.conns[ip]++
+	return true
+}
+
+func (l *connectionLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[ip] <= 1 {
+		delete(l.conns, ip)
+		return
+	}
+	l.conns[ip]--
+}
+
+// Listen wraps a listener so that connections beyond the limit of their
+// client address are turned away with a 421 reply. A limit of 0 leaves the
+// listener unchanged.
+func (l *connectionLimiter) Listen(inner net.Listener) net.Listener {
+	if l.max <= 0 {
+		return inner
+	}
+	return &limitedListener{Listener: inner, limiter: l}
+}
+
+type limitedListener struct {
+	net.Listener
+	limiter *connectionLimiter
+}
+
+func (l *limitedListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		ip := remoteIP(conn)
+		if l.limiter.acquire(ip) {
+			return &limitedConn{Conn: conn, release: func() { l.limiter.release(ip) }}, nil
+		}
+
+		l.limiter.logger.Info("Rejecting SMTP connection from %s: too many connections", ip)
+		go reject(conn)
+	}
+}
+
+// reject sends the 421 reply without holding up the accept loop
+func reject(conn net.Conn) {
+	_ = conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	_, _ = conn.Write([]byte(tooManyConnections))
+	conn.Close()
+}
+
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// errServerBusy is returned when a large message cannot be loaded before the
+// DATA command times out, the client retries later
+var errServerBusy = &smtp.SMTPError{
+	Code:         451,
+	EnhancedCode: smtp.EnhancedCode{4, 3, 2},
+	Message:      "Too many messages in progress, try again later",
+}
+
+// largeMessageLimiter bounds how many messages larger than spoolMemoryBytes
+// are parsed and stored at once. They are received into temporary files,
+// but parsing and storing them needs a few times their size in memory.
+type largeMessageLimiter struct {
+	slots chan struct{}
+}
+
+func newLargeMessageLimiter(max int) *largeMessageLimiter {
+	return &largeMessageLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire waits for a free slot until ctx is done. A nil limiter has no
+// limit.
+func (l *largeMessageLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *largeMessageLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,119 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"inbox451/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionLimiter(t *testing.T) {
+	limiter := newConnectionLimiter(2, logger.New(io.Discard, logger.DEBUG))
+
+	assert.True(t, limiter.acquire("192.0.2.1"))
+	assert.True(t, limiter.acquire("192.0.2.1"))
+	assert.False(t, limiter.acquire("192.0.2.1"))
+	// Other addresses have their own limit
+	assert.True(t, limiter.acquire("192.0.2.2"))
+
+	limiter.release("192.0.2.1")
+	assert.True(t, limiter.acquire("192.0.2.1"))
+
+	limiter.release("192.0.2.1")
+	limiter.release("192.0.2.1")
+	limiter.release("192.0.2.2")
+	assert.Empty(t, limiter.conns)
+}
+
+func TestLargeMessageLimiter(t *testing.T) {
+	limiter := newLargeMessageLimiter(2)
+	ctx := context.Background()
+
+	require.NoError(t, limiter.acquire(ctx))
+	require.NoError(t, limiter.acquire(ctx))
+
+	// A third message waits until its context is done
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.acquire(waitCtx), context.DeadlineExceeded)
+
+	limiter.release()
+	assert.NoError(t, limiter.acquire(ctx))
+
+	// A nil limiter has no limit
+	var unlimited *largeMessageLimiter
+	assert.NoError(t, unlimited.acquire(ctx))
+	unlimited.release()
+}
+
+func TestConnectionLimiter_NoLimit(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	limiter := newConnectionLimiter(0, logger.New(io.Discard, logger.DEBUG))
+	assert.Same(t, l, limiter.Listen(l))
+}
+
+func TestLimitedListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	limiter := newConnectionLimiter(1, logger.New(io.Discard, logger.DEBUG))
+	listener := limiter.Listen(l)
+	defer listener.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+		return conn
+	}
+
+	dial()
+	server := <-accepted
+
+	// The connection over the limit gets a 421 and is closed
+	over := dial()
+	reply, err := bufio.NewReader(over).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, tooManyConnections, reply)
+	_, err = over.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	// Closing the accepted connection frees its slot, closing it twice
+	// does not free another one
+	require.NoError(t, server.Close())
+	server.Close()
+	limiter.mu.Lock()
+	assert.Empty(t, limiter.conns)
+	limiter.mu.Unlock()
+
+	dial()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection under the limit was not accepted")
+	}
+}
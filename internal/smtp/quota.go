@@ -0,0 +1,54 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+
+	"inbox451/internal/core"
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-smtp"
+)
+
+var (
+	errInboxRateLimited = &smtp.SMTPError{
+		Code:         452,
+		EnhancedCode: smtp.EnhancedCode{4, 2, 2},
+		Message:      "Mailbox received too many messages, try again later",
+	}
+
+	errInboxFull = &smtp.SMTPError{
+		Code:         552,
+		EnhancedCode: smtp.EnhancedCode{5, 2, 2},
+		Message:      "Mailbox full",
+	}
+
+	errMessageTooLarge = &smtp.SMTPError{
+		Code:         552,
+		EnhancedCode: smtp.EnhancedCode{5, 3, 4},
+		Message:      "Message too big for this mailbox",
+	}
+)
+
+// checkQuota rejects a message of size bytes, 0 when not known yet, that
+// the inbox does not accept with the SMTP reply matching the exceeded quota
+func (s *SmtpSession) checkQuota(ctx context.Context, inbox *models.Inbox, size int) error {
+	err := s.core.InboxService.CheckQuota(ctx, inbox, size)
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, core.ErrInboxRateLimited):
+		err = errInboxRateLimited
+	case errors.Is(err, core.ErrInboxFull):
+		err = errInboxFull
+	case errors.Is(err, core.ErrMessageTooLarge):
+		err = errMessageTooLarge
+	default:
+		return err
+	}
+
+	s.core.Logger.Info("Rejecting message to inbox %d over its quota: %v", inbox.ID, err)
+	return err
+}
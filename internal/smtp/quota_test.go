@@ -0,0 +1,200 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"inbox451/internal/core"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+
+	"github.com/emersion/go-smtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func TestSmtpSession_CheckQuota(t *testing.T) {
+	errDatabase := errors.New("connection refused")
+
+	tests := []struct {
+		name             string
+		inbox            *models.Inbox
+		size             int
+		mockFn           func(*mocks.Repository)
+		wantErr          error
+		wantCode         int
+		wantEnhancedCode smtp.EnhancedCode
+	}{
+		{
+			name:   "no quotas",
+			inbox:  &models.Inbox{Base: models.Base{ID: 1}},
+			size:   4096,
+			mockFn: func(*mocks.Repository) {},
+		},
+		{
+			name:  "within quotas",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxMessagesPerHour: null.IntFrom(10), MaxTotalBytes: null.Int64From(10000)},
+			size:  4096,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).
+					Return(&models.InboxUsage{MessagesLastHour: 9, TotalBytes: 5000}, nil)
+			},
+		},
+		{
+			name:  "rate limited",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxMessagesPerHour: null.IntFrom(10)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).
+					Return(&models.InboxUsage{MessagesLastHour: 10}, nil)
+			},
+			wantErr:          errInboxRateLimited,
+			wantCode:         452,
+			wantEnhancedCode: smtp.EnhancedCode{4, 2, 2},
+		},
+		{
+			name:  "inbox full",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxTotalBytes: null.Int64From(10000)},
+			size:  4096,
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).
+					Return(&models.InboxUsage{TotalBytes: 8000}, nil)
+			},
+			wantErr:          errInboxFull,
+			wantCode:         552,
+			wantEnhancedCode: smtp.EnhancedCode{5, 2, 2},
+		},
+		{
+			name:             "message too large",
+			inbox:            &models.Inbox{Base: models.Base{ID: 1}, MaxMessageBytes: null.IntFrom(1000)},
+			size:             4096,
+			mockFn:           func(*mocks.Repository) {},
+			wantErr:          errMessageTooLarge,
+			wantCode:         552,
+			wantEnhancedCode: smtp.EnhancedCode{5, 3, 4},
+		},
+		{
+			name:  "usage unavailable",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, MaxMessagesPerHour: null.IntFrom(10)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).Return(nil, errDatabase)
+			},
+			wantErr: errDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			session := &SmtpSession{core: c}
+			err := session.checkQuota(core.WithSystemContext(context.Background()), tt.inbox, tt.size)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantCode != 0 {
+				var smtpErr *smtp.SMTPError
+				require.ErrorAs(t, err, &smtpErr)
+				assert.Equal(t, tt.wantCode, smtpErr.Code)
+				assert.Equal(t, tt.wantEnhancedCode, smtpErr.EnhancedCode)
+			}
+		})
+	}
+}
+
+func TestSmtpSession_RcptDeclaredSize(t *testing.T) {
+	inbox := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com", MaxMessageBytes: null.IntFrom(1000)}
+
+	tests := []struct {
+		name    string
+		opts    *smtp.MailOptions
+		wantErr error
+	}{
+		{
+			name:    "declared size over the limit",
+			opts:    &smtp.MailOptions{Size: 4096},
+			wantErr: errMessageTooLarge,
+		},
+		{
+			name: "declared size within the limit",
+			opts: &smtp.MailOptions{Size: 512},
+		},
+		{
+			name: "size not declared",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			expectRecipient(mockRepo, "qa@example.com", inbox)
+
+			session := &SmtpSession{core: c}
+			require.NoError(t, session.Mail("sender@example.net", tt.opts))
+
+			err := session.Rcpt("qa@example.com", nil)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Empty(t, session.inboxes)
+			}
+		})
+	}
+}
+
+func TestSmtpSession_DataActualSize(t *testing.T) {
+	body := "From: sender@example.net\r\nSubject: Logs\r\n\r\n" + strings.Repeat("line\r\n", 400)
+
+	tests := []struct {
+		name    string
+		inbox   *models.Inbox
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name:    "message larger than declared",
+			inbox:   &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com", MaxMessageBytes: null.IntFrom(1000)},
+			mockFn:  func(*mocks.Repository) {},
+			wantErr: errMessageTooLarge,
+		},
+		{
+			name:  "message filling the inbox",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com", MaxTotalBytes: null.Int64From(10000)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxUsage", mock.Anything, 1).
+					Return(&models.InboxUsage{TotalBytes: 8000}, nil)
+			},
+			wantErr: errInboxFull,
+		},
+		{
+			name:  "message within quotas",
+			inbox: &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com", MaxMessageBytes: null.IntFrom(10000)},
+			mockFn: func(m *mocks.Repository) {
+				m.On("CreateMessages", mock.Anything, mock.AnythingOfType("[]*models.Message")).Return(nil)
+				m.On("ListAllRulesByInbox", mock.Anything, mock.Anything).Return(nil, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			expectRecipient(mockRepo, "qa@example.com", tt.inbox)
+			tt.mockFn(mockRepo)
+
+			// The client declares a size small enough to pass RCPT
+			session := &SmtpSession{core: c}
+			require.NoError(t, session.Mail("sender@example.net", &smtp.MailOptions{Size: 100}))
+			require.NoError(t, session.Rcpt("qa@example.com", nil))
+
+			err := session.Data(strings.NewReader(body))
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -1,115 +1,265 @@
 package smtp
 
 import (
-	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"time"
 
 	"inbox451/internal/core"
 	"inbox451/internal/models"
+	"inbox451/internal/tlsconfig"
 
-	"github.com/emersion/go-message"
 	"github.com/emersion/go-smtp"
-	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultMaxMessageBytes bounds the size of a message when
+	// server.smtp.max_message_bytes is not configured
+	DefaultMaxMessageBytes = 25 << 20
+	// DefaultMaxRecipients bounds the recipients of a message when
+	// server.smtp.max_recipients is not configured
+	DefaultMaxRecipients = 100
+	// DefaultTimeout is the read and write timeout when
+	// server.smtp.read_timeout or write_timeout are not configured
+	DefaultTimeout = time.Minute
+	// DefaultMaxLargeMessages bounds the large messages loaded at once when
+	// server.smtp.max_large_messages is not configured
+	DefaultMaxLargeMessages = 4
 )
 
 type SmtpServer struct {
-	core *core.Core
-	smtp *smtp.Server
+	core    *core.Core
+	smtp    *smtp.Server
+	limiter *connectionLimiter
 }
 
 type SmtpBackend struct {
-	core *core.Core
+	core  *core.Core
+	large *largeMessageLimiter
 }
 
 type SmtpSession struct {
 	core *core.Core
-	from string
-	to   string
+	conn *smtp.Conn
+	// authenticated is set once the client passed AUTH, user is the
+	// authenticated user unless the configured credentials were used
+	authenticated bool
+	user          *models.User
+	// large is shared by the sessions of the server, nil for no limit
+	large *largeMessageLimiter
+	from  string
+	// size is the message size declared with MAIL FROM, 0 when unknown
+	size int64
+	// to holds the accepted recipients in RCPT order, inboxes the inbox each
+	// of them resolved to
+	to      []string
+	inboxes []*models.Inbox
 }
 
-func NewServer(core *core.Core) *SmtpServer {
-	be := &SmtpBackend{core: core}
+var errUnknownRecipient = &smtp.SMTPError{
+	Code:         550,
+	EnhancedCode: smtp.EnhancedCode{5, 1, 1},
+	Message:      "Recipient address rejected: no such inbox",
+}
+
+func NewServer(core *core.Core) (*SmtpServer, error) {
+	cfg := core.Config.Server.SMTP
+
+	tlsConfig, err := tlsconfig.Load(cfg.TLS, cfg.Hostname)
+	if err != nil {
+		return nil, fmt.Errorf("SMTP: %w", err)
+	}
+
+	maxLarge := cfg.MaxLargeMessages
+	if maxLarge <= 0 {
+		maxLarge = DefaultMaxLargeMessages
+	}
+	be := &SmtpBackend{core: core, large: newLargeMessageLimiter(maxLarge)}
 	s := smtp.NewServer(be)
 
-	s.Addr = core.Config.Server.SMTP.Port
-	s.Domain = core.Config.Server.SMTP.Hostname
-	s.AllowInsecureAuth = true
+	s.Addr = cfg.Port
+	s.Domain = cfg.Hostname
+	// STARTTLS is offered whenever a TLS configuration is set
+	s.TLSConfig = tlsConfig
+	s.AllowInsecureAuth = !cfg.TLS.AuthRequiresTLS
 
-	return &SmtpServer{
-		core: core,
-		smtp: s,
+	s.MaxMessageBytes = cfg.MaxMessageBytes
+	if s.MaxMessageBytes <= 0 {
+		s.MaxMessageBytes = DefaultMaxMessageBytes
+	}
+	s.MaxRecipients = cfg.MaxRecipients
+	if s.MaxRecipients <= 0 {
+		s.MaxRecipients = DefaultMaxRecipients
 	}
+	s.ReadTimeout = cfg.ReadTimeout
+	if s.ReadTimeout <= 0 {
+		s.ReadTimeout = DefaultTimeout
+	}
+	s.WriteTimeout = cfg.WriteTimeout
+	if s.WriteTimeout <= 0 {
+		s.WriteTimeout = DefaultTimeout
+	}
+
+	return &SmtpServer{
+		core:    core,
+		smtp:    s,
+		limiter: newConnectionLimiter(cfg.MaxConnectionsPerIP, core.Logger),
+	}, nil
 }
 
+// ListenAndServe serves the plain port and, when configured, the implicit
+// TLS port until the server is shut down. Both ports share the connection
+// limit of client addresses.
 func (s *SmtpServer) ListenAndServe() error {
-	return s.smtp.ListenAndServe()
+	implicitPort := s.core.Config.Server.SMTP.TLS.ImplicitPort
+	if s.smtp.TLSConfig == nil || implicitPort == "" {
+		return s.serve(s.smtp.Addr)
+	}
+
+	errc := make(chan error, 2)
+	go func() { errc <- s.serve(s.smtp.Addr) }()
+	go func() {
+		l, err := net.Listen("tcp", implicitPort)
+		if err != nil {
+			errc <- err
+			return
+		}
+		s.core.Logger.Info("SMTP implicit TLS listening on %s", implicitPort)
+		// The limit applies below TLS so the SMTP server still sees TLS
+		// connections, clients over the limit do not get a readable reply
+		errc <- s.smtp.Serve(tls.NewListener(s.limiter.Listen(l), s.smtp.TLSConfig))
+	}()
+	return <-errc
 }
 
-func (be *SmtpBackend) NewSession(_ *smtp.Conn) (smtp.Session, error) {
-	return &SmtpSession{core: be.core}, nil
+func (s *SmtpServer) serve(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.smtp.Serve(s.limiter.Listen(l))
 }
 
-func (s *SmtpSession) Mail(from string, _ *smtp.MailOptions) error {
+func (be *SmtpBackend) NewSession(c *smtp.Conn) (smtp.Session, error) {
+	return &SmtpSession{core: be.core, conn: c, large: be.large}, nil
+}
+
+func (s *SmtpSession) Mail(from string, opts *smtp.MailOptions) error {
+	if s.core.Config.Server.SMTP.RequireAuth && !s.authenticated {
+		return errAuthRequired
+	}
 	s.from = from
+	if opts != nil {
+		s.size = opts.Size
+	}
 	return nil
 }
 
+// Rcpt resolves the recipient to an inbox right away so unknown addresses are
+// rejected with a 550 instead of failing the whole transaction at DATA
 func (s *SmtpSession) Rcpt(to string, _ *smtp.RcptOptions) error {
-	s.to = to
+	ctx, cancel := context.WithTimeout(core.WithSystemContext(context.Background()), 10*time.Second)
+	defer cancel()
+
+	inbox, err := s.core.InboxService.GetByAddress(ctx, to)
+	if err != nil {
+		return err
+	}
+	if inbox == nil {
+		s.core.Logger.Info("Rejecting unknown recipient %s", to)
+		return errUnknownRecipient
+	}
+
+	if err := s.authorizeDelivery(ctx, to, inbox); err != nil {
+		return err
+	}
+
+	// Inboxes over their quotas are refused before the message is sent
+	if err := s.checkQuota(ctx, inbox, int(s.size)); err != nil {
+		return err
+	}
+
+	s.to = append(s.to, to)
+	s.inboxes = append(s.inboxes, inbox)
 	return nil
 }
 
 func (s *SmtpSession) Data(r io.Reader) error {
-	// Create a context with timeout for the email processing
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Delivery is done on behalf of no user, the recipients were authorized
+	// by Rcpt
+	ctx, cancel := context.WithTimeout(core.WithSystemContext(context.Background()), 30*time.Second)
 	defer cancel()
 
-	// Parse the email to get the subject
-	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(r); err != nil {
+	// Receive the whole message, it is persisted verbatim as the raw source.
+	// The server stops reading at server.smtp.max_message_bytes.
+	data := &spool{}
+	defer data.Close()
+	if _, err := io.Copy(data, s.idleTimeout(r)); err != nil {
 		return err
 	}
 
-	// Parse email headers and body
-	msg, err := message.Read(bytes.NewReader(buf.Bytes()))
-	if err != nil {
-		s.core.Logger.Error("Failed to parse email: %v", err)
-		return err
+	// The message is refused for every recipient when one of the inboxes
+	// does not accept its size, before it is loaded
+	checked := make(map[int]bool, len(s.inboxes))
+	for _, inbox := range s.inboxes {
+		if checked[inbox.ID] {
+			continue
+		}
+		checked[inbox.ID] = true
+		if err := s.checkQuota(ctx, inbox, int(data.Size())); err != nil {
+			return err
+		}
 	}
 
-	header := msg.Header
-	body := new(bytes.Buffer)
-	if _, err := io.Copy(body, msg.Body); err != nil {
-		s.core.Logger.Error("Failed to read message body: %v", err)
-		return err
+	// Parsing and storing needs the raw source and the decoded parts in
+	// memory together, only a few messages larger than the in-memory part
+	// of the spool are loaded at once
+	if data.Size() > spoolMemoryBytes {
+		if err := s.large.acquire(ctx); err != nil {
+			s.core.Logger.Info("Deferring email from %s: too many large messages in progress", s.from)
+			return errServerBusy
+		}
+		defer s.large.release()
 	}
 
-	message := &models.Message{
-		Body:     body.String(),
-		Sender:   s.from,
-		Receiver: s.to,
-		Subject:  header.Get("Subject"),
-		InboxID:  0, // We need to look up the inbox ID based on the recipient email
+	// Parse headers, text/html alternatives and attachments from the spool
+	r, err := data.Reader()
+	if err != nil {
+		s.core.Logger.Error("Failed to read spooled email: %v", err)
+		return err
 	}
-
-	// Look up the inbox ID based on the recipient email
-	inbox, err := s.core.Repository.GetInboxByEmail(ctx, s.to)
+	message, err := core.ReadMessage(r)
 	if err != nil {
-		s.core.Logger.Error("Failed to find inbox for email %s: %v", s.to, err)
+		s.core.Logger.Error("Failed to parse email: %v", err)
 		return err
 	}
-	if inbox == nil {
-		s.core.Logger.Error("No inbox found for email %s", s.to)
-		return fmt.Errorf("no inbox found for recipient")
+
+	// The raw source is stored as a single column so it has to be loaded
+	// as a whole, every inbox copy shares it
+	message.Raw, err = data.Bytes()
+	if err != nil {
+		s.core.Logger.Error("Failed to read spooled email: %v", err)
+		return err
 	}
+	message.Size = len(message.Raw)
 
-	message.InboxID = inbox.ID
+	// Deliver one copy per inbox; an inbox addressed twice only gets one
+	messages := make([]*models.Message, 0, len(s.inboxes))
+	delivered := make(map[int]bool, len(s.inboxes))
+	for i, inbox := range s.inboxes {
+		if delivered[inbox.ID] {
+			continue
+		}
+		delivered[inbox.ID] = true
+		messages = append(messages, copyMessage(message, inbox.ID, s.from, s.to[i]))
+	}
 
-	s.core.Logger.Info("Received email from %s to %s", s.from, s.to)
+	s.core.Logger.Info("Received email from %s to %v", s.from, s.to)
 
-	if err := s.core.StoreMessage(message); err != nil {
+	if err := s.core.MessageService.StoreAll(ctx, messages); err != nil {
 		s.core.Logger.Error("Failed to store message: %v", err)
 		return err
 	}
@@ -118,14 +268,57 @@ func (s *SmtpSession) Data(r io.Reader) error {
 	return nil
 }
 
-func (s *SmtpSession) Reset() {}
+// idleTimeout extends the read deadline of the connection before every read
+// of r. The server only sets the deadline when a command is read, so a
+// large message would otherwise have to arrive within a single timeout.
+func (s *SmtpSession) idleTimeout(r io.Reader) io.Reader {
+	timeout := s.core.Config.Server.SMTP.ReadTimeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if s.conn == nil {
+		return r
+	}
+	return &deadlineReader{r: r, conn: s.conn.Conn(), timeout: timeout}
+}
 
-func (s *SmtpSession) Logout() error {
-	return nil
+type deadlineReader struct {
+	r       io.Reader
+	conn    net.Conn
+	timeout time.Duration
 }
 
-func (s *SmtpSession) AuthPlain(username, password string) error {
-	return nil // TODO: For now, accept all auth
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func (s *SmtpSession) Reset() {
+	s.from = ""
+	s.size = 0
+	s.to = nil
+	s.inboxes = nil
+}
+
+// copyMessage returns a copy of a parsed message addressed to a single inbox.
+// Attachments are copied as well since storing a message assigns their IDs.
+func copyMessage(message *models.Message, inboxID int, from, to string) *models.Message {
+	c := *message
+	c.InboxID = inboxID
+	c.Sender = from
+	c.Receiver = to
+	c.Attachments = make([]*models.Attachment, len(message.Attachments))
+	for i, attachment := range message.Attachments {
+		a := *attachment
+		c.Attachments[i] = &a
+	}
+	return &c
+}
+
+func (s *SmtpSession) Logout() error {
+	return nil
 }
 
 func (s *SmtpServer) Shutdown(ctx context.Context) error {
@@ -0,0 +1,292 @@
+package smtp
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"inbox451/internal/config"
+	"inbox451/internal/core"
+	"inbox451/internal/events"
+	"inbox451/internal/logger"
+	"inbox451/internal/mocks"
+	"inbox451/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testAttachmentMessage = "From: sender@example.net\r\n" +
+	"To: qa@example.com, dev@example.com\r\n" +
+	"Subject: Report\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=b1\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"See attached\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Content-Disposition: attachment; filename=\"report.txt\"\r\n" +
+	"\r\n" +
+	"all green\r\n" +
+	"--b1--\r\n"
+
+func setupSessionTestCore(t *testing.T) (*core.Core, *mocks.Repository) {
+	mockRepo := mocks.NewRepository(t)
+	logger := logger.New(io.Discard, logger.DEBUG)
+
+	c := &core.Core{
+		Config:     &config.Config{},
+		Logger:     logger,
+		Repository: mockRepo,
+		Events:     events.NewBus(),
+	}
+	c.UserService = core.NewUserService(c)
+	c.ProjectService = core.NewProjectService(c)
+	c.InboxService = core.NewInboxService(c)
+	c.DomainService = core.NewDomainService(c)
+	c.RuleService = core.NewRuleService(c)
+	c.MessageService = core.NewMessageService(c)
+
+	return c, mockRepo
+}
+
+// expectRecipient sets up the lookups accepting a recipient of an inbox in
+// a project that does not require authentication
+func expectRecipient(m *mocks.Repository, address string, inbox *models.Inbox) {
+	m.On("GetInboxByAddress", mock.Anything, address, core.BaseAddress(address)).Return(inbox, nil).Once()
+	m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+		Return(&models.Domain{ProjectID: inbox.ProjectID, Name: "example.com"}, nil).Once()
+	m.On("GetProject", mock.Anything, inbox.ProjectID).
+		Return(&models.Project{Base: models.Base{ID: inbox.ProjectID}}, nil).Once()
+}
+
+func TestSmtpSession_Rcpt(t *testing.T) {
+	inbox := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}
+
+	tests := []struct {
+		name    string
+		to      string
+		mockFn  func(*mocks.Repository)
+		wantErr error
+	}{
+		{
+			name: "known recipient",
+			to:   "qa@example.com",
+			mockFn: func(m *mocks.Repository) {
+				expectRecipient(m, "qa@example.com", inbox)
+			},
+		},
+		{
+			name: "unknown recipient",
+			to:   "nobody@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "nobody@example.com", "nobody@example.com").
+					Return(nil, nil)
+			},
+			wantErr: errUnknownRecipient,
+		},
+		{
+			name: "unverified domain",
+			to:   "qa@example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "qa@example.com", "qa@example.com").
+					Return(inbox, nil)
+				m.On("GetVerifiedDomainForHost", mock.Anything, "example.com").
+					Return(nil, nil)
+			},
+			wantErr: errDomainNotVerified,
+		},
+		{
+			name: "subdomain verified by another project",
+			to:   "qa@sub.example.com",
+			mockFn: func(m *mocks.Repository) {
+				m.On("GetInboxByAddress", mock.Anything, "qa@sub.example.com", "qa@sub.example.com").
+					Return(&models.Inbox{Base: models.Base{ID: 4}, ProjectID: 2, Email: "*@sub.example.com"}, nil)
+				m.On("GetVerifiedDomainForHost", mock.Anything, "sub.example.com").
+					Return(&models.Domain{ProjectID: 3, Name: "sub.example.com"}, nil)
+			},
+			wantErr: errDomainNotVerified,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, mockRepo := setupSessionTestCore(t)
+			tt.mockFn(mockRepo)
+
+			session := &SmtpSession{core: c}
+			err := session.Rcpt(tt.to, nil)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Empty(t, session.to)
+				assert.Empty(t, session.inboxes)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.to}, session.to)
+			assert.Equal(t, []*models.Inbox{inbox}, session.inboxes)
+		})
+	}
+}
+
+func TestSmtpSession_Data(t *testing.T) {
+	qa := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}
+	dev := &models.Inbox{Base: models.Base{ID: 3}, ProjectID: 2, Email: "dev@example.com"}
+
+	c, mockRepo := setupSessionTestCore(t)
+	expectRecipient(mockRepo, "qa@example.com", qa)
+	expectRecipient(mockRepo, "qa+build@example.com", qa)
+	expectRecipient(mockRepo, "dev@example.com", dev)
+
+	var stored []*models.Message
+	mockRepo.On("CreateMessages", mock.Anything, mock.AnythingOfType("[]*models.Message")).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(1).([]*models.Message)
+			for i, message := range stored {
+				message.ID = 10 + i
+			}
+		}).
+		Return(nil)
+	mockRepo.On("ListAllRulesByInbox", mock.Anything, mock.Anything).Return(nil, nil)
+
+	session := &SmtpSession{core: c}
+	require.NoError(t, session.Mail("sender@example.net", nil))
+	for _, to := range []string{"qa@example.com", "qa+build@example.com", "dev@example.com"} {
+		require.NoError(t, session.Rcpt(to, nil))
+	}
+	require.NoError(t, session.Data(strings.NewReader(testAttachmentMessage)))
+
+	// The inbox addressed twice gets a single copy, for its first address
+	require.Len(t, stored, 2)
+	assert.Equal(t, 1, stored[0].InboxID)
+	assert.Equal(t, "qa@example.com", stored[0].Receiver)
+	assert.Equal(t, 3, stored[1].InboxID)
+	assert.Equal(t, "dev@example.com", stored[1].Receiver)
+	for _, message := range stored {
+		assert.Equal(t, "sender@example.net", message.Sender)
+		assert.Equal(t, "Report", message.Subject)
+		assert.Equal(t, []byte(testAttachmentMessage), message.Raw)
+		require.Len(t, message.Attachments, 1)
+		assert.Equal(t, "report.txt", message.Attachments[0].Filename)
+	}
+	assert.NotSame(t, stored[0].Attachments[0], stored[1].Attachments[0])
+}
+
+func TestSmtpSession_DataLargeMessage(t *testing.T) {
+	qa := &models.Inbox{Base: models.Base{ID: 1}, ProjectID: 2, Email: "qa@example.com"}
+	body := "From: sender@example.net\r\nSubject: Dump\r\n\r\n" + strings.Repeat("0123456789abcdef\r\n", spoolMemoryBytes/16)
+
+	c, mockRepo := setupSessionTestCore(t)
+	expectRecipient(mockRepo, "qa@example.com", qa)
+
+	stored := make(chan []*models.Message, 1)
+	mockRepo.On("CreateMessages", mock.Anything, mock.AnythingOfType("[]*models.Message")).
+		Run(func(args mock.Arguments) { stored <- args.Get(1).([]*models.Message) }).
+		Return(nil)
+	mockRepo.On("ListAllRulesByInbox", mock.Anything, mock.Anything).Return(nil, nil)
+
+	// The only slot is taken by another session
+	limiter := newLargeMessageLimiter(1)
+	require.NoError(t, limiter.acquire(context.Background()))
+
+	session := &SmtpSession{core: c, large: limiter}
+	require.NoError(t, session.Mail("sender@example.net", nil))
+	require.NoError(t, session.Rcpt("qa@example.com", nil))
+
+	done := make(chan error, 1)
+	go func() { done <- session.Data(strings.NewReader(body)) }()
+
+	select {
+	case <-stored:
+		t.Fatal("large message stored while the limit was reached")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("large message not stored once a slot was free")
+	}
+	messages := <-stored
+	require.Len(t, messages, 1)
+	assert.Len(t, messages[0].Raw, len(body))
+
+	// The slot is given back once the message is stored
+	assert.NoError(t, limiter.acquire(context.Background()))
+}
+
+func TestCopyMessage(t *testing.T) {
+	message := &models.Message{
+		InboxID:  1,
+		Subject:  "Report",
+		Receiver: "qa@example.com",
+		Attachments: []*models.Attachment{
+			{Filename: "report.txt", Content: []byte("all green")},
+		},
+	}
+
+	c := copyMessage(message, 3, "sender@example.net", "dev@example.com")
+
+	assert.Equal(t, 3, c.InboxID)
+	assert.Equal(t, "sender@example.net", c.Sender)
+	assert.Equal(t, "dev@example.com", c.Receiver)
+	assert.Equal(t, "Report", c.Subject)
+	require.Len(t, c.Attachments, 1)
+	assert.NotSame(t, message.Attachments[0], c.Attachments[0])
+
+	// Storing a copy assigns the IDs of its attachments, the original keeps its own
+	c.Attachments[0].ID = 7
+	assert.Equal(t, 0, message.Attachments[0].ID)
+	assert.Equal(t, 1, message.InboxID)
+	assert.Equal(t, "qa@example.com", message.Receiver)
+}
+
+// deadlineConn records the read deadlines set on it
+type deadlineConn struct {
+	net.Conn
+	deadlines []time.Time
+	err       error
+}
+
+func (c *deadlineConn) SetReadDeadline(t time.Time) error {
+	c.deadlines = append(c.deadlines, t)
+	return c.err
+}
+
+func TestDeadlineReader(t *testing.T) {
+	t.Run("deadline extended before every read", func(t *testing.T) {
+		conn := &deadlineConn{}
+		r := &deadlineReader{r: iotest.OneByteReader(strings.NewReader("DATA")), conn: conn, timeout: time.Minute}
+
+		start := time.Now()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "DATA", string(data))
+
+		// One read per byte and the read reporting EOF
+		require.Len(t, conn.deadlines, 5)
+		for _, deadline := range conn.deadlines {
+			assert.WithinDuration(t, start.Add(time.Minute), deadline, 5*time.Second)
+		}
+	})
+
+	t.Run("deadline failure", func(t *testing.T) {
+		conn := &deadlineConn{err: net.ErrClosed}
+		r := &deadlineReader{r: strings.NewReader("DATA"), conn: conn, timeout: time.Minute}
+
+		n, err := r.Read(make([]byte, 4))
+		assert.Zero(t, n)
+		assert.Equal(t, net.ErrClosed, err)
+	})
+}
@@ -0,0 +1,89 @@
+package smtp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// spoolMemoryBytes is the part of a message kept in memory while it is
+// received, larger messages are spooled to a temporary file
+const spoolMemoryBytes = 1 << 20
+
+// spool collects the DATA of a message. Small messages stay in memory,
+// larger ones move to a temporary file, so slow clients sending large
+// messages over many connections do not hold their messages in memory
+// until they are complete.
+type spool struct {
+	buf  bytes.Buffer
+	file *os.File
+	size int64
+}
+
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && s.buf.Len()+len(p) > spoolMemoryBytes {
+		file, err := os.CreateTemp("", "inbox451-smtp-*")
+		if err != nil {
+			return 0, err
+		}
+		s.file = file
+		if _, err := s.buf.WriteTo(file); err != nil {
+			return 0, err
+		}
+	}
+
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// Size is the number of bytes received so far
+func (s *spool) Size() int64 {
+	return s.size
+}
+
+// Reader returns a reader of the complete message. Writing to the spool
+// again invalidates it.
+func (s *spool) Reader() (io.Reader, error) {
+	if s.file == nil {
+		return bytes.NewReader(s.buf.Bytes()), nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return bufio.NewReader(s.file), nil
+}
+
+// Bytes loads the complete message. It is only needed for the raw source
+// stored with the message, everything else reads the spool with Reader.
+func (s *spool) Bytes() ([]byte, error) {
+	if s.file == nil {
+		return s.buf.Bytes(), nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, s.size)
+	if _, err := io.ReadFull(s.file, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// Close removes the temporary file, if any
+func (s *spool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
@@ -0,0 +1,57 @@
+package smtp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		wantSpill bool
+	}{
+		{name: "small message", size: 512},
+		{name: "message at the memory limit", size: spoolMemoryBytes},
+		{name: "message over the memory limit", size: spoolMemoryBytes + 1, wantSpill: true},
+		{name: "large message", size: 3 * spoolMemoryBytes, wantSpill: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte("0123456789abcdef"), tt.size/16+1)[:tt.size]
+
+			s := &spool{}
+			// Written in chunks as received from the connection
+			n, err := io.CopyBuffer(s, bytes.NewReader(data), make([]byte, 32<<10))
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.size), n)
+			assert.Equal(t, int64(tt.size), s.Size())
+			assert.Equal(t, tt.wantSpill, s.file != nil)
+			if tt.wantSpill {
+				assert.Zero(t, s.buf.Len())
+			}
+
+			r, err := s.Reader()
+			require.NoError(t, err)
+			read, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, read)
+
+			raw, err := s.Bytes()
+			require.NoError(t, err)
+			assert.Equal(t, data, raw)
+
+			require.NoError(t, s.Close())
+			if tt.wantSpill {
+				_, err := os.Stat(s.file.Name())
+				assert.True(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
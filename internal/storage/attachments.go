@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+
+	"inbox451/internal/models"
+)
+
+func (r *repository) ListAttachmentsByMessage(ctx context.Context, messageID int) ([]*models.Attachment, error) {
+	attachments := []*models.Attachment{}
+	err := r.queries.ListAttachmentsByMessage.SelectContext(ctx, &attachments, messageID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return attachments, nil
+}
+
+func (r *repository) GetAttachment(ctx context.Context, id int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.queries.GetAttachment.GetContext(ctx, &attachment, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupAttachmentTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("SELECT (.+) FROM attachments WHERE message_id") // ListAttachmentsByMessage
+	mock.ExpectPrepare("SELECT (.+) FROM attachments WHERE id")         // GetAttachment
+
+	listAttachments, err := sqlxDB.Preparex("SELECT id, message_id, filename, content_type, content_id, size, created_at, updated_at FROM attachments WHERE message_id = ? ORDER BY id")
+	require.NoError(t, err)
+
+	getAttachment, err := sqlxDB.Preparex("SELECT id, message_id, filename, content_type, content_id, size, content, created_at, updated_at FROM attachments WHERE id = ?")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		ListAttachmentsByMessage: listAttachments,
+		GetAttachment:            getAttachment,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+func TestRepository_ListAttachmentsByMessage(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		messageID int
+		mockFn    func(sqlmock.Sqlmock)
+		want      []*models.Attachment
+		wantErr   bool
+	}{
+		{
+			name:      "attachments found",
+			messageID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"id", "message_id", "filename", "content_type", "content_id", "size", "created_at", "updated_at",
+				}).
+					AddRow(1, 1, "report.pdf", "application/pdf", "", 1024, now, now).
+					AddRow(2, 1, "logo.png", "image/png", "logo@example.com", 512, now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM attachments").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			want: []*models.Attachment{
+				{
+					Base:        models.Base{ID: 1, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+					MessageID:   1,
+					Filename:    "report.pdf",
+					ContentType: "application/pdf",
+					Size:        1024,
+				},
+				{
+					Base:        models.Base{ID: 2, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+					MessageID:   1,
+					Filename:    "logo.png",
+					ContentType: "image/png",
+					ContentID:   "logo@example.com",
+					Size:        512,
+				},
+			},
+		},
+		{
+			name:      "no attachments",
+			messageID: 2,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM attachments").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			want: []*models.Attachment{},
+		},
+		{
+			name:      "database error",
+			messageID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM attachments").
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupAttachmentTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.ListAttachmentsByMessage(context.Background(), tt.messageID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRepository_GetAttachment(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		id      int
+		mockFn  func(sqlmock.Sqlmock)
+		want    *models.Attachment
+		wantErr bool
+		errType error
+	}{
+		{
+			name: "existing attachment",
+			id:   1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"id", "message_id", "filename", "content_type", "content_id", "size", "content", "created_at", "updated_at",
+				}).AddRow(1, 1, "report.pdf", "application/pdf", "", 3, []byte("PDF"), now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM attachments").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			want: &models.Attachment{
+				Base:        models.Base{ID: 1, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+				MessageID:   1,
+				Filename:    "report.pdf",
+				ContentType: "application/pdf",
+				Size:        3,
+				Content:     []byte("PDF"),
+			},
+		},
+		{
+			name: "non-existent attachment",
+			id:   999,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM attachments").
+					WithArgs(999).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+			errType: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupAttachmentTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetAttachment(context.Background(), tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != nil {
+					assert.ErrorIs(t, err, tt.errType)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
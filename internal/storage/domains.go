@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"inbox451/internal/models"
+)
+
+func (r *repository) ListDomainsByProject(ctx context.Context, projectID, limit, offset int) ([]*models.Domain, int, error) {
+	var total int
+	err := r.queries.CountDomainsByProject.GetContext(ctx, &total, projectID)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	domains := []*models.Domain{}
+	if total > 0 {
+		err = r.queries.ListDomainsByProject.SelectContext(ctx, &domains, projectID, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return domains, total, nil
+}
+
+func (r *repository) GetDomain(ctx context.Context, id int) (*models.Domain, error) {
+	var domain models.Domain
+	err := r.queries.GetDomain.GetContext(ctx, &domain, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &domain, nil
+}
+
+func (r *repository) CreateDomain(ctx context.Context, domain *models.Domain) error {
+	err := r.queries.CreateDomain.QueryRowContext(ctx, domain.ProjectID, domain.Name, domain.VerificationToken).
+		Scan(&domain.ID, &domain.CreatedAt, &domain.UpdatedAt)
+	return handleDBError(err)
+}
+
+// VerifyDomain marks a domain as verified. Only one project may have a
+// verified domain of a given name, verifying it a second time fails with
+// ErrConflict.
+func (r *repository) VerifyDomain(ctx context.Context, domain *models.Domain) error {
+	err := r.queries.VerifyDomain.QueryRowContext(ctx, domain.ID).
+		Scan(&domain.VerifiedAt, &domain.UpdatedAt)
+	return handleDBError(err)
+}
+
+func (r *repository) DeleteDomain(ctx context.Context, id int) error {
+	result, err := r.queries.DeleteDomain.ExecContext(ctx, id)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// GetVerifiedDomainForHost returns the most specific verified domain covering
+// host, either the domain itself or one of its parents, whichever project
+// verified it. Returns nil when no such domain is verified.
+func (r *repository) GetVerifiedDomainForHost(ctx context.Context, host string) (*models.Domain, error) {
+	var domain models.Domain
+	err := r.queries.GetVerifiedDomainForHost.GetContext(ctx, &domain, host)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, handleDBError(err)
+	}
+	return &domain, nil
+}
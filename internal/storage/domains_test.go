@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDomainTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("SELECT (.+) FROM domains WHERE project_id = \\? ORDER BY") // ListDomainsByProject
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM domains WHERE project_id")           // CountDomainsByProject
+	mock.ExpectPrepare("SELECT (.+) FROM domains WHERE id")                        // GetDomain
+	mock.ExpectPrepare("INSERT INTO domains")                                      // CreateDomain
+	mock.ExpectPrepare("UPDATE domains")                                           // VerifyDomain
+	mock.ExpectPrepare("DELETE FROM domains")                                      // DeleteDomain
+	mock.ExpectPrepare("SELECT (.+) FROM domains WHERE verified_at IS NOT NULL")   // GetVerifiedDomainForHost
+
+	listDomains, err := sqlxDB.Preparex("SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at FROM domains WHERE project_id = ? ORDER BY name LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	countDomains, err := sqlxDB.Preparex("SELECT COUNT(*) FROM domains WHERE project_id = ?")
+	require.NoError(t, err)
+
+	getDomain, err := sqlxDB.Preparex("SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at FROM domains WHERE id = ?")
+	require.NoError(t, err)
+
+	createDomain, err := sqlxDB.Preparex("INSERT INTO domains (project_id, name, verification_token) VALUES (?, ?, ?)")
+	require.NoError(t, err)
+
+	verifyDomain, err := sqlxDB.Preparex("UPDATE domains SET verified_at = CURRENT_TIMESTAMP WHERE id = ? RETURNING verified_at, updated_at")
+	require.NoError(t, err)
+
+	deleteDomain, err := sqlxDB.Preparex("DELETE FROM domains WHERE id = ?")
+	require.NoError(t, err)
+
+	getVerifiedDomain, err := sqlxDB.Preparex("SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at FROM domains WHERE verified_at IS NOT NULL AND name = ?")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		ListDomainsByProject:     listDomains,
+		CountDomainsByProject:    countDomains,
+		GetDomain:                getDomain,
+		CreateDomain:             createDomain,
+		VerifyDomain:             verifyDomain,
+		DeleteDomain:             deleteDomain,
+		GetVerifiedDomainForHost: getVerifiedDomain,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+var domainColumns = []string{"id", "project_id", "name", "verification_token", "verified_at", "created_at", "updated_at"}
+
+func TestRepository_CreateDomain(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "successful creation",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO domains").
+					WithArgs(1, "example.com", "abc123").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, now, now))
+			},
+		},
+		{
+			name: "already claimed by the project",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO domains").
+					WithArgs(1, "example.com", "abc123").
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupDomainTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			domain := &models.Domain{ProjectID: 1, Name: "example.com", VerificationToken: "abc123"}
+			err := repo.CreateDomain(context.Background(), domain)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 2, domain.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetDomain(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "existing domain",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM domains WHERE id").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows(domainColumns).
+						AddRow(2, 1, "example.com", "abc123", nil, now, now))
+			},
+		},
+		{
+			name: "non-existent domain",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM domains WHERE id").
+					WithArgs(2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupDomainTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetDomain(context.Background(), 2)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "example.com", got.Name)
+			assert.Equal(t, "abc123", got.VerificationToken)
+			assert.False(t, got.VerifiedAt.Valid)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_VerifyDomain(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "successful verification",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE domains").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"verified_at", "updated_at"}).AddRow(now, now))
+			},
+		},
+		{
+			name: "verified by another project",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE domains").
+					WithArgs(2).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupDomainTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			domain := &models.Domain{ProjectID: 1, Name: "example.com"}
+			domain.ID = 2
+			err := repo.VerifyDomain(context.Background(), domain)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, domain.VerifiedAt.Valid)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_DeleteDomain(t *testing.T) {
+	repo, mock := setupDomainTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("DELETE FROM domains").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteDomain(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNoRowsAffected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ListDomainsByProject(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupDomainTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM domains WHERE project_id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT (.+) FROM domains WHERE project_id = \\? ORDER BY").
+		WithArgs(1, 10, 0).
+		WillReturnRows(sqlmock.NewRows(domainColumns).
+			AddRow(2, 1, "example.com", "abc123", now, now, now).
+			AddRow(3, 1, "example.org", "def456", nil, now, now))
+
+	got, total, err := repo.ListDomainsByProject(context.Background(), 1, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, got, 2)
+	assert.True(t, got[0].VerifiedAt.Valid)
+	assert.False(t, got[1].VerifiedAt.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetVerifiedDomainForHost(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		host     string
+		mockFn   func(sqlmock.Sqlmock)
+		wantName string
+	}{
+		{
+			name: "parent domain verified",
+			host: "qa.example.com",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM domains").
+					WithArgs("qa.example.com").
+					WillReturnRows(sqlmock.NewRows(domainColumns).
+						AddRow(2, 1, "example.com", "abc123", now, now, now))
+			},
+			wantName: "example.com",
+		},
+		{
+			name: "no verified domain",
+			host: "example.org",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM domains").
+					WithArgs("example.org").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupDomainTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetVerifiedDomainForHost(context.Background(), tt.host)
+			assert.NoError(t, err)
+			if tt.wantName == "" {
+				assert.Nil(t, got)
+			} else {
+				require.NotNil(t, got)
+				assert.Equal(t, tt.wantName, got.Name)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+
+	"github.com/lib/pq"
 )
 
 var (
@@ -11,8 +13,13 @@ var (
 	ErrNotFound = errors.New("resource not found")
 	// ErrNoRowsAffected is returned when an update/delete operation affects no rows
 	ErrNoRowsAffected = errors.New("no rows affected")
+	// ErrConflict is returned when a write violates a unique constraint
+	ErrConflict = errors.New("resource already exists")
 )
 
+// uniqueViolation is the PostgreSQL error code of unique constraint violations
+const uniqueViolation = "23505"
+
 // handleDBError standardizes database error handling
 func handleDBError(err error) error {
 	if err == nil {
@@ -22,6 +29,11 @@ func handleDBError(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return ErrNotFound
 	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrConflict
+	}
 	return fmt.Errorf("database error: %w", err)
 }
 
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+
+	"github.com/lib/pq"
 )
 
 func TestHandleDBError(t *testing.T) {
@@ -22,6 +24,11 @@ func TestHandleDBError(t *testing.T) {
 			err:      sql.ErrNoRows,
 			expected: ErrNotFound,
 		},
+		{
+			name:     "unique violation returns ErrConflict",
+			err:      &pq.Error{Code: "23505"},
+			expected: ErrConflict,
+		},
 		{
 			name:     "other errors are wrapped",
 			err:      errors.New("some error"),
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+
+	"inbox451/internal/models"
+)
+
+func (r *repository) ListFoldersByInbox(ctx context.Context, inboxID, limit, offset int) ([]*models.Folder, int, error) {
+	var total int
+	err := r.queries.CountFoldersByInbox.GetContext(ctx, &total, inboxID)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	folders := []*models.Folder{}
+	if total > 0 {
+		err = r.queries.ListFoldersByInbox.SelectContext(ctx, &folders, inboxID, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return folders, total, nil
+}
+
+// ListAllFoldersByInbox returns every folder of an inbox ordered by name
+func (r *repository) ListAllFoldersByInbox(ctx context.Context, inboxID int) ([]*models.Folder, error) {
+	folders := []*models.Folder{}
+	err := r.queries.ListAllFoldersByInbox.SelectContext(ctx, &folders, inboxID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return folders, nil
+}
+
+func (r *repository) GetFolder(ctx context.Context, id int) (*models.Folder, error) {
+	var folder models.Folder
+	err := r.queries.GetFolder.GetContext(ctx, &folder, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &folder, nil
+}
+
+func (r *repository) GetFolderByName(ctx context.Context, inboxID int, name string) (*models.Folder, error) {
+	var folder models.Folder
+	err := r.queries.GetFolderByName.GetContext(ctx, &folder, inboxID, name)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &folder, nil
+}
+
+func (r *repository) CreateFolder(ctx context.Context, folder *models.Folder) error {
+	err := r.queries.CreateFolder.QueryRowContext(ctx, folder.InboxID, folder.Name).
+		Scan(&folder.ID, &folder.CreatedAt, &folder.UpdatedAt)
+	return handleDBError(err)
+}
+
+// RenameFolder renames a folder of an inbox together with the folders below
+// it, so "Archive" -> "Old" also moves "Archive/2024" to "Old/2024"
+func (r *repository) RenameFolder(ctx context.Context, inboxID int, oldName, newName string) error {
+	result, err := r.queries.RenameFolder.ExecContext(ctx, inboxID, oldName, newName)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// DeleteFolder removes a folder and the messages filed in it
+func (r *repository) DeleteFolder(ctx context.Context, id int) error {
+	result, err := r.queries.DeleteFolder.ExecContext(ctx, id)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
@@ -0,0 +1,401 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFolderTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("INSERT INTO folders")                                          // CreateFolder
+	mock.ExpectPrepare("SELECT (.+) FROM folders WHERE id")                            // GetFolder
+	mock.ExpectPrepare("SELECT (.+) FROM folders WHERE inbox_id = \\? AND name")       // GetFolderByName
+	mock.ExpectPrepare("UPDATE folders")                                               // RenameFolder
+	mock.ExpectPrepare("DELETE FROM folders")                                          // DeleteFolder
+	mock.ExpectPrepare("SELECT (.+) FROM folders WHERE inbox_id = \\? (.+) LIMIT")     // ListFoldersByInbox
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM folders WHERE inbox_id")                 // CountFoldersByInbox
+	mock.ExpectPrepare("SELECT (.+) FROM folders WHERE inbox_id = \\? ORDER BY name$") // ListAllFoldersByInbox
+
+	createFolder, err := sqlxDB.Preparex("INSERT INTO folders (inbox_id, name) VALUES (?, ?)")
+	require.NoError(t, err)
+
+	getFolder, err := sqlxDB.Preparex("SELECT id, inbox_id, name, created_at, updated_at FROM folders WHERE id = ?")
+	require.NoError(t, err)
+
+	getFolderByName, err := sqlxDB.Preparex("SELECT id, inbox_id, name, created_at, updated_at FROM folders WHERE inbox_id = ? AND name = ?")
+	require.NoError(t, err)
+
+	renameFolder, err := sqlxDB.Preparex("UPDATE folders SET name = ? WHERE inbox_id = ? AND name = ?")
+	require.NoError(t, err)
+
+	deleteFolder, err := sqlxDB.Preparex("DELETE FROM folders WHERE id = ?")
+	require.NoError(t, err)
+
+	listFolders, err := sqlxDB.Preparex("SELECT id, inbox_id, name, created_at, updated_at FROM folders WHERE inbox_id = ? ORDER BY name LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	countFolders, err := sqlxDB.Preparex("SELECT COUNT(*) FROM folders WHERE inbox_id = ?")
+	require.NoError(t, err)
+
+	listAllFolders, err := sqlxDB.Preparex("SELECT id, inbox_id, name, created_at, updated_at FROM folders WHERE inbox_id = ? ORDER BY name")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		CreateFolder:          createFolder,
+		GetFolder:             getFolder,
+		GetFolderByName:       getFolderByName,
+		RenameFolder:          renameFolder,
+		DeleteFolder:          deleteFolder,
+		ListFoldersByInbox:    listFolders,
+		CountFoldersByInbox:   countFolders,
+		ListAllFoldersByInbox: listAllFolders,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+func TestRepository_CreateFolder(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "successful creation",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO folders").
+					WithArgs(1, "Archive").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+			},
+		},
+		{
+			name: "duplicate name",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO folders").
+					WithArgs(1, "Archive").
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			folder := &models.Folder{InboxID: 1, Name: "Archive"}
+			err := repo.CreateFolder(context.Background(), folder)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, folder.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetFolder(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		want    *models.Folder
+		wantErr error
+	}{
+		{
+			name: "existing folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM folders WHERE id").
+					WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "name", "created_at", "updated_at"}).
+						AddRow(3, 1, "Archive", now, now))
+			},
+			want: &models.Folder{InboxID: 1, Name: "Archive"},
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM folders WHERE id").
+					WithArgs(3).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetFolder(context.Background(), 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.InboxID, got.InboxID)
+			assert.Equal(t, tt.want.Name, got.Name)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetFolderByName(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "existing folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM folders WHERE inbox_id = \\? AND name").
+					WithArgs(1, "Archive/2024").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "name", "created_at", "updated_at"}).
+						AddRow(4, 1, "Archive/2024", now, now))
+			},
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM folders WHERE inbox_id = \\? AND name").
+					WithArgs(1, "Archive/2024").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetFolderByName(context.Background(), 1, "Archive/2024")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 4, got.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_RenameFolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "renames the folder and its children",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE folders").
+					WithArgs(1, "Archive", "Old").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE folders").
+					WithArgs(1, "Archive", "Old").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNoRowsAffected,
+		},
+		{
+			name: "new name taken",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE folders").
+					WithArgs(1, "Archive", "Old").
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantErr: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.RenameFolder(context.Background(), 1, "Archive", "Old")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_DeleteFolder(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "successful deletion",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM folders").
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "non-existent folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM folders").
+					WithArgs(3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrNoRowsAffected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.DeleteFolder(context.Background(), 3)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ListFoldersByInbox(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		mockFn    func(sqlmock.Sqlmock)
+		wantCount int
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name: "folders found",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM folders WHERE inbox_id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM folders WHERE inbox_id = \\? (.+) LIMIT").
+					WithArgs(1, 10, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "name", "created_at", "updated_at"}).
+						AddRow(3, 1, "Archive", now, now).
+						AddRow(4, 1, "Archive/2024", now, now))
+			},
+			wantCount: 2,
+			wantTotal: 2,
+		},
+		{
+			name: "no folders",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM folders WHERE inbox_id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			wantCount: 0,
+			wantTotal: 0,
+		},
+		{
+			name: "count error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM folders WHERE inbox_id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupFolderTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, total, err := repo.ListFoldersByInbox(context.Background(), 1, 10, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, got, tt.wantCount)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ListAllFoldersByInbox(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupFolderTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM folders WHERE inbox_id = \\? ORDER BY name$").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "name", "created_at", "updated_at"}).
+			AddRow(3, 1, "Archive", now, now))
+
+	got, err := repo.ListAllFoldersByInbox(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -9,7 +9,8 @@ import (
 )
 
 func (r *repository) CreateInbox(ctx context.Context, inbox *models.Inbox) error {
-	return r.queries.CreateInbox.QueryRowContext(ctx, inbox.ProjectID, inbox.Email).
+	return r.queries.CreateInbox.QueryRowContext(ctx, inbox.ProjectID, inbox.Email,
+		inbox.MaxMessagesPerHour, inbox.MaxTotalBytes, inbox.MaxMessageBytes).
 		Scan(&inbox.ID, &inbox.CreatedAt, &inbox.UpdatedAt)
 }
 
@@ -31,8 +32,26 @@ func (r *repository) GetInboxByEmail(ctx context.Context, email string) (*models
 	return &inbox, nil
 }
 
+// GetInboxByAddress resolves the recipient address of a message to an inbox.
+// An inbox with exactly that email wins, then one with the email of the base
+// address, the address without its +tag, and finally the most specific
+// inbox whose email is a pattern matching the address, * standing for any
+// characters. Returns nil when no inbox matches.
+func (r *repository) GetInboxByAddress(ctx context.Context, address, baseAddress string) (*models.Inbox, error) {
+	var inbox models.Inbox
+	err := r.queries.GetInboxByAddress.GetContext(ctx, &inbox, address, baseAddress)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, handleDBError(err)
+	}
+	return &inbox, nil
+}
+
 func (r *repository) UpdateInbox(ctx context.Context, inbox *models.Inbox) error {
-	result, err := r.queries.UpdateInbox.ExecContext(ctx, inbox.Email, inbox.ID)
+	result, err := r.queries.UpdateInbox.ExecContext(ctx, inbox.Email,
+		inbox.MaxMessagesPerHour, inbox.MaxTotalBytes, inbox.MaxMessageBytes, inbox.ID)
 	if err != nil {
 		return handleDBError(err)
 	}
@@ -64,3 +83,24 @@ func (r *repository) ListInboxesByProject(ctx context.Context, projectID, limit,
 
 	return inboxes, total, nil
 }
+
+// ListInboxesByUser returns every inbox of the projects the user belongs to
+func (r *repository) ListInboxesByUser(ctx context.Context, userID int) ([]*models.Inbox, error) {
+	inboxes := []*models.Inbox{}
+	err := r.queries.ListInboxesByUser.SelectContext(ctx, &inboxes, userID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return inboxes, nil
+}
+
+// GetInboxUsage counts the messages of an inbox, their total size and the
+// messages received during the last hour
+func (r *repository) GetInboxUsage(ctx context.Context, inboxID int) (*models.InboxUsage, error) {
+	usage := models.InboxUsage{InboxID: inboxID}
+	err := r.queries.GetInboxUsage.GetContext(ctx, &usage, inboxID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &usage, nil
+}
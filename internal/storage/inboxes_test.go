@@ -21,13 +21,16 @@ func setupInboxTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
-	mock.ExpectPrepare("SELECT (.+) FROM inboxes")             // ListInboxes
-	mock.ExpectPrepare("SELECT COUNT(.+) FROM inboxes")        // CountInboxes
-	mock.ExpectPrepare("SELECT (.+) FROM inboxes WHERE id")    // GetInbox
-	mock.ExpectPrepare("INSERT INTO inboxes")                  // CreateInbox
-	mock.ExpectPrepare("UPDATE inboxes")                       // UpdateInbox
-	mock.ExpectPrepare("DELETE FROM inboxes")                  // DeleteInbox
-	mock.ExpectPrepare("SELECT (.+) FROM inboxes WHERE email") // GetInboxByEmail
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes")              // ListInboxes
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM inboxes")         // CountInboxes
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes WHERE id")     // GetInbox
+	mock.ExpectPrepare("INSERT INTO inboxes")                   // CreateInbox
+	mock.ExpectPrepare("UPDATE inboxes")                        // UpdateInbox
+	mock.ExpectPrepare("DELETE FROM inboxes")                   // DeleteInbox
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes WHERE email")  // GetInboxByEmail
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes i INNER JOIN") // ListInboxesByUser
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes WHERE lower")  // GetInboxByAddress
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages")        // GetInboxUsage
 
 	listInboxes, err := sqlxDB.Preparex("SELECT id, project_id, email, created_at, updated_at FROM inboxes WHERE project_id = ? LIMIT ? OFFSET ?")
 	require.NoError(t, err)
@@ -50,6 +53,15 @@ func setupInboxTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	getInboxByEmail, err := sqlxDB.Preparex("SELECT id, project_id, email, created_at, updated_at FROM inboxes WHERE email = ?")
 	require.NoError(t, err)
 
+	listInboxesByUser, err := sqlxDB.Preparex("SELECT i.id, i.project_id, i.email, i.created_at, i.updated_at FROM inboxes i INNER JOIN project_users pu ON pu.project_id = i.project_id WHERE pu.user_id = ? ORDER BY i.id")
+	require.NoError(t, err)
+
+	getInboxByAddress, err := sqlxDB.Preparex("SELECT id, project_id, email, created_at, updated_at FROM inboxes WHERE lower(email) IN (lower(?), lower(?)) LIMIT 1")
+	require.NoError(t, err)
+
+	getInboxUsage, err := sqlxDB.Preparex("SELECT COUNT(*) AS messages, COALESCE(SUM(size), 0) AS total_bytes, COUNT(*) AS messages_last_hour FROM messages WHERE inbox_id = ?")
+	require.NoError(t, err)
+
 	queries := &Queries{
 		ListInboxesByProject:  listInboxes,
 		CountInboxesByProject: countInboxes,
@@ -58,6 +70,9 @@ func setupInboxTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 		UpdateInbox:           updateInbox,
 		DeleteInbox:           deleteInbox,
 		GetInboxByEmail:       getInboxByEmail,
+		ListInboxesByUser:     listInboxesByUser,
+		GetInboxByAddress:     getInboxByAddress,
+		GetInboxUsage:         getInboxUsage,
 	}
 
 	repo := &repository{
@@ -80,12 +95,13 @@ func TestRepository_CreateInbox(t *testing.T) {
 		{
 			name: "successful creation",
 			inbox: &models.Inbox{
-				ProjectID: 1,
-				Email:     "test@example.com",
+				ProjectID:       1,
+				Email:           "test@example.com",
+				MaxMessageBytes: null.IntFrom(1 << 20),
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO inboxes").
-					WithArgs(1, "test@example.com").
+					WithArgs(1, "test@example.com", nil, nil, 1<<20).
 					WillReturnRows(
 						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 							AddRow(1, now, now),
@@ -101,7 +117,7 @@ func TestRepository_CreateInbox(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO inboxes").
-					WithArgs(1, "existing@example.com").
+					WithArgs(1, "existing@example.com", nil, nil, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -273,6 +289,89 @@ func TestRepository_GetInboxByEmail(t *testing.T) {
 	}
 }
 
+func TestRepository_GetInboxByAddress(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		address     string
+		baseAddress string
+		mockFn      func(sqlmock.Sqlmock)
+		want        *models.Inbox
+		wantErr     bool
+	}{
+		{
+			name:        "wildcard inbox",
+			address:     "signup+run42@qa.example.com",
+			baseAddress: "signup@qa.example.com",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"id", "project_id", "email", "created_at", "updated_at",
+				}).AddRow(3, 1, "*@qa.example.com", now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM inboxes").
+					WithArgs("signup+run42@qa.example.com", "signup@qa.example.com").
+					WillReturnRows(rows)
+			},
+			want: &models.Inbox{
+				Base: models.Base{
+					ID:        3,
+					CreatedAt: null.TimeFrom(now),
+					UpdatedAt: null.TimeFrom(now),
+				},
+				ProjectID: 1,
+				Email:     "*@qa.example.com",
+			},
+			wantErr: false,
+		},
+		{
+			name:        "no matching inbox",
+			address:     "nobody@example.com",
+			baseAddress: "nobody@example.com",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM inboxes").
+					WithArgs("nobody@example.com", "nobody@example.com").
+					WillReturnError(sql.ErrNoRows)
+			},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:        "database error",
+			address:     "test@example.com",
+			baseAddress: "test@example.com",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM inboxes").
+					WithArgs("test@example.com", "test@example.com").
+					WillReturnError(sql.ErrConnDone)
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupInboxTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetInboxByAddress(context.Background(), tt.address, tt.baseAddress)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestRepository_UpdateInbox(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -283,13 +382,15 @@ func TestRepository_UpdateInbox(t *testing.T) {
 		{
 			name: "successful update",
 			inbox: &models.Inbox{
-				Base:      models.Base{ID: 1},
-				ProjectID: 1,
-				Email:     "updated@example.com",
+				Base:               models.Base{ID: 1},
+				ProjectID:          1,
+				Email:              "updated@example.com",
+				MaxMessagesPerHour: null.IntFrom(100),
+				MaxTotalBytes:      null.Int64From(1 << 30),
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec("UPDATE inboxes").
-					WithArgs("updated@example.com", 1).
+					WithArgs("updated@example.com", 100, 1<<30, nil, 1).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
@@ -303,7 +404,7 @@ func TestRepository_UpdateInbox(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec("UPDATE inboxes").
-					WithArgs("updated@example.com", 999).
+					WithArgs("updated@example.com", nil, nil, nil, 999).
 					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
 			wantErr: true,
@@ -477,3 +578,87 @@ func TestRepository_ListInboxesByProject(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_ListInboxesByUser(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		userID  int
+		mockFn  func(sqlmock.Sqlmock)
+		want    []*models.Inbox
+		wantErr bool
+	}{
+		{
+			name:   "inboxes of every project",
+			userID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "project_id", "email", "created_at", "updated_at"}).
+					AddRow(1, 1, "one@example.com", now, now).
+					AddRow(3, 2, "two@example.com", now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM inboxes i INNER JOIN").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			want: []*models.Inbox{
+				{
+					Base:      models.Base{ID: 1, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+					ProjectID: 1,
+					Email:     "one@example.com",
+				},
+				{
+					Base:      models.Base{ID: 3, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+					ProjectID: 2,
+					Email:     "two@example.com",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "database error",
+			userID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM inboxes i INNER JOIN").
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupInboxTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.ListInboxesByUser(context.Background(), tt.userID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRepository_GetInboxUsage(t *testing.T) {
+	repo, mock := setupInboxTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM messages").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"messages", "total_bytes", "messages_last_hour"}).AddRow(12, 40960, 3))
+
+	got, err := repo.GetInboxUsage(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, &models.InboxUsage{InboxID: 1, Messages: 12, TotalBytes: 40960, MessagesLastHour: 3}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,15 +2,61 @@ package storage
 
 import (
 	"context"
+	"strings"
 
 	"inbox451/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	null "github.com/volatiletech/null/v9"
 )
 
+// CreateMessage stores a message and its attachments in a single transaction
 func (r *repository) CreateMessage(ctx context.Context, message *models.Message) error {
-	err := r.queries.CreateMessage.QueryRowContext(ctx,
-		message.InboxID, message.Sender, message.Receiver, message.Subject, message.Body).
+	return r.CreateMessages(ctx, []*models.Message{message})
+}
+
+// CreateMessages stores several messages and their attachments in a single
+// transaction, either all of them are persisted or none is
+func (r *repository) CreateMessages(ctx context.Context, messages []*models.Message) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return handleDBError(err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback() }()
+
+	for _, message := range messages {
+		if err := r.createMessageTx(ctx, tx, message); err != nil {
+			return err
+		}
+	}
+
+	return handleDBError(tx.Commit())
+}
+
+func (r *repository) createMessageTx(ctx context.Context, tx *sqlx.Tx, message *models.Message) error {
+	err := tx.StmtxContext(ctx, r.queries.CreateMessage).QueryRowxContext(ctx,
+		message.InboxID, message.FolderID, message.Sender, message.Receiver, message.Subject, message.Body,
+		message.HTMLBody, message.Raw, message.Size).
 		Scan(&message.ID, &message.CreatedAt, &message.UpdatedAt)
-	return handleDBError(err)
+	if err != nil {
+		return handleDBError(err)
+	}
+
+	createAttachment := tx.StmtxContext(ctx, r.queries.CreateAttachment)
+	for _, attachment := range message.Attachments {
+		attachment.MessageID = message.ID
+		err := createAttachment.QueryRowxContext(ctx,
+			attachment.MessageID, attachment.Filename, attachment.ContentType, attachment.ContentID,
+			attachment.Size, attachment.Content).
+			Scan(&attachment.ID, &attachment.CreatedAt, &attachment.UpdatedAt)
+		if err != nil {
+			return handleDBError(err)
+		}
+	}
+
+	return nil
 }
 
 func (r *repository) GetMessage(ctx context.Context, id int) (*models.Message, error) {
@@ -22,6 +68,15 @@ func (r *repository) GetMessage(ctx context.Context, id int) (*models.Message, e
 	return &message, nil
 }
 
+func (r *repository) GetMessageRaw(ctx context.Context, id int) ([]byte, error) {
+	var raw []byte
+	err := r.queries.GetMessageRaw.GetContext(ctx, &raw, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return raw, nil
+}
+
 func (r *repository) ListMessagesByInbox(ctx context.Context, inboxID, limit, offset int) ([]*models.Message, int, error) {
 	var total int
 	err := r.queries.CountMessagesByInbox.GetContext(ctx, &total, inboxID)
@@ -41,6 +96,85 @@ func (r *repository) ListMessagesByInbox(ctx context.Context, inboxID, limit, of
 	return messages, total, nil
 }
 
+// GetLastMessageID returns the ID of the message most recently stored in an
+// inbox, 0 when the inbox is empty
+func (r *repository) GetLastMessageID(ctx context.Context, inboxID int) (int, error) {
+	var id int
+	err := r.queries.GetLastMessageID.GetContext(ctx, &id, inboxID)
+	if err != nil {
+		return 0, handleDBError(err)
+	}
+	return id, nil
+}
+
+// ListMessagesByFolder returns a page of the messages of a folder, a null
+// folderID selects the messages at the top level of the inbox
+func (r *repository) ListMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int, limit, offset int) ([]*models.Message, int, error) {
+	var total int
+	err := r.queries.CountMessagesByFolder.GetContext(ctx, &total, inboxID, folderID)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	messages := []*models.Message{}
+
+	if total > 0 {
+		err = r.queries.ListMessagesByFolder.SelectContext(ctx, &messages, inboxID, folderID, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return messages, total, nil
+}
+
+// ListMessageFlagsByFolder returns every message of a folder ordered by ID,
+// without pagination and with only the ID, flags, size and date loaded. A
+// null folderID selects the messages at the top level of the inbox. Used by
+// the IMAP backend to build mailbox views.
+func (r *repository) ListMessageFlagsByFolder(ctx context.Context, inboxID int, folderID null.Int) ([]*models.Message, error) {
+	messages := []*models.Message{}
+	err := r.queries.ListMessageFlagsByFolder.SelectContext(ctx, &messages, inboxID, folderID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return messages, nil
+}
+
+// GetFolderStatus returns the counters of a folder reported by IMAP STATUS
+// and SELECT, a null folderID selects the top level of the inbox
+func (r *repository) GetFolderStatus(ctx context.Context, inboxID int, folderID null.Int) (*models.FolderStatus, error) {
+	var status models.FolderStatus
+	err := r.queries.GetFolderStatus.GetContext(ctx, &status, inboxID, folderID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &status, nil
+}
+
+// CountMessagesByFolder returns the number of messages of a folder, a null
+// folderID counts the messages at the top level of the inbox
+func (r *repository) CountMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int) (int, error) {
+	var total int
+	err := r.queries.CountMessagesByFolder.GetContext(ctx, &total, inboxID, folderID)
+	if err != nil {
+		return 0, handleDBError(err)
+	}
+	return total, nil
+}
+
+// CountMessagesBelow returns for each of ids the number of messages of a
+// folder with a lower ID, which is the position of a message in the IMAP
+// view of the folder
+func (r *repository) CountMessagesBelow(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	counts := []int{}
+	err := r.queries.CountMessagesBelow.SelectContext(ctx, &counts, inboxID, folderID, int64Array(ids))
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return counts, nil
+}
+
 func (r *repository) UpdateMessageReadStatus(ctx context.Context, messageID int, isRead bool) error {
 	result, err := r.queries.UpdateMessageReadStatus.ExecContext(ctx, isRead, messageID)
 	if err != nil {
@@ -49,6 +183,100 @@ func (r *repository) UpdateMessageReadStatus(ctx context.Context, messageID int,
 	return handleRowsAffected(result)
 }
 
+// UpdateMessageFlags persists the read, flagged, answered and deleted state
+// and the keywords of a message
+func (r *repository) UpdateMessageFlags(ctx context.Context, message *models.Message) error {
+	keywords := message.Keywords
+	if keywords == nil {
+		keywords = pq.StringArray{}
+	}
+
+	result, err := r.queries.UpdateMessageFlags.ExecContext(ctx,
+		message.IsRead, message.IsFlagged, message.IsAnswered, message.IsDeleted, keywords, message.ID)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// ExpungeMessages permanently removes the messages of a folder flagged as
+// deleted and returns their IDs. When ids is not nil only those messages are
+// considered.
+func (r *repository) ExpungeMessages(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error) {
+	var filter pq.Int64Array
+	if ids != nil {
+		filter = int64Array(ids)
+	}
+
+	expunged := []int{}
+	err := r.queries.ExpungeMessagesByFolder.SelectContext(ctx, &expunged, inboxID, folderID, filter)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return expunged, nil
+}
+
+// CopyMessages copies messages, with their flags and attachments, into a
+// folder of an inbox and returns the IDs of the copies in the order of ids
+func (r *repository) CopyMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	copies, err := r.copyMessagesTx(ctx, tx, ids, inboxID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, handleDBError(tx.Commit())
+}
+
+// MoveMessages moves messages into a folder of an inbox. The messages are
+// copied and the originals removed, so the moved messages get new IDs that
+// are higher than any other in the destination, as IMAP requires for UIDs.
+// The IDs of the moved messages are returned in the order of ids.
+func (r *repository) MoveMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	copies, err := r.copyMessagesTx(ctx, tx, ids, inboxID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteMessage := tx.StmtxContext(ctx, r.queries.DeleteMessage)
+	for _, id := range ids {
+		if _, err := deleteMessage.ExecContext(ctx, id); err != nil {
+			return nil, handleDBError(err)
+		}
+	}
+
+	return copies, handleDBError(tx.Commit())
+}
+
+func (r *repository) copyMessagesTx(ctx context.Context, tx *sqlx.Tx, ids []int, inboxID int, folderID null.Int) ([]int, error) {
+	copyMessage := tx.StmtxContext(ctx, r.queries.CopyMessage)
+	copyAttachments := tx.StmtxContext(ctx, r.queries.CopyAttachments)
+
+	copies := make([]int, 0, len(ids))
+	for _, id := range ids {
+		var copyID int
+		if err := copyMessage.QueryRowxContext(ctx, id, inboxID, folderID).Scan(&copyID); err != nil {
+			return nil, handleDBError(err)
+		}
+		if _, err := copyAttachments.ExecContext(ctx, id, copyID); err != nil {
+			return nil, handleDBError(err)
+		}
+		copies = append(copies, copyID)
+	}
+	return copies, nil
+}
+
 func (r *repository) DeleteMessage(ctx context.Context, messageID int) error {
 	result, err := r.queries.DeleteMessage.ExecContext(ctx, messageID)
 	if err != nil {
@@ -57,7 +285,15 @@ func (r *repository) DeleteMessage(ctx context.Context, messageID int) error {
 	return handleRowsAffected(result)
 }
 
-func (r *repository) ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, limit, offset int) ([]*models.Message, int, error) {
+// ListMessagesByInboxWithFilter returns a page of the messages of an inbox,
+// optionally only the read or unread ones. A non-empty receiver only keeps
+// the messages whose original recipient matches it, case-insensitively, with
+// * standing for any characters.
+func (r *repository) ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, receiver string, limit, offset int) ([]*models.Message, int, error) {
+	if receiver != "" {
+		return r.listMessagesByReceiver(ctx, inboxID, isRead, receiver, limit, offset)
+	}
+
 	var total int
 	var err error
 
@@ -85,3 +321,39 @@ func (r *repository) ListMessagesByInboxWithFilter(ctx context.Context, inboxID
 
 	return messages, total, nil
 }
+
+func (r *repository) listMessagesByReceiver(ctx context.Context, inboxID int, isRead *bool, receiver string, limit, offset int) ([]*models.Message, int, error) {
+	pattern := likePattern(strings.ToLower(receiver))
+
+	var total int
+	err := r.queries.CountMessagesByInboxWithReceiverFilter.GetContext(ctx, &total, inboxID, isRead, pattern)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	messages := []*models.Message{}
+
+	if total > 0 {
+		err = r.queries.ListMessagesByInboxWithReceiverFilter.SelectContext(ctx, &messages,
+			inboxID, isRead, pattern, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return messages, total, nil
+}
+
+// likePattern turns a pattern using * wildcards into a LIKE pattern, the LIKE
+// metacharacters of the pattern are matched literally
+func likePattern(pattern string) string {
+	return strings.ReplaceAll(escapeLike(pattern), "*", "%")
+}
+
+func int64Array(ids []int) pq.Int64Array {
+	array := make(pq.Int64Array, len(ids))
+	for i, id := range ids {
+		array[i] = int64(id)
+	}
+	return array
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	null "github.com/volatiletech/null/v9"
@@ -21,14 +22,28 @@ func setupMessageTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
-	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id")                              // ListMessagesByInbox
-	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id")                         // CountMessagesByInbox
-	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE id")                                    // GetMessage
-	mock.ExpectPrepare("INSERT INTO messages")                                                  // CreateMessage
-	mock.ExpectPrepare("UPDATE messages")                                                       // UpdateMessageReadStatus
-	mock.ExpectPrepare("DELETE FROM messages")                                                  // DeleteMessage
-	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id = \\? AND is_read = \\?")      // ListMessagesWithFilter
-	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND is_read = \\?") // CountMessagesWithFilter
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id")                                // ListMessagesByInbox
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id")                           // CountMessagesByInbox
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE id")                                      // GetMessage
+	mock.ExpectPrepare("INSERT INTO messages")                                                    // CreateMessage
+	mock.ExpectPrepare("UPDATE messages")                                                         // UpdateMessageReadStatus
+	mock.ExpectPrepare("DELETE FROM messages")                                                    // DeleteMessage
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id = \\? AND is_read = \\?")        // ListMessagesWithFilter
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND is_read = \\?")   // CountMessagesWithFilter
+	mock.ExpectPrepare("SELECT raw FROM messages WHERE id")                                       // GetMessageRaw
+	mock.ExpectPrepare("INSERT INTO attachments")                                                 // CreateAttachment
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id")            // ListMessageFlagsByFolder
+	mock.ExpectPrepare("UPDATE messages SET is_read = \\?, is_flagged")                           // UpdateMessageFlags
+	mock.ExpectPrepare("DELETE FROM messages WHERE inbox_id")                                     // ExpungeMessagesByFolder
+	mock.ExpectPrepare("INSERT INTO messages (.+) SELECT")                                        // CopyMessage
+	mock.ExpectPrepare("INSERT INTO attachments (.+) SELECT")                                     // CopyAttachments
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id (.+) LIMIT") // ListMessagesByFolder
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND folder_id")       // CountMessagesByFolder
+	mock.ExpectPrepare("SELECT (.+) FROM messages WHERE inbox_id = \\? AND (.+) LIKE")            // ListMessagesWithReceiverFilter
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND (.+) LIKE")       // CountMessagesWithReceiverFilter
+	mock.ExpectPrepare("SELECT COALESCE(.+) FROM messages WHERE inbox_id")                        // GetLastMessageID
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM unnest")                                            // CountMessagesBelow
+	mock.ExpectPrepare("SELECT COUNT(.+) AS messages")                                            // GetFolderStatus
 
 	listMessages, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at FROM messages WHERE inbox_id = ? LIMIT ? OFFSET ?")
 	require.NoError(t, err)
@@ -39,7 +54,7 @@ func setupMessageTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	getMessage, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at FROM messages WHERE id = ?")
 	require.NoError(t, err)
 
-	createMessage, err := sqlxDB.Preparex("INSERT INTO messages (inbox_id, sender, receiver, subject, body) VALUES (?, ?, ?, ?, ?)")
+	createMessage, err := sqlxDB.Preparex("INSERT INTO messages (inbox_id, folder_id, sender, receiver, subject, body, html_body, raw, size) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	require.NoError(t, err)
 
 	updateMessageReadStatus, err := sqlxDB.Preparex("UPDATE messages SET is_read = ? WHERE id = ?")
@@ -54,15 +69,71 @@ func setupMessageTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	countMessagesWithFilter, err := sqlxDB.Preparex("SELECT COUNT(*) FROM messages WHERE inbox_id = ? AND is_read = ?")
 	require.NoError(t, err)
 
+	getMessageRaw, err := sqlxDB.Preparex("SELECT raw FROM messages WHERE id = ?")
+	require.NoError(t, err)
+
+	createAttachment, err := sqlxDB.Preparex("INSERT INTO attachments (message_id, filename, content_type, content_id, size, content) VALUES (?, ?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	listMessageFlags, err := sqlxDB.Preparex("SELECT id, inbox_id, folder_id, is_read, is_flagged, is_answered, is_deleted, keywords, size, created_at FROM messages WHERE inbox_id = ? AND folder_id IS NOT DISTINCT FROM ? ORDER BY id")
+	require.NoError(t, err)
+
+	updateMessageFlags, err := sqlxDB.Preparex("UPDATE messages SET is_read = ?, is_flagged = ?, is_answered = ?, is_deleted = ?, keywords = ? WHERE id = ?")
+	require.NoError(t, err)
+
+	expungeMessages, err := sqlxDB.Preparex("DELETE FROM messages WHERE inbox_id = ? AND folder_id IS NOT DISTINCT FROM ? AND is_deleted AND (?::integer[] IS NULL OR id = ANY(?)) RETURNING id")
+	require.NoError(t, err)
+
+	copyMessage, err := sqlxDB.Preparex("INSERT INTO messages (inbox_id, folder_id, sender) SELECT ?, ?, sender FROM messages WHERE id = ? RETURNING id")
+	require.NoError(t, err)
+
+	copyAttachments, err := sqlxDB.Preparex("INSERT INTO attachments (message_id, filename) SELECT ?, filename FROM attachments WHERE message_id = ?")
+	require.NoError(t, err)
+
+	listMessagesByFolder, err := sqlxDB.Preparex("SELECT id, inbox_id, folder_id, sender, receiver, subject, body, is_read, created_at, updated_at FROM messages WHERE inbox_id = ? AND folder_id IS NOT DISTINCT FROM ? ORDER BY id LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	countMessagesByFolder, err := sqlxDB.Preparex("SELECT COUNT(*) FROM messages WHERE inbox_id = ? AND folder_id IS NOT DISTINCT FROM ?")
+	require.NoError(t, err)
+
+	listMessagesWithReceiver, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at FROM messages WHERE inbox_id = ? AND (?::boolean IS NULL OR is_read = ?) AND lower(receiver) LIKE ? LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	countMessagesWithReceiver, err := sqlxDB.Preparex("SELECT COUNT(*) FROM messages WHERE inbox_id = ? AND (?::boolean IS NULL OR is_read = ?) AND lower(receiver) LIKE ?")
+	require.NoError(t, err)
+
+	getLastMessageID, err := sqlxDB.Preparex("SELECT COALESCE(MAX(id), 0) FROM messages WHERE inbox_id = ?")
+	require.NoError(t, err)
+
+	countMessagesBelow, err := sqlxDB.Preparex("SELECT COUNT(m.id) FROM unnest(?::integer[]) WITH ORDINALITY AS below(id, ord) LEFT JOIN messages m ON m.inbox_id = ? AND m.folder_id IS NOT DISTINCT FROM ? AND m.id < below.id GROUP BY below.ord ORDER BY below.ord")
+	require.NoError(t, err)
+
+	getFolderStatus, err := sqlxDB.Preparex("SELECT COUNT(*) AS messages, COUNT(*) FILTER (WHERE NOT is_read) AS unseen, 0 AS first_unseen, 1 AS uid_next FROM messages WHERE inbox_id = ? AND folder_id IS NOT DISTINCT FROM ?")
+	require.NoError(t, err)
+
 	queries := &Queries{
-		ListMessagesByInbox:                listMessages,
-		CountMessagesByInbox:               countMessages,
-		GetMessage:                         getMessage,
-		CreateMessage:                      createMessage,
-		UpdateMessageReadStatus:            updateMessageReadStatus,
-		DeleteMessage:                      deleteMessage,
-		ListMessagesByInboxWithReadFilter:  listMessagesWithFilter,
-		CountMessagesByInboxWithReadFilter: countMessagesWithFilter,
+		ListMessagesByInbox:                    listMessages,
+		CountMessagesByInbox:                   countMessages,
+		GetMessage:                             getMessage,
+		CreateMessage:                          createMessage,
+		UpdateMessageReadStatus:                updateMessageReadStatus,
+		DeleteMessage:                          deleteMessage,
+		ListMessagesByInboxWithReadFilter:      listMessagesWithFilter,
+		CountMessagesByInboxWithReadFilter:     countMessagesWithFilter,
+		GetMessageRaw:                          getMessageRaw,
+		CreateAttachment:                       createAttachment,
+		ListMessageFlagsByFolder:               listMessageFlags,
+		GetFolderStatus:                        getFolderStatus,
+		UpdateMessageFlags:                     updateMessageFlags,
+		ExpungeMessagesByFolder:                expungeMessages,
+		CopyMessage:                            copyMessage,
+		CopyAttachments:                        copyAttachments,
+		ListMessagesByFolder:                   listMessagesByFolder,
+		CountMessagesByFolder:                  countMessagesByFolder,
+		ListMessagesByInboxWithReceiverFilter:  listMessagesWithReceiver,
+		CountMessagesByInboxWithReceiverFilter: countMessagesWithReceiver,
+		GetLastMessageID:                       getLastMessageID,
+		CountMessagesBelow:                     countMessagesBelow,
 	}
 
 	repo := &repository{
@@ -90,23 +161,88 @@ func TestRepository_CreateMessage(t *testing.T) {
 				Receiver: "receiver@example.com",
 				Subject:  "Test Subject",
 				Body:     "Test Body",
+				Raw:      []byte("Subject: Test Subject\r\n\r\nTest Body"),
+				Size:     37,
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO messages").
 					WithArgs(
 						1,
+						null.Int{},
 						"sender@example.com",
 						"receiver@example.com",
 						"Test Subject",
 						"Test Body",
+						"",
+						[]byte("Subject: Test Subject\r\n\r\nTest Body"),
+						37,
 					).
 					WillReturnRows(
 						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 							AddRow(1, now, now),
 					)
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "creation with attachments",
+			message: &models.Message{
+				InboxID:  1,
+				Sender:   "sender@example.com",
+				Receiver: "receiver@example.com",
+				Subject:  "Test Subject",
+				Body:     "Test Body",
+				HTMLBody: "<p>Test Body</p>",
+				Attachments: []*models.Attachment{
+					{Filename: "report.pdf", ContentType: "application/pdf", Size: 3, Content: []byte("PDF")},
+				},
+			},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WithArgs(1, null.Int{}, "sender@example.com", "receiver@example.com", "Test Subject", "Test Body",
+						"<p>Test Body</p>", []byte(nil), 0).
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+							AddRow(1, now, now),
+					)
+				mock.ExpectQuery("INSERT INTO attachments").
+					WithArgs(1, "report.pdf", "application/pdf", "", 3, []byte("PDF")).
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+							AddRow(5, now, now),
+					)
+				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
+		{
+			name: "attachment error rolls back",
+			message: &models.Message{
+				InboxID:  1,
+				Sender:   "sender@example.com",
+				Receiver: "receiver@example.com",
+				Subject:  "Test Subject",
+				Body:     "Test Body",
+				Attachments: []*models.Attachment{
+					{Filename: "report.pdf", ContentType: "application/pdf", Size: 3, Content: []byte("PDF")},
+				},
+			},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WillReturnRows(
+						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+							AddRow(1, now, now),
+					)
+				mock.ExpectQuery("INSERT INTO attachments").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
 		{
 			name: "database error",
 			message: &models.Message{
@@ -117,15 +253,21 @@ func TestRepository_CreateMessage(t *testing.T) {
 				Body:     "Test Body",
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
 				mock.ExpectQuery("INSERT INTO messages").
 					WithArgs(
 						1,
+						null.Int{},
 						"sender@example.com",
 						"receiver@example.com",
 						"Test Subject",
 						"Test Body",
+						"",
+						[]byte(nil),
+						0,
 					).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -141,6 +283,7 @@ func TestRepository_CreateMessage(t *testing.T) {
 			err := repo.CreateMessage(context.Background(), tt.message)
 			if tt.wantErr {
 				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
 				return
 			}
 
@@ -148,6 +291,10 @@ func TestRepository_CreateMessage(t *testing.T) {
 			assert.NotZero(t, tt.message.ID)
 			assert.NotZero(t, tt.message.CreatedAt)
 			assert.NotZero(t, tt.message.UpdatedAt)
+			for _, attachment := range tt.message.Attachments {
+				assert.Equal(t, tt.message.ID, attachment.MessageID)
+				assert.NotZero(t, attachment.ID)
+			}
 
 			err = mock.ExpectationsWereMet()
 			assert.NoError(t, err)
@@ -155,6 +302,72 @@ func TestRepository_CreateMessage(t *testing.T) {
 	}
 }
 
+func TestRepository_CreateMessages(t *testing.T) {
+	now := time.Now()
+
+	newMessages := func() []*models.Message {
+		return []*models.Message{
+			{InboxID: 1, Sender: "sender@example.com", Receiver: "one@example.com", Subject: "Test Subject", Body: "Test Body"},
+			{InboxID: 2, FolderID: null.IntFrom(4), Sender: "sender@example.com", Receiver: "two@example.com", Subject: "Test Subject", Body: "Test Body"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "stores every copy in one transaction",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WithArgs(1, null.Int{}, "sender@example.com", "one@example.com", "Test Subject", "Test Body", "", []byte(nil), 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+				mock.ExpectQuery("INSERT INTO messages").
+					WithArgs(2, null.IntFrom(4), "sender@example.com", "two@example.com", "Test Subject", "Test Body", "", []byte(nil), 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(2, now, now))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "failure on a later copy rolls back all",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+				mock.ExpectQuery("INSERT INTO messages").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			messages := newMessages()
+			err := repo.CreateMessages(context.Background(), messages)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, messages[0].ID)
+			assert.Equal(t, 2, messages[1].ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestRepository_GetMessage(t *testing.T) {
 	now := time.Now()
 
@@ -355,15 +568,16 @@ func TestRepository_ListMessagesByInboxWithFilter(t *testing.T) {
 	isRead := true
 
 	tests := []struct {
-		name    string
-		inboxID int
-		isRead  *bool
-		limit   int
-		offset  int
-		mockFn  func(sqlmock.Sqlmock)
-		want    []*models.Message
-		total   int
-		wantErr bool
+		name     string
+		inboxID  int
+		isRead   *bool
+		receiver string
+		limit    int
+		offset   int
+		mockFn   func(sqlmock.Sqlmock)
+		want     []*models.Message
+		total    int
+		wantErr  bool
 	}{
 		{
 			name:    "list with read filter",
@@ -479,6 +693,48 @@ func TestRepository_ListMessagesByInboxWithFilter(t *testing.T) {
 			total:   0,
 			wantErr: false,
 		},
+		{
+			name:     "list with receiver filter",
+			inboxID:  1,
+			isRead:   nil,
+			receiver: "*+Run_42@example.com",
+			limit:    10,
+			offset:   0,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+				mock.ExpectQuery("SELECT COUNT(.+) LIKE").
+					WithArgs(1, nil, `%+run\_42@example.com`).
+					WillReturnRows(countRows)
+
+				rows := sqlmock.NewRows([]string{
+					"id", "inbox_id", "sender", "receiver", "subject",
+					"body", "is_read", "created_at", "updated_at",
+				}).AddRow(
+					1, 1, "sender@example.com", "qa+run_42@example.com",
+					"Test Subject", "Test Body", false, now, now,
+				)
+
+				mock.ExpectQuery("SELECT (.+) LIKE").
+					WithArgs(1, nil, `%+run\_42@example.com`, 10, 0).
+					WillReturnRows(rows)
+			},
+			want: []*models.Message{
+				{
+					Base: models.Base{
+						ID:        1,
+						CreatedAt: null.TimeFrom(now),
+						UpdatedAt: null.TimeFrom(now),
+					},
+					InboxID:  1,
+					Sender:   "sender@example.com",
+					Receiver: "qa+run_42@example.com",
+					Subject:  "Test Subject",
+					Body:     "Test Body",
+				},
+			},
+			total:   1,
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -488,7 +744,7 @@ func TestRepository_ListMessagesByInboxWithFilter(t *testing.T) {
 
 			tt.mockFn(mock)
 
-			got, total, err := repo.ListMessagesByInboxWithFilter(context.Background(), tt.inboxID, tt.isRead, tt.limit, tt.offset)
+			got, total, err := repo.ListMessagesByInboxWithFilter(context.Background(), tt.inboxID, tt.isRead, tt.receiver, tt.limit, tt.offset)
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -708,3 +964,520 @@ func TestRepository_ListMessagesByInbox(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_GetMessageRaw(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		mockFn  func(sqlmock.Sqlmock)
+		want    []byte
+		wantErr bool
+		errType error
+	}{
+		{
+			name: "stored source",
+			id:   1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT raw FROM messages").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"raw"}).AddRow([]byte("Subject: Hi\r\n\r\nBody")))
+			},
+			want: []byte("Subject: Hi\r\n\r\nBody"),
+		},
+		{
+			name: "message stored without source",
+			id:   2,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT raw FROM messages").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"raw"}).AddRow(nil))
+			},
+			want: nil,
+		},
+		{
+			name: "non-existent message",
+			id:   999,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT raw FROM messages").
+					WithArgs(999).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+			errType: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetMessageRaw(context.Background(), tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != nil {
+					assert.ErrorIs(t, err, tt.errType)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRepository_ListMessagesByFolder(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		mockFn    func(sqlmock.Sqlmock)
+		wantCount int
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name: "messages found",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WithArgs(1, null.IntFrom(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id (.+) LIMIT").
+					WithArgs(1, null.IntFrom(3), 10, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "folder_id", "sender", "receiver", "subject", "body", "is_read", "created_at", "updated_at"}).
+						AddRow(4, 1, 3, "sender@example.com", "inbox@example.com", "Filed", "Body", true, now, now))
+			},
+			wantCount: 1,
+			wantTotal: 1,
+			wantErr:   false,
+		},
+		{
+			name: "empty folder",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WithArgs(1, null.IntFrom(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			wantCount: 0,
+			wantTotal: 0,
+			wantErr:   false,
+		},
+		{
+			name: "count error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, total, err := repo.ListMessagesByFolder(context.Background(), 1, null.IntFrom(3), 10, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, got, tt.wantCount)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ListMessageFlagsByFolder(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		folderID null.Int
+		mockFn   func(sqlmock.Sqlmock)
+		want     int
+		wantErr  bool
+	}{
+		{
+			name:     "top level of the inbox",
+			folderID: null.Int{},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "inbox_id", "folder_id", "is_read", "is_flagged", "is_answered", "is_deleted", "keywords", "size", "created_at"}).
+					AddRow(1, 1, nil, false, false, false, false, "{}", 120, now).
+					AddRow(2, 1, nil, true, true, false, false, "{$Important}", 240, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WithArgs(1, null.Int{}).
+					WillReturnRows(rows)
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:     "folder",
+			folderID: null.IntFrom(3),
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "inbox_id", "folder_id", "is_read", "is_flagged", "is_answered", "is_deleted", "keywords", "size", "created_at"}).
+					AddRow(4, 1, 3, true, false, true, false, "{}", 360, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WithArgs(1, null.IntFrom(3)).
+					WillReturnRows(rows)
+			},
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:     "database error",
+			folderID: null.Int{},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.ListMessageFlagsByFolder(context.Background(), 1, tt.folderID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, got, tt.want)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRepository_UpdateMessageFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *models.Message
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+		errType error
+	}{
+		{
+			name: "successful update",
+			message: &models.Message{
+				Base:      models.Base{ID: 1},
+				IsRead:    true,
+				IsFlagged: true,
+				IsDeleted: true,
+				Keywords:  pq.StringArray{"$Important"},
+			},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE messages SET is_read = \\?, is_flagged").
+					WithArgs(true, true, false, true, pq.StringArray{"$Important"}, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name:    "nil keywords are stored as an empty array",
+			message: &models.Message{Base: models.Base{ID: 1}},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE messages SET is_read = \\?, is_flagged").
+					WithArgs(false, false, false, false, pq.StringArray{}, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name:    "non-existent message",
+			message: &models.Message{Base: models.Base{ID: 999}},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE messages SET is_read = \\?, is_flagged").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+			errType: ErrNoRowsAffected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.UpdateMessageFlags(context.Background(), tt.message)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != nil {
+					assert.ErrorIs(t, err, tt.errType)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ExpungeMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []int
+		mockFn  func(sqlmock.Sqlmock)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "expunge every deleted message",
+			ids:  nil,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM messages WHERE inbox_id").
+					WithArgs(1, null.Int{}, pq.Int64Array(nil)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(5))
+			},
+			want:    []int{2, 5},
+			wantErr: false,
+		},
+		{
+			name: "expunge a subset",
+			ids:  []int{5},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM messages WHERE inbox_id").
+					WithArgs(1, null.Int{}, pq.Int64Array{5}).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+			},
+			want:    []int{5},
+			wantErr: false,
+		},
+		{
+			name: "database error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM messages WHERE inbox_id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.ExpungeMessages(context.Background(), 1, null.Int{}, tt.ids)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_CopyMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "copies messages and attachments",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WithArgs(2, 1, null.IntFrom(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec("INSERT INTO attachments (.+) SELECT").
+					WithArgs(2, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WithArgs(5, 1, null.IntFrom(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+				mock.ExpectExec("INSERT INTO attachments (.+) SELECT").
+					WithArgs(5, 11).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			want:    []int{10, 11},
+			wantErr: false,
+		},
+		{
+			name: "missing message rolls back",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec("INSERT INTO attachments (.+) SELECT").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.CopyMessages(context.Background(), []int{2, 5}, 1, null.IntFrom(3))
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_MoveMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "copies then removes the originals",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WithArgs(2, 1, null.Int{}).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec("INSERT INTO attachments (.+) SELECT").
+					WithArgs(2, 10).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM messages WHERE id").
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want:    []int{10},
+			wantErr: false,
+		},
+		{
+			name: "delete error rolls back",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages (.+) SELECT").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec("INSERT INTO attachments (.+) SELECT").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM messages WHERE id").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupMessageTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.MoveMessages(context.Background(), []int{2}, 1, null.Int{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetLastMessageID(t *testing.T) {
+	repo, mock := setupMessageTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COALESCE(.+) FROM messages WHERE inbox_id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+
+	id, err := repo.GetLastMessageID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CountMessagesByFolder(t *testing.T) {
+	repo, mock := setupMessageTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM messages WHERE inbox_id = \\? AND folder_id").
+		WithArgs(1, null.IntFrom(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	total, err := repo.CountMessagesByFolder(context.Background(), 1, null.IntFrom(4))
+	require.NoError(t, err)
+	assert.Equal(t, 12, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetFolderStatus(t *testing.T) {
+	repo, mock := setupMessageTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) AS messages").
+		WithArgs(1, null.IntFrom(4)).
+		WillReturnRows(sqlmock.NewRows([]string{"messages", "unseen", "first_unseen", "uid_next"}).AddRow(12, 3, 7, 58))
+
+	status, err := repo.GetFolderStatus(context.Background(), 1, null.IntFrom(4))
+	require.NoError(t, err)
+	assert.Equal(t, &models.FolderStatus{Messages: 12, Unseen: 3, FirstUnseen: 7, UIDNext: 58}, status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CountMessagesBelow(t *testing.T) {
+	repo, mock := setupMessageTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM unnest").
+		WithArgs(1, null.Int{}, pq.Int64Array{5, 9}).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2).AddRow(3))
+
+	counts, err := repo.CountMessagesBelow(context.Background(), 1, null.Int{}, []int{5, 9})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
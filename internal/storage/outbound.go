@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"inbox451/internal/models"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+// QueueOutboundMessage files the sent copy of a message in its folder, marked
+// as read, and queues the message for the relay. Both happen in a single
+// transaction, so no message is sent without a copy or the other way round.
+// A nil sent only queues the message, as for forwarded messages.
+func (r *repository) QueueOutboundMessage(ctx context.Context, outbound *models.OutboundMessage, sent *models.Message) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return handleDBError(err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback() }()
+
+	if sent != nil {
+		if err := r.createMessageTx(ctx, tx, sent); err != nil {
+			return err
+		}
+		if _, err := tx.StmtxContext(ctx, r.queries.UpdateMessageReadStatus).ExecContext(ctx, true, sent.ID); err != nil {
+			return handleDBError(err)
+		}
+		sent.IsRead = true
+		outbound.MessageID = null.IntFrom(sent.ID)
+	}
+
+	err = tx.StmtxContext(ctx, r.queries.CreateOutboundMessage).QueryRowxContext(ctx,
+		outbound.InboxID, outbound.MessageID, outbound.Sender, outbound.Recipients, outbound.Raw).
+		Scan(&outbound.ID, &outbound.Status, &outbound.Attempts, &outbound.NextAttemptAt,
+			&outbound.CreatedAt, &outbound.UpdatedAt)
+	if err != nil {
+		return handleDBError(err)
+	}
+
+	return handleDBError(tx.Commit())
+}
+
+func (r *repository) GetOutboundMessage(ctx context.Context, id int) (*models.OutboundMessage, error) {
+	var outbound models.OutboundMessage
+	err := r.queries.GetOutboundMessage.GetContext(ctx, &outbound, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &outbound, nil
+}
+
+func (r *repository) ListOutboundMessagesByInbox(ctx context.Context, inboxID int, status string, limit, offset int) ([]*models.OutboundMessage, int, error) {
+	var total int
+	err := r.queries.CountOutboundMessagesByInbox.GetContext(ctx, &total, inboxID, status)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	messages := []*models.OutboundMessage{}
+	if total > 0 {
+		err = r.queries.ListOutboundMessagesByInbox.SelectContext(ctx, &messages, inboxID, status, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return messages, total, nil
+}
+
+// ClaimOutboundMessages returns up to limit queued messages that are due and
+// postpones their next attempt by lease, so that no other dispatcher picks
+// them up while they are being sent. A message whose dispatcher stops before
+// recording the outcome is attempted again once the lease expires.
+func (r *repository) ClaimOutboundMessages(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboundMessage, error) {
+	messages := []*models.OutboundMessage{}
+	err := r.queries.ClaimOutboundMessages.SelectContext(ctx, &messages, limit, lease.Seconds())
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return messages, nil
+}
+
+// UpdateOutboundMessage records the outcome of an attempt
+func (r *repository) UpdateOutboundMessage(ctx context.Context, outbound *models.OutboundMessage) error {
+	err := r.queries.UpdateOutboundMessage.QueryRowContext(ctx,
+		outbound.Status, outbound.Attempts, outbound.NextAttemptAt, outbound.LastError, outbound.SentAt, outbound.ID).
+		Scan(&outbound.UpdatedAt)
+	return handleDBError(err)
+}
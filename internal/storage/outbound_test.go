@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+var outboundColumns = []string{"id", "inbox_id", "message_id", "sender", "recipients", "raw", "status", "attempts",
+	"next_attempt_at", "last_error", "sent_at", "created_at", "updated_at"}
+
+func setupOutboundTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("INSERT INTO messages")                                // CreateMessage
+	mock.ExpectPrepare("INSERT INTO attachments")                             // CreateAttachment
+	mock.ExpectPrepare("UPDATE messages SET is_read")                         // UpdateMessageReadStatus
+	mock.ExpectPrepare("INSERT INTO outbound_messages")                       // CreateOutboundMessage
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM outbound_messages")             // CountOutboundMessagesByInbox
+	mock.ExpectPrepare("SELECT (.+) FROM outbound_messages WHERE inbox_id")   // ListOutboundMessagesByInbox
+	mock.ExpectPrepare("UPDATE outbound_messages SET next_attempt_at")        // ClaimOutboundMessages
+	mock.ExpectPrepare("UPDATE outbound_messages SET status = \\?, attempts") // UpdateOutboundMessage
+
+	createMessage, err := sqlxDB.Preparex("INSERT INTO messages (inbox_id, folder_id, sender, receiver, subject, body, html_body, raw, size) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	createAttachment, err := sqlxDB.Preparex("INSERT INTO attachments (message_id, filename, content_type, content_id, size, content) VALUES (?, ?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	updateMessageReadStatus, err := sqlxDB.Preparex("UPDATE messages SET is_read = ? WHERE id = ?")
+	require.NoError(t, err)
+
+	createOutbound, err := sqlxDB.Preparex("INSERT INTO outbound_messages (inbox_id, message_id, sender, recipients, raw) VALUES (?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	countOutbound, err := sqlxDB.Preparex("SELECT COUNT(*) FROM outbound_messages WHERE inbox_id = ? AND (? = '' OR status = ?)")
+	require.NoError(t, err)
+
+	listOutbound, err := sqlxDB.Preparex("SELECT id, inbox_id, message_id, sender, recipients, status, attempts, next_attempt_at, last_error, sent_at, created_at, updated_at FROM outbound_messages WHERE inbox_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	claimOutbound, err := sqlxDB.Preparex("UPDATE outbound_messages SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => ?) WHERE id IN (SELECT id FROM outbound_messages LIMIT ? FOR UPDATE SKIP LOCKED)")
+	require.NoError(t, err)
+
+	updateOutbound, err := sqlxDB.Preparex("UPDATE outbound_messages SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, sent_at = ? WHERE id = ?")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		CreateMessage:                createMessage,
+		CreateAttachment:             createAttachment,
+		UpdateMessageReadStatus:      updateMessageReadStatus,
+		CreateOutboundMessage:        createOutbound,
+		CountOutboundMessagesByInbox: countOutbound,
+		ListOutboundMessagesByInbox:  listOutbound,
+		ClaimOutboundMessages:        claimOutbound,
+		UpdateOutboundMessage:        updateOutbound,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+func TestRepository_QueueOutboundMessage(t *testing.T) {
+	now := time.Now()
+	raw := []byte("Subject: Hi\r\n\r\nHello")
+
+	newMessages := func() (*models.OutboundMessage, *models.Message) {
+		outbound := &models.OutboundMessage{
+			InboxID:    1,
+			Sender:     "qa@example.com",
+			Recipients: pq.StringArray{"dev@example.com"},
+			Raw:        raw,
+		}
+		sent := &models.Message{
+			InboxID:  1,
+			FolderID: null.IntFrom(4),
+			Sender:   "qa@example.com",
+			Receiver: "dev@example.com",
+			Subject:  "Hi",
+			Body:     "Hello",
+			Raw:      raw,
+			Size:     len(raw),
+		}
+		return outbound, sent
+	}
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "files the copy and queues the message",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WithArgs(1, null.IntFrom(4), "qa@example.com", "dev@example.com", "Hi", "Hello", "", raw, len(raw)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(7, now, now))
+				mock.ExpectExec("UPDATE messages SET is_read").
+					WithArgs(true, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO outbound_messages").
+					WithArgs(1, null.IntFrom(7), "qa@example.com", pq.StringArray{"dev@example.com"}, raw).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "status", "attempts", "next_attempt_at", "created_at", "updated_at"}).
+						AddRow(3, "queued", 0, now, now, now))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "failure to queue drops the copy",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("INSERT INTO messages").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(7, now, now))
+				mock.ExpectExec("UPDATE messages SET is_read").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("INSERT INTO outbound_messages").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupOutboundTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			outbound, sent := newMessages()
+			err := repo.QueueOutboundMessage(context.Background(), outbound, sent)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.NoError(t, mock.ExpectationsWereMet())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 3, outbound.ID)
+			assert.Equal(t, null.IntFrom(7), outbound.MessageID)
+			assert.Equal(t, models.OutboundQueued, outbound.Status)
+			assert.True(t, sent.IsRead)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("queues the message without a copy", func(t *testing.T) {
+		repo, mock := setupOutboundTestDB(t)
+		defer repo.db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO outbound_messages").
+			WithArgs(1, null.Int{}, "qa@example.com", pq.StringArray{"dev@example.com"}, raw).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "attempts", "next_attempt_at", "created_at", "updated_at"}).
+				AddRow(3, "queued", 0, now, now, now))
+		mock.ExpectCommit()
+
+		outbound, _ := newMessages()
+		require.NoError(t, repo.QueueOutboundMessage(context.Background(), outbound, nil))
+		assert.Equal(t, 3, outbound.ID)
+		assert.False(t, outbound.MessageID.Valid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ListOutboundMessagesByInbox(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupOutboundTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM outbound_messages").
+		WithArgs(1, "failed").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT (.+) FROM outbound_messages WHERE inbox_id").
+		WithArgs(1, "failed", 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "message_id", "sender", "recipients", "status", "attempts",
+			"next_attempt_at", "last_error", "sent_at", "created_at", "updated_at"}).
+			AddRow(3, 1, 7, "qa@example.com", "{dev@example.com,ops@example.com}", "failed", 1, nil, "550 no such user", nil, now, now))
+
+	messages, total, err := repo.ListOutboundMessagesByInbox(context.Background(), 1, "failed", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, pq.StringArray{"dev@example.com", "ops@example.com"}, messages[0].Recipients)
+	assert.Equal(t, null.StringFrom("550 no such user"), messages[0].LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimOutboundMessages(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupOutboundTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("UPDATE outbound_messages SET next_attempt_at").
+		WithArgs(10, float64(90)).
+		WillReturnRows(sqlmock.NewRows(outboundColumns).
+			AddRow(3, 1, 7, "qa@example.com", "{dev@example.com}", []byte("raw"), "queued", 2, now, "timeout", nil, now, now))
+
+	messages, err := repo.ClaimOutboundMessages(context.Background(), 10, 90*time.Second)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, []byte("raw"), messages[0].Raw)
+	assert.Equal(t, 2, messages[0].Attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UpdateOutboundMessage(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupOutboundTestDB(t)
+	defer repo.db.Close()
+
+	outbound := &models.OutboundMessage{
+		Base:     models.Base{ID: 3},
+		Status:   models.OutboundSent,
+		Attempts: 1,
+		SentAt:   null.TimeFrom(now),
+	}
+
+	mock.ExpectQuery("UPDATE outbound_messages SET status").
+		WithArgs("sent", 1, null.Time{}, null.String{}, null.TimeFrom(now), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+	require.NoError(t, repo.UpdateOutboundMessage(context.Background(), outbound))
+	assert.True(t, outbound.UpdatedAt.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -49,13 +49,13 @@ func (r *repository) GetProject(ctx context.Context, id int) (*models.Project, e
 }
 
 func (r *repository) CreateProject(ctx context.Context, project *models.Project) error {
-	err := r.queries.CreateProject.QueryRowContext(ctx, project.Name).
+	err := r.queries.CreateProject.QueryRowContext(ctx, project.Name, project.SMTPRequireAuth).
 		Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
 	return handleDBError(err)
 }
 
 func (r *repository) UpdateProject(ctx context.Context, project *models.Project) error {
-	err := r.queries.UpdateProject.QueryRowContext(ctx, project.Name, project.ID).
+	err := r.queries.UpdateProject.QueryRowContext(ctx, project.Name, project.SMTPRequireAuth, project.ID).
 		Scan(&project.UpdatedAt)
 	return handleDBError(err)
 }
@@ -66,6 +66,16 @@ func (r *repository) ProjectAddUser(ctx context.Context, projectUser *models.Pro
 	return handleDBError(err)
 }
 
+// GetProjectUser returns the membership of a user in a project
+func (r *repository) GetProjectUser(ctx context.Context, projectID int, userID int) (*models.ProjectUser, error) {
+	var projectUser models.ProjectUser
+	err := r.queries.GetProjectUser.GetContext(ctx, &projectUser, projectID, userID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &projectUser, nil
+}
+
 func (r *repository) DeleteProject(ctx context.Context, id int) error {
 	result, err := r.queries.DeleteProject.ExecContext(ctx, id)
 	if err != nil {
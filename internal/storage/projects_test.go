@@ -29,6 +29,7 @@ func setupProjectTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	mock.ExpectPrepare("DELETE FROM projects")                                    // DeleteProject
 	mock.ExpectPrepare("INSERT INTO project_users")                               // AddUserToProject
 	mock.ExpectPrepare("DELETE FROM project_users")                               // RemoveUserFromProject
+	mock.ExpectPrepare("SELECT (.+) FROM project_users WHERE project_id")         // GetProjectUser
 	mock.ExpectPrepare("SELECT (.+) FROM projects INNER JOIN project_users")      // ListProjectsByUser
 	mock.ExpectPrepare("SELECT COUNT(.+) FROM projects INNER JOIN project_users") // CountProjectsByUser
 
@@ -41,19 +42,22 @@ func setupProjectTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	getProject, err := sqlxDB.Preparex("SELECT id, name, created_at, updated_at FROM projects WHERE id = ?")
 	require.NoError(t, err)
 
-	createProject, err := sqlxDB.Preparex("INSERT INTO projects (name) VALUES (?)")
+	createProject, err := sqlxDB.Preparex("INSERT INTO projects (name, smtp_require_auth) VALUES (?, ?)")
 	require.NoError(t, err)
 
-	updateProject, err := sqlxDB.Preparex("UPDATE projects SET name = ? WHERE id = ?")
+	updateProject, err := sqlxDB.Preparex("UPDATE projects SET name = ?, smtp_require_auth = ? WHERE id = ?")
 	require.NoError(t, err)
 
 	deleteProject, err := sqlxDB.Preparex("DELETE FROM projects WHERE id = ?")
 	require.NoError(t, err)
 
-	addUserToProject, err := sqlxDB.Preparex("INSERT INTO project_users (user_id, project_id, role) VALUES (?, ?, ?)")
+	addUserToProject, err := sqlxDB.Preparex("INSERT INTO project_users (project_id, user_id, role) VALUES (?, ?, ?)")
 	require.NoError(t, err)
 
-	removeUserFromProject, err := sqlxDB.Preparex("DELETE FROM project_users WHERE user_id = ? AND project_id = ?")
+	removeUserFromProject, err := sqlxDB.Preparex("DELETE FROM project_users WHERE project_id = ? AND user_id = ?")
+	require.NoError(t, err)
+
+	getProjectUser, err := sqlxDB.Preparex("SELECT id, project_id, user_id, role, created_at, updated_at FROM project_users WHERE project_id = ? AND user_id = ?")
 	require.NoError(t, err)
 
 	listProjectsByUser, err := sqlxDB.Preparex("SELECT projects.id, projects.name, projects.created_at, projects.updated_at FROM projects INNER JOIN project_users ON projects.id = project_users.project_id WHERE project_users.user_id = ? ORDER BY projects.id LIMIT ? OFFSET ?")
@@ -71,6 +75,7 @@ func setupProjectTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 		DeleteProject:         deleteProject,
 		AddUserToProject:      addUserToProject,
 		RemoveUserFromProject: removeUserFromProject,
+		GetProjectUser:        getProjectUser,
 		ListProjectsByUser:    listProjectsByUser,
 		CountProjectsByUser:   countProjectsByUser,
 	}
@@ -99,7 +104,7 @@ func TestRepository_CreateProject(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO projects").
-					WithArgs("Test Project").
+					WithArgs("Test Project", false).
 					WillReturnRows(
 						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 							AddRow(1, now, now),
@@ -114,7 +119,7 @@ func TestRepository_CreateProject(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO projects").
-					WithArgs("Test Project").
+					WithArgs("Test Project", false).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -234,7 +239,7 @@ func TestRepository_UpdateProject(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("UPDATE projects").
-					WithArgs("Updated Project", 1).
+					WithArgs("Updated Project", false, 1).
 					WillReturnRows(
 						sqlmock.NewRows([]string{"updated_at"}).
 							AddRow(now),
@@ -250,7 +255,7 @@ func TestRepository_UpdateProject(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("UPDATE projects").
-					WithArgs("Updated Project", 999).
+					WithArgs("Updated Project", false, 999).
 					WillReturnError(sql.ErrNoRows)
 			},
 			wantErr: true,
@@ -579,6 +584,61 @@ func TestRepository_ProjectAddUser(t *testing.T) {
 	}
 }
 
+func TestRepository_GetProjectUser(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		want    *models.ProjectUser
+		wantErr error
+	}{
+		{
+			name: "member found",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM project_users WHERE project_id").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "user_id", "role", "created_at", "updated_at"}).
+						AddRow(3, 1, 2, "admin", now, now))
+			},
+			want: &models.ProjectUser{
+				Base:      models.Base{ID: 3, CreatedAt: null.TimeFrom(now), UpdatedAt: null.TimeFrom(now)},
+				ProjectID: 1,
+				UserID:    2,
+				Role:      "admin",
+			},
+		},
+		{
+			name: "not a member",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM project_users WHERE project_id").
+					WithArgs(1, 2).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupProjectTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetProjectUser(context.Background(), 1, 2)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestRepository_ProjectRemoveUser(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -26,6 +26,7 @@ type Queries struct {
 	// ProjectUser queries
 	AddUserToProject      *sqlx.Stmt `query:"add-user-to-project"`
 	RemoveUserFromProject *sqlx.Stmt `query:"remove-user-from-project"`
+	GetProjectUser        *sqlx.Stmt `query:"get-project-user"`
 
 	// Inbox queries
 	CreateInbox           *sqlx.Stmt `query:"create-inbox"`
@@ -35,42 +36,130 @@ type Queries struct {
 	ListInboxesByProject  *sqlx.Stmt `query:"list-inboxes-by-project"`
 	CountInboxesByProject *sqlx.Stmt `query:"count-inboxes-by-project"`
 	GetInboxByEmail       *sqlx.Stmt `query:"get-inbox-by-email"`
+	GetInboxByAddress     *sqlx.Stmt `query:"get-inbox-by-address"`
+	ListInboxesByUser     *sqlx.Stmt `query:"list-inboxes-by-user"`
+	GetInboxUsage         *sqlx.Stmt `query:"get-inbox-usage"`
+
+	// Domain queries
+	ListDomainsByProject     *sqlx.Stmt `query:"list-domains-by-project"`
+	CountDomainsByProject    *sqlx.Stmt `query:"count-domains-by-project"`
+	GetDomain                *sqlx.Stmt `query:"get-domain"`
+	CreateDomain             *sqlx.Stmt `query:"create-domain"`
+	VerifyDomain             *sqlx.Stmt `query:"verify-domain"`
+	DeleteDomain             *sqlx.Stmt `query:"delete-domain"`
+	GetVerifiedDomainForHost *sqlx.Stmt `query:"get-verified-domain-for-host"`
 
 	// Rule queries
-	CreateRule        *sqlx.Stmt `query:"create-rule"`
-	GetRule           *sqlx.Stmt `query:"get-rule"`
-	UpdateRule        *sqlx.Stmt `query:"update-rule"`
-	DeleteRule        *sqlx.Stmt `query:"delete-rule"`
-	ListRulesByInbox  *sqlx.Stmt `query:"list-rules-by-inbox"`
-	CountRulesByInbox *sqlx.Stmt `query:"count-rules-by-inbox"`
-	ListRules         *sqlx.Stmt `query:"list-rules"`
-	CountRules        *sqlx.Stmt `query:"count-rules"`
+	CreateRule            *sqlx.Stmt `query:"create-rule"`
+	GetRule               *sqlx.Stmt `query:"get-rule"`
+	UpdateRule            *sqlx.Stmt `query:"update-rule"`
+	DeleteRule            *sqlx.Stmt `query:"delete-rule"`
+	ListRulesByInbox      *sqlx.Stmt `query:"list-rules-by-inbox"`
+	CountRulesByInbox     *sqlx.Stmt `query:"count-rules-by-inbox"`
+	ListAllRulesByInbox   *sqlx.Stmt `query:"list-all-rules-by-inbox"`
+	IncrementRuleHitCount *sqlx.Stmt `query:"increment-rule-hit-count"`
+	ListRules             *sqlx.Stmt `query:"list-rules"`
+	CountRules            *sqlx.Stmt `query:"count-rules"`
 
 	// Message queries
-	CreateMessage                      *sqlx.Stmt `query:"create-message"`
-	GetMessage                         *sqlx.Stmt `query:"get-message"`
-	ListMessagesByInbox                *sqlx.Stmt `query:"list-messages-by-inbox"`
-	CountMessagesByInbox               *sqlx.Stmt `query:"count-messages-by-inbox"`
-	UpdateMessageReadStatus            *sqlx.Stmt `query:"update-message-read-status"`
-	DeleteMessage                      *sqlx.Stmt `query:"delete-message"`
-	ListMessagesByInboxWithReadFilter  *sqlx.Stmt `query:"list-messages-by-inbox-with-read-filter"`
-	CountMessagesByInboxWithReadFilter *sqlx.Stmt `query:"count-messages-by-inbox-with-read-filter"`
+	CreateMessage                          *sqlx.Stmt `query:"create-message"`
+	GetMessage                             *sqlx.Stmt `query:"get-message"`
+	GetMessageRaw                          *sqlx.Stmt `query:"get-message-raw"`
+	ListMessagesByInbox                    *sqlx.Stmt `query:"list-messages-by-inbox"`
+	CountMessagesByInbox                   *sqlx.Stmt `query:"count-messages-by-inbox"`
+	ListMessagesByFolder                   *sqlx.Stmt `query:"list-messages-by-folder"`
+	CountMessagesByFolder                  *sqlx.Stmt `query:"count-messages-by-folder"`
+	CountMessagesBelow                     *sqlx.Stmt `query:"count-messages-below"`
+	ListMessageFlagsByFolder               *sqlx.Stmt `query:"list-message-flags-by-folder"`
+	GetFolderStatus                        *sqlx.Stmt `query:"get-folder-status"`
+	UpdateMessageReadStatus                *sqlx.Stmt `query:"update-message-read-status"`
+	UpdateMessageFlags                     *sqlx.Stmt `query:"update-message-flags"`
+	ExpungeMessagesByFolder                *sqlx.Stmt `query:"expunge-messages-by-folder"`
+	CopyMessage                            *sqlx.Stmt `query:"copy-message"`
+	CopyAttachments                        *sqlx.Stmt `query:"copy-attachments"`
+	DeleteMessage                          *sqlx.Stmt `query:"delete-message"`
+	ListMessagesByInboxWithReadFilter      *sqlx.Stmt `query:"list-messages-by-inbox-with-read-filter"`
+	CountMessagesByInboxWithReadFilter     *sqlx.Stmt `query:"count-messages-by-inbox-with-read-filter"`
+	ListMessagesByInboxWithReceiverFilter  *sqlx.Stmt `query:"list-messages-by-inbox-with-receiver-filter"`
+	CountMessagesByInboxWithReceiverFilter *sqlx.Stmt `query:"count-messages-by-inbox-with-receiver-filter"`
+	GetLastMessageID                       *sqlx.Stmt `query:"get-last-message-id"`
+
+	// Folder queries
+	CreateFolder          *sqlx.Stmt `query:"create-folder"`
+	GetFolder             *sqlx.Stmt `query:"get-folder"`
+	GetFolderByName       *sqlx.Stmt `query:"get-folder-by-name"`
+	RenameFolder          *sqlx.Stmt `query:"rename-folder"`
+	DeleteFolder          *sqlx.Stmt `query:"delete-folder"`
+	ListFoldersByInbox    *sqlx.Stmt `query:"list-folders-by-inbox"`
+	CountFoldersByInbox   *sqlx.Stmt `query:"count-folders-by-inbox"`
+	ListAllFoldersByInbox *sqlx.Stmt `query:"list-all-folders-by-inbox"`
+
+	// Attachment queries
+	CreateAttachment         *sqlx.Stmt `query:"create-attachment"`
+	ListAttachmentsByMessage *sqlx.Stmt `query:"list-attachments-by-message"`
+	GetAttachment            *sqlx.Stmt `query:"get-attachment"`
 
 	// User queries
-	ListUsers         *sqlx.Stmt `query:"list-users"`
-	CountUsers        *sqlx.Stmt `query:"count-users"`
-	GetUser           *sqlx.Stmt `query:"get-user"`
-	CreateUser        *sqlx.Stmt `query:"create-user"`
-	UpdateUser        *sqlx.Stmt `query:"update-user"`
-	DeleteUser        *sqlx.Stmt `query:"delete-user"`
-	GetUserByUsername *sqlx.Stmt `query:"get-user-by-username"`
+	ListUsers            *sqlx.Stmt `query:"list-users"`
+	CountUsers           *sqlx.Stmt `query:"count-users"`
+	GetUser              *sqlx.Stmt `query:"get-user"`
+	CreateUser           *sqlx.Stmt `query:"create-user"`
+	UpdateUser           *sqlx.Stmt `query:"update-user"`
+	DeleteUser           *sqlx.Stmt `query:"delete-user"`
+	GetUserByUsername    *sqlx.Stmt `query:"get-user-by-username"`
+	UpdateUserLoggedInAt *sqlx.Stmt `query:"update-user-loggedin-at"`
 
 	// Tokens
-	ListTokensByUser  *sqlx.Stmt `query:"list-tokens-by-user"`
-	CountTokensByUser *sqlx.Stmt `query:"count-tokens-by-user"`
-	GetTokenByUser    *sqlx.Stmt `query:"get-token-by-user"`
-	DeleteToken       *sqlx.Stmt `query:"delete-token"`
-	CreateToken       *sqlx.Stmt `query:"create-token"`
+	ListTokensByUser    *sqlx.Stmt `query:"list-tokens-by-user"`
+	CountTokensByUser   *sqlx.Stmt `query:"count-tokens-by-user"`
+	GetTokenByUser      *sqlx.Stmt `query:"get-token-by-user"`
+	GetTokenByValue     *sqlx.Stmt `query:"get-token-by-value"`
+	DeleteToken         *sqlx.Stmt `query:"delete-token"`
+	CreateToken         *sqlx.Stmt `query:"create-token"`
+	UpdateTokenLastUsed *sqlx.Stmt `query:"update-token-last-used"`
+
+	// Session queries
+	CreateSession         *sqlx.Stmt `query:"create-session"`
+	GetSession            *sqlx.Stmt `query:"get-session"`
+	TouchSession          *sqlx.Stmt `query:"touch-session"`
+	DeactivateSession     *sqlx.Stmt `query:"deactivate-session"`
+	ListSessionsByUser    *sqlx.Stmt `query:"list-sessions-by-user"`
+	CountSessionsByUser   *sqlx.Stmt `query:"count-sessions-by-user"`
+	RevokeSession         *sqlx.Stmt `query:"revoke-session"`
+	RevokeSessionsByUser  *sqlx.Stmt `query:"revoke-sessions-by-user"`
+	DeleteExpiredSessions *sqlx.Stmt `query:"delete-expired-sessions"`
+
+	// Webhook queries
+	ListWebhooksByProject     *sqlx.Stmt `query:"list-webhooks-by-project"`
+	CountWebhooksByProject    *sqlx.Stmt `query:"count-webhooks-by-project"`
+	GetWebhook                *sqlx.Stmt `query:"get-webhook"`
+	CreateWebhook             *sqlx.Stmt `query:"create-webhook"`
+	UpdateWebhook             *sqlx.Stmt `query:"update-webhook"`
+	DeleteWebhook             *sqlx.Stmt `query:"delete-webhook"`
+	ListActiveWebhooksByInbox *sqlx.Stmt `query:"list-active-webhooks-by-inbox"`
+	CreateWebhookDelivery     *sqlx.Stmt `query:"create-webhook-delivery"`
+	GetWebhookDelivery        *sqlx.Stmt `query:"get-webhook-delivery"`
+	ListWebhookDeliveries     *sqlx.Stmt `query:"list-webhook-deliveries"`
+	CountWebhookDeliveries    *sqlx.Stmt `query:"count-webhook-deliveries"`
+	ClaimWebhookDeliveries    *sqlx.Stmt `query:"claim-webhook-deliveries"`
+	UpdateWebhookDelivery     *sqlx.Stmt `query:"update-webhook-delivery"`
+	ReplayWebhookDelivery     *sqlx.Stmt `query:"replay-webhook-delivery"`
+
+	// Retention policy queries
+	GetProjectRetentionPolicy      *sqlx.Stmt `query:"get-project-retention-policy"`
+	GetInboxRetentionPolicy        *sqlx.Stmt `query:"get-inbox-retention-policy"`
+	UpsertProjectRetentionPolicy   *sqlx.Stmt `query:"upsert-project-retention-policy"`
+	UpsertInboxRetentionPolicy     *sqlx.Stmt `query:"upsert-inbox-retention-policy"`
+	ListEffectiveRetentionPolicies *sqlx.Stmt `query:"list-effective-retention-policies"`
+	PurgeMessages                  *sqlx.Stmt `query:"purge-messages"`
+
+	// Outbound message queries
+	CreateOutboundMessage        *sqlx.Stmt `query:"create-outbound-message"`
+	GetOutboundMessage           *sqlx.Stmt `query:"get-outbound-message"`
+	ListOutboundMessagesByInbox  *sqlx.Stmt `query:"list-outbound-messages-by-inbox"`
+	CountOutboundMessagesByInbox *sqlx.Stmt `query:"count-outbound-messages-by-inbox"`
+	ClaimOutboundMessages        *sqlx.Stmt `query:"claim-outbound-messages"`
+	UpdateOutboundMessage        *sqlx.Stmt `query:"update-outbound-message"`
 }
 
 func PrepareQueries(db *sqlx.DB) (*Queries, error) {
@@ -3,13 +3,13 @@
 -- -------------------------------------------
 
 -- name: list-projects
-SELECT id, name, created_at, updated_at
+SELECT id, name, smtp_require_auth, created_at, updated_at
 FROM projects
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
 -- name: list-projects-by-user
-SELECT projects.id, projects.name, projects.created_at, projects.updated_at
+SELECT projects.id, projects.name, projects.smtp_require_auth, projects.created_at, projects.updated_at
 FROM projects
 INNER JOIN project_users ON projects.id = project_users.project_id
 WHERE project_users.user_id = $1
@@ -23,19 +23,19 @@ INNER JOIN project_users ON projects.id = project_users.project_id
 WHERE project_users.user_id = $1;
 
 -- name: get-project
-SELECT id, name, created_at, updated_at
+SELECT id, name, smtp_require_auth, created_at, updated_at
 FROM projects
 WHERE id = $1;
 
 -- name: create-project
-INSERT INTO projects (name, created_at, updated_at)
-VALUES ($1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO projects (name, smtp_require_auth, created_at, updated_at)
+VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING id, created_at, updated_at;
 
 -- name: update-project
 UPDATE projects
-SET name = $1, updated_at = CURRENT_TIMESTAMP
-WHERE id = $2
+SET name = $1, smtp_require_auth = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $3
 RETURNING updated_at;
 
 -- name: delete-project
@@ -49,38 +49,43 @@ SELECT COUNT(*) FROM projects;
 -- -------------------------------------------
 
 -- name: add-user-to-project
-INSERT INTO project_users (user_id, project_id, role)
+INSERT INTO project_users (project_id, user_id, role)
 VALUES ($1, $2, $3)
 RETURNING created_at, updated_at;
 
 -- name: remove-user-from-project
 DELETE FROM project_users
-WHERE user_id = $1 AND project_id = $2;
+WHERE project_id = $1 AND user_id = $2;
+
+-- name: get-project-user
+SELECT id, project_id, user_id, role, created_at, updated_at
+FROM project_users
+WHERE project_id = $1 AND user_id = $2;
 
 --- ------------------------------------------
 -- Inboxes
 -- -------------------------------------------
 
 -- name: create-inbox
-INSERT INTO inboxes (project_id, email, created_at, updated_at)
-VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO inboxes (project_id, email, max_messages_per_hour, max_total_bytes, max_message_bytes, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING id, created_at, updated_at;
 
 -- name: get-inbox
-SELECT id, project_id, email, created_at, updated_at
+SELECT id, project_id, email, max_messages_per_hour, max_total_bytes, max_message_bytes, created_at, updated_at
 FROM inboxes
 WHERE id = $1;
 
 -- name: update-inbox
 UPDATE inboxes
-SET email = $1
-WHERE id = $2;
+SET email = $1, max_messages_per_hour = $2, max_total_bytes = $3, max_message_bytes = $4
+WHERE id = $5;
 
 -- name: delete-inbox
 DELETE FROM inboxes WHERE id = $1;
 
 -- name: list-inboxes-by-project
-SELECT id, project_id, email, created_at, updated_at
+SELECT id, project_id, email, max_messages_per_hour, max_total_bytes, max_message_bytes, created_at, updated_at
 FROM inboxes
 WHERE project_id = $1
 ORDER BY id
@@ -92,34 +97,102 @@ FROM inboxes
 WHERE project_id = $1;
 
 -- name: get-inbox-by-email
-SELECT id, project_id, email, created_at, updated_at
+SELECT id, project_id, email, max_messages_per_hour, max_total_bytes, max_message_bytes, created_at, updated_at
 FROM inboxes
 WHERE email = $1;
 
+-- name: get-inbox-by-address
+SELECT id, project_id, email, max_messages_per_hour, max_total_bytes, max_message_bytes, created_at, updated_at
+FROM inboxes
+WHERE lower(email) IN (lower($1), lower($2))
+   OR (strpos(email, '*') > 0
+       AND lower($1) LIKE replace(replace(replace(replace(lower(email), '\', '\\'), '%', '\%'), '_', '\_'), '*', '%'))
+ORDER BY lower(email) = lower($1) DESC, lower(email) = lower($2) DESC,
+         length(replace(email, '*', '')) DESC, id
+LIMIT 1;
+
+-- name: list-inboxes-by-user
+SELECT i.id, i.project_id, i.email, i.max_messages_per_hour, i.max_total_bytes, i.max_message_bytes,
+       i.created_at, i.updated_at
+FROM inboxes i
+INNER JOIN project_users pu ON pu.project_id = i.project_id
+WHERE pu.user_id = $1
+ORDER BY i.id;
+
+-- name: get-inbox-usage
+SELECT COUNT(*) AS messages,
+       COALESCE(SUM(size), 0) AS total_bytes,
+       COUNT(*) FILTER (WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '1 hour') AS messages_last_hour
+FROM messages
+WHERE inbox_id = $1;
+
+--- ------------------------------------------
+-- Domains
+-- -------------------------------------------
+
+-- name: list-domains-by-project
+SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at
+FROM domains
+WHERE project_id = $1
+ORDER BY name
+LIMIT $2 OFFSET $3;
+
+-- name: count-domains-by-project
+SELECT COUNT(*)
+FROM domains
+WHERE project_id = $1;
+
+-- name: get-domain
+SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at
+FROM domains
+WHERE id = $1;
+
+-- name: create-domain
+INSERT INTO domains (project_id, name, verification_token)
+VALUES ($1, $2, $3)
+RETURNING id, created_at, updated_at;
+
+-- name: verify-domain
+UPDATE domains
+SET verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING verified_at, updated_at;
+
+-- name: delete-domain
+DELETE FROM domains WHERE id = $1;
+
+-- name: get-verified-domain-for-host
+SELECT id, project_id, name, verification_token, verified_at, created_at, updated_at
+FROM domains
+WHERE verified_at IS NOT NULL
+  AND (name = lower($1) OR right(lower($1), length(name) + 1) = '.' || name)
+ORDER BY length(name) DESC
+LIMIT 1;
+
 --- ------------------------------------------
 -- Rules
 -- -------------------------------------------
 
 -- name: create-rule
-INSERT INTO forward_rules (inbox_id, sender, receiver, subject, created_at, updated_at)
-VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO forward_rules (inbox_id, sender, receiver, subject, match_type, forward_to, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING id, created_at, updated_at;
 
 -- name: get-rule
-SELECT id, inbox_id, sender, receiver, subject, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, match_type, forward_to, hit_count, last_hit_at, created_at, updated_at
 FROM forward_rules
 WHERE id = $1;
 
 -- name: update-rule
 UPDATE forward_rules
-SET sender = $1, receiver = $2, subject = $3
-WHERE id = $4;
+SET sender = $1, receiver = $2, subject = $3, match_type = $4, forward_to = $5, updated_at = CURRENT_TIMESTAMP
+WHERE id = $6;
 
 -- name: delete-rule
 DELETE FROM forward_rules WHERE id = $1;
 
 -- name: list-rules-by-inbox
-SELECT id, inbox_id, sender, receiver, subject, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, match_type, forward_to, hit_count, last_hit_at, created_at, updated_at
 FROM forward_rules
 WHERE inbox_id = $1
 ORDER BY id
@@ -130,8 +203,19 @@ SELECT COUNT(*)
 FROM forward_rules
 WHERE inbox_id = $1;
 
+-- name: list-all-rules-by-inbox
+SELECT id, inbox_id, folder_id, sender, receiver, subject, match_type, forward_to, hit_count, last_hit_at, created_at, updated_at
+FROM forward_rules
+WHERE inbox_id = $1
+ORDER BY id;
+
+-- name: increment-rule-hit-count
+UPDATE forward_rules
+SET hit_count = hit_count + 1, last_hit_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
 -- name: list-rules
-SELECT id, inbox_id, sender, receiver, subject, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, match_type, forward_to, hit_count, last_hit_at, created_at, updated_at
 FROM forward_rules
 ORDER BY id
 LIMIT $1 OFFSET $2;
@@ -144,37 +228,120 @@ SELECT COUNT(*) FROM forward_rules;
 -- -------------------------------------------
 
 -- name: create-message
-INSERT INTO messages (inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, false, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO messages (inbox_id, folder_id, sender, receiver, subject, body, html_body, raw, size, is_read, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING id, created_at, updated_at;
 
+-- name: get-message-raw
+SELECT raw
+FROM messages
+WHERE id = $1;
+
 -- name: get-message
-SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read,
+       is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at
 FROM messages
 WHERE id = $1;
 
 -- name: list-messages-by-inbox
-SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read,
+       is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at
 FROM messages
 WHERE inbox_id = $1
 ORDER BY id
 LIMIT $2 OFFSET $3;
 
+-- name: list-message-flags-by-folder
+SELECT id, inbox_id, folder_id, is_read, is_flagged, is_answered, is_deleted,
+       keywords, size, created_at
+FROM messages
+WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2
+ORDER BY id;
+
+-- name: list-messages-by-folder
+SELECT id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read,
+       is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at
+FROM messages
+WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2
+ORDER BY id
+LIMIT $3 OFFSET $4;
+
+-- name: count-messages-by-folder
+SELECT COUNT(*)
+FROM messages
+WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2;
+
 -- name: count-messages-by-inbox
 SELECT COUNT(*)
 FROM messages
 WHERE inbox_id = $1;
 
+-- name: count-messages-below
+-- For each of the IDs in $3, in order, the number of messages of the folder
+-- with a lower ID
+SELECT COUNT(m.id)
+FROM unnest($3::integer[]) WITH ORDINALITY AS below(id, ord)
+LEFT JOIN messages m
+  ON m.inbox_id = $1 AND m.folder_id IS NOT DISTINCT FROM $2 AND m.id < below.id
+GROUP BY below.ord
+ORDER BY below.ord;
+
+-- name: get-folder-status
+-- uid_next is the next value of the message ID sequence, which only ever
+-- increases unlike the highest ID of the folder
+SELECT COUNT(*) AS messages,
+       COUNT(*) FILTER (WHERE NOT is_read) AS unseen,
+       COUNT(*) FILTER (WHERE id <= (
+           SELECT MIN(id) FROM messages
+           WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2 AND NOT is_read
+       )) AS first_unseen,
+       (SELECT last_value + CASE WHEN is_called THEN 1 ELSE 0 END FROM messages_id_seq) AS uid_next
+FROM messages
+WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2;
+
+-- name: get-last-message-id
+SELECT COALESCE(MAX(id), 0)
+FROM messages
+WHERE inbox_id = $1;
+
 -- name: update-message-read-status
 UPDATE messages
 SET is_read = $1, updated_at = CURRENT_TIMESTAMP
 WHERE id = $2;
 
+-- name: update-message-flags
+UPDATE messages
+SET is_read = $1, is_flagged = $2, is_answered = $3, is_deleted = $4, keywords = $5, updated_at = CURRENT_TIMESTAMP
+WHERE id = $6;
+
 -- name: delete-message
 DELETE FROM messages WHERE id = $1;
 
+-- name: expunge-messages-by-folder
+DELETE FROM messages
+WHERE inbox_id = $1 AND folder_id IS NOT DISTINCT FROM $2 AND is_deleted
+  AND ($3::integer[] IS NULL OR id = ANY($3))
+RETURNING id;
+
+-- name: copy-message
+INSERT INTO messages (inbox_id, folder_id, sender, receiver, subject, body, html_body, raw, size,
+                      is_read, is_flagged, is_answered, is_deleted, keywords, created_at, updated_at)
+SELECT $2, $3, sender, receiver, subject, body, html_body, raw, size,
+       is_read, is_flagged, is_answered, is_deleted, keywords, created_at, CURRENT_TIMESTAMP
+FROM messages
+WHERE id = $1
+RETURNING id;
+
+-- name: copy-attachments
+INSERT INTO attachments (message_id, filename, content_type, content_id, size, content, created_at, updated_at)
+SELECT $2, filename, content_type, content_id, size, content, created_at, CURRENT_TIMESTAMP
+FROM attachments
+WHERE message_id = $1
+ORDER BY id;
+
 -- name: list-messages-by-inbox-with-read-filter
-SELECT id, inbox_id, sender, receiver, subject, body, is_read, created_at, updated_at
+SELECT id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read,
+       is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at
 FROM messages
 WHERE inbox_id = $1 AND is_read = $2
 ORDER BY id
@@ -185,6 +352,85 @@ SELECT COUNT(*)
 FROM messages
 WHERE inbox_id = $1 AND is_read = $2;
 
+-- name: list-messages-by-inbox-with-receiver-filter
+SELECT id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read,
+       is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at
+FROM messages
+WHERE inbox_id = $1 AND ($2::boolean IS NULL OR is_read = $2) AND lower(receiver) LIKE $3
+ORDER BY id
+LIMIT $4 OFFSET $5;
+
+-- name: count-messages-by-inbox-with-receiver-filter
+SELECT COUNT(*)
+FROM messages
+WHERE inbox_id = $1 AND ($2::boolean IS NULL OR is_read = $2) AND lower(receiver) LIKE $3;
+
+--- ------------------------------------------
+-- Folders
+-- -------------------------------------------
+
+-- name: create-folder
+INSERT INTO folders (inbox_id, name, created_at, updated_at)
+VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, created_at, updated_at;
+
+-- name: get-folder
+SELECT id, inbox_id, name, created_at, updated_at
+FROM folders
+WHERE id = $1;
+
+-- name: get-folder-by-name
+SELECT id, inbox_id, name, created_at, updated_at
+FROM folders
+WHERE inbox_id = $1 AND name = $2;
+
+-- name: rename-folder
+-- Renames the folder and every folder below it in the hierarchy
+UPDATE folders
+SET name = $3 || substr(name, length($2) + 1), updated_at = CURRENT_TIMESTAMP
+WHERE inbox_id = $1 AND (name = $2 OR left(name, length($2) + 1) = $2 || '/');
+
+-- name: delete-folder
+DELETE FROM folders WHERE id = $1;
+
+-- name: list-folders-by-inbox
+SELECT id, inbox_id, name, created_at, updated_at
+FROM folders
+WHERE inbox_id = $1
+ORDER BY name
+LIMIT $2 OFFSET $3;
+
+-- name: count-folders-by-inbox
+SELECT COUNT(*)
+FROM folders
+WHERE inbox_id = $1;
+
+-- name: list-all-folders-by-inbox
+SELECT id, inbox_id, name, created_at, updated_at
+FROM folders
+WHERE inbox_id = $1
+ORDER BY name;
+
+--- ------------------------------------------
+-- Attachments
+-- -------------------------------------------
+
+-- name: create-attachment
+INSERT INTO attachments (message_id, filename, content_type, content_id, size, content, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, created_at, updated_at;
+
+-- name: list-attachments-by-message
+SELECT id, message_id, filename, content_type, content_id, size, created_at, updated_at
+FROM attachments
+WHERE message_id = $1
+ORDER BY id;
+
+-- name: get-attachment
+SELECT id, message_id, filename, content_type, content_id, size, content, created_at, updated_at
+FROM attachments
+WHERE id = $1;
+
 --- ------------------------------------------
 -- Users
 -- -------------------------------------------
@@ -230,12 +476,17 @@ SELECT id, name, username, password, email, status, role,
 FROM users
 WHERE username = $1;
 
+-- name: update-user-loggedin-at
+UPDATE users
+SET loggedin_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
 --- ------------------------------------------
 -- Tokens
 -- -------------------------------------------
 
 -- name: list-tokens-by-user
-SELECT id, user_id, token, name, expires_at, created_at, updated_at
+SELECT id, user_id, token, name, expires_at, last_used_at, created_at, updated_at
 FROM tokens
 WHERE user_id = $1
 ORDER BY id
@@ -247,15 +498,283 @@ FROM tokens
 WHERE user_id = $1;
 
 -- name: get-token-by-user
-SELECT id, user_id, token, name, expires_at, created_at, updated_at
+SELECT id, user_id, token, name, expires_at, last_used_at, created_at, updated_at
 FROM tokens
 WHERE id = $1 AND user_id = $2
 
+-- name: get-token-by-value
+SELECT id, user_id, token, name, expires_at, last_used_at, created_at, updated_at
+FROM tokens
+WHERE token = $1;
+
 -- name: create-token
 INSERT INTO tokens (user_id, token, name, expires_at)
 VALUES ($1, $2, $3, $4)
 RETURNING id, user_id, token, name, expires_at, created_at, updated_at;
 
+-- name: update-token-last-used
+UPDATE tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
 -- name: delete-token
 DELETE FROM tokens
 WHERE id = $1
+
+--- ------------------------------------------
+-- Webhooks
+-- -------------------------------------------
+
+-- name: list-webhooks-by-project
+SELECT id, project_id, inbox_id, url, secret, event_types, is_active, created_at, updated_at
+FROM webhooks
+WHERE project_id = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: count-webhooks-by-project
+SELECT COUNT(*) FROM webhooks WHERE project_id = $1;
+
+-- name: get-webhook
+SELECT id, project_id, inbox_id, url, secret, event_types, is_active, created_at, updated_at
+FROM webhooks
+WHERE id = $1;
+
+-- name: create-webhook
+INSERT INTO webhooks (project_id, inbox_id, url, secret, event_types, is_active)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at;
+
+-- name: update-webhook
+UPDATE webhooks
+SET inbox_id = $1, url = $2, secret = $3, event_types = $4, is_active = $5, updated_at = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING updated_at;
+
+-- name: delete-webhook
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: list-active-webhooks-by-inbox
+SELECT w.id, w.project_id, w.inbox_id, w.url, w.secret, w.event_types, w.is_active, w.created_at, w.updated_at
+FROM webhooks w
+JOIN inboxes i ON i.project_id = w.project_id
+WHERE i.id = $1 AND w.is_active AND (w.inbox_id IS NULL OR w.inbox_id = i.id)
+ORDER BY w.id;
+
+-- name: create-webhook-delivery
+INSERT INTO webhook_deliveries (webhook_id, event_type, message_id, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING id, status, attempts, next_attempt_at, created_at, updated_at;
+
+-- name: get-webhook-delivery
+SELECT id, webhook_id, event_type, message_id, payload, status, attempts, next_attempt_at,
+       response_status, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: list-webhook-deliveries
+SELECT id, webhook_id, event_type, message_id, payload, status, attempts, next_attempt_at,
+       response_status, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: count-webhook-deliveries
+SELECT COUNT(*)
+FROM webhook_deliveries
+WHERE webhook_id = $1 AND ($2 = '' OR status = $2);
+
+-- name: claim-webhook-deliveries
+UPDATE webhook_deliveries
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, webhook_id, event_type, message_id, payload, status, attempts, next_attempt_at,
+          response_status, last_error, created_at, updated_at;
+
+-- name: update-webhook-delivery
+UPDATE webhook_deliveries
+SET status = $1, attempts = $2, next_attempt_at = $3, response_status = $4, last_error = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING updated_at;
+
+-- name: replay-webhook-delivery
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, response_status = NULL,
+    last_error = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status <> 'pending'
+RETURNING status, attempts, next_attempt_at, response_status, last_error, updated_at;
+
+--- ------------------------------------------
+-- Retention policies
+-- -------------------------------------------
+
+-- name: get-project-retention-policy
+SELECT id, project_id, inbox_id, max_age_hours, max_messages, max_size_bytes, created_at, updated_at
+FROM retention_policies
+WHERE project_id = $1 AND inbox_id IS NULL;
+
+-- name: get-inbox-retention-policy
+SELECT id, project_id, inbox_id, max_age_hours, max_messages, max_size_bytes, created_at, updated_at
+FROM retention_policies
+WHERE inbox_id = $1;
+
+-- name: upsert-project-retention-policy
+INSERT INTO retention_policies (project_id, max_age_hours, max_messages, max_size_bytes)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id) WHERE inbox_id IS NULL
+DO UPDATE SET max_age_hours = EXCLUDED.max_age_hours, max_messages = EXCLUDED.max_messages,
+              max_size_bytes = EXCLUDED.max_size_bytes, updated_at = CURRENT_TIMESTAMP
+RETURNING id, created_at, updated_at;
+
+-- name: upsert-inbox-retention-policy
+INSERT INTO retention_policies (project_id, inbox_id, max_age_hours, max_messages, max_size_bytes)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (inbox_id) WHERE inbox_id IS NOT NULL
+DO UPDATE SET max_age_hours = EXCLUDED.max_age_hours, max_messages = EXCLUDED.max_messages,
+              max_size_bytes = EXCLUDED.max_size_bytes, updated_at = CURRENT_TIMESTAMP
+RETURNING id, created_at, updated_at;
+
+-- name: list-effective-retention-policies
+SELECT i.project_id, i.id AS inbox_id,
+       COALESCE(ip.max_age_hours, pp.max_age_hours) AS max_age_hours,
+       COALESCE(ip.max_messages, pp.max_messages) AS max_messages,
+       COALESCE(ip.max_size_bytes, pp.max_size_bytes) AS max_size_bytes
+FROM inboxes i
+LEFT JOIN retention_policies ip ON ip.inbox_id = i.id
+LEFT JOIN retention_policies pp ON pp.project_id = i.project_id AND pp.inbox_id IS NULL
+WHERE COALESCE(ip.max_age_hours, pp.max_age_hours, ip.max_messages, pp.max_messages,
+               ip.max_size_bytes, pp.max_size_bytes) IS NOT NULL
+ORDER BY i.id;
+
+-- name: purge-messages
+-- Deletes up to $5 of the oldest messages of an inbox that were received
+-- before $2, or fall beyond the newest $3 messages or the newest $4 bytes
+DELETE FROM messages
+WHERE id IN (
+    SELECT id
+    FROM (
+        SELECT id, created_at,
+               ROW_NUMBER() OVER (ORDER BY id DESC) AS recency,
+               SUM(size) OVER (ORDER BY id DESC) AS kept_size
+        FROM messages
+        WHERE inbox_id = $1
+    ) ranked
+    WHERE ($2::timestamptz IS NOT NULL AND created_at < $2)
+       OR ($3::integer IS NOT NULL AND recency > $3)
+       OR ($4::bigint IS NOT NULL AND kept_size > $4)
+    ORDER BY id
+    LIMIT $5
+)
+RETURNING id, inbox_id, folder_id, size;
+
+-- ------------------------------------------
+-- Outbound messages
+-- -------------------------------------------
+
+-- name: create-outbound-message
+INSERT INTO outbound_messages (inbox_id, message_id, sender, recipients, raw)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, status, attempts, next_attempt_at, created_at, updated_at;
+
+-- name: get-outbound-message
+SELECT id, inbox_id, message_id, sender, recipients, status, attempts, next_attempt_at,
+       last_error, sent_at, created_at, updated_at
+FROM outbound_messages
+WHERE id = $1;
+
+-- name: list-outbound-messages-by-inbox
+SELECT id, inbox_id, message_id, sender, recipients, status, attempts, next_attempt_at,
+       last_error, sent_at, created_at, updated_at
+FROM outbound_messages
+WHERE inbox_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: count-outbound-messages-by-inbox
+SELECT COUNT(*)
+FROM outbound_messages
+WHERE inbox_id = $1 AND ($2 = '' OR status = $2);
+
+-- name: claim-outbound-messages
+UPDATE outbound_messages
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2), updated_at = CURRENT_TIMESTAMP
+WHERE id IN (
+    SELECT id
+    FROM outbound_messages
+    WHERE status = 'queued' AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, inbox_id, message_id, sender, recipients, raw, status, attempts, next_attempt_at,
+          last_error, sent_at, created_at, updated_at;
+
+-- name: update-outbound-message
+UPDATE outbound_messages
+SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, sent_at = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $6
+RETURNING updated_at;
+
+--- ------------------------------------------
+-- Sessions
+-- -------------------------------------------
+
+-- name: create-session
+INSERT INTO sessions (session_id, user_id, expires_at, ip_address, user_agent)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, data, created_at, last_accessed_at, is_active;
+
+-- name: get-session
+SELECT id, session_id, user_id, data, created_at, expires_at, last_accessed_at,
+       COALESCE(ip_address, '') AS ip_address, COALESCE(user_agent, '') AS user_agent,
+       is_active
+FROM sessions
+WHERE session_id = $1;
+
+-- name: touch-session
+UPDATE sessions
+SET last_accessed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: deactivate-session
+UPDATE sessions
+SET is_active = false
+WHERE session_id = $1;
+
+-- name: list-sessions-by-user
+SELECT id, user_id, data, created_at, expires_at, last_accessed_at,
+       COALESCE(ip_address, '') AS ip_address, COALESCE(user_agent, '') AS user_agent,
+       is_active
+FROM sessions
+WHERE user_id = $1 AND is_active AND expires_at > CURRENT_TIMESTAMP
+ORDER BY last_accessed_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: count-sessions-by-user
+SELECT COUNT(*)
+FROM sessions
+WHERE user_id = $1 AND is_active AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: revoke-session
+UPDATE sessions
+SET is_active = false
+WHERE id = $1 AND user_id = $2 AND is_active;
+
+-- name: revoke-sessions-by-user
+UPDATE sessions
+SET is_active = false
+WHERE user_id = $1 AND is_active;
+
+-- name: delete-expired-sessions
+DELETE FROM sessions
+WHERE expires_at < $1;
@@ -3,11 +3,13 @@ package storage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"inbox451/internal/models"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	null "github.com/volatiletech/null/v9"
 )
 
 type Repository interface {
@@ -23,6 +25,7 @@ type Repository interface {
 	// This is a many-to-many relationship between projects and users
 	ProjectAddUser(ctx context.Context, projectUser *models.ProjectUser) error
 	ProjectRemoveUser(ctx context.Context, projectID int, userID int) error
+	GetProjectUser(ctx context.Context, projectID int, userID int) (*models.ProjectUser, error)
 
 	// Inbox operations
 	ListInboxesByProject(ctx context.Context, projectID, limit, offset int) ([]*models.Inbox, int, error)
@@ -30,6 +33,16 @@ type Repository interface {
 	CreateInbox(ctx context.Context, inbox *models.Inbox) error
 	UpdateInbox(ctx context.Context, inbox *models.Inbox) error
 	DeleteInbox(ctx context.Context, id int) error
+	ListInboxesByUser(ctx context.Context, userID int) ([]*models.Inbox, error)
+	GetInboxUsage(ctx context.Context, inboxID int) (*models.InboxUsage, error)
+
+	// Domain operations
+	ListDomainsByProject(ctx context.Context, projectID, limit, offset int) ([]*models.Domain, int, error)
+	GetDomain(ctx context.Context, id int) (*models.Domain, error)
+	CreateDomain(ctx context.Context, domain *models.Domain) error
+	VerifyDomain(ctx context.Context, domain *models.Domain) error
+	DeleteDomain(ctx context.Context, id int) error
+	GetVerifiedDomainForHost(ctx context.Context, host string) (*models.Domain, error)
 
 	// Rule operations
 	ListRulesByInbox(ctx context.Context, inboxID, limit, offset int) ([]*models.ForwardRule, int, error)
@@ -37,17 +50,47 @@ type Repository interface {
 	CreateRule(ctx context.Context, rule *models.ForwardRule) error
 	UpdateRule(ctx context.Context, rule *models.ForwardRule) error
 	DeleteRule(ctx context.Context, id int) error
+	ListAllRulesByInbox(ctx context.Context, inboxID int) ([]*models.ForwardRule, error)
+	IncrementRuleHitCount(ctx context.Context, id int) error
 
 	// Message operations
 	ListRules(ctx context.Context, limit, offset int) ([]*models.ForwardRule, int, error)
 	GetInboxByEmail(ctx context.Context, email string) (*models.Inbox, error)
+	GetInboxByAddress(ctx context.Context, address, baseAddress string) (*models.Inbox, error)
 	GetMessage(ctx context.Context, id int) (*models.Message, error)
+	GetMessageRaw(ctx context.Context, id int) ([]byte, error)
 	ListMessagesByInbox(ctx context.Context, inboxID, limit, offset int) ([]*models.Message, int, error)
-	ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, limit, offset int) ([]*models.Message, int, error)
+	ListMessagesByInboxWithFilter(ctx context.Context, inboxID int, isRead *bool, receiver string, limit, offset int) ([]*models.Message, int, error)
+	ListMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int, limit, offset int) ([]*models.Message, int, error)
+	ListMessageFlagsByFolder(ctx context.Context, inboxID int, folderID null.Int) ([]*models.Message, error)
+	GetFolderStatus(ctx context.Context, inboxID int, folderID null.Int) (*models.FolderStatus, error)
+	CountMessagesByFolder(ctx context.Context, inboxID int, folderID null.Int) (int, error)
+	CountMessagesBelow(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error)
 	CreateMessage(ctx context.Context, message *models.Message) error
+	CreateMessages(ctx context.Context, messages []*models.Message) error
 	UpdateMessageReadStatus(ctx context.Context, messageID int, isRead bool) error
+	UpdateMessageFlags(ctx context.Context, message *models.Message) error
+	ExpungeMessages(ctx context.Context, inboxID int, folderID null.Int, ids []int) ([]int, error)
+	CopyMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error)
+	MoveMessages(ctx context.Context, ids []int, inboxID int, folderID null.Int) ([]int, error)
+	SearchMessages(ctx context.Context, inboxID int, folderID null.Int, search *models.MessageSearch) ([]int, error)
+	ListMessagesByFilter(ctx context.Context, filter *models.MessageFilter, limit, offset int) ([]*models.Message, int, error)
+	GetLastMessageID(ctx context.Context, inboxID int) (int, error)
 	DeleteMessage(ctx context.Context, messageID int) error
 
+	// Folder operations
+	ListFoldersByInbox(ctx context.Context, inboxID, limit, offset int) ([]*models.Folder, int, error)
+	ListAllFoldersByInbox(ctx context.Context, inboxID int) ([]*models.Folder, error)
+	GetFolder(ctx context.Context, id int) (*models.Folder, error)
+	GetFolderByName(ctx context.Context, inboxID int, name string) (*models.Folder, error)
+	CreateFolder(ctx context.Context, folder *models.Folder) error
+	RenameFolder(ctx context.Context, inboxID int, oldName, newName string) error
+	DeleteFolder(ctx context.Context, id int) error
+
+	// Attachment operations
+	ListAttachmentsByMessage(ctx context.Context, messageID int) ([]*models.Attachment, error)
+	GetAttachment(ctx context.Context, id int) (*models.Attachment, error)
+
 	// User operations
 	ListUsers(ctx context.Context, limit, offset int) ([]*models.User, int, error)
 	GetUser(ctx context.Context, id int) (*models.User, error)
@@ -55,12 +98,53 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, userId int) error
+	UpdateUserLoggedInAt(ctx context.Context, userID int) error
 
 	// Tokens
 	ListTokensByUser(ctx context.Context, userID int, limit, offset int) ([]*models.Token, int, error)
-	GetTokenByUser(ctx context.Context, userID int, tokenID int) (*models.Token, error)
+	GetTokenByUser(ctx context.Context, tokenID int, userID int) (*models.Token, error)
+	GetTokenByValue(ctx context.Context, value string) (*models.Token, error)
 	CreateToken(ctx context.Context, token *models.Token) error
+	UpdateTokenLastUsed(ctx context.Context, tokenID int) error
+
+	// Session operations
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, sessionID string) (*models.Session, error)
+	TouchSession(ctx context.Context, id int) error
+	DeactivateSession(ctx context.Context, sessionID string) error
+	ListSessionsByUser(ctx context.Context, userID, limit, offset int) ([]*models.Session, int, error)
+	RevokeSession(ctx context.Context, id, userID int) error
+	RevokeSessionsByUser(ctx context.Context, userID int) (int, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int, error)
 	DeleteToken(ctx context.Context, tokenID int) error
+
+	// Webhook operations
+	ListWebhooksByProject(ctx context.Context, projectID, limit, offset int) ([]*models.Webhook, int, error)
+	GetWebhook(ctx context.Context, id int) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int) error
+	ListActiveWebhooksByInbox(ctx context.Context, inboxID int) ([]*models.Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, id int) (*models.WebhookDelivery, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*models.WebhookDelivery, int, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	ReplayWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+
+	// Retention policy operations
+	GetProjectRetentionPolicy(ctx context.Context, projectID int) (*models.RetentionPolicy, error)
+	GetInboxRetentionPolicy(ctx context.Context, inboxID int) (*models.RetentionPolicy, error)
+	UpsertRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) error
+	ListEffectiveRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error)
+	PurgeMessages(ctx context.Context, policy *models.RetentionPolicy, now time.Time, limit int) ([]*models.Message, error)
+
+	// Outbound message operations
+	QueueOutboundMessage(ctx context.Context, outbound *models.OutboundMessage, sent *models.Message) error
+	GetOutboundMessage(ctx context.Context, id int) (*models.OutboundMessage, error)
+	ListOutboundMessagesByInbox(ctx context.Context, inboxID int, status string, limit, offset int) ([]*models.OutboundMessage, int, error)
+	ClaimOutboundMessages(ctx context.Context, limit int, lease time.Duration) ([]*models.OutboundMessage, error)
+	UpdateOutboundMessage(ctx context.Context, outbound *models.OutboundMessage) error
 }
 
 type repository struct {
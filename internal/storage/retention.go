@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"inbox451/internal/models"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+func (r *repository) GetProjectRetentionPolicy(ctx context.Context, projectID int) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	err := r.queries.GetProjectRetentionPolicy.GetContext(ctx, &policy, projectID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &policy, nil
+}
+
+func (r *repository) GetInboxRetentionPolicy(ctx context.Context, inboxID int) (*models.RetentionPolicy, error) {
+	var policy models.RetentionPolicy
+	err := r.queries.GetInboxRetentionPolicy.GetContext(ctx, &policy, inboxID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &policy, nil
+}
+
+// UpsertRetentionPolicy creates or replaces the policy of an inbox, or the
+// policy of the project when the inbox is null
+func (r *repository) UpsertRetentionPolicy(ctx context.Context, policy *models.RetentionPolicy) error {
+	var err error
+	if policy.InboxID.Valid {
+		err = r.queries.UpsertInboxRetentionPolicy.QueryRowContext(ctx,
+			policy.ProjectID, policy.InboxID, policy.MaxAgeHours, policy.MaxMessages, policy.MaxSizeBytes).
+			Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+	} else {
+		err = r.queries.UpsertProjectRetentionPolicy.QueryRowContext(ctx,
+			policy.ProjectID, policy.MaxAgeHours, policy.MaxMessages, policy.MaxSizeBytes).
+			Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+	}
+	return handleDBError(err)
+}
+
+// ListEffectiveRetentionPolicies returns the limits applying to every inbox
+// that has any, combining the policy of the inbox with that of its project
+func (r *repository) ListEffectiveRetentionPolicies(ctx context.Context) ([]*models.RetentionPolicy, error) {
+	policies := []*models.RetentionPolicy{}
+	err := r.queries.ListEffectiveRetentionPolicies.SelectContext(ctx, &policies)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return policies, nil
+}
+
+// PurgeMessages deletes up to limit of the oldest messages of the inbox of
+// policy that fall outside its limits at now. The deleted messages are
+// returned with their ID, inbox, folder and size.
+func (r *repository) PurgeMessages(ctx context.Context, policy *models.RetentionPolicy, now time.Time, limit int) ([]*models.Message, error) {
+	var before null.Time
+	if policy.MaxAgeHours.Valid {
+		before = null.TimeFrom(now.Add(-time.Duration(policy.MaxAgeHours.Int) * time.Hour))
+	}
+
+	messages := []*models.Message{}
+	err := r.queries.PurgeMessages.SelectContext(ctx, &messages,
+		policy.InboxID, before, policy.MaxMessages, policy.MaxSizeBytes, limit)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return messages, nil
+}
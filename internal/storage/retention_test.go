@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupRetentionTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("SELECT (.+) FROM retention_policies WHERE project_id") // GetProjectRetentionPolicy
+	mock.ExpectPrepare("INSERT INTO retention_policies \\(project_id, max")    // UpsertProjectRetentionPolicy
+	mock.ExpectPrepare("INSERT INTO retention_policies \\(project_id, inbox")  // UpsertInboxRetentionPolicy
+	mock.ExpectPrepare("SELECT (.+) FROM inboxes i LEFT JOIN")                 // ListEffectiveRetentionPolicies
+	mock.ExpectPrepare("DELETE FROM messages")                                 // PurgeMessages
+
+	getProjectPolicy, err := sqlxDB.Preparex("SELECT id, project_id, inbox_id, max_age_hours, max_messages, max_size_bytes, created_at, updated_at FROM retention_policies WHERE project_id = ? AND inbox_id IS NULL")
+	require.NoError(t, err)
+
+	upsertProjectPolicy, err := sqlxDB.Preparex("INSERT INTO retention_policies (project_id, max_age_hours, max_messages, max_size_bytes) VALUES (?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	upsertInboxPolicy, err := sqlxDB.Preparex("INSERT INTO retention_policies (project_id, inbox_id, max_age_hours, max_messages, max_size_bytes) VALUES (?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	listEffectivePolicies, err := sqlxDB.Preparex("SELECT i.project_id, i.id AS inbox_id, max_age_hours, max_messages, max_size_bytes FROM inboxes i LEFT JOIN retention_policies")
+	require.NoError(t, err)
+
+	purgeMessages, err := sqlxDB.Preparex("DELETE FROM messages WHERE id IN (SELECT id FROM messages WHERE inbox_id = ? AND created_at < ? AND ? AND ? LIMIT ?) RETURNING id, inbox_id, folder_id, size")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		GetProjectRetentionPolicy:      getProjectPolicy,
+		UpsertProjectRetentionPolicy:   upsertProjectPolicy,
+		UpsertInboxRetentionPolicy:     upsertInboxPolicy,
+		ListEffectiveRetentionPolicies: listEffectivePolicies,
+		PurgeMessages:                  purgeMessages,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+func TestRepository_GetProjectRetentionPolicy(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "existing policy",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM retention_policies").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "inbox_id", "max_age_hours", "max_messages", "max_size_bytes", "created_at", "updated_at"}).
+						AddRow(3, 1, nil, 72, nil, nil, now, now))
+			},
+		},
+		{
+			name: "no policy",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM retention_policies").
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupRetentionTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetProjectRetentionPolicy(context.Background(), 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, null.IntFrom(72), got.MaxAgeHours)
+			assert.False(t, got.InboxID.Valid)
+			assert.False(t, got.MaxMessages.Valid)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_UpsertRetentionPolicy(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		policy *models.RetentionPolicy
+		mockFn func(sqlmock.Sqlmock)
+	}{
+		{
+			name:   "project policy",
+			policy: &models.RetentionPolicy{ProjectID: 1, MaxMessages: null.IntFrom(100)},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO retention_policies \\(project_id, max").
+					WithArgs(1, nil, 100, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+			},
+		},
+		{
+			name:   "inbox policy",
+			policy: &models.RetentionPolicy{ProjectID: 1, InboxID: null.IntFrom(2), MaxSizeBytes: null.Int64From(1 << 20)},
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO retention_policies \\(project_id, inbox").
+					WithArgs(1, 2, nil, nil, 1<<20).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupRetentionTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.UpsertRetentionPolicy(context.Background(), tt.policy)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, tt.policy.ID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ListEffectiveRetentionPolicies(t *testing.T) {
+	repo, mock := setupRetentionTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM inboxes i LEFT JOIN").
+		WillReturnRows(sqlmock.NewRows([]string{"project_id", "inbox_id", "max_age_hours", "max_messages", "max_size_bytes"}).
+			AddRow(1, 2, 24, nil, nil).
+			AddRow(1, 3, nil, 10, 4096))
+
+	got, err := repo.ListEffectiveRetentionPolicies(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, null.IntFrom(2), got[0].InboxID)
+	assert.Equal(t, null.IntFrom(24), got[0].MaxAgeHours)
+	assert.Equal(t, null.IntFrom(10), got[1].MaxMessages)
+	assert.Equal(t, null.Int64From(4096), got[1].MaxSizeBytes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_PurgeMessages(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		policy *models.RetentionPolicy
+		before interface{}
+	}{
+		{
+			name:   "max age",
+			policy: &models.RetentionPolicy{InboxID: null.IntFrom(2), MaxAgeHours: null.IntFrom(24), MaxMessages: null.IntFrom(10)},
+			before: now.Add(-24 * time.Hour),
+		},
+		{
+			name:   "no max age",
+			policy: &models.RetentionPolicy{InboxID: null.IntFrom(2), MaxMessages: null.IntFrom(10)},
+			before: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupRetentionTestDB(t)
+			defer repo.db.Close()
+
+			mock.ExpectQuery("DELETE FROM messages").
+				WithArgs(2, tt.before, 10, nil, 500).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "folder_id", "size"}).
+					AddRow(4, 2, nil, 120).
+					AddRow(5, 2, 7, 300))
+
+			got, err := repo.PurgeMessages(context.Background(), tt.policy, now, 500)
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+			assert.Equal(t, 4, got[0].ID)
+			assert.Equal(t, null.IntFrom(7), got[1].FolderID)
+			assert.Equal(t, 300, got[1].Size)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -42,6 +42,23 @@ func (r *repository) ListRulesByInbox(ctx context.Context, inboxID, limit, offse
 	return rules, total, nil
 }
 
+func (r *repository) ListAllRulesByInbox(ctx context.Context, inboxID int) ([]*models.ForwardRule, error) {
+	rules := []*models.ForwardRule{}
+	err := r.queries.ListAllRulesByInbox.SelectContext(ctx, &rules, inboxID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return rules, nil
+}
+
+func (r *repository) IncrementRuleHitCount(ctx context.Context, id int) error {
+	result, err := r.queries.IncrementRuleHitCount.ExecContext(ctx, id)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
 func (r *repository) GetRule(ctx context.Context, id int) (*models.ForwardRule, error) {
 	var rule models.ForwardRule
 	err := r.queries.GetRule.GetContext(ctx, &rule, id)
@@ -49,12 +66,12 @@ func (r *repository) GetRule(ctx context.Context, id int) (*models.ForwardRule,
 }
 
 func (r *repository) CreateRule(ctx context.Context, rule *models.ForwardRule) error {
-	return r.queries.CreateRule.QueryRowContext(ctx, rule.InboxID, rule.Sender, rule.Receiver, rule.Subject).
+	return r.queries.CreateRule.QueryRowContext(ctx, rule.InboxID, rule.Sender, rule.Receiver, rule.Subject, rule.MatchType, rule.ForwardTo).
 		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
 }
 
 func (r *repository) UpdateRule(ctx context.Context, rule *models.ForwardRule) error {
-	result, err := r.queries.UpdateRule.ExecContext(ctx, rule.Sender, rule.Receiver, rule.Subject, rule.ID)
+	result, err := r.queries.UpdateRule.ExecContext(ctx, rule.Sender, rule.Receiver, rule.Subject, rule.MatchType, rule.ForwardTo, rule.ID)
 	if err != nil {
 		return handleDBError(err)
 	}
@@ -29,6 +29,8 @@ func setupRuleTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	mock.ExpectPrepare("INSERT INTO forward_rules")                          // CreateRule
 	mock.ExpectPrepare("UPDATE forward_rules")                               // UpdateRule
 	mock.ExpectPrepare("DELETE FROM forward_rules")                          // DeleteRule
+	mock.ExpectPrepare("SELECT (.+) FROM forward_rules WHERE inbox_id")      // ListAllRulesByInbox
+	mock.ExpectPrepare("UPDATE forward_rules SET hit_count")                 // IncrementRuleHitCount
 
 	listRulesByInbox, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, created_at, updated_at FROM forward_rules WHERE inbox_id = ? LIMIT ? OFFSET ?")
 	require.NoError(t, err)
@@ -45,15 +47,21 @@ func setupRuleTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	getRule, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, created_at, updated_at FROM forward_rules WHERE id = ?")
 	require.NoError(t, err)
 
-	createRule, err := sqlxDB.Preparex("INSERT INTO forward_rules (inbox_id, sender, receiver, subject) VALUES (?, ?, ?, ?)")
+	createRule, err := sqlxDB.Preparex("INSERT INTO forward_rules (inbox_id, sender, receiver, subject, match_type, forward_to) VALUES (?, ?, ?, ?, ?, ?)")
 	require.NoError(t, err)
 
-	updateRule, err := sqlxDB.Preparex("UPDATE forward_rules SET sender = ?, receiver = ?, subject = ? WHERE id = ?")
+	updateRule, err := sqlxDB.Preparex("UPDATE forward_rules SET sender = ?, receiver = ?, subject = ?, match_type = ?, forward_to = ? WHERE id = ?")
 	require.NoError(t, err)
 
 	deleteRule, err := sqlxDB.Preparex("DELETE FROM forward_rules WHERE id = ?")
 	require.NoError(t, err)
 
+	listAllRulesByInbox, err := sqlxDB.Preparex("SELECT id, inbox_id, sender, receiver, subject, match_type, forward_to, hit_count, last_hit_at, created_at, updated_at FROM forward_rules WHERE inbox_id = ? ORDER BY id")
+	require.NoError(t, err)
+
+	incrementRuleHitCount, err := sqlxDB.Preparex("UPDATE forward_rules SET hit_count = hit_count + 1 WHERE id = ?")
+	require.NoError(t, err)
+
 	// Initialize queries struct in the same order
 	queries := &Queries{
 		ListRulesByInbox:  listRulesByInbox,
@@ -64,6 +72,9 @@ func setupRuleTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 		CreateRule:        createRule,
 		UpdateRule:        updateRule,
 		DeleteRule:        deleteRule,
+
+		ListAllRulesByInbox:   listAllRulesByInbox,
+		IncrementRuleHitCount: incrementRuleHitCount,
 	}
 
 	repo := &repository{
@@ -86,14 +97,16 @@ func TestRepository_CreateRule(t *testing.T) {
 		{
 			name: "successful creation",
 			rule: &models.ForwardRule{
-				InboxID:  1,
-				Sender:   "sender@example.com",
-				Receiver: "receiver@example.com",
-				Subject:  "Test Subject",
+				InboxID:   1,
+				Sender:    "sender@example.com",
+				Receiver:  "receiver@example.com",
+				Subject:   "Test Subject",
+				MatchType: models.RuleMatchExact,
+				ForwardTo: "forward@example.com",
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO forward_rules").
-					WithArgs(1, "sender@example.com", "receiver@example.com", "Test Subject").
+					WithArgs(1, "sender@example.com", "receiver@example.com", "Test Subject", "exact", "forward@example.com").
 					WillReturnRows(
 						sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
 							AddRow(1, now, now),
@@ -104,14 +117,16 @@ func TestRepository_CreateRule(t *testing.T) {
 		{
 			name: "database error",
 			rule: &models.ForwardRule{
-				InboxID:  1,
-				Sender:   "sender@example.com",
-				Receiver: "receiver@example.com",
-				Subject:  "Test Subject",
+				InboxID:   1,
+				Sender:    "sender@example.com",
+				Receiver:  "receiver@example.com",
+				Subject:   "Test Subject",
+				MatchType: models.RuleMatchExact,
+				ForwardTo: "forward@example.com",
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery("INSERT INTO forward_rules").
-					WithArgs(1, "sender@example.com", "receiver@example.com", "Test Subject").
+					WithArgs(1, "sender@example.com", "receiver@example.com", "Test Subject", "exact", "forward@example.com").
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -235,7 +250,7 @@ func TestRepository_UpdateRule(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec("UPDATE forward_rules").
-					WithArgs("updated@example.com", "newreceiver@example.com", "Updated Subject", 1).
+					WithArgs("updated@example.com", "newreceiver@example.com", "Updated Subject", "", "", 1).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
@@ -251,7 +266,7 @@ func TestRepository_UpdateRule(t *testing.T) {
 			},
 			mockFn: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec("UPDATE forward_rules").
-					WithArgs("updated@example.com", "newreceiver@example.com", "Updated Subject", 999).
+					WithArgs("updated@example.com", "newreceiver@example.com", "Updated Subject", "", "", 999).
 					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
 			wantErr: true,
@@ -525,3 +540,128 @@ func TestRepository_ListRulesByInbox(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_ListAllRulesByInbox(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		inboxID int
+		mockFn  func(sqlmock.Sqlmock)
+		want    []*models.ForwardRule
+		wantErr bool
+	}{
+		{
+			name:    "rules found",
+			inboxID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"id", "inbox_id", "sender", "receiver", "subject", "match_type", "forward_to",
+					"hit_count", "last_hit_at", "created_at", "updated_at",
+				}).
+					AddRow(1, 1, "*@example.com", "", "", "wildcard", "qa@example.com", 3, now, now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM forward_rules").
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			want: []*models.ForwardRule{
+				{
+					Base: models.Base{
+						ID:        1,
+						CreatedAt: null.TimeFrom(now),
+						UpdatedAt: null.TimeFrom(now),
+					},
+					InboxID:   1,
+					Sender:    "*@example.com",
+					MatchType: models.RuleMatchWildcard,
+					ForwardTo: "qa@example.com",
+					HitCount:  3,
+					LastHitAt: null.TimeFrom(now),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "database error",
+			inboxID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM forward_rules").
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupRuleTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.ListAllRulesByInbox(context.Background(), tt.inboxID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRepository_IncrementRuleHitCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "successful increment",
+			id:   1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE forward_rules SET hit_count").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "non-existent rule",
+			id:   999,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE forward_rules SET hit_count").
+					WithArgs(999).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupRuleTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.IncrementRuleHitCount(context.Background(), tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"inbox451/internal/models"
+
+	null "github.com/volatiletech/null/v9"
+)
+
+// SearchMessages returns the IDs, in ascending order, of the messages of a
+// folder matching the search, a null folderID searches the top level of the
+// inbox
+func (r *repository) SearchMessages(ctx context.Context, inboxID int, folderID null.Int, search *models.MessageSearch) ([]int, error) {
+	query, args := buildSearchQuery(inboxID, folderID, search)
+
+	ids := []int{}
+	if err := r.db.SelectContext(ctx, &ids, query, args...); err != nil {
+		return nil, handleDBError(err)
+	}
+	return ids, nil
+}
+
+func buildSearchQuery(inboxID int, folderID null.Int, search *models.MessageSearch) (string, []interface{}) {
+	b := &searchBuilder{}
+	where := fmt.Sprintf("inbox_id = %s", b.arg(inboxID))
+	if folderID.Valid {
+		where += fmt.Sprintf(" AND folder_id = %s", b.arg(folderID.Int))
+	} else {
+		where += " AND folder_id IS NULL"
+	}
+	if cond := b.where(search); cond != "TRUE" {
+		where += " AND " + cond
+	}
+	return "SELECT id FROM messages WHERE " + where + " ORDER BY id", b.args
+}
+
+// messageColumns are the columns of models.Message listed by the queries
+// built here, the raw source is left out
+const messageColumns = "id, inbox_id, folder_id, sender, receiver, subject, body, html_body, is_read, " +
+	"is_flagged, is_answered, is_deleted, keywords, size, created_at, updated_at"
+
+// ListMessagesByFilter returns a page of the messages of an inbox, or of
+// every inbox of a project, matching the filter. Messages are listed in the
+// order they were received, or with the most relevant matches of the
+// full-text query first when sorted by relevance.
+func (r *repository) ListMessagesByFilter(ctx context.Context, filter *models.MessageFilter, limit, offset int) ([]*models.Message, int, error) {
+	b := &searchBuilder{}
+	where, orderBy := b.filter(filter)
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM messages WHERE "+where, b.args...); err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	messages := []*models.Message{}
+	if total > 0 {
+		query := fmt.Sprintf("SELECT %s FROM messages WHERE %s ORDER BY %s LIMIT %s OFFSET %s",
+			messageColumns, where, orderBy, b.arg(limit), b.arg(offset))
+		if err := r.db.SelectContext(ctx, &messages, query, b.args...); err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return messages, total, nil
+}
+
+// filter compiles a models.MessageFilter into a WHERE clause and the ORDER BY
+// clause ranking the matches
+func (b *searchBuilder) filter(f *models.MessageFilter) (string, string) {
+	var conds []string
+	orderBy := "id"
+
+	switch {
+	case f.InboxID > 0:
+		conds = append(conds, fmt.Sprintf("inbox_id = %s", b.arg(f.InboxID)))
+	case f.ProjectID > 0:
+		conds = append(conds, fmt.Sprintf("inbox_id IN (SELECT id FROM inboxes WHERE project_id = %s)", b.arg(f.ProjectID)))
+	}
+
+	if f.AfterID > 0 {
+		conds = append(conds, fmt.Sprintf("id > %s", b.arg(f.AfterID)))
+	}
+	if f.Query != "" {
+		tsquery := fmt.Sprintf("websearch_to_tsquery('english', %s)", b.arg(f.Query))
+		conds = append(conds, "search_vector @@ "+tsquery)
+		// Following the messages stored after AfterID keeps them in the
+		// order they were stored. Ties in rank are broken by ID so pages
+		// do not overlap.
+		if f.Sort == models.MessageSortRelevance && f.AfterID == 0 {
+			orderBy = fmt.Sprintf("ts_rank(search_vector, %s) DESC, id DESC", tsquery)
+		}
+	}
+	if f.From != "" {
+		conds = append(conds, fmt.Sprintf("lower(sender) LIKE %s", b.arg(likePattern(strings.ToLower(f.From)))))
+	}
+	if f.To != "" {
+		conds = append(conds, fmt.Sprintf("lower(receiver) LIKE %s", b.arg(likePattern(strings.ToLower(f.To)))))
+	}
+	if f.Subject != "" {
+		conds = append(conds, fmt.Sprintf("subject ILIKE %s", b.like(f.Subject)))
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at >= %s", b.arg(f.Since)))
+	}
+	if !f.Before.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at < %s", b.arg(f.Before)))
+	}
+	if f.IsRead != nil {
+		conds = append(conds, fmt.Sprintf("is_read = %s", b.arg(*f.IsRead)))
+	}
+	if f.HasAttachments != nil {
+		exists := "EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = messages.id)"
+		if !*f.HasAttachments {
+			exists = "NOT " + exists
+		}
+		conds = append(conds, exists)
+	}
+
+	if f.ExcludeSent {
+		conds = append(conds, "NOT EXISTS (SELECT 1 FROM outbound_messages o WHERE o.message_id = messages.id)")
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", orderBy
+	}
+	return strings.Join(conds, " AND "), orderBy
+}
+
+// searchBuilder compiles a models.MessageSearch into a parameterized WHERE
+// clause, values are never interpolated into the SQL
+type searchBuilder struct {
+	args []interface{}
+}
+
+func (b *searchBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *searchBuilder) like(v string) string {
+	return b.arg("%" + escapeLike(v) + "%")
+}
+
+func (b *searchBuilder) where(s *models.MessageSearch) string {
+	if s == nil {
+		return "TRUE"
+	}
+	if s.MatchNone {
+		return "FALSE"
+	}
+
+	var conds []string
+	contains := func(columns []string, values []string) {
+		for _, v := range values {
+			p := b.like(v)
+			matches := make([]string, len(columns))
+			for i, column := range columns {
+				matches[i] = fmt.Sprintf("%s ILIKE %s", column, p)
+			}
+			conds = append(conds, b.or(matches...))
+		}
+	}
+
+	contains([]string{"sender"}, s.From)
+	contains([]string{"receiver"}, s.To)
+	contains([]string{"subject"}, s.Subject)
+	contains([]string{"body", "html_body"}, s.Body)
+	contains([]string{"sender", "receiver", "subject", "body", "html_body"}, s.Text)
+	contains([]string{"encode(COALESCE(raw, ''::bytea), 'escape')"}, s.Source)
+
+	if !s.Since.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at >= %s", b.arg(s.Since)))
+	}
+	if !s.Before.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at < %s", b.arg(s.Before)))
+	}
+	if s.Larger > 0 {
+		conds = append(conds, fmt.Sprintf("size > %s", b.arg(s.Larger)))
+	}
+	if s.Smaller > 0 {
+		conds = append(conds, fmt.Sprintf("size < %s", b.arg(s.Smaller)))
+	}
+
+	flag := func(column string, value *bool) {
+		if value != nil {
+			conds = append(conds, fmt.Sprintf("%s = %s", column, b.arg(*value)))
+		}
+	}
+	flag("is_read", s.IsRead)
+	flag("is_flagged", s.IsFlagged)
+	flag("is_answered", s.IsAnswered)
+	flag("is_deleted", s.IsDeleted)
+
+	for _, keyword := range s.Keywords {
+		conds = append(conds, b.keyword(keyword))
+	}
+	for _, keyword := range s.NotKeywords {
+		conds = append(conds, "NOT "+b.keyword(keyword))
+	}
+
+	if len(s.IDs) > 0 {
+		ranges := make([]string, len(s.IDs))
+		for i, r := range s.IDs {
+			switch {
+			case r.Stop == 0:
+				ranges[i] = fmt.Sprintf("id >= %s", b.arg(r.Start))
+			case r.Start == r.Stop:
+				ranges[i] = fmt.Sprintf("id = %s", b.arg(r.Start))
+			default:
+				ranges[i] = fmt.Sprintf("id BETWEEN %s AND %s", b.arg(r.Start), b.arg(r.Stop))
+			}
+		}
+		conds = append(conds, b.or(ranges...))
+	}
+
+	for _, not := range s.Not {
+		conds = append(conds, fmt.Sprintf("NOT (%s)", b.where(not)))
+	}
+	for _, or := range s.Or {
+		conds = append(conds, b.or(b.where(or[0]), b.where(or[1])))
+	}
+
+	return b.and(conds...)
+}
+
+func (b *searchBuilder) keyword(keyword string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(keywords) k WHERE lower(k) = lower(%s))", b.arg(keyword))
+}
+
+func (b *searchBuilder) and(conds ...string) string {
+	switch len(conds) {
+	case 0:
+		return "TRUE"
+	case 1:
+		return conds[0]
+	}
+	return "(" + strings.Join(conds, " AND ") + ")"
+}
+
+func (b *searchBuilder) or(conds ...string) string {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+// escapeLike escapes the LIKE wildcards so values are matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
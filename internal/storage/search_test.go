@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	yes, no := true, false
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		search    *models.MessageSearch
+		folderID  null.Int
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "no criteria",
+			search:    &models.MessageSearch{},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL ORDER BY id",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "folder",
+			search:    &models.MessageSearch{},
+			folderID:  null.IntFrom(3),
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id = $2 ORDER BY id",
+			wantArgs:  []interface{}{1, 3},
+		},
+		{
+			name: "address, subject and date",
+			search: &models.MessageSearch{
+				From:    []string{"alice"},
+				Subject: []string{"50%_off"},
+				Since:   since,
+			},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL AND " +
+				"(sender ILIKE $2 AND subject ILIKE $3 AND created_at >= $4) ORDER BY id",
+			wantArgs: []interface{}{1, "%alice%", `%50\%\_off%`, since},
+		},
+		{
+			name:      "body matches both bodies",
+			search:    &models.MessageSearch{Body: []string{"hello"}},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL AND (body ILIKE $2 OR html_body ILIKE $2) ORDER BY id",
+			wantArgs:  []interface{}{1, "%hello%"},
+		},
+		{
+			name: "flags and keywords",
+			search: &models.MessageSearch{
+				IsRead:      &no,
+				IsFlagged:   &yes,
+				NotKeywords: []string{"$Junk"},
+			},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL AND " +
+				"(is_read = $2 AND is_flagged = $3 AND " +
+				"NOT EXISTS (SELECT 1 FROM unnest(keywords) k WHERE lower(k) = lower($4))) ORDER BY id",
+			wantArgs: []interface{}{1, false, true, "$Junk"},
+		},
+		{
+			name: "id ranges",
+			search: &models.MessageSearch{
+				IDs: []models.IDRange{{Start: 3, Stop: 3}, {Start: 5, Stop: 9}, {Start: 20}},
+			},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL AND " +
+				"(id = $2 OR id BETWEEN $3 AND $4 OR id >= $5) ORDER BY id",
+			wantArgs: []interface{}{1, 3, 5, 9, 20},
+		},
+		{
+			name: "not and or",
+			search: &models.MessageSearch{
+				Not: []*models.MessageSearch{{IsDeleted: &yes}},
+				Or: [][2]*models.MessageSearch{{
+					{To: []string{"a@example.com"}},
+					{MatchNone: true},
+				}},
+			},
+			wantQuery: "SELECT id FROM messages WHERE inbox_id = $1 AND folder_id IS NULL AND " +
+				"(NOT (is_deleted = $2) AND (receiver ILIKE $3 OR FALSE)) ORDER BY id",
+			wantArgs: []interface{}{1, true, "%a@example.com%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildSearchQuery(1, tt.folderID, tt.search)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestRepository_SearchMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "matching messages",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id FROM messages WHERE inbox_id = \\$1 AND folder_id IS NULL AND subject ILIKE \\$2 ORDER BY id").
+					WithArgs(1, "%report%").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(7))
+			},
+			want:    []int{4, 7},
+			wantErr: false,
+		},
+		{
+			name: "database error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT id FROM messages").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+
+			repo := &repository{db: sqlx.NewDb(mockDB, "sqlmock"), queries: &Queries{}}
+			tt.mockFn(mock)
+
+			got, err := repo.SearchMessages(context.Background(), 1, null.Int{}, &models.MessageSearch{Subject: []string{"report"}})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSearchBuilder_Filter(t *testing.T) {
+	yes, no := true, false
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		filter      *models.MessageFilter
+		wantWhere   string
+		wantOrderBy string
+		wantArgs    []interface{}
+	}{
+		{
+			name:        "inbox",
+			filter:      &models.MessageFilter{InboxID: 1, IsRead: &no},
+			wantWhere:   "inbox_id = $1 AND is_read = $2",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1, false},
+		},
+		{
+			name:   "project with full-text query",
+			filter: &models.MessageFilter{ProjectID: 2, Query: `"build failed" -flaky`},
+			wantWhere: "inbox_id IN (SELECT id FROM inboxes WHERE project_id = $1) AND " +
+				"search_vector @@ websearch_to_tsquery('english', $2)",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{2, `"build failed" -flaky`},
+		},
+		{
+			name:        "full-text query by relevance",
+			filter:      &models.MessageFilter{InboxID: 1, Query: "deploy", Sort: models.MessageSortRelevance},
+			wantWhere:   "inbox_id = $1 AND search_vector @@ websearch_to_tsquery('english', $2)",
+			wantOrderBy: "ts_rank(search_vector, websearch_to_tsquery('english', $2)) DESC, id DESC",
+			wantArgs:    []interface{}{1, "deploy"},
+		},
+		{
+			name:        "received messages after a message",
+			filter:      &models.MessageFilter{InboxID: 1, AfterID: 6, ExcludeSent: true},
+			wantWhere:   "inbox_id = $1 AND id > $2 AND NOT EXISTS (SELECT 1 FROM outbound_messages o WHERE o.message_id = messages.id)",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1, 6},
+		},
+		{
+			name:        "relevance without full-text query",
+			filter:      &models.MessageFilter{InboxID: 1, Sort: models.MessageSortRelevance},
+			wantWhere:   "inbox_id = $1",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1},
+		},
+		{
+			name: "structured filters",
+			filter: &models.MessageFilter{
+				InboxID:        1,
+				From:           "*@CI.example.com",
+				To:             "qa+run_1@example.com",
+				Subject:        "50%",
+				Since:          since,
+				HasAttachments: &yes,
+			},
+			wantWhere: "inbox_id = $1 AND lower(sender) LIKE $2 AND lower(receiver) LIKE $3 AND " +
+				"subject ILIKE $4 AND created_at >= $5 AND " +
+				"EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = messages.id)",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1, "%@ci.example.com", `qa+run\_1@example.com`, `%50\%%`, since},
+		},
+		{
+			name:        "without attachments",
+			filter:      &models.MessageFilter{InboxID: 1, HasAttachments: &no},
+			wantWhere:   "inbox_id = $1 AND NOT EXISTS (SELECT 1 FROM attachments a WHERE a.message_id = messages.id)",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1},
+		},
+		{
+			name:        "after a message",
+			filter:      &models.MessageFilter{InboxID: 1, AfterID: 42, Query: "welcome"},
+			wantWhere:   "inbox_id = $1 AND id > $2 AND search_vector @@ websearch_to_tsquery('english', $3)",
+			wantOrderBy: "id",
+			wantArgs:    []interface{}{1, 42, "welcome"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &searchBuilder{}
+			where, orderBy := b.filter(tt.filter)
+			assert.Equal(t, tt.wantWhere, where)
+			assert.Equal(t, tt.wantOrderBy, orderBy)
+			assert.Equal(t, tt.wantArgs, b.args)
+		})
+	}
+}
+
+func TestRepository_ListMessagesByFilter(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		filter    *models.MessageFilter
+		wantOrder string
+	}{
+		{
+			name:      "full-text query in the order received",
+			filter:    &models.MessageFilter{ProjectID: 2, Query: "invoice"},
+			wantOrder: "ORDER BY id LIMIT",
+		},
+		{
+			name:      "full-text query by relevance",
+			filter:    &models.MessageFilter{ProjectID: 2, Query: "invoice", Sort: models.MessageSortRelevance},
+			wantOrder: "ORDER BY ts_rank(.+) DESC, id DESC LIMIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer mockDB.Close()
+
+			repo := &repository{db: sqlx.NewDb(mockDB, "sqlmock"), queries: &Queries{}}
+
+			// Every page is listed in the same total order, so paging
+			// through the results neither repeats nor skips messages
+			for _, offset := range []int{0, 10} {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM messages WHERE inbox_id IN (.+) AND search_vector @@").
+					WithArgs(2, "invoice").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+				mock.ExpectQuery("SELECT (.+) FROM messages WHERE (.+) "+tt.wantOrder+" \\$3 OFFSET \\$4").
+					WithArgs(2, "invoice", 10, offset).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "inbox_id", "sender", "receiver", "subject", "created_at"}).
+						AddRow(5+offset, 3, "billing@example.com", "qa@example.com", "Your invoice", now))
+
+				got, total, err := repo.ListMessagesByFilter(context.Background(), tt.filter, 10, offset)
+				require.NoError(t, err)
+				assert.Equal(t, 11, total)
+				require.Len(t, got, 1)
+				assert.Equal(t, 3, got[0].InboxID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
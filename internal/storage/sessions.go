@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"inbox451/internal/models"
+)
+
+func (r *repository) CreateSession(ctx context.Context, session *models.Session) error {
+	err := r.queries.CreateSession.QueryRowxContext(ctx,
+		session.SessionID, session.UserID, session.ExpiresAt, session.IPAddress, session.UserAgent).
+		Scan(&session.ID, &session.Data, &session.CreatedAt, &session.LastAccessedAt, &session.IsActive)
+	return handleDBError(err)
+}
+
+// GetSession returns a session by the secret session ID of its cookie,
+// whether or not it is still active
+func (r *repository) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	var session models.Session
+	err := r.queries.GetSession.GetContext(ctx, &session, sessionID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &session, nil
+}
+
+// TouchSession records that a session has just been used
+func (r *repository) TouchSession(ctx context.Context, id int) error {
+	result, err := r.queries.TouchSession.ExecContext(ctx, id)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// DeactivateSession ends a session, the row is kept until it expires
+func (r *repository) DeactivateSession(ctx context.Context, sessionID string) error {
+	result, err := r.queries.DeactivateSession.ExecContext(ctx, sessionID)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// ListSessionsByUser returns a page of the active, unexpired sessions of a
+// user, most recently used first
+func (r *repository) ListSessionsByUser(ctx context.Context, userID, limit, offset int) ([]*models.Session, int, error) {
+	var total int
+	err := r.queries.CountSessionsByUser.GetContext(ctx, &total, userID)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	sessions := []*models.Session{}
+	if total > 0 {
+		err = r.queries.ListSessionsByUser.SelectContext(ctx, &sessions, userID, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return sessions, total, nil
+}
+
+// RevokeSession ends an active session of a user
+func (r *repository) RevokeSession(ctx context.Context, id, userID int) error {
+	result, err := r.queries.RevokeSession.ExecContext(ctx, id, userID)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// RevokeSessionsByUser ends every active session of a user and returns how
+// many were ended
+func (r *repository) RevokeSessionsByUser(ctx context.Context, userID int) (int, error) {
+	result, err := r.queries.RevokeSessionsByUser.ExecContext(ctx, userID)
+	if err != nil {
+		return 0, handleDBError(err)
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// DeleteExpiredSessions removes the sessions that expired before the given
+// time and returns how many were removed
+func (r *repository) DeleteExpiredSessions(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.queries.DeleteExpiredSessions.ExecContext(ctx, before)
+	if err != nil {
+		return 0, handleDBError(err)
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
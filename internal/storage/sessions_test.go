@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("INSERT INTO sessions")                                   // CreateSession
+	mock.ExpectPrepare("SELECT (.+) FROM sessions WHERE session_id")             // GetSession
+	mock.ExpectPrepare("UPDATE sessions SET last_accessed_at")                   // TouchSession
+	mock.ExpectPrepare("UPDATE sessions SET is_active = false WHERE session_id") // DeactivateSession
+	mock.ExpectPrepare("SELECT (.+) FROM sessions WHERE user_id")                // ListSessionsByUser
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM sessions WHERE user_id")           // CountSessionsByUser
+	mock.ExpectPrepare("UPDATE sessions SET is_active = false WHERE id")         // RevokeSession
+	mock.ExpectPrepare("UPDATE sessions SET is_active = false WHERE user_id")    // RevokeSessionsByUser
+	mock.ExpectPrepare("DELETE FROM sessions WHERE expires_at")                  // DeleteExpiredSessions
+
+	createSession, err := sqlxDB.Preparex("INSERT INTO sessions (session_id, user_id, expires_at, ip_address, user_agent) VALUES (?, ?, ?, ?, ?) RETURNING id, data, created_at, last_accessed_at, is_active")
+	require.NoError(t, err)
+
+	getSession, err := sqlxDB.Preparex("SELECT id, session_id, user_id, data, created_at, expires_at, last_accessed_at, ip_address, user_agent, is_active FROM sessions WHERE session_id = ?")
+	require.NoError(t, err)
+
+	touchSession, err := sqlxDB.Preparex("UPDATE sessions SET last_accessed_at = CURRENT_TIMESTAMP WHERE id = ?")
+	require.NoError(t, err)
+
+	deactivateSession, err := sqlxDB.Preparex("UPDATE sessions SET is_active = false WHERE session_id = ?")
+	require.NoError(t, err)
+
+	listSessionsByUser, err := sqlxDB.Preparex("SELECT id, user_id, data, created_at, expires_at, last_accessed_at, ip_address, user_agent, is_active FROM sessions WHERE user_id = ? LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	countSessionsByUser, err := sqlxDB.Preparex("SELECT COUNT(*) FROM sessions WHERE user_id = ?")
+	require.NoError(t, err)
+
+	revokeSession, err := sqlxDB.Preparex("UPDATE sessions SET is_active = false WHERE id = ? AND user_id = ?")
+	require.NoError(t, err)
+
+	revokeSessionsByUser, err := sqlxDB.Preparex("UPDATE sessions SET is_active = false WHERE user_id = ?")
+	require.NoError(t, err)
+
+	deleteExpiredSessions, err := sqlxDB.Preparex("DELETE FROM sessions WHERE expires_at < ?")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		CreateSession:         createSession,
+		GetSession:            getSession,
+		TouchSession:          touchSession,
+		DeactivateSession:     deactivateSession,
+		ListSessionsByUser:    listSessionsByUser,
+		CountSessionsByUser:   countSessionsByUser,
+		RevokeSession:         revokeSession,
+		RevokeSessionsByUser:  revokeSessionsByUser,
+		DeleteExpiredSessions: deleteExpiredSessions,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+func TestRepository_CreateSession(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "successful creation",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO sessions").
+					WithArgs("secret", 1, expiresAt, "127.0.0.1", "curl/8.0").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "data", "created_at", "last_accessed_at", "is_active"}).
+						AddRow(5, []byte("{}"), now, now, true))
+			},
+			wantErr: false,
+		},
+		{
+			name: "database error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO sessions").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupSessionTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			session := &models.Session{
+				SessionID: "secret",
+				UserID:    1,
+				ExpiresAt: expiresAt,
+				IPAddress: "127.0.0.1",
+				UserAgent: "curl/8.0",
+			}
+			err := repo.CreateSession(context.Background(), session)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 5, session.ID)
+			assert.True(t, session.IsActive)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_GetSession(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "session found",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM sessions WHERE session_id").
+					WithArgs("secret").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "user_id", "data", "created_at", "expires_at", "last_accessed_at", "ip_address", "user_agent", "is_active"}).
+						AddRow(5, "secret", 1, []byte("{}"), now, now.Add(time.Hour), now, "127.0.0.1", "curl/8.0", true))
+			},
+		},
+		{
+			name: "unknown session",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM sessions WHERE session_id").
+					WithArgs("secret").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupSessionTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetSession(context.Background(), "secret")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 5, got.ID)
+			assert.Equal(t, 1, got.UserID)
+			assert.Equal(t, "curl/8.0", got.UserAgent)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_TouchSession(t *testing.T) {
+	repo, mock := setupSessionTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("UPDATE sessions SET last_accessed_at").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.TouchSession(context.Background(), 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeactivateSession(t *testing.T) {
+	repo, mock := setupSessionTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("UPDATE sessions SET is_active = false WHERE session_id").
+		WithArgs("secret").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sessions SET is_active = false WHERE session_id").
+		WithArgs("unknown").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeactivateSession(context.Background(), "secret"))
+	assert.ErrorIs(t, repo.DeactivateSession(context.Background(), "unknown"), ErrNoRowsAffected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ListSessionsByUser(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		mockFn    func(sqlmock.Sqlmock)
+		wantCount int
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name: "sessions found",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM sessions WHERE user_id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM sessions WHERE user_id").
+					WithArgs(1, 10, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "data", "created_at", "expires_at", "last_accessed_at", "ip_address", "user_agent", "is_active"}).
+						AddRow(2, 1, []byte("{}"), now, now.Add(time.Hour), now, "127.0.0.1", "curl/8.0", true).
+						AddRow(1, 1, []byte("{}"), now, now.Add(time.Hour), now, "10.0.0.1", "Firefox", true))
+			},
+			wantCount: 2,
+			wantTotal: 2,
+		},
+		{
+			name: "no sessions",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM sessions WHERE user_id").
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+		},
+		{
+			name: "database error",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT(.+) FROM sessions WHERE user_id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupSessionTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			sessions, total, err := repo.ListSessionsByUser(context.Background(), 1, 10, 0)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, sessions, tt.wantCount)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_RevokeSession(t *testing.T) {
+	repo, mock := setupSessionTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("UPDATE sessions SET is_active = false WHERE id").
+		WithArgs(5, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE sessions SET is_active = false WHERE id").
+		WithArgs(5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.RevokeSession(context.Background(), 5, 1))
+	assert.ErrorIs(t, repo.RevokeSession(context.Background(), 5, 2), ErrNoRowsAffected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RevokeSessionsByUser(t *testing.T) {
+	repo, mock := setupSessionTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("UPDATE sessions SET is_active = false WHERE user_id").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	revoked, err := repo.RevokeSessionsByUser(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteExpiredSessions(t *testing.T) {
+	repo, mock := setupSessionTestDB(t)
+	defer repo.db.Close()
+
+	now := time.Now()
+	mock.ExpectExec("DELETE FROM sessions WHERE expires_at").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeleteExpiredSessions(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -32,6 +32,15 @@ func (r *repository) GetTokenByUser(ctx context.Context, token_id int, user_id i
 	return &token, handleDBError(err)
 }
 
+func (r *repository) GetTokenByValue(ctx context.Context, value string) (*models.Token, error) {
+	var token models.Token
+	err := r.queries.GetTokenByValue.GetContext(ctx, &token, value)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &token, nil
+}
+
 func (r *repository) CreateToken(ctx context.Context, token *models.Token) error {
 	err := r.queries.CreateToken.QueryRowContext(
 		ctx,
@@ -51,6 +60,15 @@ func (r *repository) CreateToken(ctx context.Context, token *models.Token) error
 	return handleDBError(err)
 }
 
+// UpdateTokenLastUsed records that a token has just been used to authenticate
+func (r *repository) UpdateTokenLastUsed(ctx context.Context, tokenID int) error {
+	result, err := r.queries.UpdateTokenLastUsed.ExecContext(ctx, tokenID)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
 func (r *repository) DeleteToken(ctx context.Context, tokenID int) error {
 	result, err := r.queries.DeleteToken.ExecContext(ctx, tokenID)
 	if err != nil {
@@ -26,6 +26,8 @@ func setupTokenTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	mock.ExpectPrepare("SELECT (.+) FROM tokens WHERE id")           // GetTokenByUser
 	mock.ExpectPrepare("INSERT INTO tokens")                         // CreateToken
 	mock.ExpectPrepare("DELETE FROM tokens")                         // DeleteToken
+	mock.ExpectPrepare("SELECT (.+) FROM tokens WHERE token")        // GetTokenByValue
+	mock.ExpectPrepare("UPDATE tokens SET last_used_at")             // UpdateTokenLastUsed
 
 	listTokens, err := sqlxDB.Preparex("SELECT id, user_id, token, name, expires_at, created_at, updated_at FROM tokens WHERE user_id = ? ORDER BY id LIMIT ? OFFSET ?")
 	require.NoError(t, err)
@@ -42,12 +44,20 @@ func setupTokenTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	deleteToken, err := sqlxDB.Preparex("DELETE FROM tokens WHERE id = ?")
 	require.NoError(t, err)
 
+	getTokenByValue, err := sqlxDB.Preparex("SELECT id, user_id, token, name, expires_at, created_at, updated_at FROM tokens WHERE token = ?")
+	require.NoError(t, err)
+
+	updateTokenLastUsed, err := sqlxDB.Preparex("UPDATE tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?")
+	require.NoError(t, err)
+
 	queries := &Queries{
-		ListTokensByUser:  listTokens,
-		CountTokensByUser: countTokens,
-		GetTokenByUser:    getToken,
-		CreateToken:       createToken,
-		DeleteToken:       deleteToken,
+		ListTokensByUser:    listTokens,
+		CountTokensByUser:   countTokens,
+		GetTokenByUser:      getToken,
+		CreateToken:         createToken,
+		DeleteToken:         deleteToken,
+		GetTokenByValue:     getTokenByValue,
+		UpdateTokenLastUsed: updateTokenLastUsed,
 	}
 
 	repo := &repository{
@@ -209,6 +219,81 @@ func TestRepository_GetTokenByUser(t *testing.T) {
 	}
 }
 
+func TestRepository_GetTokenByValue(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		value   string
+		mockFn  func(sqlmock.Sqlmock)
+		want    *models.Token
+		wantErr bool
+		errType error
+	}{
+		{
+			name:  "existing token",
+			value: "test-token",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"id", "user_id", "token", "name",
+					"expires_at", "created_at", "updated_at",
+				}).AddRow(1, 2, "test-token", "Test Token", nil, now, now)
+
+				mock.ExpectQuery("SELECT (.+) FROM tokens WHERE token").
+					WithArgs("test-token").
+					WillReturnRows(rows)
+			},
+			want: &models.Token{
+				Base: models.Base{
+					ID:        1,
+					CreatedAt: null.TimeFrom(now),
+					UpdatedAt: null.TimeFrom(now),
+				},
+				UserID: 2,
+				Token:  "test-token",
+				Name:   "Test Token",
+			},
+			wantErr: false,
+		},
+		{
+			name:  "unknown token",
+			value: "missing",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM tokens WHERE token").
+					WithArgs("missing").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: true,
+			errType: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupTokenTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			got, err := repo.GetTokenByValue(context.Background(), tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.errType != nil {
+					assert.ErrorIs(t, err, tt.errType)
+				}
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestRepository_DeleteToken(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -259,6 +344,56 @@ func TestRepository_DeleteToken(t *testing.T) {
 	}
 }
 
+func TestRepository_UpdateTokenLastUsed(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokenID int
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name:    "successful update",
+			tokenID: 1,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE tokens SET last_used_at").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name:    "non-existent token",
+			tokenID: 999,
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE tokens SET last_used_at").
+					WithArgs(999).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupTokenTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			err := repo.UpdateTokenLastUsed(context.Background(), tt.tokenID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			err = mock.ExpectationsWereMet()
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestRepository_ListTokensByUser(t *testing.T) {
 	now := time.Now()
 	expiresAt := now.Add(24 * time.Hour)
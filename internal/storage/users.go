@@ -69,6 +69,15 @@ func (r *repository) UpdateUser(ctx context.Context, user *models.User) error {
 		Scan(&user.UpdatedAt)
 }
 
+// UpdateUserLoggedInAt records a successful login of the user
+func (r *repository) UpdateUserLoggedInAt(ctx context.Context, userID int) error {
+	result, err := r.queries.UpdateUserLoggedInAt.ExecContext(ctx, userID)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
 func (r *repository) DeleteUser(ctx context.Context, id int) error {
 	result, err := r.queries.DeleteUser.ExecContext(ctx, id)
 	if err != nil {
@@ -28,6 +28,7 @@ func setupTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	mock.ExpectPrepare("INSERT INTO users")                     // CreateUser
 	mock.ExpectPrepare("UPDATE users")                          // UpdateUser
 	mock.ExpectPrepare("DELETE FROM users")                     // DeleteUser
+	mock.ExpectPrepare("UPDATE users SET loggedin_at")          // UpdateUserLoggedInAt
 
 	listUsers, err := sqlxDB.Preparex("SELECT id, name, username, password, email, status, role, loggedin_at, created_at, updated_at FROM users ORDER BY id LIMIT ? OFFSET ?")
 	require.NoError(t, err)
@@ -50,14 +51,18 @@ func setupTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
 	deleteUser, err := sqlxDB.Preparex("DELETE FROM users WHERE id = ?")
 	require.NoError(t, err)
 
+	updateUserLoggedInAt, err := sqlxDB.Preparex("UPDATE users SET loggedin_at = CURRENT_TIMESTAMP WHERE id = ?")
+	require.NoError(t, err)
+
 	queries := &Queries{
-		ListUsers:         listUsers,
-		CountUsers:        countUsers,
-		GetUser:           getUser,
-		GetUserByUsername: getUserByUsername,
-		CreateUser:        createUser,
-		UpdateUser:        updateUser,
-		DeleteUser:        deleteUser,
+		ListUsers:            listUsers,
+		CountUsers:           countUsers,
+		GetUser:              getUser,
+		GetUserByUsername:    getUserByUsername,
+		CreateUser:           createUser,
+		UpdateUser:           updateUser,
+		DeleteUser:           deleteUser,
+		UpdateUserLoggedInAt: updateUserLoggedInAt,
 	}
 
 	repo := &repository{
@@ -578,3 +583,19 @@ func TestRepository_GetUserByUsername(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_UpdateUserLoggedInAt(t *testing.T) {
+	repo, mock := setupTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectExec("UPDATE users SET loggedin_at").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET loggedin_at").
+		WithArgs(999).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdateUserLoggedInAt(context.Background(), 1))
+	assert.ErrorIs(t, repo.UpdateUserLoggedInAt(context.Background(), 999), ErrNoRowsAffected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
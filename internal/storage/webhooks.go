@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"inbox451/internal/models"
+)
+
+func (r *repository) ListWebhooksByProject(ctx context.Context, projectID, limit, offset int) ([]*models.Webhook, int, error) {
+	var total int
+	err := r.queries.CountWebhooksByProject.GetContext(ctx, &total, projectID)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	webhooks := []*models.Webhook{}
+	if total > 0 {
+		err = r.queries.ListWebhooksByProject.SelectContext(ctx, &webhooks, projectID, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return webhooks, total, nil
+}
+
+func (r *repository) GetWebhook(ctx context.Context, id int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.queries.GetWebhook.GetContext(ctx, &webhook, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &webhook, nil
+}
+
+func (r *repository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	err := r.queries.CreateWebhook.QueryRowContext(ctx,
+		webhook.ProjectID, webhook.InboxID, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.IsActive).
+		Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	return handleDBError(err)
+}
+
+func (r *repository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	err := r.queries.UpdateWebhook.QueryRowContext(ctx,
+		webhook.InboxID, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.IsActive, webhook.ID).
+		Scan(&webhook.UpdatedAt)
+	return handleDBError(err)
+}
+
+func (r *repository) DeleteWebhook(ctx context.Context, id int) error {
+	result, err := r.queries.DeleteWebhook.ExecContext(ctx, id)
+	if err != nil {
+		return handleDBError(err)
+	}
+	return handleRowsAffected(result)
+}
+
+// ListActiveWebhooksByInbox returns the active webhooks receiving the events
+// of an inbox, those of the inbox itself and those of its whole project
+func (r *repository) ListActiveWebhooksByInbox(ctx context.Context, inboxID int) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	err := r.queries.ListActiveWebhooksByInbox.SelectContext(ctx, &webhooks, inboxID)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return webhooks, nil
+}
+
+// CreateWebhookDelivery queues a delivery, it is due right away
+func (r *repository) CreateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	err := r.queries.CreateWebhookDelivery.QueryRowContext(ctx,
+		delivery.WebhookID, delivery.EventType, delivery.MessageID, string(delivery.Payload)).
+		Scan(&delivery.ID, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt,
+			&delivery.CreatedAt, &delivery.UpdatedAt)
+	return handleDBError(err)
+}
+
+func (r *repository) GetWebhookDelivery(ctx context.Context, id int) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.queries.GetWebhookDelivery.GetContext(ctx, &delivery, id)
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return &delivery, nil
+}
+
+// ListWebhookDeliveries returns a page of the deliveries of a webhook, most
+// recent first. An empty status lists deliveries of every status.
+func (r *repository) ListWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*models.WebhookDelivery, int, error) {
+	var total int
+	err := r.queries.CountWebhookDeliveries.GetContext(ctx, &total, webhookID, status)
+	if err != nil {
+		return nil, 0, handleDBError(err)
+	}
+
+	deliveries := []*models.WebhookDelivery{}
+	if total > 0 {
+		err = r.queries.ListWebhookDeliveries.SelectContext(ctx, &deliveries, webhookID, status, limit, offset)
+		if err != nil {
+			return nil, 0, handleDBError(err)
+		}
+	}
+
+	return deliveries, total, nil
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries that are due
+// and postpones their next attempt by lease, so that no other dispatcher
+// picks them up while they are being sent. A delivery whose dispatcher
+// stops before recording the outcome is attempted again once the lease
+// expires.
+func (r *repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	err := r.queries.ClaimWebhookDeliveries.SelectContext(ctx, &deliveries, limit, lease.Seconds())
+	if err != nil {
+		return nil, handleDBError(err)
+	}
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery records the outcome of an attempt
+func (r *repository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	err := r.queries.UpdateWebhookDelivery.QueryRowContext(ctx,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.ID).
+		Scan(&delivery.UpdatedAt)
+	return handleDBError(err)
+}
+
+// ReplayWebhookDelivery queues a delivery again with a fresh set of
+// attempts. Deliveries that are still pending are left alone and reported
+// as ErrNotFound.
+func (r *repository) ReplayWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	err := r.queries.ReplayWebhookDelivery.QueryRowContext(ctx, delivery.ID).
+		Scan(&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.ResponseStatus,
+			&delivery.LastError, &delivery.UpdatedAt)
+	return handleDBError(err)
+}
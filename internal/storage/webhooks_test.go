@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"inbox451/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	null "github.com/volatiletech/null/v9"
+)
+
+func setupWebhookTestDB(t *testing.T) (*repository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	mock.ExpectPrepare("INSERT INTO webhooks")                                 // CreateWebhook
+	mock.ExpectPrepare("SELECT (.+) FROM webhooks w JOIN inboxes")             // ListActiveWebhooksByInbox
+	mock.ExpectPrepare("INSERT INTO webhook_deliveries")                       // CreateWebhookDelivery
+	mock.ExpectPrepare("SELECT COUNT(.+) FROM webhook_deliveries")             // CountWebhookDeliveries
+	mock.ExpectPrepare("SELECT (.+) FROM webhook_deliveries WHERE webhook_id") // ListWebhookDeliveries
+	mock.ExpectPrepare("UPDATE webhook_deliveries SET next_attempt_at")        // ClaimWebhookDeliveries
+	mock.ExpectPrepare("UPDATE webhook_deliveries SET status = \\?, attempts") // UpdateWebhookDelivery
+	mock.ExpectPrepare("UPDATE webhook_deliveries SET status = 'pending'")     // ReplayWebhookDelivery
+
+	createWebhook, err := sqlxDB.Preparex("INSERT INTO webhooks (project_id, inbox_id, url, secret, event_types, is_active) VALUES (?, ?, ?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	listActiveWebhooks, err := sqlxDB.Preparex("SELECT w.id, w.project_id, w.inbox_id, w.url, w.secret, w.event_types, w.is_active, w.created_at, w.updated_at FROM webhooks w JOIN inboxes i ON i.project_id = w.project_id WHERE i.id = ?")
+	require.NoError(t, err)
+
+	createDelivery, err := sqlxDB.Preparex("INSERT INTO webhook_deliveries (webhook_id, event_type, message_id, payload) VALUES (?, ?, ?, ?)")
+	require.NoError(t, err)
+
+	countDeliveries, err := sqlxDB.Preparex("SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ? AND (? = '' OR status = ?)")
+	require.NoError(t, err)
+
+	listDeliveries, err := sqlxDB.Preparex("SELECT id, webhook_id, event_type, message_id, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, updated_at FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ? OFFSET ?")
+	require.NoError(t, err)
+
+	claimDeliveries, err := sqlxDB.Preparex("UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => ?) WHERE id IN (SELECT id FROM webhook_deliveries LIMIT ? FOR UPDATE SKIP LOCKED)")
+	require.NoError(t, err)
+
+	updateDelivery, err := sqlxDB.Preparex("UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, response_status = ?, last_error = ? WHERE id = ?")
+	require.NoError(t, err)
+
+	replayDelivery, err := sqlxDB.Preparex("UPDATE webhook_deliveries SET status = 'pending', attempts = 0 WHERE id = ? AND status <> 'pending'")
+	require.NoError(t, err)
+
+	queries := &Queries{
+		CreateWebhook:             createWebhook,
+		ListActiveWebhooksByInbox: listActiveWebhooks,
+		CreateWebhookDelivery:     createDelivery,
+		CountWebhookDeliveries:    countDeliveries,
+		ListWebhookDeliveries:     listDeliveries,
+		ClaimWebhookDeliveries:    claimDeliveries,
+		UpdateWebhookDelivery:     updateDelivery,
+		ReplayWebhookDelivery:     replayDelivery,
+	}
+
+	repo := &repository{
+		db:      sqlxDB,
+		queries: queries,
+	}
+
+	return repo, mock
+}
+
+var (
+	webhookColumns  = []string{"id", "project_id", "inbox_id", "url", "secret", "event_types", "is_active", "created_at", "updated_at"}
+	deliveryColumns = []string{"id", "webhook_id", "event_type", "message_id", "payload", "status", "attempts",
+		"next_attempt_at", "response_status", "last_error", "created_at", "updated_at"}
+)
+
+func TestRepository_CreateWebhook(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	webhook := &models.Webhook{
+		ProjectID:  1,
+		InboxID:    null.IntFrom(2),
+		URL:        "https://ci.example.com/hooks/mail",
+		Secret:     "s3cret",
+		EventTypes: pq.StringArray{"message.created"},
+		IsActive:   true,
+	}
+
+	mock.ExpectQuery("INSERT INTO webhooks").
+		WithArgs(1, null.IntFrom(2), webhook.URL, "s3cret", webhook.EventTypes, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+
+	require.NoError(t, repo.CreateWebhook(context.Background(), webhook))
+	assert.Equal(t, 3, webhook.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ListActiveWebhooksByInbox(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM webhooks w JOIN inboxes").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(3, 1, nil, "https://ci.example.com/a", "s1", "{message.created}", true, now, now).
+			AddRow(4, 1, 2, "https://ci.example.com/b", "s2", "{message.created,message.deleted}", true, now, now))
+
+	webhooks, err := repo.ListActiveWebhooksByInbox(context.Background(), 2)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.False(t, webhooks[0].InboxID.Valid)
+	assert.Equal(t, pq.StringArray{"message.created", "message.deleted"}, webhooks[1].EventTypes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreateWebhookDelivery(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: 3,
+		EventType: "message.created",
+		MessageID: null.IntFrom(7),
+		Payload:   json.RawMessage(`{"event":"message.created"}`),
+	}
+
+	mock.ExpectQuery("INSERT INTO webhook_deliveries").
+		WithArgs(3, "message.created", null.IntFrom(7), `{"event":"message.created"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "attempts", "next_attempt_at", "created_at", "updated_at"}).
+			AddRow(9, "pending", 0, now, now, now))
+
+	require.NoError(t, repo.CreateWebhookDelivery(context.Background(), delivery))
+	assert.Equal(t, 9, delivery.ID)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ListWebhookDeliveries(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM webhook_deliveries").
+		WithArgs(3, "failed").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE webhook_id").
+		WithArgs(3, "failed", 10, 0).
+		WillReturnRows(sqlmock.NewRows(deliveryColumns).
+			AddRow(9, 3, "message.created", 7, []byte(`{"event":"message.created"}`), "failed", 8, now, 500, "HTTP 500", now, now))
+
+	deliveries, total, err := repo.ListWebhookDeliveries(context.Background(), 3, "failed", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, deliveries, 1)
+	assert.JSONEq(t, `{"event":"message.created"}`, string(deliveries[0].Payload))
+	assert.Equal(t, null.IntFrom(500), deliveries[0].ResponseStatus)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimWebhookDeliveries(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	mock.ExpectQuery("UPDATE webhook_deliveries SET next_attempt_at").
+		WithArgs(20, float64(60)).
+		WillReturnRows(sqlmock.NewRows(deliveryColumns).
+			AddRow(9, 3, "message.created", 7, []byte(`{}`), "pending", 1, now, nil, nil, now, now))
+
+	deliveries, err := repo.ClaimWebhookDeliveries(context.Background(), 20, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UpdateWebhookDelivery(t *testing.T) {
+	now := time.Now()
+
+	repo, mock := setupWebhookTestDB(t)
+	defer repo.db.Close()
+
+	delivery := &models.WebhookDelivery{
+		Base:           models.Base{ID: 9},
+		Status:         models.WebhookDeliveryPending,
+		Attempts:       2,
+		NextAttemptAt:  null.TimeFrom(now),
+		ResponseStatus: null.IntFrom(503),
+		LastError:      null.StringFrom("HTTP 503"),
+	}
+
+	mock.ExpectQuery("UPDATE webhook_deliveries SET status = \\?, attempts").
+		WithArgs("pending", 2, null.TimeFrom(now), null.IntFrom(503), null.StringFrom("HTTP 503"), 9).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+	require.NoError(t, repo.UpdateWebhookDelivery(context.Background(), delivery))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ReplayWebhookDelivery(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		mockFn  func(sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "failed delivery",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE webhook_deliveries SET status = 'pending'").
+					WithArgs(9).
+					WillReturnRows(sqlmock.NewRows([]string{"status", "attempts", "next_attempt_at", "response_status", "last_error", "updated_at"}).
+						AddRow("pending", 0, now, nil, nil, now))
+			},
+		},
+		{
+			name: "still pending",
+			mockFn: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("UPDATE webhook_deliveries SET status = 'pending'").
+					WithArgs(9).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := setupWebhookTestDB(t)
+			defer repo.db.Close()
+
+			tt.mockFn(mock)
+
+			delivery := &models.WebhookDelivery{Base: models.Base{ID: 9}, Status: models.WebhookDeliveryFailed, Attempts: 8}
+			err := repo.ReplayWebhookDelivery(context.Background(), delivery)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+			assert.Equal(t, 0, delivery.Attempts)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
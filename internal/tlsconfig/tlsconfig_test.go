@@ -0,0 +1,45 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"inbox451/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		cfg, err := Load(config.TLSConfig{}, "mail.example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("self-signed", func(t *testing.T) {
+		cfg, err := Load(config.TLSConfig{Enabled: true, SelfSigned: true}, "mail.example.com")
+		require.NoError(t, err)
+		require.Len(t, cfg.Certificates, 1)
+
+		leaf := cfg.Certificates[0].Leaf
+		assert.NoError(t, leaf.VerifyHostname("mail.example.com"))
+		assert.NoError(t, leaf.VerifyHostname("localhost"))
+		assert.NoError(t, leaf.VerifyHostname("127.0.0.1"))
+
+		pool := x509.NewCertPool()
+		pool.AddCert(leaf)
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: "mail.example.com", Roots: pool})
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		_, err := Load(config.TLSConfig{Enabled: true}, "mail.example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("unreadable certificate files", func(t *testing.T) {
+		_, err := Load(config.TLSConfig{Enabled: true, CertFile: "missing.crt", KeyFile: "missing.key"}, "")
+		assert.Error(t, err)
+	})
+}